@@ -0,0 +1,126 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// name of the multipart form field carrying the uploaded file
+const attachmentFormField = "file"
+
+type AttachmentController struct {
+	attachmentService services.AttachmentService
+}
+
+func NewAttachmentController(attachmentService services.AttachmentService) *AttachmentController {
+	return &AttachmentController{attachmentService: attachmentService}
+}
+
+// function to stream the file part of the multipart upload to the service, the body is never buffered
+// so the size limit is enforced while the upload is read
+func (a *AttachmentController) AddAttachment(c *gin.Context) {
+	taskId := c.Param("id")
+	if len(strings.TrimSpace(taskId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
+		return
+	}
+	if c.ContentType() != gin.MIMEMultipartPOSTForm {
+		c.JSON(http.StatusUnsupportedMediaType, commons.ApiErrorResponse(apperrors.UnsupportedMediaType,
+			"Content-Type must be "+gin.MIMEMultipartPOSTForm, nil))
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid multipart payload", nil))
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid multipart payload", nil))
+			return
+		}
+		if part.FormName() != attachmentFormField {
+			part.Close()
+			continue
+		}
+
+		attachment, err := a.attachmentService.AddAttachment(c, taskId, &models.AttachmentUpload{Name: part.FileName(), Content: part}, version)
+		part.Close()
+		if err != nil {
+			respondError(c, err, "Failed to add attachment")
+			return
+		}
+		c.Header("Location", c.Request.URL.Path+"/"+attachment.ID.Hex())
+		c.Header("ETag", strconv.Quote(attachment.SHA256))
+		c.JSON(http.StatusCreated, attachment)
+		return
+	}
+	c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "The file part is required", nil))
+}
+
+// function to download the attachment, http.ServeContent answers Range, If-Range and If-None-Match requests
+func (a *AttachmentController) GetAttachment(c *gin.Context) {
+	taskId, attachmentId, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+
+	attachment, content, err := a.attachmentService.GetAttachment(c, taskId, attachmentId)
+	if err != nil {
+		respondError(c, err, "Failed to fetch attachment")
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Type", attachment.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("ETag", strconv.Quote(attachment.SHA256))
+	http.ServeContent(c.Writer, c.Request, attachment.Name, attachment.UploadedAt, content)
+}
+
+func (a *AttachmentController) DeleteAttachment(c *gin.Context) {
+	taskId, attachmentId, ok := attachmentParams(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := a.attachmentService.DeleteAttachment(c, taskId, attachmentId, version); err != nil {
+		respondError(c, err, "Failed to delete attachment")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// function to read the task and attachment ids of the path, writes a 400 and returns false when one is missing
+func attachmentParams(c *gin.Context) (string, string, bool) {
+	taskId, attachmentId := c.Param("id"), c.Param("attachmentId")
+	if len(strings.TrimSpace(taskId)) == 0 || len(strings.TrimSpace(attachmentId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID and attachment ID are required", nil))
+		return "", "", false
+	}
+	return taskId, attachmentId, true
+}
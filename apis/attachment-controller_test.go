@@ -0,0 +1,83 @@
+package apis
+
+import (
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type nopSeekCloser struct {
+	io.ReadSeeker
+}
+
+func (nopSeekCloser) Close() error {
+	return nil
+}
+
+var _ = Describe("Attachment API Controller", func() {
+
+	Describe("AddAttachment", func() {
+		It("requires a multipart payload", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/tasks/t1/attachments", bytes.NewBufferString(`{}`))
+			c.Request.Header.Set("Content-Type", "application/json")
+			c.Params = gin.Params{{Key: "id", Value: "t1"}}
+
+			NewAttachmentController(services.MockAttachmentService{}).AddAttachment(c)
+
+			Expect(rec.Code).To(Equal(http.StatusUnsupportedMediaType))
+		})
+
+		It("requires the file part", func() {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			Expect(writer.WriteField("note", "no file")).To(Succeed())
+			Expect(writer.Close()).To(Succeed())
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/tasks/t1/attachments", body)
+			c.Request.Header.Set("Content-Type", writer.FormDataContentType())
+			c.Params = gin.Params{{Key: "id", Value: "t1"}}
+
+			NewAttachmentController(services.MockAttachmentService{}).AddAttachment(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("GetAttachment", func() {
+		It("serves a range of the content", func() {
+			service := services.MockAttachmentService{
+				FakeGetAttachment: func(ctx context.Context, taskId string, attachmentId string) (*models.Attachment, io.ReadSeekCloser, error) {
+					attachment := &models.Attachment{Name: "server.log", ContentType: "text/plain", SHA256: "abc", UploadedAt: time.Now()}
+					return attachment, nopSeekCloser{strings.NewReader("0123456789")}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/tasks/t1/attachments/a1", nil)
+			c.Request.Header.Set("Range", "bytes=2-5")
+			c.Params = gin.Params{{Key: "id", Value: "t1"}, {Key: "attachmentId", Value: "a1"}}
+
+			NewAttachmentController(service).GetAttachment(c)
+
+			Expect(rec.Code).To(Equal(http.StatusPartialContent))
+			Expect(rec.Body.String()).To(Equal("2345"))
+			Expect(rec.Header().Get("Content-Range")).To(Equal("bytes 2-5/10"))
+			Expect(rec.Header().Get("Content-Disposition")).To(Equal(`attachment; filename=server.log`))
+		})
+	})
+})
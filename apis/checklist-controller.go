@@ -0,0 +1,124 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ChecklistController struct {
+	checklistService services.ChecklistService
+}
+
+func NewChecklistController(checklistService services.ChecklistService) *ChecklistController {
+	return &ChecklistController{checklistService: checklistService}
+}
+
+func (cl *ChecklistController) AddChecklistItem(c *gin.Context) {
+	taskId := c.Param("id")
+	if len(strings.TrimSpace(taskId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
+		return
+	}
+	var input *models.ChecklistItemInput
+	if err := c.ShouldBindJSON(&input); err != nil || input == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := cl.checklistService.AddChecklistItem(c, taskId, input, version)
+	if err != nil {
+		respondError(c, err, "Failed to add checklist item")
+		return
+	}
+	c.Header("ETag", formatETag(task.Version))
+	c.JSON(http.StatusCreated, task)
+}
+
+// function to change the text of an item or tick it off with {"done": true}
+func (cl *ChecklistController) UpdateChecklistItem(c *gin.Context) {
+	taskId, itemId, ok := checklistItemParams(c)
+	if !ok {
+		return
+	}
+	var change *models.ChecklistItemChange
+	if err := c.ShouldBindJSON(&change); err != nil || change == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := cl.checklistService.UpdateChecklistItem(c, taskId, itemId, change, version)
+	if err != nil {
+		respondError(c, err, "Failed to update checklist item")
+		return
+	}
+	c.Header("ETag", formatETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
+
+func (cl *ChecklistController) MoveChecklistItem(c *gin.Context) {
+	taskId, itemId, ok := checklistItemParams(c)
+	if !ok {
+		return
+	}
+	var move *models.ChecklistItemMove
+	if err := c.ShouldBindJSON(&move); err != nil || move == nil || move.Position == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "position is required", nil))
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := cl.checklistService.MoveChecklistItem(c, taskId, itemId, *move.Position, version)
+	if err != nil {
+		respondError(c, err, "Failed to move checklist item")
+		return
+	}
+	c.Header("ETag", formatETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
+
+func (cl *ChecklistController) RemoveChecklistItem(c *gin.Context) {
+	taskId, itemId, ok := checklistItemParams(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := cl.checklistService.RemoveChecklistItem(c, taskId, itemId, version)
+	if err != nil {
+		respondError(c, err, "Failed to remove checklist item")
+		return
+	}
+	c.Header("ETag", formatETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
+
+// function to read the task and item ids of the path, writes a 400 and returns false when one is missing
+func checklistItemParams(c *gin.Context) (string, string, bool) {
+	taskId := c.Param("id")
+	itemId := c.Param("itemId")
+	if len(strings.TrimSpace(taskId)) == 0 || len(strings.TrimSpace(itemId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID and item ID are required", nil))
+		return "", "", false
+	}
+	return taskId, itemId, true
+}
@@ -0,0 +1,74 @@
+package apis
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Checklist API Controller", func() {
+
+	Describe("AddChecklistItem", func() {
+		It("returns the task and its entity tag", func() {
+			service := services.MockChecklistService{
+				FakeAddChecklistItem: func(ctx context.Context, taskId string, input *models.ChecklistItemInput, version int64) (*models.Task, error) {
+					Expect(taskId).To(Equal("t1"))
+					Expect(input.Text).To(Equal("Build"))
+					Expect(version).To(Equal(int64(3)))
+					return &models.Task{Version: 4}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/tasks/t1/checklist", bytes.NewBufferString(`{"text":"Build"}`))
+			c.Request.Header.Set("If-Match", `"3"`)
+			c.Params = gin.Params{{Key: "id", Value: "t1"}}
+
+			NewChecklistController(service).AddChecklistItem(c)
+
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+			Expect(rec.Header().Get("ETag")).To(Equal(`"4"`))
+		})
+	})
+
+	Describe("UpdateChecklistItem", func() {
+		It("missing item", func() {
+			service := services.MockChecklistService{
+				FakeUpdateChecklistItem: func(ctx context.Context, taskId string, itemId string, change *models.ChecklistItemChange, version int64) (*models.Task, error) {
+					Expect(*change.Done).To(BeTrue())
+					return nil, apperrors.NewNotFoundError("checklist item i1 not found on task t1")
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/tasks/t1/checklist/i1", bytes.NewBufferString(`{"done":true}`))
+			c.Params = gin.Params{{Key: "id", Value: "t1"}, {Key: "itemId", Value: "i1"}}
+
+			NewChecklistController(service).UpdateChecklistItem(c)
+
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("MoveChecklistItem", func() {
+		It("missing position", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPut, "/tasks/t1/checklist/i1/position", bytes.NewBufferString(`{}`))
+			c.Params = gin.Params{{Key: "id", Value: "t1"}, {Key: "itemId", Value: "i1"}}
+
+			NewChecklistController(services.MockChecklistService{}).MoveChecklistItem(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
@@ -0,0 +1,122 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentController struct {
+	commentService services.CommentService
+}
+
+func NewCommentController(commentService services.CommentService) *CommentController {
+	return &CommentController{commentService: commentService}
+}
+
+func (cc *CommentController) GetComments(c *gin.Context) {
+	taskId := c.Param("id")
+	if len(strings.TrimSpace(taskId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
+		return
+	}
+	query, err := parseCommentQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, err.Error(), nil))
+		return
+	}
+
+	comments, err := cc.commentService.GetComments(c, taskId, query)
+	if err != nil {
+		respondError(c, err, "Failed to fetch comments")
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+func (cc *CommentController) AddComment(c *gin.Context) {
+	taskId := c.Param("id")
+	if len(strings.TrimSpace(taskId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
+		return
+	}
+	var comment *models.Comment
+	if err := c.ShouldBindJSON(&comment); err != nil || comment == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+
+	created, err := cc.commentService.AddComment(c, taskId, comment)
+	if err != nil {
+		respondError(c, err, "Failed to add comment")
+		return
+	}
+	c.Header("ETag", formatETag(created.Version))
+	c.JSON(http.StatusCreated, created)
+}
+
+func (cc *CommentController) UpdateComment(c *gin.Context) {
+	taskId, commentId, ok := commentParams(c)
+	if !ok {
+		return
+	}
+	var comment *models.Comment
+	if err := c.ShouldBindJSON(&comment); err != nil || comment == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	updated, err := cc.commentService.UpdateComment(c, taskId, commentId, comment, version)
+	if err != nil {
+		respondError(c, err, "Failed to update comment")
+		return
+	}
+	c.Header("ETag", formatETag(updated.Version))
+	c.JSON(http.StatusOK, updated)
+}
+
+func (cc *CommentController) DeleteComment(c *gin.Context) {
+	taskId, commentId, ok := commentParams(c)
+	if !ok {
+		return
+	}
+	if err := cc.commentService.DeleteComment(c, taskId, commentId); err != nil {
+		respondError(c, err, "Failed to delete comment")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// function to read the task and comment ids of the path, writes a 400 and returns false when one is missing
+func commentParams(c *gin.Context) (string, string, bool) {
+	taskId, commentId := c.Param("id"), c.Param("commentId")
+	if len(strings.TrimSpace(taskId)) == 0 || len(strings.TrimSpace(commentId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID and comment ID are required", nil))
+		return "", "", false
+	}
+	return taskId, commentId, true
+}
+
+// function to read the page of comments from the limit and cursor query parameters
+func parseCommentQuery(c *gin.Context) (*models.CommentQuery, error) {
+	query := &models.CommentQuery{Limit: models.DefaultCommentLimit, Cursor: c.Query("cursor")}
+	if limit := c.Query("limit"); len(limit) > 0 {
+		value, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || value < 1 || value > models.MaxCommentLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", models.MaxCommentLimit)
+		}
+		query.Limit = value
+	}
+	return query, nil
+}
@@ -0,0 +1,63 @@
+package apis
+
+import (
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Comment API Controller", func() {
+
+	Describe("AddComment", func() {
+		It("invalid payload", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/tasks/t1/comments", bytes.NewBufferString(`not json`))
+			c.Params = gin.Params{{Key: "id", Value: "t1"}}
+
+			NewCommentController(services.MockCommentService{}).AddComment(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("returns the comment and its entity tag", func() {
+			service := services.MockCommentService{
+				FakeAddComment: func(ctx context.Context, taskId string, comment *models.Comment) (*models.Comment, error) {
+					Expect(taskId).To(Equal("t1"))
+					comment.Version = 1
+					return comment, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/tasks/t1/comments", bytes.NewBufferString(`{"body":"Looks good"}`))
+			c.Params = gin.Params{{Key: "id", Value: "t1"}}
+
+			NewCommentController(service).AddComment(c)
+
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+			Expect(rec.Header().Get("ETag")).To(Equal(`"1"`))
+		})
+	})
+
+	Describe("GetComments", func() {
+		It("invalid limit", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/tasks/t1/comments?limit=0", nil)
+			c.Params = gin.Params{{Key: "id", Value: "t1"}}
+
+			NewCommentController(services.MockCommentService{}).GetComments(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+})
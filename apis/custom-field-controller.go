@@ -0,0 +1,107 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomFieldController struct {
+	customFieldService services.CustomFieldService
+}
+
+func NewCustomFieldController(customFieldService services.CustomFieldService) *CustomFieldController {
+	return &CustomFieldController{customFieldService: customFieldService}
+}
+
+func (f *CustomFieldController) GetCustomFields(c *gin.Context) {
+	fields, err := f.customFieldService.GetCustomFields(c)
+	if err != nil {
+		respondError(c, err, "Failed to fetch custom fields")
+		return
+	}
+	c.JSON(http.StatusOK, fields)
+}
+
+func (f *CustomFieldController) GetCustomFieldById(c *gin.Context) {
+	fieldId, ok := customFieldIdParam(c)
+	if !ok {
+		return
+	}
+	field, err := f.customFieldService.GetCustomFieldById(c, fieldId)
+	if err != nil {
+		respondError(c, err, "Failed to fetch custom field")
+		return
+	}
+	c.JSON(http.StatusOK, field)
+}
+
+func (f *CustomFieldController) CreateCustomField(c *gin.Context) {
+	var field *models.CustomField
+	if err := c.ShouldBindJSON(&field); err != nil || field == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	if len(strings.TrimSpace(field.Key)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Key is required", nil))
+		return
+	}
+	if len(strings.TrimSpace(field.Type)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Type is required", nil))
+		return
+	}
+
+	created, err := f.customFieldService.CreateCustomField(c, field)
+	if err != nil {
+		respondError(c, err, "Failed to create custom field")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (f *CustomFieldController) UpdateCustomField(c *gin.Context) {
+	fieldId, ok := customFieldIdParam(c)
+	if !ok {
+		return
+	}
+	var field *models.CustomField
+	if err := c.ShouldBindJSON(&field); err != nil || field == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+
+	updated, err := f.customFieldService.UpdateCustomField(c, field, fieldId)
+	if err != nil {
+		respondError(c, err, "Failed to update custom field")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// function to delete the field, the tasks lose their value for it
+func (f *CustomFieldController) DeleteCustomField(c *gin.Context) {
+	fieldId, ok := customFieldIdParam(c)
+	if !ok {
+		return
+	}
+	if err := f.customFieldService.DeleteCustomField(c, fieldId); err != nil {
+		respondError(c, err, "Failed to delete custom field")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// function to read the custom field id of the path, writes a 400 and returns false when it is missing
+func customFieldIdParam(c *gin.Context) (string, bool) {
+	fieldId := c.Param("id")
+	if len(strings.TrimSpace(fieldId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Custom field ID is required", nil))
+		return "", false
+	}
+	return fieldId, true
+}
@@ -0,0 +1,66 @@
+package apis
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Custom Field API Controller", func() {
+
+	Describe("CreateCustomField", func() {
+		It("type missing", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/custom-fields", bytes.NewBufferString(`{"key":"points","name":"Points"}`))
+
+			NewCustomFieldController(services.MockCustomFieldService{}).CreateCustomField(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("reports a key in use", func() {
+			service := services.MockCustomFieldService{
+				FakeCreateCustomField: func(ctx context.Context, field *models.CustomField) (*models.CustomField, error) {
+					Expect(field.Type).To(Equal(models.CustomFieldNumber))
+					return nil, apperrors.NewConflictError("custom field key points is already in use", nil)
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/custom-fields", bytes.NewBufferString(`{"key":"points","name":"Points","type":"number"}`))
+
+			NewCustomFieldController(service).CreateCustomField(c)
+
+			Expect(rec.Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Describe("DeleteCustomField", func() {
+		It("valid", func() {
+			service := services.MockCustomFieldService{
+				FakeDeleteCustomField: func(ctx context.Context, fieldId string) error {
+					Expect(fieldId).To(Equal("f1"))
+					return nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/custom-fields/f1", nil)
+			c.Params = gin.Params{{Key: "id", Value: "f1"}}
+
+			NewCustomFieldController(service).DeleteCustomField(c)
+
+			Expect(c.Writer.Status()).To(Equal(http.StatusNoContent))
+		})
+	})
+})
@@ -0,0 +1,36 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errorStatus = map[apperrors.Code]int{
+	apperrors.NotFound:   http.StatusNotFound,
+	apperrors.InvalidID:  http.StatusBadRequest,
+	apperrors.BadRequest: http.StatusBadRequest,
+	apperrors.Conflict:   http.StatusConflict,
+	apperrors.Validation: http.StatusUnprocessableEntity,
+	apperrors.Forbidden:  http.StatusForbidden,
+
+	apperrors.PreconditionFailed:   http.StatusPreconditionFailed,
+	apperrors.UnsupportedMediaType: http.StatusUnsupportedMediaType,
+	apperrors.PayloadTooLarge:      http.StatusRequestEntityTooLarge,
+}
+
+// function to write the error response for a service error
+// errors without a known code are reported as 500 with the fallback message
+func respondError(c *gin.Context, err error, fallbackMessage string) {
+	var appErr *apperrors.AppError
+	if errors.As(err, &appErr) {
+		if status, ok := errorStatus[appErr.Code]; ok {
+			c.JSON(status, commons.ApiErrorResponse(appErr.Code, appErr.Message, appErr.Details))
+			return
+		}
+	}
+	c.JSON(http.StatusInternalServerError, commons.ApiErrorResponse(apperrors.Internal, fallbackMessage, nil))
+}
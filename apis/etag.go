@@ -0,0 +1,77 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// function to format the task version as a strong entity tag
+func formatETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// function to format the entity tag of a task read on its own. the rollup of the subtasks and the comment
+// count are computed on the read and change without a version bump, so they follow the version in the tag
+func formatTaskETag(task *models.Task) string {
+	tag := strconv.FormatInt(task.Version, 10)
+	if task.Rollup != nil && task.Rollup.TotalSubtasks > 0 {
+		tag += fmt.Sprintf("-s%d.%d", task.Rollup.CompletedSubtasks, task.Rollup.TotalSubtasks)
+	}
+	if task.CommentCount != nil && *task.CommentCount > 0 {
+		tag += fmt.Sprintf("-c%d", *task.CommentCount)
+	}
+	return strconv.Quote(tag)
+}
+
+// function to read the If-Match header as the expected task version, 0 when there is no precondition.
+// the computed parts of a tag from formatTaskETag are ignored, only the stored task is written.
+// a tag that cannot be a task version can never match, so the request fails with 412
+func parseIfMatch(c *gin.Context) (int64, bool) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if len(header) == 0 || header == "*" {
+		return 0, true
+	}
+	value, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, false
+	}
+	value, _, _ = strings.Cut(value, "-")
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+	return version, true
+}
+
+// function to read the If-Match precondition, writing the 412 response when it is unusable
+func ifMatchVersion(c *gin.Context) (int64, bool) {
+	version, ok := parseIfMatch(c)
+	if !ok {
+		c.JSON(http.StatusPreconditionFailed, commons.ApiErrorResponse(apperrors.PreconditionFailed, "If-Match does not match the task version", nil))
+	}
+	return version, ok
+}
+
+// function to check the If-None-Match header against the current entity tag, using weak comparison
+func matchesIfNoneMatch(c *gin.Context, etag string) bool {
+	header := strings.TrimSpace(c.GetHeader("If-None-Match"))
+	if len(header) == 0 {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
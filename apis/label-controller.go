@@ -0,0 +1,62 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabelController struct {
+	labelService services.LabelService
+}
+
+func NewLabelController(labelService services.LabelService) *LabelController {
+	return &LabelController{labelService: labelService}
+}
+
+// function to list the labels in use for autocomplete, the prefix query parameter narrows them down
+func (l *LabelController) GetLabels(c *gin.Context) {
+	query := &models.LabelQuery{Prefix: c.Query("prefix"), Limit: models.DefaultLabelLimit}
+	if limit := c.Query("limit"); len(limit) > 0 {
+		value, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || value < 1 || value > models.MaxLabelLimit {
+			c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest,
+				fmt.Sprintf("limit must be between 1 and %d", models.MaxLabelLimit), nil))
+			return
+		}
+		query.Limit = value
+	}
+
+	labels, err := l.labelService.GetLabels(c, query)
+	if err != nil {
+		respondError(c, err, "Failed to fetch labels")
+		return
+	}
+	c.JSON(http.StatusOK, labels)
+}
+
+func (l *LabelController) RenameLabel(c *gin.Context) {
+	var rename *models.LabelRename
+	if err := c.ShouldBindJSON(&rename); err != nil || rename == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	if len(strings.TrimSpace(rename.From)) == 0 || len(strings.TrimSpace(rename.To)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "from and to are required", nil))
+		return
+	}
+
+	result, err := l.labelService.RenameLabel(c, rename)
+	if err != nil {
+		respondError(c, err, "Failed to rename label")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
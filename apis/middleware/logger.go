@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"TaskSvc/commons/apploggers"
+
+	"github.com/gin-gonic/gin"
+)
+
+const CorrelationIdHeader = "X-Correlation-Id"
+
+// function to attach a logger with the request correlationid to the request context
+// the engine must have ContextWithFallback enabled for services to find it through the gin context
+func RequestLogger(c *gin.Context) {
+	ctx, _ := apploggers.NewLoggerWithCorrelationid(c.Request.Context(), c.GetHeader(CorrelationIdHeader))
+	c.Request = c.Request.WithContext(ctx)
+	c.Header(CorrelationIdHeader, apploggers.GetCorrelationId(ctx))
+	c.Next()
+}
@@ -1,18 +1,32 @@
 package middleware
 
 import (
+	"TaskSvc/commons/appauth"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func AuthenticateJWT(c *gin.Context) {
-	token := c.GetHeader("Authorization")
-	if token == "" || !strings.HasPrefix(token, "Bearer ") {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token is required"})
-		c.Abort()
-		return
+func AuthenticateJWT(verifier appauth.TokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader("Authorization")
+		if token == "" || !strings.HasPrefix(token, "Bearer ") {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization token is required"})
+			c.Abort()
+			return
+		}
+
+		claims, err := verifier.Verify(c, strings.TrimSpace(strings.TrimPrefix(token, "Bearer ")))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid authorization token"})
+			c.Abort()
+			return
+		}
+
+		subject, _ := claims.GetSubject()
+		c.Set(appauth.SubjectContextKey, subject)
+		c.Set(appauth.ClaimsContextKey, claims)
+		c.Next()
 	}
-	c.Next()
 }
@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apploggers"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const testSecret = "test-secret"
+
+func signToken(method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if len(kid) > 0 {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	Expect(err).NotTo(HaveOccurred())
+	return signed
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub": "user-1",
+		"iss": "task-svc-tests",
+		"aud": "task-svc",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func encodeInt(value *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(value.Bytes())
+}
+
+func writeJWKS(path string, keys ...map[string]string) {
+	pbytes, err := json.Marshal(map[string]interface{}{"keys": keys})
+	Expect(err).NotTo(HaveOccurred())
+	Expect(os.WriteFile(path, pbytes, 0600)).To(Succeed())
+}
+
+func rsaJWK(kid string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kid": kid, "kty": "RSA", "alg": "RS256", "use": "sig",
+		"n": encodeInt(key.N), "e": encodeInt(big.NewInt(int64(key.E))),
+	}
+}
+
+func authenticate(verifier appauth.TokenVerifier, header string) (*httptest.ResponseRecorder, *gin.Context) {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+	if len(header) > 0 {
+		c.Request.Header.Set("Authorization", header)
+	}
+	AuthenticateJWT(verifier)(c)
+	return rec, c
+}
+
+var _ = Describe("AuthenticateJWT", func() {
+	var (
+		jwksFile string
+		rsaKey   *rsa.PrivateKey
+		verifier appauth.TokenVerifier
+	)
+
+	BeforeEach(func() {
+		var err error
+		rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		jwksFile = filepath.Join(GinkgoT().TempDir(), "jwks.json")
+		writeJWKS(jwksFile, rsaJWK("rsa-1", rsaKey))
+
+		verifier, err = appauth.NewTokenVerifier(appauth.VerifierConfig{
+			HmacSecret: testSecret,
+			JwksFile:   jwksFile,
+			Issuer:     "task-svc-tests",
+			Audience:   "task-svc",
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("valid HS256 token", func() {
+		token := signToken(jwt.SigningMethodHS256, []byte(testSecret), "", validClaims())
+		rec, c := authenticate(verifier, "Bearer "+token)
+
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(c.IsAborted()).To(BeFalse())
+		Expect(appauth.GetSubject(c)).To(Equal("user-1"))
+		Expect(appauth.GetClaims(c)["aud"]).To(Equal("task-svc"))
+	})
+
+	It("valid RS256 token from jwks file", func() {
+		token := signToken(jwt.SigningMethodRS256, rsaKey, "rsa-1", validClaims())
+		_, c := authenticate(verifier, "Bearer "+token)
+
+		Expect(c.IsAborted()).To(BeFalse())
+		Expect(appauth.GetSubject(c)).To(Equal("user-1"))
+	})
+
+	It("valid ES256 token from jwks file", func() {
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		Expect(err).NotTo(HaveOccurred())
+		writeJWKS(jwksFile, rsaJWK("rsa-1", rsaKey), map[string]string{
+			"kid": "ec-1", "kty": "EC", "crv": "P-256",
+			"x": encodeInt(ecKey.X), "y": encodeInt(ecKey.Y),
+		})
+
+		token := signToken(jwt.SigningMethodES256, ecKey, "ec-1", validClaims())
+		_, c := authenticate(verifier, "Bearer "+token)
+
+		Expect(c.IsAborted()).To(BeFalse())
+	})
+
+	It("picks up rotated keys without a restart", func() {
+		rotated, err := rsa.GenerateKey(rand.Reader, 2048)
+		Expect(err).NotTo(HaveOccurred())
+		token := signToken(jwt.SigningMethodRS256, rotated, "rsa-2", validClaims())
+
+		_, c := authenticate(verifier, "Bearer "+token)
+		Expect(c.IsAborted()).To(BeTrue())
+
+		writeJWKS(jwksFile, rsaJWK("rsa-2", rotated))
+		later := time.Now().Add(time.Minute)
+		Expect(os.Chtimes(jwksFile, later, later)).To(Succeed())
+
+		_, c = authenticate(verifier, "Bearer "+token)
+		Expect(c.IsAborted()).To(BeFalse())
+	})
+
+	It("missing token", func() {
+		rec, c := authenticate(verifier, "")
+
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		Expect(c.IsAborted()).To(BeTrue())
+	})
+
+	It("invalid signature", func() {
+		token := signToken(jwt.SigningMethodHS256, []byte("other-secret"), "", validClaims())
+		rec, c := authenticate(verifier, "Bearer "+token)
+
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+		Expect(c.IsAborted()).To(BeTrue())
+	})
+
+	It("unsigned token", func() {
+		token := signToken(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", validClaims())
+		rec, _ := authenticate(verifier, "Bearer "+token)
+
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("expired token", func() {
+		claims := validClaims()
+		claims["exp"] = time.Now().Add(-time.Hour).Unix()
+		token := signToken(jwt.SigningMethodHS256, []byte(testSecret), "", claims)
+		rec, _ := authenticate(verifier, "Bearer "+token)
+
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("token without exp", func() {
+		claims := validClaims()
+		delete(claims, "exp")
+		token := signToken(jwt.SigningMethodHS256, []byte(testSecret), "", claims)
+		rec, _ := authenticate(verifier, "Bearer "+token)
+
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("token not yet valid", func() {
+		claims := validClaims()
+		claims["nbf"] = time.Now().Add(time.Hour).Unix()
+		token := signToken(jwt.SigningMethodHS256, []byte(testSecret), "", claims)
+		rec, _ := authenticate(verifier, "Bearer "+token)
+
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("wrong issuer", func() {
+		claims := validClaims()
+		claims["iss"] = "someone-else"
+		token := signToken(jwt.SigningMethodHS256, []byte(testSecret), "", claims)
+		rec, _ := authenticate(verifier, "Bearer "+token)
+
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("wrong audience", func() {
+		claims := validClaims()
+		claims["aud"] = "another-service"
+		token := signToken(jwt.SigningMethodHS256, []byte(testSecret), "", claims)
+		rec, _ := authenticate(verifier, "Bearer "+token)
+
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("token without subject", func() {
+		claims := validClaims()
+		delete(claims, "sub")
+		token := signToken(jwt.SigningMethodHS256, []byte(testSecret), "", claims)
+		rec, _ := authenticate(verifier, "Bearer "+token)
+
+		Expect(rec.Code).To(Equal(http.StatusUnauthorized))
+	})
+})
+
+var _ = Describe("RequestLogger", func() {
+	It("attaches a logger with the request correlationid", func() {
+		rec := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(rec)
+		c.Request = httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+		c.Request.Header.Set(CorrelationIdHeader, "request-1")
+
+		RequestLogger(c)
+
+		Expect(apploggers.GetLoggerWithCorrelationid(c.Request.Context())).NotTo(BeNil())
+		Expect(apploggers.GetCorrelationId(c.Request.Context())).To(Equal("request-1"))
+		Expect(rec.Header().Get(CorrelationIdHeader)).To(Equal("request-1"))
+	})
+})
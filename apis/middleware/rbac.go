@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResolveRoles sets the roles of the authenticated caller and the permissions they grant,
+// it runs after AuthenticateJWT and, on workspace routes, after ResolveWorkspace whose membership narrows the permissions
+func ResolveRoles(policy *appauth.Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		roles := policy.RolesFor(appauth.GetSubject(c), appauth.GetClaims(c))
+		c.Set(appauth.RolesContextKey, roles)
+		c.Set(appauth.PermissionsContextKey, policy.PermissionsIn(roles, appauth.GetWorkspaceRoles(c)))
+		c.Next()
+	}
+}
+
+// Require rejects the request with 403 unless the roles of the caller grant the permission
+func Require(permission appauth.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !appauth.HasPermission(c, permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, commons.ApiErrorResponse(apperrors.Forbidden,
+				fmt.Sprintf("permission %s is required", permission),
+				map[string]interface{}{"permission": permission}))
+			return
+		}
+		c.Next()
+	}
+}
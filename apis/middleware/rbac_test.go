@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// function to run ResolveRoles and Require for a caller with the claims
+func authorize(policy *appauth.Policy, claims jwt.MapClaims, permission appauth.Permission) (*httptest.ResponseRecorder, *gin.Context) {
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	subject, _ := claims.GetSubject()
+	c.Set(appauth.SubjectContextKey, subject)
+	c.Set(appauth.ClaimsContextKey, claims)
+	ResolveRoles(policy)(c)
+	if !c.IsAborted() {
+		Require(permission)(c)
+	}
+	return rec, c
+}
+
+var _ = Describe("RBAC", func() {
+	policy := appauth.DefaultPolicy()
+
+	It("gives callers without roles the default member role", func() {
+		_, c := authorize(policy, jwt.MapClaims{"sub": "user-1"}, appauth.PermissionTaskWrite)
+
+		Expect(c.IsAborted()).To(BeFalse())
+		Expect(appauth.GetRoles(c)).To(Equal([]string{appauth.MemberRole}))
+		Expect(appauth.HasPermission(c, appauth.PermissionTaskManage)).To(BeFalse())
+	})
+
+	It("reads the roles claim as a list or a string", func() {
+		_, c := authorize(policy, jwt.MapClaims{"sub": "user-1", "roles": []interface{}{"viewer", "unknown"}}, appauth.PermissionTaskRead)
+		Expect(appauth.GetRoles(c)).To(Equal([]string{appauth.ViewerRole}))
+
+		_, c = authorize(policy, jwt.MapClaims{"sub": "user-1", "roles": "viewer maintainer"}, appauth.PermissionTaskRead)
+		Expect(appauth.GetRoles(c)).To(Equal([]string{appauth.ViewerRole, appauth.MaintainerRole}))
+		Expect(appauth.HasPermission(c, appauth.PermissionTaskManage)).To(BeTrue())
+	})
+
+	It("grants admins every permission", func() {
+		_, c := authorize(policy, jwt.MapClaims{"sub": "user-1", "roles": "admin"}, appauth.Permission("anything:at-all"))
+
+		Expect(c.IsAborted()).To(BeFalse())
+	})
+
+	It("rejects a missing permission with 403 and the permission", func() {
+		rec, c := authorize(policy, jwt.MapClaims{"sub": "user-1", "roles": "viewer"}, appauth.PermissionTaskDelete)
+
+		Expect(c.IsAborted()).To(BeTrue())
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+		var response commons.ApiErrorResponsePayload
+		Expect(json.Unmarshal(rec.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.Message).To(Equal("permission task:delete is required"))
+		Expect(response.AdditionalInfo["permission"]).To(Equal("task:delete"))
+	})
+
+	Describe("policy file", func() {
+		writePolicy := func(content string) string {
+			path := filepath.Join(GinkgoT().TempDir(), "policy.json")
+			Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+			return path
+		}
+
+		It("assigns roles to subjects and reads a custom roles claim", func() {
+			filePolicy, err := appauth.LoadPolicy(writePolicy(`{
+				"roles": {"reader": ["task:read"], "owner": ["*"]},
+				"subjects": {"user-2": ["owner"]},
+				"defaultRoles": ["reader"],
+				"rolesClaim": "groups"
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, c := authorize(filePolicy, jwt.MapClaims{"sub": "user-1", "roles": "owner"}, appauth.PermissionTaskWrite)
+			Expect(c.IsAborted()).To(BeTrue())
+
+			_, c = authorize(filePolicy, jwt.MapClaims{"sub": "user-1", "groups": []interface{}{"owner"}}, appauth.PermissionTaskWrite)
+			Expect(c.IsAborted()).To(BeFalse())
+
+			_, c = authorize(filePolicy, jwt.MapClaims{"sub": "user-2"}, appauth.PermissionTaskDelete)
+			Expect(c.IsAborted()).To(BeFalse())
+		})
+
+		It("loads the shipped policy file", func() {
+			filePolicy, err := appauth.LoadPolicy("../../configs/policy.json")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filePolicy).To(Equal(appauth.DefaultPolicy()))
+		})
+
+		DescribeTable("rejects invalid policy files",
+			func(content string, message string) {
+				_, err := appauth.LoadPolicy(writePolicy(content))
+				Expect(err).To(MatchError(ContainSubstring(message)))
+			},
+			Entry("not json", `roles: []`, "invalid policy file"),
+			Entry("no roles", `{}`, "no roles"),
+			Entry("unknown default role", `{"roles": {"reader": ["task:read"]}, "defaultRoles": ["member"]}`, "default role member is not defined"),
+			Entry("unknown subject role", `{"roles": {"reader": ["task:read"]}, "subjects": {"user-1": ["admin"]}}`, "role admin of subject user-1 is not defined"),
+		)
+	})
+})
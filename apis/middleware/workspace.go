@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	WorkspaceHeader = "X-Workspace-Id"
+	// WorkspaceClaim pins the token to a workspace, the header cannot select another one
+	WorkspaceClaim = "workspace"
+)
+
+// WorkspaceResolver looks up the role of a subject in a workspace, empty when the subject is not a member
+// and a NOT_FOUND error when the workspace does not exist
+type WorkspaceResolver interface {
+	GetMembershipRole(ctx context.Context, workspaceId string, subject string) (string, error)
+}
+
+// ResolveWorkspace sets the workspace the request acts in, from the workspace claim of the token or the
+// X-Workspace-Id header, and the role of the membership of the caller in it.
+// It runs after AuthenticateJWT and before ResolveRoles, requests without a workspace act in the default workspace
+func ResolveWorkspace(policy *appauth.Policy, resolver WorkspaceResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claimed, _ := appauth.GetClaims(c)[WorkspaceClaim].(string)
+		requested := c.GetHeader(WorkspaceHeader)
+		if len(claimed) > 0 && len(requested) > 0 && claimed != requested {
+			c.AbortWithStatusJSON(http.StatusForbidden, commons.ApiErrorResponse(apperrors.Forbidden,
+				fmt.Sprintf("the token is limited to workspace %s", claimed), map[string]interface{}{"workspace": claimed}))
+			return
+		}
+		workspace := claimed
+		if len(workspace) == 0 {
+			workspace = requested
+		}
+		if len(workspace) == 0 || workspace == appauth.DefaultWorkspace {
+			c.Set(appauth.WorkspaceContextKey, appauth.DefaultWorkspace)
+			c.Next()
+			return
+		}
+
+		subject := appauth.GetSubject(c)
+		role, err := resolver.GetMembershipRole(c, workspace, subject)
+		if err != nil {
+			if apperrors.Is(err, apperrors.NotFound) {
+				c.AbortWithStatusJSON(http.StatusNotFound, commons.ApiErrorResponse(apperrors.NotFound,
+					fmt.Sprintf("workspace %s not found", workspace), nil))
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusInternalServerError, commons.ApiErrorResponse(apperrors.Internal,
+				"Failed to resolve the workspace", nil))
+			return
+		}
+		if len(role) == 0 && !grants(policy, policy.RolesFor(subject, appauth.GetClaims(c)), appauth.PermissionWorkspaceAdmin) {
+			c.AbortWithStatusJSON(http.StatusForbidden, commons.ApiErrorResponse(apperrors.Forbidden,
+				fmt.Sprintf("%s is not a member of workspace %s", subject, workspace), map[string]interface{}{"workspace": workspace}))
+			return
+		}
+
+		c.Set(appauth.WorkspaceContextKey, workspace)
+		if len(role) > 0 {
+			c.Set(appauth.WorkspaceRolesContextKey, []string{role})
+		}
+		c.Next()
+	}
+}
+
+// function to check the roles grant the permission, before ResolveRoles has put them on the context
+func grants(policy *appauth.Policy, roles []string, permission appauth.Permission) bool {
+	for _, granted := range policy.PermissionsOf(roles) {
+		if granted == permission || granted == appauth.PermissionAll {
+			return true
+		}
+	}
+	return false
+}
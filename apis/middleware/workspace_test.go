@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeResolver map[string]map[string]string
+
+func (f fakeResolver) GetMembershipRole(ctx context.Context, workspaceId string, subject string) (string, error) {
+	members, ok := f[workspaceId]
+	if !ok {
+		return "", apperrors.NewNotFoundError("workspace " + workspaceId + " not found")
+	}
+	return members[subject], nil
+}
+
+// function to run ResolveWorkspace and ResolveRoles for a caller with the claims and the workspace header
+func resolveWorkspace(claims jwt.MapClaims, header string) (*httptest.ResponseRecorder, *gin.Context) {
+	policy := appauth.DefaultPolicy()
+	resolver := fakeResolver{"team-a": {"user-1": appauth.ViewerRole, "user-3": appauth.OwnerRole}}
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/tasks", nil)
+	if len(header) > 0 {
+		c.Request.Header.Set(WorkspaceHeader, header)
+	}
+	subject, _ := claims.GetSubject()
+	c.Set(appauth.SubjectContextKey, subject)
+	c.Set(appauth.ClaimsContextKey, claims)
+	ResolveWorkspace(policy, resolver)(c)
+	if !c.IsAborted() {
+		ResolveRoles(policy)(c)
+	}
+	return rec, c
+}
+
+var _ = Describe("ResolveWorkspace", func() {
+	It("acts in the default workspace without a header or claim", func() {
+		_, c := resolveWorkspace(jwt.MapClaims{"sub": "user-1"}, "")
+
+		Expect(c.IsAborted()).To(BeFalse())
+		Expect(appauth.GetWorkspace(c)).To(Equal(appauth.DefaultWorkspace))
+		Expect(appauth.GetRoles(c)).To(Equal([]string{appauth.MemberRole}))
+	})
+
+	It("narrows the permissions to the role of the membership in the workspace of the header", func() {
+		_, c := resolveWorkspace(jwt.MapClaims{"sub": "user-1"}, "team-a")
+
+		Expect(c.IsAborted()).To(BeFalse())
+		Expect(appauth.GetWorkspace(c)).To(Equal("team-a"))
+		Expect(appauth.GetWorkspaceRoles(c)).To(Equal([]string{appauth.ViewerRole}))
+		Expect(appauth.HasPermission(c, appauth.PermissionTaskRead)).To(BeTrue())
+		Expect(appauth.HasPermission(c, appauth.PermissionTaskWrite)).To(BeFalse())
+	})
+
+	It("never grants an owner more than the roles of the caller", func() {
+		_, c := resolveWorkspace(jwt.MapClaims{"sub": "user-3"}, "team-a")
+
+		Expect(c.IsAborted()).To(BeFalse())
+		Expect(appauth.HasPermission(c, appauth.PermissionTaskWrite)).To(BeTrue())
+		Expect(appauth.HasPermission(c, appauth.PermissionLabelManage)).To(BeFalse())
+		Expect(appauth.HasPermission(c, appauth.PermissionTaskManage)).To(BeFalse())
+	})
+
+	It("reads the workspace from the token claim", func() {
+		_, c := resolveWorkspace(jwt.MapClaims{"sub": "user-1", WorkspaceClaim: "team-a"}, "")
+
+		Expect(appauth.GetWorkspace(c)).To(Equal("team-a"))
+	})
+
+	It("rejects a header selecting another workspace than the claim", func() {
+		rec, c := resolveWorkspace(jwt.MapClaims{"sub": "user-1", WorkspaceClaim: "team-a"}, "team-b")
+
+		Expect(c.IsAborted()).To(BeTrue())
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("rejects non members and unknown workspaces", func() {
+		rec, _ := resolveWorkspace(jwt.MapClaims{"sub": "user-2"}, "team-a")
+		Expect(rec.Code).To(Equal(http.StatusForbidden))
+
+		rec, _ = resolveWorkspace(jwt.MapClaims{"sub": "user-1"}, "team-b")
+		Expect(rec.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("lets callers managing every workspace in without a membership", func() {
+		_, c := resolveWorkspace(jwt.MapClaims{"sub": "root", "roles": "admin"}, "team-a")
+
+		Expect(c.IsAborted()).To(BeFalse())
+		Expect(appauth.GetWorkspace(c)).To(Equal("team-a"))
+	})
+})
@@ -0,0 +1,149 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProjectController struct {
+	projectService services.ProjectService
+	taskService    services.TaskService
+}
+
+func NewProjectController(projectService services.ProjectService, taskService services.TaskService) *ProjectController {
+	return &ProjectController{projectService: projectService, taskService: taskService}
+}
+
+func (p *ProjectController) GetProjects(c *gin.Context) {
+	projects, err := p.projectService.GetProjects(c)
+	if err != nil {
+		respondError(c, err, "Failed to fetch projects")
+		return
+	}
+	c.JSON(http.StatusOK, projects)
+}
+
+func (p *ProjectController) GetProjectById(c *gin.Context) {
+	projectId, ok := projectIdParam(c)
+	if !ok {
+		return
+	}
+	project, err := p.projectService.GetProjectById(c, projectId)
+	if err != nil {
+		respondError(c, err, "Failed to fetch project")
+		return
+	}
+	c.JSON(http.StatusOK, project)
+}
+
+func (p *ProjectController) CreateProject(c *gin.Context) {
+	var project *models.Project
+	if err := c.ShouldBindJSON(&project); err != nil || project == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	if len(strings.TrimSpace(project.Key)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Key is required", nil))
+		return
+	}
+	if len(strings.TrimSpace(project.Name)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Name is required", nil))
+		return
+	}
+
+	created, err := p.projectService.CreateProject(c, project)
+	if err != nil {
+		respondError(c, err, "Failed to create project")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (p *ProjectController) UpdateProject(c *gin.Context) {
+	projectId, ok := projectIdParam(c)
+	if !ok {
+		return
+	}
+	var project *models.Project
+	if err := c.ShouldBindJSON(&project); err != nil || project == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	if len(strings.TrimSpace(project.Name)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Name is required", nil))
+		return
+	}
+
+	updated, err := p.projectService.UpdateProject(c, project, projectId)
+	if err != nil {
+		respondError(c, err, "Failed to update project")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (p *ProjectController) DeleteProject(c *gin.Context) {
+	projectId, ok := projectIdParam(c)
+	if !ok {
+		return
+	}
+	if err := p.projectService.DeleteProjectById(c, projectId); err != nil {
+		respondError(c, err, "Failed to delete project")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// function to list the tasks of the project, with the filters, sort and paging of GET /tasks
+func (p *ProjectController) GetProjectTasks(c *gin.Context) {
+	projectId, ok := projectIdParam(c)
+	if !ok {
+		return
+	}
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, err.Error(), nil))
+		return
+	}
+	if _, err := p.projectService.GetProjectById(c, projectId); err != nil {
+		respondError(c, err, "Failed to fetch project")
+		return
+	}
+
+	query.ProjectID = projectId
+	tasks, err := p.taskService.GetTasks(c, query)
+	if err != nil {
+		respondError(c, err, "Failed to fetch tasks")
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+func (p *ProjectController) GetProjectWorkflow(c *gin.Context) {
+	projectId, ok := projectIdParam(c)
+	if !ok {
+		return
+	}
+	definition, err := p.projectService.GetWorkflow(c, projectId)
+	if err != nil {
+		respondError(c, err, "Failed to fetch workflow")
+		return
+	}
+	c.JSON(http.StatusOK, definition)
+}
+
+// function to read the project id of the path, writes a 400 and returns false when it is missing
+func projectIdParam(c *gin.Context) (string, bool) {
+	projectId := c.Param("id")
+	if len(strings.TrimSpace(projectId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Project ID is required", nil))
+		return "", false
+	}
+	return projectId, true
+}
@@ -0,0 +1,72 @@
+package apis
+
+import (
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Project API Controller", func() {
+
+	Describe("CreateProject", func() {
+		It("key missing", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/projects", bytes.NewBufferString(`{"name":"Operations"}`))
+
+			NewProjectController(services.MockProjectService{}, services.MockTaskService{}).CreateProject(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("GetProjectTasks", func() {
+		It("scopes the query to the project", func() {
+			pservice := services.MockProjectService{
+				FakeGetProjectById: func(ctx context.Context, projectId string) (*models.Project, error) {
+					return &models.Project{Key: "OPS"}, nil
+				},
+			}
+			tservice := services.MockTaskService{
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
+					Expect(query.ProjectID).To(Equal("p1"))
+					Expect(query.Status).To(Equal([]string{"New"}))
+					return &models.TaskList{}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/projects/p1/tasks?status=New", nil)
+			c.Params = gin.Params{{Key: "id", Value: "p1"}}
+
+			NewProjectController(pservice, tservice).GetProjectTasks(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+		})
+
+		It("error fetching project", func() {
+			pservice := services.MockProjectService{
+				FakeGetProjectById: func(ctx context.Context, projectId string) (*models.Project, error) {
+					return nil, fmt.Errorf("database error")
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/projects/p1/tasks", nil)
+			c.Params = gin.Params{{Key: "id", Value: "p1"}}
+
+			NewProjectController(pservice, services.MockTaskService{}).GetProjectTasks(c)
+
+			Expect(rec.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
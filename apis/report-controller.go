@@ -0,0 +1,126 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const csvContentType = "text/csv"
+
+type ReportController struct {
+	worklogService services.WorklogService
+}
+
+func NewReportController(worklogService services.WorklogService) *ReportController {
+	return &ReportController{worklogService: worklogService}
+}
+
+// function to sum the time logged in the workspace, as json or as csv when format=csv or Accept asks for it
+func (r *ReportController) GetTimeReport(c *gin.Context) {
+	query, err := parseTimeReportQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, err.Error(), nil))
+		return
+	}
+	format := c.Query("format")
+	if len(format) > 0 && format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "format must be json or csv", nil))
+		return
+	}
+
+	report, err := r.worklogService.GetTimeReport(c, query)
+	if err != nil {
+		respondError(c, err, "Failed to build the time report")
+		return
+	}
+	if format == "csv" || (len(format) == 0 && c.NegotiateFormat(gin.MIMEJSON, csvContentType) == csvContentType) {
+		writeTimeReportCSV(c, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// function to read the report query parameters, group_by takes a comma separated list or is repeated
+func parseTimeReportQuery(c *gin.Context) (*models.TimeReportQuery, error) {
+	query := &models.TimeReportQuery{
+		User:      strings.TrimSpace(c.Query("user")),
+		ProjectID: strings.TrimSpace(c.Query("project_id")),
+	}
+	var err error
+	if query.From, err = parseTimeParam(c, "from"); err != nil {
+		return nil, err
+	}
+	if query.To, err = parseTimeParam(c, "to"); err != nil {
+		return nil, err
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return nil, fmt.Errorf("from must be before to")
+	}
+
+	requested := map[string]bool{}
+	for _, values := range c.QueryArray("group_by") {
+		for _, group := range strings.Split(values, ",") {
+			group = strings.TrimSpace(group)
+			if len(group) == 0 {
+				continue
+			}
+			if !containsGroup(models.TimeReportGroups, group) {
+				return nil, fmt.Errorf("group_by must be a list of %s", strings.Join(models.TimeReportGroups, ", "))
+			}
+			requested[group] = true
+		}
+	}
+	// the groups always come in the order of the report columns
+	for _, group := range models.TimeReportGroups {
+		if requested[group] {
+			query.GroupBy = append(query.GroupBy, group)
+		}
+	}
+	return query, nil
+}
+
+// function to write the report as csv, a column per group then the duration in seconds and the worklog count
+func writeTimeReportCSV(c *gin.Context, report *models.TimeReport) {
+	c.Header("Content-Disposition", `attachment; filename="time-report.csv"`)
+	c.Status(http.StatusOK)
+	c.Writer.Header().Set("Content-Type", csvContentType+"; charset=utf-8")
+
+	writer := csv.NewWriter(c.Writer)
+	header := append([]string{}, report.GroupBy...)
+	writer.Write(append(header, "duration", "worklogs"))
+	for _, row := range report.Rows {
+		record := []string{}
+		for _, group := range report.GroupBy {
+			switch group {
+			case models.TimeReportByUser:
+				record = append(record, row.User)
+			case models.TimeReportByProject:
+				record = append(record, row.ProjectID)
+			case models.TimeReportByTask:
+				record = append(record, row.TaskID)
+			case models.TimeReportByDay:
+				record = append(record, row.Day)
+			}
+		}
+		writer.Write(append(record, strconv.FormatInt(row.Duration, 10), strconv.FormatInt(row.Worklogs, 10)))
+	}
+	writer.Flush()
+}
+
+func containsGroup(groups []string, group string) bool {
+	for _, candidate := range groups {
+		if candidate == group {
+			return true
+		}
+	}
+	return false
+}
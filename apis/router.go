@@ -0,0 +1,128 @@
+package apis
+
+import (
+	"TaskSvc/apis/middleware"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/internals/services"
+	"TaskSvc/internals/workflow"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RouterConfig struct {
+	TokenVerifier      appauth.TokenVerifier
+	Policy             *appauth.Policy
+	TaskService        services.TaskService
+	ProjectService     services.ProjectService
+	CommentService     services.CommentService
+	AttachmentService  services.AttachmentService
+	LabelService       services.LabelService
+	ChecklistService   services.ChecklistService
+	CustomFieldService services.CustomFieldService
+	TemplateService    services.TemplateService
+	TrashService       services.TrashService
+	WorklogService     services.WorklogService
+	WorkspaceService   services.WorkspaceService
+	Workflow           *workflow.Workflow
+}
+
+func NewRouter(config RouterConfig) *gin.Engine {
+	taskController := NewTaskController(config.TaskService)
+	workflowController := NewWorkflowController(config.Workflow)
+	workspaceController := NewWorkspaceController(config.WorkspaceService)
+	projectController := NewProjectController(config.ProjectService, config.TaskService)
+	commentController := NewCommentController(config.CommentService)
+	attachmentController := NewAttachmentController(config.AttachmentService)
+	labelController := NewLabelController(config.LabelService)
+	checklistController := NewChecklistController(config.ChecklistService)
+	customFieldController := NewCustomFieldController(config.CustomFieldService)
+	templateController := NewTemplateController(config.TemplateService)
+	trashController := NewTrashController(config.TrashService)
+	worklogController := NewWorklogController(config.WorklogService)
+	reportController := NewReportController(config.WorklogService)
+
+	// Initialize Gin router
+	r := gin.Default()
+	r.ContextWithFallback = true
+	r.Use(middleware.RequestLogger)
+	authenticate := middleware.AuthenticateJWT(config.TokenVerifier)
+	resolveWorkspace := middleware.ResolveWorkspace(config.Policy, config.WorkspaceService)
+	resolveRoles := middleware.ResolveRoles(config.Policy)
+
+	// public requests carry no workspace and only see the default workspace
+	r.GET("/public/tasks", taskController.GetTasks)
+
+	api := r.Group("", authenticate, resolveWorkspace, resolveRoles)
+	api.GET("/tasks", middleware.Require(appauth.PermissionTaskRead), taskController.GetTasks)
+	api.GET("/tasks/:id", middleware.Require(appauth.PermissionTaskRead), taskController.GetTaskById)
+	api.GET("/tasks/:id/subtasks", middleware.Require(appauth.PermissionTaskRead), taskController.GetSubtasks)
+	api.GET("/tasks/:id/dependency-graph", middleware.Require(appauth.PermissionTaskRead), taskController.GetDependencyGraph)
+	api.POST("/tasks/:id/dependencies", middleware.Require(appauth.PermissionTaskWrite), taskController.AddDependency)
+	api.DELETE("/tasks/:id/dependencies/:blockerId", middleware.Require(appauth.PermissionTaskWrite), taskController.RemoveDependency)
+	api.GET("/tasks/:id/comments", middleware.Require(appauth.PermissionTaskRead), commentController.GetComments)
+	api.POST("/tasks/:id/comments", middleware.Require(appauth.PermissionTaskWrite), commentController.AddComment)
+	api.PUT("/tasks/:id/comments/:commentId", middleware.Require(appauth.PermissionTaskWrite), commentController.UpdateComment)
+	api.DELETE("/tasks/:id/comments/:commentId", middleware.Require(appauth.PermissionTaskWrite), commentController.DeleteComment)
+	api.POST("/tasks/:id/attachments", middleware.Require(appauth.PermissionTaskWrite), attachmentController.AddAttachment)
+	api.GET("/tasks/:id/attachments/:attachmentId", middleware.Require(appauth.PermissionTaskRead), attachmentController.GetAttachment)
+	api.DELETE("/tasks/:id/attachments/:attachmentId", middleware.Require(appauth.PermissionTaskWrite), attachmentController.DeleteAttachment)
+	api.POST("/tasks/:id/checklist", middleware.Require(appauth.PermissionTaskWrite), checklistController.AddChecklistItem)
+	api.PATCH("/tasks/:id/checklist/:itemId", middleware.Require(appauth.PermissionTaskWrite), checklistController.UpdateChecklistItem)
+	api.PUT("/tasks/:id/checklist/:itemId/position", middleware.Require(appauth.PermissionTaskWrite), checklistController.MoveChecklistItem)
+	api.DELETE("/tasks/:id/checklist/:itemId", middleware.Require(appauth.PermissionTaskWrite), checklistController.RemoveChecklistItem)
+	api.GET("/tasks/:id/worklogs", middleware.Require(appauth.PermissionTaskRead), worklogController.GetWorklogs)
+	api.POST("/tasks/:id/worklogs", middleware.Require(appauth.PermissionTaskWrite), worklogController.LogWork)
+	api.POST("/tasks/:id/timer/start", middleware.Require(appauth.PermissionTaskWrite), worklogController.StartTimer)
+	api.POST("/tasks/:id/timer/stop", middleware.Require(appauth.PermissionTaskWrite), worklogController.StopTimer)
+	api.POST("/tasks", middleware.Require(appauth.PermissionTaskWrite), taskController.CreateTask)
+	api.PUT("/tasks/:id", middleware.Require(appauth.PermissionTaskWrite), taskController.UpdateTask)
+	api.PATCH("/tasks/:id", middleware.Require(appauth.PermissionTaskWrite), taskController.PatchTask)
+	api.DELETE("/tasks/:id", middleware.Require(appauth.PermissionTaskDelete), taskController.DeleteTask)
+	api.POST("/tasks/:id/restore", middleware.Require(appauth.PermissionTaskDelete), trashController.RestoreTask)
+
+	api.GET("/trash", middleware.Require(appauth.PermissionTaskRead), trashController.GetTrash)
+	api.DELETE("/trash/:id", middleware.Require(appauth.PermissionTaskDelete), trashController.DeleteTrashedTask)
+
+	api.GET("/labels", middleware.Require(appauth.PermissionTaskRead), labelController.GetLabels)
+	api.POST("/labels/rename", middleware.Require(appauth.PermissionLabelManage), labelController.RenameLabel)
+
+	api.GET("/custom-fields", middleware.Require(appauth.PermissionTaskRead), customFieldController.GetCustomFields)
+	api.POST("/custom-fields", middleware.Require(appauth.PermissionFieldManage), customFieldController.CreateCustomField)
+	api.GET("/custom-fields/:id", middleware.Require(appauth.PermissionTaskRead), customFieldController.GetCustomFieldById)
+	api.PUT("/custom-fields/:id", middleware.Require(appauth.PermissionFieldManage), customFieldController.UpdateCustomField)
+	api.DELETE("/custom-fields/:id", middleware.Require(appauth.PermissionFieldManage), customFieldController.DeleteCustomField)
+
+	api.GET("/templates", middleware.Require(appauth.PermissionTaskRead), templateController.GetTemplates)
+	api.POST("/templates", middleware.Require(appauth.PermissionTemplateManage), templateController.CreateTemplate)
+	api.GET("/templates/:id", middleware.Require(appauth.PermissionTaskRead), templateController.GetTemplateById)
+	api.PUT("/templates/:id", middleware.Require(appauth.PermissionTemplateManage), templateController.UpdateTemplate)
+	api.DELETE("/templates/:id", middleware.Require(appauth.PermissionTemplateManage), templateController.DeleteTemplate)
+	api.POST("/templates/:id/instantiate", middleware.Require(appauth.PermissionTaskWrite), templateController.InstantiateTemplate)
+
+	api.GET("/timers", middleware.Require(appauth.PermissionTaskRead), worklogController.GetTimers)
+	api.GET("/reports/time", middleware.Require(appauth.PermissionReportRead), reportController.GetTimeReport)
+
+	api.GET("/workflow", middleware.Require(appauth.PermissionTaskRead), workflowController.GetWorkflow)
+
+	api.GET("/projects", middleware.Require(appauth.PermissionTaskRead), projectController.GetProjects)
+	api.POST("/projects", middleware.Require(appauth.PermissionProjectManage), projectController.CreateProject)
+	api.GET("/projects/:id", middleware.Require(appauth.PermissionTaskRead), projectController.GetProjectById)
+	api.PUT("/projects/:id", middleware.Require(appauth.PermissionProjectManage), projectController.UpdateProject)
+	api.DELETE("/projects/:id", middleware.Require(appauth.PermissionProjectManage), projectController.DeleteProject)
+	api.GET("/projects/:id/tasks", middleware.Require(appauth.PermissionTaskRead), projectController.GetProjectTasks)
+	api.GET("/projects/:id/workflow", middleware.Require(appauth.PermissionTaskRead), projectController.GetProjectWorkflow)
+
+	// workspaces are managed outside of any workspace, access is checked against the memberships by the service
+	workspaces := r.Group("/workspaces", authenticate, resolveRoles)
+	workspaces.GET("", middleware.Require(appauth.PermissionTaskRead), workspaceController.GetWorkspaces)
+	workspaces.POST("", middleware.Require(appauth.PermissionWorkspaceManage), workspaceController.CreateWorkspace)
+	workspaces.GET("/:id", middleware.Require(appauth.PermissionTaskRead), workspaceController.GetWorkspaceById)
+	workspaces.PUT("/:id", middleware.Require(appauth.PermissionWorkspaceManage), workspaceController.UpdateWorkspace)
+	workspaces.DELETE("/:id", middleware.Require(appauth.PermissionWorkspaceManage), workspaceController.DeleteWorkspace)
+	workspaces.GET("/:id/members", middleware.Require(appauth.PermissionTaskRead), workspaceController.GetMembers)
+	workspaces.POST("/:id/members", middleware.Require(appauth.PermissionWorkspaceManage), workspaceController.AddMember)
+	workspaces.PUT("/:id/members/:subject", middleware.Require(appauth.PermissionWorkspaceManage), workspaceController.UpdateMember)
+	workspaces.DELETE("/:id/members/:subject", middleware.Require(appauth.PermissionWorkspaceManage), workspaceController.RemoveMember)
+
+	return r
+}
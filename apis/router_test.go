@@ -0,0 +1,725 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/internals/db"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+	"TaskSvc/internals/workflow"
+
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const routerTestSecret = "router-test-secret"
+
+func tokenFor(subject string, roles ...string) string {
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   subject,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": roles,
+	}).SignedString([]byte(routerTestSecret))
+	Expect(err).NotTo(HaveOccurred())
+	return signed
+}
+
+var _ = Describe("Router on the memory backend", func() {
+	var (
+		router *gin.Engine
+		token  string
+	)
+
+	BeforeEach(func() {
+		verifier, err := appauth.NewTokenVerifier(appauth.VerifierConfig{HmacSecret: routerTestSecret})
+		Expect(err).NotTo(HaveOccurred())
+		storage := db.NewKVStorage(db.NewMemoryStore())
+		tasks := storage.Tasks()
+		projects := storage.Projects()
+		comments := storage.Comments()
+		customFields := storage.CustomFields()
+		worklogs := storage.Worklogs()
+		blobs, err := db.NewLocalBlobStore(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		router = NewRouter(RouterConfig{
+			TokenVerifier: verifier,
+			Policy:        appauth.DefaultPolicy(),
+			TaskService: services.NewTaskService(tasks, services.WithProjects(projects), services.WithComments(comments), services.WithBlobStore(blobs),
+				services.WithCustomFields(customFields), services.WithWorklogs(worklogs)),
+			ProjectService:     services.NewProjectService(projects, tasks, workflow.Default()),
+			CommentService:     services.NewCommentService(comments, tasks),
+			AttachmentService:  services.NewAttachmentService(tasks, blobs),
+			LabelService:       services.NewLabelService(tasks),
+			ChecklistService:   services.NewChecklistService(tasks),
+			CustomFieldService: services.NewCustomFieldService(customFields, tasks),
+			TemplateService: services.NewTemplateService(storage.Templates(), tasks, services.WithProjects(projects),
+				services.WithCustomFields(customFields)),
+			TrashService: services.NewTrashService(tasks, time.Hour, services.WithComments(comments), services.WithBlobStore(blobs),
+				services.WithWorklogs(worklogs)),
+			WorklogService:   services.NewWorklogService(worklogs, tasks),
+			WorkspaceService: services.NewWorkspaceService(storage.Workspaces(), tasks, appauth.DefaultPolicy()),
+			Workflow:         workflow.Default(),
+		})
+
+		token = tokenFor("user-1")
+	})
+
+	send := func(method, path string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
+		var reader *bytes.Reader
+		if body != nil {
+			pbytes, err := json.Marshal(body)
+			Expect(err).NotTo(HaveOccurred())
+			reader = bytes.NewReader(pbytes)
+		} else {
+			reader = bytes.NewReader(nil)
+		}
+		req := httptest.NewRequest(method, path, reader)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	create := func(title string) string {
+		w := send(http.MethodPost, "/tasks", models.Task{Title: title, Description: "Description", Status: "New"}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		var response map[string]string
+		Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+		return response["id"]
+	}
+
+	It("creates, reads, patches and deletes a task", func() {
+		id := create("Task 1")
+
+		w := send(http.MethodGet, "/tasks/"+id, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("ETag")).To(Equal(`"1"`))
+		var task models.Task
+		Expect(json.Unmarshal(w.Body.Bytes(), &task)).To(Succeed())
+		Expect(task.Title).To(Equal("Task 1"))
+		Expect(task.CreatedAt).NotTo(BeZero())
+
+		w = send(http.MethodGet, "/tasks/"+id, nil, map[string]string{"If-None-Match": `"1"`})
+		Expect(w.Code).To(Equal(http.StatusNotModified))
+
+		w = send(http.MethodPatch, "/tasks/"+id, map[string]string{"status": "inprogress"}, map[string]string{
+			"Content-Type": models.MergePatchContentType,
+			"If-Match":     `"1"`,
+		})
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("ETag")).To(Equal(`"2"`))
+		Expect(json.Unmarshal(w.Body.Bytes(), &task)).To(Succeed())
+		Expect(task.Status).To(Equal("InProgress"))
+		Expect(task.Title).To(Equal("Task 1"))
+
+		w = send(http.MethodDelete, "/tasks/"+id, nil, map[string]string{"If-Match": `"1"`})
+		Expect(w.Code).To(Equal(http.StatusPreconditionFailed))
+
+		w = send(http.MethodDelete, "/tasks/"+id, nil, map[string]string{"If-Match": `"2"`})
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+
+		w = send(http.MethodGet, "/tasks/"+id, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("pages through the task list with the cursor", func() {
+		for i := 1; i <= 5; i++ {
+			create(fmt.Sprintf("Task %d", i))
+		}
+
+		var titles []string
+		path := "/public/tasks?limit=2&sort=title"
+		for pages := 0; pages < 5; pages++ {
+			w := send(http.MethodGet, path, nil, nil)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			var list models.TaskList
+			Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+			Expect(list.Total).To(Equal(int64(5)))
+			for _, task := range list.Tasks {
+				titles = append(titles, task.Title)
+			}
+			if len(list.NextCursor) == 0 {
+				break
+			}
+			path = "/public/tasks?limit=2&sort=title&cursor=" + list.NextCursor
+		}
+		Expect(titles).To(Equal([]string{"Task 1", "Task 2", "Task 3", "Task 4", "Task 5"}))
+	})
+
+	It("filters the task list by status", func() {
+		id := create("Task 1")
+		create("Task 2")
+		w := send(http.MethodPut, "/tasks/"+id, models.Task{Title: "Task 1", Description: "Description", Status: "InProgress"}, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		w = send(http.MethodGet, "/public/tasks?status=InProgress", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var list models.TaskList
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Total).To(Equal(int64(1)))
+		Expect(list.Tasks[0].ID.Hex()).To(Equal(id))
+	})
+
+	It("exposes the workflow and enforces its transitions", func() {
+		w := send(http.MethodGet, "/workflow", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var definition workflow.Workflow
+		Expect(json.Unmarshal(w.Body.Bytes(), &definition)).To(Succeed())
+		Expect(definition.Initial).To(Equal("New"))
+
+		id := create("Task 1")
+		w = send(http.MethodPut, "/tasks/"+id, models.Task{Title: "Task 1", Description: "Description", Status: "Done"}, nil)
+		Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+		var response commons.ApiErrorResponsePayload
+		Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.AdditionalInfo["allowed"]).To(Equal([]interface{}{"InProgress"}))
+	})
+
+	It("scopes deletes to the creator and lists the caller's tasks", func() {
+		id := create("Task 1")
+		w := send(http.MethodPatch, "/tasks/"+id, map[string]interface{}{"assignees": []string{"user-2"}}, map[string]string{
+			"Content-Type": models.MergePatchContentType,
+		})
+		Expect(w.Code).To(Equal(http.StatusOK))
+		create("Task 2")
+
+		token = tokenFor("user-2")
+		w = send(http.MethodGet, "/tasks?assignee=me", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var list models.TaskList
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Total).To(Equal(int64(1)))
+		Expect(list.Tasks[0].CreatedBy).To(Equal("user-1"))
+
+		w = send(http.MethodDelete, "/tasks/"+id, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+
+		token = tokenFor("user-1")
+		w = send(http.MethodDelete, "/tasks/"+id, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("checks the permissions of the caller's roles", func() {
+		id := create("Task 1")
+
+		token = tokenFor("user-2", appauth.ViewerRole)
+		w := send(http.MethodGet, "/tasks/"+id, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		w = send(http.MethodPost, "/tasks", models.Task{Title: "Task 2", Description: "Description"}, nil)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		var response commons.ApiErrorResponsePayload
+		Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.AdditionalInfo["permission"]).To(Equal("task:write"))
+
+		token = tokenFor("user-3", appauth.MaintainerRole)
+		w = send(http.MethodDelete, "/tasks/"+id, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("keeps the tasks of a workspace to its members", func() {
+		w := send(http.MethodPost, "/workspaces", models.Workspace{ID: "team-a", Name: "Team A"}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		w = send(http.MethodPost, "/workspaces/team-a/members", models.Membership{Subject: "user-2", Role: appauth.ViewerRole}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+
+		inTeamA := map[string]string{"X-Workspace-Id": "team-a"}
+		w = send(http.MethodPost, "/tasks", models.Task{Title: "Team A task", Description: "Description"}, inTeamA)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		var created map[string]string
+		Expect(json.Unmarshal(w.Body.Bytes(), &created)).To(Succeed())
+		create("Default task")
+
+		w = send(http.MethodGet, "/public/tasks", nil, nil)
+		var list models.TaskList
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Total).To(Equal(int64(1)))
+		Expect(list.Tasks[0].Title).To(Equal("Default task"))
+
+		w = send(http.MethodGet, "/tasks/"+created["id"], nil, nil)
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+
+		token = tokenFor("user-2")
+		w = send(http.MethodGet, "/tasks", nil, inTeamA)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Total).To(Equal(int64(1)))
+		Expect(list.Tasks[0].WorkspaceID).To(Equal("team-a"))
+
+		w = send(http.MethodPost, "/tasks", models.Task{Title: "Viewer task", Description: "Description"}, inTeamA)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+
+		token = tokenFor("user-3")
+		w = send(http.MethodGet, "/tasks", nil, inTeamA)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+
+		token = tokenFor("user-1")
+		w = send(http.MethodDelete, "/workspaces/team-a", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusConflict))
+	})
+
+	It("grants the creator of a workspace no more than their own roles in it", func() {
+		w := send(http.MethodPost, "/workspaces", models.Workspace{ID: "team-a", Name: "Team A"}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		inTeamA := map[string]string{"X-Workspace-Id": "team-a"}
+		w = send(http.MethodPost, "/tasks", models.Task{Title: "Team A task", Description: "Description", Labels: []string{"ios"}}, inTeamA)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+
+		w = send(http.MethodPost, "/labels/rename", models.LabelRename{From: "ios", To: "mobile"}, inTeamA)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		var response commons.ApiErrorResponsePayload
+		Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.AdditionalInfo["permission"]).To(Equal("label:manage"))
+
+		token = tokenFor("user-2", appauth.ViewerRole)
+		w = send(http.MethodPost, "/workspaces", models.Workspace{ID: "team-b", Name: "Team B"}, nil)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("numbers the tasks of a project and lists them", func() {
+		w := send(http.MethodPost, "/projects", models.Project{Key: "ops", Name: "Operations"}, nil)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+
+		token = tokenFor("user-1", appauth.MaintainerRole)
+		w = send(http.MethodPost, "/projects", models.Project{Key: "ops", Name: "Operations", Workflow: &workflow.Workflow{
+			Initial:     "Todo",
+			Statuses:    []string{"Todo", "Shipped"},
+			Done:        []string{"Shipped"},
+			Transitions: map[string][]string{"Todo": {"Shipped"}},
+		}}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		var project models.Project
+		Expect(json.Unmarshal(w.Body.Bytes(), &project)).To(Succeed())
+		Expect(project.Key).To(Equal("OPS"))
+
+		for i := 1; i <= 2; i++ {
+			w = send(http.MethodPost, "/tasks", models.Task{Title: fmt.Sprintf("Task %d", i), Description: "Description", ProjectID: project.ID.Hex()}, nil)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+		}
+		create("Outside")
+
+		w = send(http.MethodGet, "/projects/"+project.ID.Hex()+"/tasks?sort=title", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var list models.TaskList
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Total).To(Equal(int64(2)))
+		Expect(list.Tasks[0].Key).To(Equal("OPS-1"))
+		Expect(list.Tasks[1].Key).To(Equal("OPS-2"))
+		Expect(list.Tasks[1].Status).To(Equal("Todo"))
+
+		w = send(http.MethodDelete, "/projects/"+project.ID.Hex(), nil, nil)
+		Expect(w.Code).To(Equal(http.StatusConflict))
+
+		w = send(http.MethodGet, "/projects/"+primitive.NewObjectID().Hex()+"/tasks", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("lists subtasks, rolls up their progress and cascades the delete", func() {
+		parent := create("Parent")
+		w := send(http.MethodPost, "/tasks", models.Task{Title: "Child", Description: "Description", Status: "Done", ParentID: parent}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		w = send(http.MethodPost, "/tasks", models.Task{Title: "Other child", Description: "Description", ParentID: parent}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+
+		w = send(http.MethodGet, "/tasks/"+parent+"/subtasks", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var list models.TaskList
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Total).To(Equal(int64(2)))
+
+		w = send(http.MethodGet, "/tasks/"+parent, nil, nil)
+		var task models.Task
+		Expect(json.Unmarshal(w.Body.Bytes(), &task)).To(Succeed())
+		Expect(task.Rollup.PercentComplete).To(Equal(int64(50)))
+		etag := w.Header().Get("ETag")
+		Expect(etag).To(Equal(`"1-s1.2"`))
+
+		w = send(http.MethodPost, "/tasks", models.Task{Title: "Third child", Description: "Description", Status: "Done", ParentID: parent}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		w = send(http.MethodGet, "/tasks/"+parent, nil, map[string]string{"If-None-Match": etag})
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("ETag")).To(Equal(`"1-s2.3"`))
+		Expect(json.Unmarshal(w.Body.Bytes(), &task)).To(Succeed())
+		Expect(task.Rollup.PercentComplete).To(Equal(int64(66)))
+
+		w = send(http.MethodPatch, "/tasks/"+parent, map[string]string{"title": "Renamed parent"}, map[string]string{
+			"Content-Type": models.MergePatchContentType,
+			"If-Match":     w.Header().Get("ETag"),
+		})
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		w = send(http.MethodDelete, "/tasks/"+parent, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusConflict))
+		w = send(http.MethodDelete, "/tasks/"+parent+"?cascade=true", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+		w = send(http.MethodGet, "/tasks?limit=10", nil, nil)
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Total).To(BeZero())
+	})
+
+	It("links dependencies, gates done on the blockers and returns the graph", func() {
+		design, build := create("Design"), create("Build")
+
+		w := send(http.MethodPost, "/tasks/"+build+"/dependencies", models.DependencyLink{BlockerID: design}, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		w = send(http.MethodPost, "/tasks/"+design+"/dependencies", models.DependencyLink{BlockerID: build}, nil)
+		Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+
+		for _, status := range []string{"InProgress", "Review"} {
+			w = send(http.MethodPut, "/tasks/"+build, models.Task{Title: "Build", Description: "Description", Status: status}, nil)
+			Expect(w.Code).To(Equal(http.StatusOK))
+		}
+		w = send(http.MethodPut, "/tasks/"+build, models.Task{Title: "Build", Description: "Description", Status: "Done"}, nil)
+		Expect(w.Code).To(Equal(http.StatusConflict))
+		var response commons.ApiErrorResponsePayload
+		Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+		Expect(response.AdditionalInfo).To(HaveKey("blockers"))
+
+		w = send(http.MethodGet, "/tasks/"+design+"/dependency-graph", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var graph models.DependencyGraph
+		Expect(json.Unmarshal(w.Body.Bytes(), &graph)).To(Succeed())
+		Expect(graph.Nodes).To(HaveLen(2))
+		Expect(graph.Edges).To(Equal([]*models.DependencyEdge{{From: design, To: build}}))
+
+		w = send(http.MethodDelete, "/tasks/"+build+"/dependencies/"+design, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		w = send(http.MethodPut, "/tasks/"+build, models.Task{Title: "Build", Description: "Description", Status: "Done"}, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+	})
+
+	It("changes the entity tag of a task when a comment is added", func() {
+		taskId := create("Discuss")
+		w := send(http.MethodGet, "/tasks/"+taskId, nil, nil)
+		etag := w.Header().Get("ETag")
+		Expect(etag).To(Equal(`"1"`))
+
+		w = send(http.MethodPost, "/tasks/"+taskId+"/comments", models.Comment{Body: "First"}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+
+		w = send(http.MethodGet, "/tasks/"+taskId, nil, map[string]string{"If-None-Match": etag})
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("ETag")).To(Equal(`"1-c1"`))
+		var task models.Task
+		Expect(json.Unmarshal(w.Body.Bytes(), &task)).To(Succeed())
+		Expect(*task.CommentCount).To(Equal(int64(1)))
+
+		w = send(http.MethodGet, "/tasks/"+taskId, nil, map[string]string{"If-None-Match": `"1-c1"`})
+		Expect(w.Code).To(Equal(http.StatusNotModified))
+	})
+
+	It("threads comments on a task and removes them with it", func() {
+		taskId := create("Discuss")
+
+		w := send(http.MethodPost, "/tasks/"+taskId+"/comments", models.Comment{Body: "First"}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		var comment models.Comment
+		Expect(json.Unmarshal(w.Body.Bytes(), &comment)).To(Succeed())
+		Expect(comment.Author).To(Equal("user-1"))
+		path := "/tasks/" + taskId + "/comments/" + comment.ID.Hex()
+
+		w = send(http.MethodPut, path, models.Comment{Body: "Edited"}, map[string]string{"If-Match": w.Header().Get("ETag")})
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(json.Unmarshal(w.Body.Bytes(), &comment)).To(Succeed())
+		Expect(comment.History).To(HaveLen(1))
+
+		token = tokenFor("user-2")
+		w = send(http.MethodPut, path, models.Comment{Body: "Not mine"}, nil)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+
+		w = send(http.MethodGet, "/tasks/"+taskId+"/comments", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var list models.CommentList
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Total).To(Equal(int64(1)))
+		Expect(list.Comments[0].Body).To(Equal("Edited"))
+
+		token = tokenFor("user-1")
+		w = send(http.MethodGet, "/tasks/"+taskId, nil, nil)
+		var task models.Task
+		Expect(json.Unmarshal(w.Body.Bytes(), &task)).To(Succeed())
+		Expect(*task.CommentCount).To(Equal(int64(1)))
+
+		w = send(http.MethodDelete, "/tasks/"+taskId, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+		w = send(http.MethodGet, "/tasks/"+taskId+"/comments", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("uploads an attachment, serves ranges of it and deletes it", func() {
+		taskId := create("Crash on login")
+		content := []byte("line one\nline two\nline three\n")
+
+		body := &bytes.Buffer{}
+		writer := multipart.NewWriter(body)
+		part, err := writer.CreateFormFile("file", "server.log")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = part.Write(content)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+		req := httptest.NewRequest(http.MethodPost, "/tasks/"+taskId+"/attachments", body)
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		var attachment models.Attachment
+		Expect(json.Unmarshal(w.Body.Bytes(), &attachment)).To(Succeed())
+		Expect(attachment.ContentType).To(Equal("text/plain"))
+		Expect(attachment.Size).To(Equal(int64(len(content))))
+		path := w.Header().Get("Location")
+		Expect(path).To(Equal("/tasks/" + taskId + "/attachments/" + attachment.ID.Hex()))
+
+		w = send(http.MethodGet, "/tasks/"+taskId, nil, nil)
+		var task models.Task
+		Expect(json.Unmarshal(w.Body.Bytes(), &task)).To(Succeed())
+		Expect(task.Attachments).To(HaveLen(1))
+
+		w = send(http.MethodGet, path, nil, map[string]string{"Range": "bytes=5-7"})
+		Expect(w.Code).To(Equal(http.StatusPartialContent))
+		Expect(w.Body.String()).To(Equal("one"))
+		w = send(http.MethodGet, path, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.Bytes()).To(Equal(content))
+
+		w = send(http.MethodDelete, path, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+		w = send(http.MethodGet, path, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("filters by label, lists the labels and lets an admin rename one", func() {
+		for title, labels := range map[string][]string{"Crash": {"Bug", "ios"}, "Typo": {"bug"}, "Dark mode": {"feature", "ios"}} {
+			w := send(http.MethodPost, "/tasks", models.Task{Title: title, Description: "Description", Labels: labels}, nil)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+		}
+
+		var list models.TaskList
+		w := send(http.MethodGet, "/tasks?label=bug&label=ios", nil, nil)
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Total).To(Equal(int64(3)))
+		w = send(http.MethodGet, "/tasks?label=bug&label=ios&label_mode=all", nil, nil)
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Total).To(Equal(int64(1)))
+		w = send(http.MethodGet, "/tasks?label=bug&label_mode=some", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+		w = send(http.MethodGet, "/labels?prefix=i", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var labels models.LabelList
+		Expect(json.Unmarshal(w.Body.Bytes(), &labels)).To(Succeed())
+		Expect(labels.Labels).To(Equal([]*models.Label{{Name: "ios", Count: 2}}))
+
+		rename := models.LabelRename{From: "ios", To: "mobile"}
+		w = send(http.MethodPost, "/labels/rename", rename, nil)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		token = tokenFor("admin-1", appauth.AdminRole)
+		w = send(http.MethodPost, "/labels/rename", rename, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(json.Unmarshal(w.Body.Bytes(), &rename)).To(Succeed())
+		Expect(rename.Tasks).To(Equal(int64(2)))
+
+		w = send(http.MethodGet, "/tasks?label=mobile", nil, nil)
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Total).To(Equal(int64(2)))
+	})
+
+	It("keeps a checklist on a task and reports its progress in the list", func() {
+		id := create("Release")
+
+		var task models.Task
+		for _, text := range []string{"Build", "Tag", "Publish"} {
+			w := send(http.MethodPost, "/tasks/"+id+"/checklist", models.ChecklistItemInput{Text: text}, nil)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+			Expect(json.Unmarshal(w.Body.Bytes(), &task)).To(Succeed())
+		}
+		w := send(http.MethodPost, "/tasks/"+id+"/checklist", models.ChecklistItemInput{Text: "Late"}, map[string]string{"If-Match": `"1"`})
+		Expect(w.Code).To(Equal(http.StatusPreconditionFailed))
+		build, publish := task.Checklist[0].ID.Hex(), task.Checklist[2].ID.Hex()
+
+		w = send(http.MethodPatch, "/tasks/"+id+"/checklist/"+build, map[string]bool{"done": true}, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(json.Unmarshal(w.Body.Bytes(), &task)).To(Succeed())
+		Expect(task.Checklist[0].DoneBy).To(Equal("user-1"))
+
+		w = send(http.MethodPut, "/tasks/"+id+"/checklist/"+publish+"/position", map[string]int{"position": 0}, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		w = send(http.MethodPut, "/tasks/"+id+"/checklist/"+publish+"/position", map[string]int{}, nil)
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+		var list models.TaskList
+		w = send(http.MethodGet, "/tasks", nil, nil)
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Tasks[0].Checklist[0].Text).To(Equal("Publish"))
+		Expect(*list.Tasks[0].ChecklistProgress).To(Equal(models.ChecklistProgress{Done: 1, Total: 3}))
+
+		w = send(http.MethodDelete, "/tasks/"+id+"/checklist/"+publish, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		w = send(http.MethodDelete, "/tasks/"+id+"/checklist/"+publish, nil, nil)
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("validates, filters and sorts the tasks by custom fields", func() {
+		field := map[string]interface{}{"key": "points", "name": "Story points", "type": "number"}
+		w := send(http.MethodPost, "/custom-fields", field, nil)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		token = tokenFor("user-1", "maintainer")
+		w = send(http.MethodPost, "/custom-fields", field, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		var created models.CustomField
+		Expect(json.Unmarshal(w.Body.Bytes(), &created)).To(Succeed())
+
+		for title, points := range map[string]interface{}{"Small": 1, "Large": 8, "Medium": 3} {
+			task := map[string]interface{}{"title": title, "description": "d", "customFields": map[string]interface{}{"points": points}}
+			w = send(http.MethodPost, "/tasks", task, nil)
+			Expect(w.Code).To(Equal(http.StatusCreated))
+		}
+		task := map[string]interface{}{"title": "Bad", "description": "d", "customFields": map[string]interface{}{"points": "many"}}
+		w = send(http.MethodPost, "/tasks", task, nil)
+		Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+
+		var list models.TaskList
+		w = send(http.MethodGet, "/tasks?sort=-field.points&field.points.gte=2", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Tasks).To(HaveLen(2))
+		Expect(list.Tasks[0].Title).To(Equal("Large"))
+		Expect(list.Tasks[0].CustomFields).To(Equal(map[string]interface{}{"points": float64(8)}))
+		w = send(http.MethodGet, "/tasks?field.size=big", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+		w = send(http.MethodDelete, "/custom-fields/"+created.ID.Hex(), nil, nil)
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+		list = models.TaskList{}
+		w = send(http.MethodGet, "/tasks", nil, nil)
+		Expect(json.Unmarshal(w.Body.Bytes(), &list)).To(Succeed())
+		Expect(list.Tasks[0].CustomFields).To(BeNil())
+	})
+
+	It("tracks the time spent on a task and reports it", func() {
+		w := send(http.MethodPost, "/tasks", map[string]interface{}{"title": "Estimated", "description": "d", "originalEstimate": 7200}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		var response map[string]string
+		Expect(json.Unmarshal(w.Body.Bytes(), &response)).To(Succeed())
+		id := response["id"]
+
+		w = send(http.MethodPost, "/tasks/"+id+"/worklogs", map[string]interface{}{"duration": 1800, "note": "spike"}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		w = send(http.MethodPost, "/tasks/"+id+"/worklogs", map[string]interface{}{"duration": -5}, nil)
+		Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+		w = send(http.MethodPatch, "/tasks/"+id, map[string]interface{}{"timeSpent": 0}, map[string]string{"Content-Type": models.MergePatchContentType})
+		Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+
+		w = send(http.MethodPost, "/tasks/"+id+"/timer/start", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		w = send(http.MethodPost, "/tasks/"+id+"/timer/start", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusConflict))
+		var timers []models.Timer
+		w = send(http.MethodGet, "/timers", nil, nil)
+		Expect(json.Unmarshal(w.Body.Bytes(), &timers)).To(Succeed())
+		Expect(timers).To(HaveLen(1))
+		w = send(http.MethodPost, "/tasks/"+id+"/timer/stop", map[string]string{"note": "pairing"}, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		var task models.Task
+		w = send(http.MethodGet, "/tasks/"+id, nil, nil)
+		Expect(json.Unmarshal(w.Body.Bytes(), &task)).To(Succeed())
+		Expect(task.TimeSpent).To(BeNumerically(">=", 1801))
+		Expect(*task.RemainingEstimate).To(Equal(7200 - task.TimeSpent))
+		var worklogs []models.Worklog
+		w = send(http.MethodGet, "/tasks/"+id+"/worklogs", nil, nil)
+		Expect(json.Unmarshal(w.Body.Bytes(), &worklogs)).To(Succeed())
+		Expect(worklogs).To(HaveLen(2))
+
+		w = send(http.MethodGet, "/reports/time?group_by=user", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		token = tokenFor("user-1", "maintainer")
+		var report models.TimeReport
+		w = send(http.MethodGet, "/reports/time?group_by=user", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(json.Unmarshal(w.Body.Bytes(), &report)).To(Succeed())
+		Expect(report.Rows).To(HaveLen(1))
+		Expect(report.Rows[0].User).To(Equal("user-1"))
+		Expect(report.Rows[0].Duration).To(Equal(task.TimeSpent))
+		w = send(http.MethodGet, "/reports/time?group_by=task&format=csv", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(HavePrefix("task,duration,worklogs\n" + id + ","))
+	})
+
+	It("lets a maintainer define a template and a member instantiate it", func() {
+		template := map[string]interface{}{"name": "Release", "task": map[string]interface{}{
+			"title": "Release {{.Version}}", "description": "Ship {{.Version}}", "dueInDays": 7,
+			"subtasks": []map[string]interface{}{{"title": "Changelog", "description": "Changelog of {{.Version}}", "checklist": []string{"Draft"}}},
+		}}
+		w := send(http.MethodPost, "/templates", template, nil)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		token = tokenFor("user-1", "maintainer")
+		w = send(http.MethodPost, "/templates", template, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		var created models.Template
+		Expect(json.Unmarshal(w.Body.Bytes(), &created)).To(Succeed())
+		Expect(created.Variables).To(Equal([]string{"Version"}))
+
+		token = tokenFor("user-2")
+		w = send(http.MethodPost, "/templates/"+created.ID.Hex()+"/instantiate", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusUnprocessableEntity))
+		w = send(http.MethodPost, "/templates/"+created.ID.Hex()+"/instantiate", map[string]interface{}{"variables": map[string]string{"Version": "1.2"}}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		var instance models.TemplateInstance
+		Expect(json.Unmarshal(w.Body.Bytes(), &instance)).To(Succeed())
+		Expect(instance.TaskIDs).To(HaveLen(2))
+
+		var subtasks models.TaskList
+		w = send(http.MethodGet, "/tasks/"+instance.ID+"/subtasks", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(json.Unmarshal(w.Body.Bytes(), &subtasks)).To(Succeed())
+		Expect(subtasks.Tasks).To(HaveLen(1))
+		Expect(subtasks.Tasks[0].Title).To(Equal("Changelog"))
+		Expect(subtasks.Tasks[0].CreatedBy).To(Equal("user-2"))
+	})
+
+	It("moves a deleted task to the trash until it is restored or deleted for good", func() {
+		w := send(http.MethodPost, "/tasks", map[string]string{"title": "Task", "description": "Description"}, nil)
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		var created map[string]string
+		Expect(json.Unmarshal(w.Body.Bytes(), &created)).To(Succeed())
+		id := created["id"]
+
+		Expect(send(http.MethodDelete, "/tasks/"+id, nil, nil).Code).To(Equal(http.StatusNoContent))
+		Expect(send(http.MethodGet, "/tasks/"+id, nil, nil).Code).To(Equal(http.StatusNotFound))
+		w = send(http.MethodGet, "/trash", nil, nil)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var trash models.TaskList
+		Expect(json.Unmarshal(w.Body.Bytes(), &trash)).To(Succeed())
+		Expect(trash.Tasks).To(HaveLen(1))
+		Expect(trash.Tasks[0].DeletedBy).To(Equal("user-1"))
+
+		token = tokenFor("user-2")
+		Expect(send(http.MethodPost, "/tasks/"+id+"/restore", nil, nil).Code).To(Equal(http.StatusForbidden))
+		token = tokenFor("user-1")
+		w = send(http.MethodPost, "/tasks/"+id+"/restore", nil, map[string]string{"If-Match": `"2"`})
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("ETag")).To(Equal(`"3"`))
+		Expect(send(http.MethodGet, "/tasks/"+id, nil, nil).Code).To(Equal(http.StatusOK))
+
+		Expect(send(http.MethodDelete, "/trash/"+id, nil, nil).Code).To(Equal(http.StatusNotFound))
+		Expect(send(http.MethodDelete, "/tasks/"+id, nil, nil).Code).To(Equal(http.StatusNoContent))
+		Expect(send(http.MethodDelete, "/trash/"+id, nil, nil).Code).To(Equal(http.StatusNoContent))
+		Expect(send(http.MethodPost, "/tasks/"+id+"/restore", nil, nil).Code).To(Equal(http.StatusNotFound))
+	})
+})
@@ -2,9 +2,12 @@ package apis
 
 import (
 	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
 	"TaskSvc/internals/models"
 	"TaskSvc/internals/services"
+	"io"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -19,56 +22,96 @@ func NewTaskController(taskService services.TaskService) *TaskController {
 }
 
 func (t *TaskController) GetTasks(c *gin.Context) {
-	tasks, err := t.taskService.GetTasks(c)
+	query, err := parseTaskQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, commons.ApiErrorResponse("Failed to fetch tasks", nil))
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, err.Error(), nil))
 		return
 	}
-	c.JSON(http.StatusOK, map[string]interface{}{
-		"total": len(tasks),
-		"tasks": tasks,
-	})
+
+	tasks, err := t.taskService.GetTasks(c, query)
+	if err != nil {
+		respondError(c, err, "Failed to fetch tasks")
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
 }
 
 func (t *TaskController) GetTaskById(c *gin.Context) {
 	taskId := c.Param("id")
 	if len(strings.TrimSpace(taskId)) == 0 {
-		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse("Task ID is required", nil))
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
 		return
 	}
 
 	task, err := t.taskService.GetTaskById(c, taskId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, commons.ApiErrorResponse("Failed to fetch task", nil))
+		respondError(c, err, "Failed to fetch task")
 		return
 	}
+
+	// tasks written before versioning have no entity tag until their next update
+	if task.Version > 0 {
+		etag := formatTaskETag(task)
+		c.Header("ETag", etag)
+		if matchesIfNoneMatch(c, etag) {
+			c.Status(http.StatusNotModified)
+			c.Writer.WriteHeaderNow()
+			return
+		}
+	}
 	c.JSON(http.StatusOK, task)
 }
 
+// function to list the direct subtasks of the task, with the filters, sort and paging of GET /tasks
+func (t *TaskController) GetSubtasks(c *gin.Context) {
+	taskId := c.Param("id")
+	if len(strings.TrimSpace(taskId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
+		return
+	}
+	query, err := parseTaskQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, err.Error(), nil))
+		return
+	}
+	if _, err := t.taskService.GetTaskById(c, taskId); err != nil {
+		respondError(c, err, "Failed to fetch task")
+		return
+	}
+
+	query.ParentID = taskId
+	tasks, err := t.taskService.GetTasks(c, query)
+	if err != nil {
+		respondError(c, err, "Failed to fetch tasks")
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
 func (t *TaskController) CreateTask(c *gin.Context) {
 	var task *models.Task
 	if err := c.ShouldBindJSON(&task); err != nil || task == nil {
-		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse("Invalid request payload", nil))
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
 		return
 	}
 
 	if len(strings.TrimSpace(task.Title)) == 0 {
-		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse("Title is required", nil))
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Title is required", nil))
 		return
 	}
 
 	if len(strings.TrimSpace(task.Description)) == 0 {
-		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse("Description is required", nil))
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Description is required", nil))
 		return
 	}
 
-	if len(strings.TrimSpace(task.Status)) == 0 {
-		task.Status = "New"
+	if !validateTaskPlanning(c, task) {
+		return
 	}
 
 	taskId, err := t.taskService.CreateTask(c, task)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, commons.ApiErrorResponse("Failed to create task", nil))
+		respondError(c, err, "Failed to create task")
 		return
 	}
 	c.JSON(http.StatusCreated, map[string]string{"id": taskId})
@@ -77,49 +120,184 @@ func (t *TaskController) CreateTask(c *gin.Context) {
 func (t *TaskController) UpdateTask(c *gin.Context) {
 	taskId := c.Param("id")
 	if len(strings.TrimSpace(taskId)) == 0 {
-		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse("Task ID is required", nil))
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
 		return
 	}
 
 	var task *models.Task
-	if err := c.ShouldBindJSON(&task); err != nil {
-		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse("Invalid request payload", nil))
+	if err := c.ShouldBindJSON(&task); err != nil || task == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
 		return
 	}
 
 	if len(strings.TrimSpace(task.Title)) == 0 {
-		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse("Title is required", nil))
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Title is required", nil))
 		return
 	}
 
 	if len(strings.TrimSpace(task.Description)) == 0 {
-		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse("Description is required", nil))
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Description is required", nil))
 		return
 	}
 
 	if len(strings.TrimSpace(task.Status)) == 0 {
-		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse("Status is required", nil))
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Status is required", nil))
 		return
 	}
 
-	if err := t.taskService.UpdateTask(c, task, taskId); err != nil {
-		c.JSON(http.StatusInternalServerError, commons.ApiErrorResponse("Failed to update task", nil))
+	if !validateTaskPlanning(c, task) {
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := t.taskService.UpdateTask(c, task, taskId, version); err != nil {
+		respondError(c, err, "Failed to update task")
 		return
 	}
 	c.Status(http.StatusOK)
 }
 
+func (t *TaskController) PatchTask(c *gin.Context) {
+	taskId := c.Param("id")
+	if len(strings.TrimSpace(taskId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
+		return
+	}
+
+	contentType := c.ContentType()
+	if contentType != models.MergePatchContentType && contentType != models.JsonPatchContentType {
+		c.JSON(http.StatusUnsupportedMediaType, commons.ApiErrorResponse(apperrors.UnsupportedMediaType,
+			"Content-Type must be "+models.MergePatchContentType+" or "+models.JsonPatchContentType, nil))
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	document, err := io.ReadAll(c.Request.Body)
+	if err != nil || len(document) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+
+	task, err := t.taskService.PatchTask(c, taskId, &models.TaskPatch{ContentType: contentType, Document: document}, version)
+	if err != nil {
+		respondError(c, err, "Failed to update task")
+		return
+	}
+	c.Header("ETag", formatETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
+
 func (t *TaskController) DeleteTask(c *gin.Context) {
 	taskId := c.Param("id")
 	if len(strings.TrimSpace(taskId)) == 0 {
-		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse("Task ID is required", nil))
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
+		return
+	}
+
+	version, ok := ifMatchVersion(c)
+	if !ok {
 		return
 	}
+	cascade := false
+	if value := c.Query("cascade"); len(value) > 0 {
+		var err error
+		if cascade, err = strconv.ParseBool(value); err != nil {
+			c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "cascade must be true or false", nil))
+			return
+		}
+	}
 
-	if err := t.taskService.DeleteTaskById(c, taskId); err != nil {
-		c.JSON(http.StatusInternalServerError, commons.ApiErrorResponse("Failed to delete task", nil))
+	if err := t.taskService.DeleteTaskById(c, taskId, version, cascade); err != nil {
+		respondError(c, err, "Failed to delete task")
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
+
+// function to link the task to a task blocking it, returns the updated task and its entity tag like PatchTask
+func (t *TaskController) AddDependency(c *gin.Context) {
+	taskId := c.Param("id")
+	if len(strings.TrimSpace(taskId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
+		return
+	}
+	var link *models.DependencyLink
+	if err := c.ShouldBindJSON(&link); err != nil || link == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	if len(strings.TrimSpace(link.BlockerID)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Blocker ID is required", nil))
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := t.taskService.AddDependency(c, taskId, link.BlockerID, version)
+	if err != nil {
+		respondError(c, err, "Failed to add dependency")
+		return
+	}
+	c.Header("ETag", formatETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
+
+func (t *TaskController) RemoveDependency(c *gin.Context) {
+	taskId := c.Param("id")
+	blockerId := c.Param("blockerId")
+	if len(strings.TrimSpace(taskId)) == 0 || len(strings.TrimSpace(blockerId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID and blocker ID are required", nil))
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := t.taskService.RemoveDependency(c, taskId, blockerId, version)
+	if err != nil {
+		respondError(c, err, "Failed to remove dependency")
+		return
+	}
+	c.Header("ETag", formatETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
+
+func (t *TaskController) GetDependencyGraph(c *gin.Context) {
+	taskId := c.Param("id")
+	if len(strings.TrimSpace(taskId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
+		return
+	}
+
+	graph, err := t.taskService.GetDependencyGraph(c, taskId)
+	if err != nil {
+		respondError(c, err, "Failed to fetch dependency graph")
+		return
+	}
+	c.JSON(http.StatusOK, graph)
+}
+
+// function to check the priority and the planned dates, writes a 400 and returns false when they are invalid
+func validateTaskPlanning(c *gin.Context, task *models.Task) bool {
+	if !models.ValidPriority(task.Priority) {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Priority must be low, medium, high or urgent", nil))
+		return false
+	}
+	if task.StartDate != nil && task.DueDate != nil && task.StartDate.After(*task.DueDate) {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Start date must not be after the due date", nil))
+		return false
+	}
+	return true
+}
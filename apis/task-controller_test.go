@@ -2,6 +2,7 @@ package apis
 
 import (
 	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
 	"TaskSvc/internals/models"
 	"TaskSvc/internals/services"
 
@@ -12,6 +13,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	. "github.com/onsi/ginkgo/v2"
@@ -24,12 +26,15 @@ var _ = Describe("Task API Controller", func() {
 	Describe("GetTasks", func() {
 		It("valid", func() {
 			eservice := services.MockTaskService{
-				FakeGetTasks: func(ctx context.Context) ([]*models.Task, error) {
-					return []*models.Task{
-						{
-							Title:       "Task 1",
-							Description: "Task 1 Description",
-							Status:      "Pending",
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
+					return &models.TaskList{
+						Total: 1,
+						Tasks: []*models.Task{
+							{
+								Title:       "Task 1",
+								Description: "Task 1 Description",
+								Status:      "Pending",
+							},
 						},
 					}, nil
 				},
@@ -69,7 +74,7 @@ var _ = Describe("Task API Controller", func() {
 
 		It("error fetching tasks", func() {
 			eservice := services.MockTaskService{
-				FakeGetTasks: func(ctx context.Context) ([]*models.Task, error) {
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
 					return nil, fmt.Errorf("failed to fetch tasks")
 				},
 			}
@@ -89,6 +94,130 @@ var _ = Describe("Task API Controller", func() {
 			Expect(response.Status).To(Equal("Error"))
 			Expect(response.Message).To(Equal("Failed to fetch tasks"))
 		})
+
+		It("passes paging, sort and filters to the service", func() {
+			var received *models.TaskQuery
+			eservice := services.MockTaskService{
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
+					received = query
+					return &models.TaskList{Total: 10, Tasks: []*models.Task{}, NextCursor: "abc"}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet,
+				"/tasks?limit=5&cursor=xyz&sort=-title&status=New&status=Done&created_after=2024-01-01T00:00:00Z&updated_before=2024-02-01T00:00:00Z", nil)
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = req
+
+			controller := NewTaskController(eservice)
+			controller.GetTasks(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(received.Limit).To(Equal(int64(5)))
+			Expect(received.Cursor).To(Equal("xyz"))
+			Expect(received.SortBy).To(Equal(models.SortByTitle))
+			Expect(received.SortDesc).To(BeTrue())
+			Expect(received.Status).To(Equal([]string{"New", "Done"}))
+			Expect(received.CreatedAfter.Year()).To(Equal(2024))
+			Expect(received.CreatedBefore).To(BeNil())
+			Expect(received.UpdatedBefore.Month()).To(Equal(time.February))
+
+			var response map[string]interface{}
+			uerr := json.Unmarshal(rec.Body.Bytes(), &response)
+			Expect(uerr).NotTo(HaveOccurred())
+			Expect(response["total"]).To(Equal(10.0))
+			Expect(response["next_cursor"]).To(Equal("abc"))
+		})
+
+		It("passes the due date filters and the priority sort to the service", func() {
+			var received *models.TaskQuery
+			eservice := services.MockTaskService{
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
+					received = query
+					return &models.TaskList{}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet,
+				"/tasks?overdue=true&sort=-priority&due_after=2024-01-01T00:00:00Z&due_before=2024-02-01T00:00:00Z", nil)
+
+			NewTaskController(eservice).GetTasks(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(received.Overdue).To(BeTrue())
+			Expect(received.SortBy).To(Equal(models.SortByPriority))
+			Expect(received.SortDesc).To(BeTrue())
+			Expect(received.DueAfter.Month()).To(Equal(time.January))
+			Expect(received.DueBefore.Month()).To(Equal(time.February))
+		})
+
+		It("passes the custom field filters and sort to the service", func() {
+			var received *models.TaskQuery
+			eservice := services.MockTaskService{
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
+					received = query
+					return &models.TaskList{}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet,
+				"/tasks?sort=-field.points&field.points.gte=2&field.points.lt=8&field.env=dev&field.env=prod", nil)
+
+			NewTaskController(eservice).GetTasks(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(received.SortBy).To(Equal("field.points"))
+			Expect(received.SortDesc).To(BeTrue())
+			Expect(received.CustomFields).To(Equal([]*models.CustomFieldFilter{
+				{Key: "env", Values: []interface{}{"dev", "prod"}},
+				{Key: "points", Gte: "2", Lt: "8"},
+			}))
+		})
+
+		It("defaults to newest first", func() {
+			var received *models.TaskQuery
+			eservice := services.MockTaskService{
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
+					received = query
+					return &models.TaskList{}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/tasks", nil)
+
+			NewTaskController(eservice).GetTasks(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(received.Limit).To(Equal(int64(models.DefaultTaskLimit)))
+			Expect(received.SortBy).To(Equal(models.SortByCreatedAt))
+			Expect(received.SortDesc).To(BeTrue())
+		})
+
+		DescribeTable("invalid query parameters",
+			func(rawQuery string, message string) {
+				rec := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(rec)
+				c.Request = httptest.NewRequest(http.MethodGet, "/tasks?"+rawQuery, nil)
+
+				NewTaskController(services.MockTaskService{}).GetTasks(c)
+
+				Expect(rec.Code).To(Equal(http.StatusBadRequest))
+				var response *commons.ApiErrorResponsePayload
+				uerr := json.Unmarshal(rec.Body.Bytes(), &response)
+				Expect(uerr).NotTo(HaveOccurred())
+				Expect(response.Message).To(Equal(message))
+			},
+			Entry("limit too large", "limit=1000", "limit must be between 1 and 200"),
+			Entry("limit not a number", "limit=ten", "limit must be between 1 and 200"),
+			Entry("unknown sort field", "sort=description", "invalid sort field: description"),
+			Entry("bad date", "created_before=yesterday", "created_before must be an RFC3339 timestamp"),
+			Entry("bad due date", "due_after=tomorrow", "due_after must be an RFC3339 timestamp"),
+			Entry("bad overdue flag", "overdue=maybe", "overdue must be true or false"),
+			Entry("bad custom field filter", "field.points.max=3", "invalid custom field filter: field.points.max"),
+		)
 	})
 
 	Describe("GetTaskById", func() {
@@ -272,12 +401,65 @@ var _ = Describe("Task API Controller", func() {
 			Expect(response.Status).To(Equal("Error"))
 			Expect(response.Message).To(Equal("Description is required"))
 		})
+
+		It("invalid priority", func() {
+			task := &models.Task{
+				Title:       "New Task",
+				Description: "New Task Description",
+				Priority:    "critical",
+			}
+			pbytes, err := json.Marshal(task)
+			Expect(err).To(BeNil())
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(pbytes))
+			req.Header.Set("Content-Type", "application/json")
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = req
+
+			controller := NewTaskController(services.MockTaskService{})
+			controller.CreateTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+
+			var response *commons.ApiErrorResponsePayload
+			uerr := json.Unmarshal(rec.Body.Bytes(), &response)
+			Expect(uerr).NotTo(HaveOccurred())
+			Expect(response.Message).To(Equal("Priority must be low, medium, high or urgent"))
+		})
+
+		It("start date after the due date", func() {
+			start := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+			due := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+			task := &models.Task{
+				Title:       "New Task",
+				Description: "New Task Description",
+				StartDate:   &start,
+				DueDate:     &due,
+			}
+			pbytes, err := json.Marshal(task)
+			Expect(err).To(BeNil())
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/tasks", bytes.NewBuffer(pbytes))
+			req.Header.Set("Content-Type", "application/json")
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = req
+
+			controller := NewTaskController(services.MockTaskService{})
+			controller.CreateTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+
+			var response *commons.ApiErrorResponsePayload
+			uerr := json.Unmarshal(rec.Body.Bytes(), &response)
+			Expect(uerr).NotTo(HaveOccurred())
+			Expect(response.Message).To(Equal("Start date must not be after the due date"))
+		})
 	})
 
 	Describe("UpdateTask", func() {
 		It("valid", func() {
 			eservice := services.MockTaskService{
-				FakeUpdateTask: func(ctx context.Context, task *models.Task, taskId string) error {
+				FakeUpdateTask: func(ctx context.Context, task *models.Task, taskId string, version int64) error {
 					return nil
 				},
 			}
@@ -402,10 +584,65 @@ var _ = Describe("Task API Controller", func() {
 		})
 	})
 
+	Describe("PatchTask", func() {
+		It("valid", func() {
+			var received *models.TaskPatch
+			eservice := services.MockTaskService{
+				FakePatchTask: func(ctx context.Context, taskId string, patch *models.TaskPatch, version int64) (*models.Task, error) {
+					received = patch
+					return &models.Task{Title: "Task", Description: "Description", Status: "Done"}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/tasks/1", strings.NewReader(`{"status":"Done"}`))
+			req.Header.Set("Content-Type", "application/merge-patch+json; charset=utf-8")
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(eservice).PatchTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(received.ContentType).To(Equal(models.MergePatchContentType))
+			Expect(string(received.Document)).To(Equal(`{"status":"Done"}`))
+
+			var response *models.Task
+			uerr := json.Unmarshal(rec.Body.Bytes(), &response)
+			Expect(uerr).NotTo(HaveOccurred())
+			Expect(response.Status).To(Equal("Done"))
+		})
+
+		It("unsupported content type", func() {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/tasks/1", strings.NewReader(`{"status":"Done"}`))
+			req.Header.Set("Content-Type", "application/json")
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(services.MockTaskService{}).PatchTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusUnsupportedMediaType))
+		})
+
+		It("empty body", func() {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/tasks/1", nil)
+			req.Header.Set("Content-Type", models.JsonPatchContentType)
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(services.MockTaskService{}).PatchTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
 	Describe("DeleteTask", func() {
 		It("valid", func() {
 			eservice := services.MockTaskService{
-				FakeDeleteTaskById: func(ctx context.Context, taskId string) error {
+				FakeDeleteTaskById: func(ctx context.Context, taskId string, version int64, cascade bool) error {
 					return nil
 				},
 			}
@@ -423,7 +660,7 @@ var _ = Describe("Task API Controller", func() {
 
 		It("error deleting task", func() {
 			eservice := services.MockTaskService{
-				FakeDeleteTaskById: func(ctx context.Context, taskId string) error {
+				FakeDeleteTaskById: func(ctx context.Context, taskId string, version int64, cascade bool) error {
 					return fmt.Errorf("failed to delete task")
 				},
 			}
@@ -465,4 +702,279 @@ var _ = Describe("Task API Controller", func() {
 			Expect(response.Message).To(Equal("Task ID is required"))
 		})
 	})
+	Describe("subtasks", func() {
+		It("passes cascade to the service", func() {
+			var cascaded bool
+			eservice := services.MockTaskService{
+				FakeDeleteTaskById: func(ctx context.Context, taskId string, version int64, cascade bool) error {
+					cascaded = cascade
+					return nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/tasks/1?cascade=true", nil)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(eservice).DeleteTask(c)
+
+			Expect(cascaded).To(BeTrue())
+		})
+
+		It("rejects an invalid cascade", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/tasks/1?cascade=maybe", nil)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(services.MockTaskService{}).DeleteTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("lists the subtasks of the task", func() {
+			eservice := services.MockTaskService{
+				FakeGetTaskById: func(ctx context.Context, taskId string) (*models.Task, error) {
+					return &models.Task{}, nil
+				},
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
+					Expect(query.ParentID).To(Equal("1"))
+					return &models.TaskList{}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/tasks/1/subtasks", nil)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(eservice).GetSubtasks(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+		})
+
+		It("reports a missing task", func() {
+			eservice := services.MockTaskService{
+				FakeGetTaskById: func(ctx context.Context, taskId string) (*models.Task, error) {
+					return nil, apperrors.NewNotFoundError("task 1 not found")
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/tasks/1/subtasks", nil)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(eservice).GetSubtasks(c)
+
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("dependencies", func() {
+		It("links the blocker and returns the entity tag", func() {
+			eservice := services.MockTaskService{
+				FakeAddDependency: func(ctx context.Context, taskId string, blockerId string, version int64) (*models.Task, error) {
+					Expect(taskId).To(Equal("1"))
+					Expect(blockerId).To(Equal("2"))
+					return &models.Task{Version: 3}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/tasks/1/dependencies", bytes.NewBufferString(`{"blockerId":"2"}`))
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(eservice).AddDependency(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("ETag")).To(Equal(`"3"`))
+		})
+
+		It("blocker missing", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/tasks/1/dependencies", bytes.NewBufferString(`{}`))
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(services.MockTaskService{}).AddDependency(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("reports a missing link", func() {
+			eservice := services.MockTaskService{
+				FakeRemoveDependency: func(ctx context.Context, taskId string, blockerId string, version int64) (*models.Task, error) {
+					return nil, apperrors.NewNotFoundError("task 1 is not blocked by task 2")
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/tasks/1/dependencies/2", nil)
+			c.Params = gin.Params{{Key: "id", Value: "1"}, {Key: "blockerId", Value: "2"}}
+
+			NewTaskController(eservice).RemoveDependency(c)
+
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("error mapping", func() {
+		DescribeTable("maps typed errors to status codes",
+			func(err error, status int, code apperrors.Code) {
+				eservice := services.MockTaskService{
+					FakeGetTaskById: func(ctx context.Context, taskId string) (*models.Task, error) {
+						return nil, err
+					},
+				}
+				rec := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(rec)
+				c.Request = httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+				c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+				NewTaskController(eservice).GetTaskById(c)
+
+				Expect(rec.Code).To(Equal(status))
+				var response *commons.ApiErrorResponsePayload
+				uerr := json.Unmarshal(rec.Body.Bytes(), &response)
+				Expect(uerr).NotTo(HaveOccurred())
+				Expect(response.Code).To(Equal(code))
+			},
+			Entry("not found", apperrors.NewNotFoundError("task 1 not found"), http.StatusNotFound, apperrors.NotFound),
+			Entry("invalid id", apperrors.NewInvalidIdError("1", nil), http.StatusBadRequest, apperrors.InvalidID),
+			Entry("conflict", apperrors.NewConflictError("task already exists", nil), http.StatusConflict, apperrors.Conflict),
+			Entry("validation", apperrors.NewValidationError("Title is required", nil), http.StatusUnprocessableEntity, apperrors.Validation),
+			Entry("forbidden", apperrors.NewForbiddenError("only the creator or an admin can delete task 1"), http.StatusForbidden, apperrors.Forbidden),
+			Entry("wrapped not found", fmt.Errorf("lookup: %w", apperrors.NewNotFoundError("gone")), http.StatusNotFound, apperrors.NotFound),
+			Entry("untyped error", fmt.Errorf("boom"), http.StatusInternalServerError, apperrors.Internal),
+		)
+
+		It("delete of a missing task", func() {
+			eservice := services.MockTaskService{
+				FakeDeleteTaskById: func(ctx context.Context, taskId string, version int64, cascade bool) error {
+					return apperrors.NewNotFoundError("task 1 not found")
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/tasks/1", nil)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(eservice).DeleteTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+			var response *commons.ApiErrorResponsePayload
+			uerr := json.Unmarshal(rec.Body.Bytes(), &response)
+			Expect(uerr).NotTo(HaveOccurred())
+			Expect(response.Message).To(Equal("task 1 not found"))
+		})
+	})
+	Describe("conditional requests", func() {
+		getTask := func(header string) *httptest.ResponseRecorder {
+			eservice := services.MockTaskService{
+				FakeGetTaskById: func(ctx context.Context, taskId string) (*models.Task, error) {
+					return &models.Task{Title: "Task 1", Description: "Description", Status: "New", Version: 3}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/tasks/1", nil)
+			if len(header) > 0 {
+				c.Request.Header.Set("If-None-Match", header)
+			}
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+			NewTaskController(eservice).GetTaskById(c)
+			return rec
+		}
+
+		It("returns the version as ETag", func() {
+			rec := getTask("")
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("ETag")).To(Equal(`"3"`))
+		})
+
+		It("returns 304 when If-None-Match matches", func() {
+			rec := getTask(`"2", W/"3"`)
+
+			Expect(rec.Code).To(Equal(http.StatusNotModified))
+			Expect(rec.Body.Len()).To(Equal(0))
+		})
+
+		It("returns the task when If-None-Match is stale", func() {
+			rec := getTask(`"2"`)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+		})
+
+		It("passes If-Match to the update", func() {
+			var received int64
+			eservice := services.MockTaskService{
+				FakeUpdateTask: func(ctx context.Context, task *models.Task, taskId string, version int64) error {
+					received = version
+					return nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/tasks/1", strings.NewReader(`{"title":"t","description":"d","status":"New"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("If-Match", `"7"`)
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(eservice).UpdateTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(received).To(Equal(int64(7)))
+		})
+
+		It("returns 412 on a version mismatch", func() {
+			eservice := services.MockTaskService{
+				FakeDeleteTaskById: func(ctx context.Context, taskId string, version int64, cascade bool) error {
+					return apperrors.NewPreconditionFailedError("task 1 has been modified")
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/tasks/1", nil)
+			c.Request.Header.Set("If-Match", `"2"`)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(eservice).DeleteTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusPreconditionFailed))
+		})
+
+		It("returns 412 for an If-Match that is not a task version", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/tasks/1", nil)
+			c.Request.Header.Set("If-Match", `W/"abc"`)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(services.MockTaskService{}).DeleteTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusPreconditionFailed))
+		})
+
+		It("returns the new ETag after a patch", func() {
+			eservice := services.MockTaskService{
+				FakePatchTask: func(ctx context.Context, taskId string, patch *models.TaskPatch, version int64) (*models.Task, error) {
+					Expect(version).To(Equal(int64(3)))
+					return &models.Task{Title: "Task", Description: "Description", Status: "Done", Version: 4}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPatch, "/tasks/1", strings.NewReader(`{"status":"Done"}`))
+			req.Header.Set("Content-Type", models.MergePatchContentType)
+			req.Header.Set("If-Match", `"3"`)
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = req
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTaskController(eservice).PatchTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("ETag")).To(Equal(`"4"`))
+		})
+	})
 })
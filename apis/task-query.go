@@ -0,0 +1,179 @@
+package apis
+
+import (
+	"TaskSvc/internals/models"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var taskSortFields = map[string]bool{
+	models.SortByCreatedAt: true,
+	models.SortByUpdatedAt: true,
+	models.SortByTitle:     true,
+	models.SortByStatus:    true,
+	models.SortByPriority:  true,
+}
+
+// the trash can also be sorted by the time the tasks were deleted, the latest first by default
+var trashSortFields = map[string]bool{
+	models.SortByCreatedAt: true,
+	models.SortByUpdatedAt: true,
+	models.SortByTitle:     true,
+	models.SortByStatus:    true,
+	models.SortByPriority:  true,
+	models.SortByDeletedAt: true,
+}
+
+// function to read the list query parameters
+// sort takes a field name or field.<key> of a custom field, prefixed with '-' for descending order
+func parseTaskQuery(c *gin.Context) (*models.TaskQuery, error) {
+	return parseTaskQueryWith(c, taskSortFields, models.SortByCreatedAt)
+}
+
+// function to read the query parameters of the trash, like those of the task list
+func parseTrashQuery(c *gin.Context) (*models.TaskQuery, error) {
+	return parseTaskQueryWith(c, trashSortFields, models.SortByDeletedAt)
+}
+
+func parseTaskQueryWith(c *gin.Context, sortFields map[string]bool, defaultSort string) (*models.TaskQuery, error) {
+	query := &models.TaskQuery{
+		Limit:    models.DefaultTaskLimit,
+		Cursor:   c.Query("cursor"),
+		SortBy:   defaultSort,
+		SortDesc: true,
+	}
+
+	if limit := c.Query("limit"); len(limit) > 0 {
+		value, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || value < 1 || value > models.MaxTaskLimit {
+			return nil, fmt.Errorf("limit must be between 1 and %d", models.MaxTaskLimit)
+		}
+		query.Limit = value
+	}
+
+	if sort := c.Query("sort"); len(sort) > 0 {
+		query.SortDesc = strings.HasPrefix(sort, "-")
+		query.SortBy = strings.TrimPrefix(sort, "-")
+		if !sortFields[query.SortBy] && !isCustomFieldParam(query.SortBy) {
+			return nil, fmt.Errorf("invalid sort field: %s", query.SortBy)
+		}
+	}
+
+	query.Assignee = strings.TrimSpace(c.Query("assignee"))
+	query.CreatedBy = strings.TrimSpace(c.Query("created_by"))
+
+	for _, status := range c.QueryArray("status") {
+		if len(strings.TrimSpace(status)) > 0 {
+			query.Status = append(query.Status, status)
+		}
+	}
+
+	// labels are stored in lower case
+	for _, label := range c.QueryArray("label") {
+		if label = strings.ToLower(strings.TrimSpace(label)); len(label) > 0 {
+			query.Labels = append(query.Labels, label)
+		}
+	}
+	query.LabelMatch = models.LabelMatchAny
+	if mode := c.Query("label_mode"); len(mode) > 0 {
+		if mode != models.LabelMatchAny && mode != models.LabelMatchAll {
+			return nil, fmt.Errorf("label_mode must be any or all")
+		}
+		query.LabelMatch = mode
+	}
+
+	var err error
+	if query.CustomFields, err = parseCustomFieldFilters(c); err != nil {
+		return nil, err
+	}
+	if query.CreatedAfter, err = parseTimeParam(c, "created_after"); err != nil {
+		return nil, err
+	}
+	if query.CreatedBefore, err = parseTimeParam(c, "created_before"); err != nil {
+		return nil, err
+	}
+	if query.UpdatedAfter, err = parseTimeParam(c, "updated_after"); err != nil {
+		return nil, err
+	}
+	if query.UpdatedBefore, err = parseTimeParam(c, "updated_before"); err != nil {
+		return nil, err
+	}
+	if query.DueAfter, err = parseTimeParam(c, "due_after"); err != nil {
+		return nil, err
+	}
+	if query.DueBefore, err = parseTimeParam(c, "due_before"); err != nil {
+		return nil, err
+	}
+
+	if overdue := c.Query("overdue"); len(overdue) > 0 {
+		if query.Overdue, err = strconv.ParseBool(overdue); err != nil {
+			return nil, fmt.Errorf("overdue must be true or false")
+		}
+	}
+	return query, nil
+}
+
+// function to read the custom field filters, field.<key> takes the values to match and
+// field.<key>.gte and field.<key>.lt the range. the values stay strings until the service types them
+func parseCustomFieldFilters(c *gin.Context) ([]*models.CustomFieldFilter, error) {
+	filters := map[string]*models.CustomFieldFilter{}
+	keys := []string{}
+	for name, values := range c.Request.URL.Query() {
+		if !isCustomFieldParam(name) {
+			continue
+		}
+		key := strings.TrimPrefix(name, models.CustomFieldParamPrefix)
+		bound := ""
+		if strings.HasSuffix(key, ".gte") || strings.HasSuffix(key, ".lt") {
+			bound = key[strings.LastIndex(key, ".")+1:]
+			key = key[:strings.LastIndex(key, ".")]
+		}
+		if len(key) == 0 || strings.Contains(key, ".") {
+			return nil, fmt.Errorf("invalid custom field filter: %s", name)
+		}
+		filter, ok := filters[key]
+		if !ok {
+			filter = &models.CustomFieldFilter{Key: key}
+			filters[key] = filter
+			keys = append(keys, key)
+		}
+		switch bound {
+		case "gte":
+			filter.Gte = values[0]
+		case "lt":
+			filter.Lt = values[0]
+		default:
+			for _, value := range values {
+				filter.Values = append(filter.Values, value)
+			}
+		}
+	}
+	// the map order is random, the filters are sorted so the same url always builds the same query
+	sort.Strings(keys)
+	var result []*models.CustomFieldFilter
+	for _, key := range keys {
+		result = append(result, filters[key])
+	}
+	return result, nil
+}
+
+func isCustomFieldParam(name string) bool {
+	return strings.HasPrefix(name, models.CustomFieldParamPrefix) && len(name) > len(models.CustomFieldParamPrefix)
+}
+
+func parseTimeParam(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if len(value) == 0 {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC3339 timestamp", name)
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,121 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TemplateController struct {
+	templateService services.TemplateService
+}
+
+func NewTemplateController(templateService services.TemplateService) *TemplateController {
+	return &TemplateController{templateService: templateService}
+}
+
+func (t *TemplateController) GetTemplates(c *gin.Context) {
+	templates, err := t.templateService.GetTemplates(c)
+	if err != nil {
+		respondError(c, err, "Failed to fetch templates")
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+func (t *TemplateController) GetTemplateById(c *gin.Context) {
+	templateId, ok := templateIdParam(c)
+	if !ok {
+		return
+	}
+	template, err := t.templateService.GetTemplateById(c, templateId)
+	if err != nil {
+		respondError(c, err, "Failed to fetch template")
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+func (t *TemplateController) CreateTemplate(c *gin.Context) {
+	var template *models.Template
+	if err := c.ShouldBindJSON(&template); err != nil || template == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+
+	created, err := t.templateService.CreateTemplate(c, template)
+	if err != nil {
+		respondError(c, err, "Failed to create template")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (t *TemplateController) UpdateTemplate(c *gin.Context) {
+	templateId, ok := templateIdParam(c)
+	if !ok {
+		return
+	}
+	var template *models.Template
+	if err := c.ShouldBindJSON(&template); err != nil || template == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+
+	updated, err := t.templateService.UpdateTemplate(c, template, templateId)
+	if err != nil {
+		respondError(c, err, "Failed to update template")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// function to delete the template, the tasks created from it stay
+func (t *TemplateController) DeleteTemplate(c *gin.Context) {
+	templateId, ok := templateIdParam(c)
+	if !ok {
+		return
+	}
+	if err := t.templateService.DeleteTemplate(c, templateId); err != nil {
+		respondError(c, err, "Failed to delete template")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// function to create the tasks of the template, the body is optional for templates without variables
+func (t *TemplateController) InstantiateTemplate(c *gin.Context) {
+	templateId, ok := templateIdParam(c)
+	if !ok {
+		return
+	}
+	var instantiation models.TemplateInstantiation
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&instantiation); err != nil {
+			c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+			return
+		}
+	}
+
+	instance, err := t.templateService.InstantiateTemplate(c, templateId, &instantiation)
+	if err != nil {
+		respondError(c, err, "Failed to instantiate template")
+		return
+	}
+	c.JSON(http.StatusCreated, instance)
+}
+
+// function to read the template id of the path, writes a 400 and returns false when it is missing
+func templateIdParam(c *gin.Context) (string, bool) {
+	templateId := c.Param("id")
+	if len(strings.TrimSpace(templateId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Template ID is required", nil))
+		return "", false
+	}
+	return templateId, true
+}
@@ -0,0 +1,86 @@
+package apis
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Template API Controller", func() {
+
+	Describe("CreateTemplate", func() {
+		It("invalid payload", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/templates", bytes.NewBufferString(`{"name":`))
+
+			NewTemplateController(services.MockTemplateService{}).CreateTemplate(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("reports an invalid template", func() {
+			service := services.MockTemplateService{
+				FakeCreateTemplate: func(ctx context.Context, template *models.Template) (*models.Template, error) {
+					Expect(template.Task.Subtasks).To(HaveLen(1))
+					return nil, apperrors.NewValidationError("Title is required", map[string]interface{}{"field": "task.subtasks.0.title"})
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/templates",
+				bytes.NewBufferString(`{"name":"Release","task":{"title":"Release","description":"d","subtasks":[{"description":"d"}]}}`))
+
+			NewTemplateController(service).CreateTemplate(c)
+
+			Expect(rec.Code).To(Equal(http.StatusUnprocessableEntity))
+		})
+	})
+
+	Describe("InstantiateTemplate", func() {
+		It("works without a body", func() {
+			service := services.MockTemplateService{
+				FakeInstantiateTemplate: func(ctx context.Context, templateId string, instantiation *models.TemplateInstantiation) (*models.TemplateInstance, error) {
+					Expect(templateId).To(Equal("t1"))
+					Expect(instantiation.Variables).To(BeEmpty())
+					return &models.TemplateInstance{ID: "a", TaskIDs: []string{"a", "b"}}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/templates/t1/instantiate", nil)
+			c.Params = gin.Params{{Key: "id", Value: "t1"}}
+
+			NewTemplateController(service).InstantiateTemplate(c)
+
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+			Expect(rec.Body.String()).To(MatchJSON(`{"id":"a","taskIds":["a","b"]}`))
+		})
+
+		It("reports a missing template", func() {
+			service := services.MockTemplateService{
+				FakeInstantiateTemplate: func(ctx context.Context, templateId string, instantiation *models.TemplateInstantiation) (*models.TemplateInstance, error) {
+					Expect(instantiation.Variables).To(Equal(map[string]string{"Version": "1.2"}))
+					return nil, apperrors.NewNotFoundError("template t1 not found")
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/templates/t1/instantiate", bytes.NewBufferString(`{"variables":{"Version":"1.2"}}`))
+			c.Params = gin.Params{{Key: "id", Value: "t1"}}
+
+			NewTemplateController(service).InstantiateTemplate(c)
+
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
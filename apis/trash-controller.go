@@ -0,0 +1,82 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashController struct {
+	trashService services.TrashService
+}
+
+func NewTrashController(trashService services.TrashService) *TrashController {
+	return &TrashController{trashService: trashService}
+}
+
+// function to list the tasks in the trash, it takes the parameters of the task list and sorts by deletedAt by default
+func (t *TrashController) GetTrash(c *gin.Context) {
+	query, err := parseTrashQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, err.Error(), nil))
+		return
+	}
+
+	tasks, err := t.trashService.GetTrash(c, query)
+	if err != nil {
+		respondError(c, err, "Failed to fetch the trash")
+		return
+	}
+	c.JSON(http.StatusOK, tasks)
+}
+
+// function to move the task out of the trash, returns the restored task and its entity tag like PatchTask
+func (t *TrashController) RestoreTask(c *gin.Context) {
+	taskId, ok := trashedTaskIdParam(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	task, err := t.trashService.RestoreTask(c, taskId, version)
+	if err != nil {
+		respondError(c, err, "Failed to restore task")
+		return
+	}
+	c.Header("ETag", formatETag(task.Version))
+	c.JSON(http.StatusOK, task)
+}
+
+// function to delete the task of the trash for good, it cannot be restored afterwards
+func (t *TrashController) DeleteTrashedTask(c *gin.Context) {
+	taskId, ok := trashedTaskIdParam(c)
+	if !ok {
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	if err := t.trashService.DeleteTrashedTask(c, taskId, version); err != nil {
+		respondError(c, err, "Failed to delete task")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func trashedTaskIdParam(c *gin.Context) (string, bool) {
+	taskId := c.Param("id")
+	if len(strings.TrimSpace(taskId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
+		return "", false
+	}
+	return taskId, true
+}
@@ -0,0 +1,104 @@
+package apis
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Trash API Controller", func() {
+
+	Describe("GetTrash", func() {
+		It("sorts by the time of deletion by default", func() {
+			service := services.MockTrashService{
+				FakeGetTrash: func(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
+					Expect(query.SortBy).To(Equal(models.SortByDeletedAt))
+					Expect(query.SortDesc).To(BeTrue())
+					Expect(query.Status).To(Equal([]string{"Done"}))
+					return &models.TaskList{Total: 0, Tasks: []*models.Task{}}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/trash?status=Done", nil)
+
+			NewTrashController(service).GetTrash(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+		})
+
+		It("rejects an unknown sort field", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/trash?sort=dueDate", nil)
+
+			NewTrashController(services.MockTrashService{}).GetTrash(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("RestoreTask", func() {
+		It("returns the restored task with its entity tag", func() {
+			service := services.MockTrashService{
+				FakeRestoreTask: func(ctx context.Context, taskId string, version int64) (*models.Task, error) {
+					Expect(taskId).To(Equal("1"))
+					Expect(version).To(Equal(int64(2)))
+					return &models.Task{Title: "Task", Version: 3}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/tasks/1/restore", nil)
+			c.Request.Header.Set("If-Match", `"2"`)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTrashController(service).RestoreTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("ETag")).To(Equal(`"3"`))
+		})
+
+		It("reports a parent still in the trash", func() {
+			service := services.MockTrashService{
+				FakeRestoreTask: func(ctx context.Context, taskId string, version int64) (*models.Task, error) {
+					return nil, apperrors.NewConflictError("the parent task 0 of task 1 is in the trash, restore it first", nil)
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/tasks/1/restore", nil)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTrashController(service).RestoreTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Describe("DeleteTrashedTask", func() {
+		It("reports a task that is not in the trash", func() {
+			service := services.MockTrashService{
+				FakeDeleteTrashedTask: func(ctx context.Context, taskId string, version int64) error {
+					return apperrors.NewNotFoundError("task 1 not found in the trash")
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/trash/1", nil)
+			c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+			NewTrashController(service).DeleteTrashedTask(c)
+
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+})
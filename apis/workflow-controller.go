@@ -0,0 +1,20 @@
+package apis
+
+import (
+	"TaskSvc/internals/workflow"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkflowController struct {
+	workflow *workflow.Workflow
+}
+
+func NewWorkflowController(workflow *workflow.Workflow) *WorkflowController {
+	return &WorkflowController{workflow: workflow}
+}
+
+func (w *WorkflowController) GetWorkflow(c *gin.Context) {
+	c.JSON(http.StatusOK, w.workflow)
+}
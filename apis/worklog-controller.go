@@ -0,0 +1,109 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorklogController struct {
+	worklogService services.WorklogService
+}
+
+func NewWorklogController(worklogService services.WorklogService) *WorklogController {
+	return &WorklogController{worklogService: worklogService}
+}
+
+func (w *WorklogController) GetWorklogs(c *gin.Context) {
+	taskId, ok := worklogTaskIdParam(c)
+	if !ok {
+		return
+	}
+	worklogs, err := w.worklogService.GetWorklogs(c, taskId)
+	if err != nil {
+		respondError(c, err, "Failed to fetch worklogs")
+		return
+	}
+	c.JSON(http.StatusOK, worklogs)
+}
+
+func (w *WorklogController) LogWork(c *gin.Context) {
+	taskId, ok := worklogTaskIdParam(c)
+	if !ok {
+		return
+	}
+	var input *models.WorklogInput
+	if err := c.ShouldBindJSON(&input); err != nil || input == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	version, ok := ifMatchVersion(c)
+	if !ok {
+		return
+	}
+
+	worklog, err := w.worklogService.LogWork(c, taskId, input, version)
+	if err != nil {
+		respondError(c, err, "Failed to log work")
+		return
+	}
+	c.JSON(http.StatusCreated, worklog)
+}
+
+func (w *WorklogController) StartTimer(c *gin.Context) {
+	taskId, ok := worklogTaskIdParam(c)
+	if !ok {
+		return
+	}
+	timer, err := w.worklogService.StartTimer(c, taskId)
+	if err != nil {
+		respondError(c, err, "Failed to start timer")
+		return
+	}
+	c.JSON(http.StatusCreated, timer)
+}
+
+// function to stop the timer of the caller, the body is optional and can only carry the note of the worklog
+func (w *WorklogController) StopTimer(c *gin.Context) {
+	taskId, ok := worklogTaskIdParam(c)
+	if !ok {
+		return
+	}
+	var input models.WorklogInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+			return
+		}
+	}
+
+	worklog, err := w.worklogService.StopTimer(c, taskId, input.Note)
+	if err != nil {
+		respondError(c, err, "Failed to stop timer")
+		return
+	}
+	c.JSON(http.StatusOK, worklog)
+}
+
+func (w *WorklogController) GetTimers(c *gin.Context) {
+	timers, err := w.worklogService.GetTimers(c)
+	if err != nil {
+		respondError(c, err, "Failed to fetch timers")
+		return
+	}
+	c.JSON(http.StatusOK, timers)
+}
+
+func worklogTaskIdParam(c *gin.Context) (string, bool) {
+	taskId := c.Param("id")
+	if len(strings.TrimSpace(taskId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Task ID is required", nil))
+		return "", false
+	}
+	return taskId, true
+}
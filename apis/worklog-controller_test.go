@@ -0,0 +1,111 @@
+package apis
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Worklog API Controller", func() {
+
+	Describe("LogWork", func() {
+		It("invalid payload", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/tasks/t1/worklogs", bytes.NewBufferString(`{"duration":"1h"}`))
+			c.Params = gin.Params{{Key: "id", Value: "t1"}}
+
+			NewWorklogController(services.MockWorklogService{}).LogWork(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("passes the If-Match version", func() {
+			service := services.MockWorklogService{
+				FakeLogWork: func(ctx context.Context, taskId string, input *models.WorklogInput, version int64) (*models.Worklog, error) {
+					Expect(taskId).To(Equal("t1"))
+					Expect(input.Duration).To(Equal(int64(900)))
+					Expect(version).To(Equal(int64(3)))
+					return &models.Worklog{TaskID: taskId, Duration: input.Duration}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/tasks/t1/worklogs", bytes.NewBufferString(`{"duration":900}`))
+			c.Request.Header.Set("If-Match", `"3"`)
+			c.Params = gin.Params{{Key: "id", Value: "t1"}}
+
+			NewWorklogController(service).LogWork(c)
+
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+		})
+	})
+
+	Describe("StopTimer", func() {
+		It("works without a body", func() {
+			service := services.MockWorklogService{
+				FakeStopTimer: func(ctx context.Context, taskId string, note string) (*models.Worklog, error) {
+					Expect(note).To(BeEmpty())
+					return nil, apperrors.NewNotFoundError("no timer is running on task t1")
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/tasks/t1/timer/stop", nil)
+			c.Params = gin.Params{{Key: "id", Value: "t1"}}
+
+			NewWorklogController(service).StopTimer(c)
+
+			Expect(rec.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("GetTimeReport", func() {
+		It("rejects an unknown group", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/reports/time?group_by=user,team", nil)
+
+			NewReportController(services.MockWorklogService{}).GetTimeReport(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+
+		It("exports the rows as csv", func() {
+			service := services.MockWorklogService{
+				FakeGetTimeReport: func(ctx context.Context, query *models.TimeReportQuery) (*models.TimeReport, error) {
+					Expect(query.GroupBy).To(Equal([]string{models.TimeReportByUser, models.TimeReportByDay}))
+					Expect(query.From).NotTo(BeNil())
+					return &models.TimeReport{GroupBy: query.GroupBy, Duration: 90, Rows: []*models.TimeReportRow{
+						{User: "user-1", Day: "2024-05-01", Duration: 60, Worklogs: 2},
+						{User: "user-2", Day: "2024-05-01", Duration: 30, Worklogs: 1},
+					}}, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/reports/time?group_by=day&group_by=user&from=2024-05-01T00:00:00Z", nil)
+			c.Request.Header.Set("Accept", "text/csv")
+
+			NewReportController(service).GetTimeReport(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			Expect(rec.Header().Get("Content-Type")).To(HavePrefix("text/csv"))
+			Expect(strings.Split(strings.TrimSpace(rec.Body.String()), "\n")).To(Equal([]string{
+				"user,day,duration,worklogs",
+				"user-1,2024-05-01,60,2",
+				"user-2,2024-05-01,30,1",
+			}))
+		})
+	})
+})
@@ -0,0 +1,175 @@
+package apis
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WorkspaceController struct {
+	workspaceService services.WorkspaceService
+}
+
+func NewWorkspaceController(workspaceService services.WorkspaceService) *WorkspaceController {
+	return &WorkspaceController{workspaceService: workspaceService}
+}
+
+func (w *WorkspaceController) GetWorkspaces(c *gin.Context) {
+	workspaces, err := w.workspaceService.GetWorkspaces(c)
+	if err != nil {
+		respondError(c, err, "Failed to fetch workspaces")
+		return
+	}
+	c.JSON(http.StatusOK, workspaces)
+}
+
+func (w *WorkspaceController) GetWorkspaceById(c *gin.Context) {
+	workspaceId, ok := workspaceIdParam(c)
+	if !ok {
+		return
+	}
+	workspace, err := w.workspaceService.GetWorkspaceById(c, workspaceId)
+	if err != nil {
+		respondError(c, err, "Failed to fetch workspace")
+		return
+	}
+	c.JSON(http.StatusOK, workspace)
+}
+
+func (w *WorkspaceController) CreateWorkspace(c *gin.Context) {
+	var workspace *models.Workspace
+	if err := c.ShouldBindJSON(&workspace); err != nil || workspace == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	if len(strings.TrimSpace(workspace.Name)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Name is required", nil))
+		return
+	}
+
+	created, err := w.workspaceService.CreateWorkspace(c, workspace)
+	if err != nil {
+		respondError(c, err, "Failed to create workspace")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (w *WorkspaceController) UpdateWorkspace(c *gin.Context) {
+	workspaceId, ok := workspaceIdParam(c)
+	if !ok {
+		return
+	}
+	var workspace *models.Workspace
+	if err := c.ShouldBindJSON(&workspace); err != nil || workspace == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	if len(strings.TrimSpace(workspace.Name)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Name is required", nil))
+		return
+	}
+
+	updated, err := w.workspaceService.UpdateWorkspace(c, workspace, workspaceId)
+	if err != nil {
+		respondError(c, err, "Failed to update workspace")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (w *WorkspaceController) DeleteWorkspace(c *gin.Context) {
+	workspaceId, ok := workspaceIdParam(c)
+	if !ok {
+		return
+	}
+	if err := w.workspaceService.DeleteWorkspaceById(c, workspaceId); err != nil {
+		respondError(c, err, "Failed to delete workspace")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (w *WorkspaceController) GetMembers(c *gin.Context) {
+	workspaceId, ok := workspaceIdParam(c)
+	if !ok {
+		return
+	}
+	members, err := w.workspaceService.GetMembers(c, workspaceId)
+	if err != nil {
+		respondError(c, err, "Failed to fetch members")
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+func (w *WorkspaceController) AddMember(c *gin.Context) {
+	workspaceId, ok := workspaceIdParam(c)
+	if !ok {
+		return
+	}
+	var membership *models.Membership
+	if err := c.ShouldBindJSON(&membership); err != nil || membership == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	if len(strings.TrimSpace(membership.Subject)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Subject is required", nil))
+		return
+	}
+
+	created, err := w.workspaceService.AddMember(c, workspaceId, membership)
+	if err != nil {
+		respondError(c, err, "Failed to add member")
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+
+func (w *WorkspaceController) UpdateMember(c *gin.Context) {
+	workspaceId, ok := workspaceIdParam(c)
+	if !ok {
+		return
+	}
+	var membership *models.Membership
+	if err := c.ShouldBindJSON(&membership); err != nil || membership == nil {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Invalid request payload", nil))
+		return
+	}
+	// the member is named by the path, a subject in the payload is ignored
+	membership.Subject = c.Param("subject")
+
+	updated, err := w.workspaceService.UpdateMember(c, workspaceId, membership)
+	if err != nil {
+		respondError(c, err, "Failed to update member")
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+func (w *WorkspaceController) RemoveMember(c *gin.Context) {
+	workspaceId, ok := workspaceIdParam(c)
+	if !ok {
+		return
+	}
+	if err := w.workspaceService.RemoveMember(c, workspaceId, c.Param("subject")); err != nil {
+		respondError(c, err, "Failed to remove member")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// function to read the workspace id of the path, writes a 400 and returns false when it is missing
+func workspaceIdParam(c *gin.Context) (string, bool) {
+	workspaceId := c.Param("id")
+	if len(strings.TrimSpace(workspaceId)) == 0 {
+		c.JSON(http.StatusBadRequest, commons.ApiErrorResponse(apperrors.BadRequest, "Workspace ID is required", nil))
+		return "", false
+	}
+	return workspaceId, true
+}
@@ -0,0 +1,95 @@
+package apis
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/services"
+
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Workspace API Controller", func() {
+
+	Describe("CreateWorkspace", func() {
+		It("valid", func() {
+			eservice := services.MockWorkspaceService{
+				FakeCreateWorkspace: func(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error) {
+					workspace.CreatedBy = "user-1"
+					return workspace, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/workspaces", bytes.NewBufferString(`{"id":"team-a","name":"Team A"}`))
+
+			NewWorkspaceController(eservice).CreateWorkspace(c)
+
+			Expect(rec.Code).To(Equal(http.StatusCreated))
+			var workspace models.Workspace
+			Expect(json.Unmarshal(rec.Body.Bytes(), &workspace)).To(Succeed())
+			Expect(workspace.ID).To(Equal("team-a"))
+			Expect(workspace.CreatedBy).To(Equal("user-1"))
+		})
+
+		It("name missing", func() {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPost, "/workspaces", bytes.NewBufferString(`{"id":"team-a"}`))
+
+			NewWorkspaceController(services.MockWorkspaceService{}).CreateWorkspace(c)
+
+			Expect(rec.Code).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Describe("DeleteWorkspace", func() {
+		It("workspace still has tasks", func() {
+			eservice := services.MockWorkspaceService{
+				FakeDeleteWorkspaceById: func(ctx context.Context, workspaceId string) error {
+					return apperrors.NewConflictError("workspace "+workspaceId+" still has 1 tasks", nil)
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/workspaces/team-a", nil)
+			c.Params = gin.Params{{Key: "id", Value: "team-a"}}
+
+			NewWorkspaceController(eservice).DeleteWorkspace(c)
+
+			Expect(rec.Code).To(Equal(http.StatusConflict))
+		})
+	})
+
+	Describe("UpdateMember", func() {
+		It("takes the subject from the path", func() {
+			eservice := services.MockWorkspaceService{
+				FakeUpdateMember: func(ctx context.Context, workspaceId string, membership *models.Membership) (*models.Membership, error) {
+					Expect(workspaceId).To(Equal("team-a"))
+					Expect(membership.Subject).To(Equal("user-2"))
+					membership.WorkspaceID = workspaceId
+					return membership, nil
+				},
+			}
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodPut, "/workspaces/team-a/members/user-2",
+				bytes.NewBufferString(`{"subject":"user-9","role":"viewer"}`))
+			c.Params = gin.Params{{Key: "id", Value: "team-a"}, {Key: "subject", Value: "user-2"}}
+
+			NewWorkspaceController(eservice).UpdateMember(c)
+
+			Expect(rec.Code).To(Equal(http.StatusOK))
+			var membership models.Membership
+			Expect(json.Unmarshal(rec.Body.Bytes(), &membership)).To(Succeed())
+			Expect(membership.Role).To(Equal("viewer"))
+		})
+	})
+})
@@ -0,0 +1,91 @@
+package appauth
+
+import (
+	"context"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// plain string keys so the values set on the gin context are also visible through context.Context
+const (
+	SubjectContextKey = "auth.subject"
+	ClaimsContextKey  = "auth.claims"
+
+	RolesContextKey       = "auth.roles"
+	PermissionsContextKey = "auth.permissions"
+
+	WorkspaceContextKey      = "auth.workspace"
+	WorkspaceRolesContextKey = "auth.workspaceRoles"
+)
+
+// DefaultWorkspace holds the tasks of callers that select no workspace, including every task
+// created before workspaces existed
+const DefaultWorkspace = "default"
+
+// function to get the verified token subject from the context
+func GetSubject(ctx context.Context) string {
+	if subject, ok := ctx.Value(SubjectContextKey).(string); ok {
+		return subject
+	}
+	return ""
+}
+
+// function to get the verified token claims from the context
+func GetClaims(ctx context.Context) jwt.MapClaims {
+	if claims, ok := ctx.Value(ClaimsContextKey).(jwt.MapClaims); ok {
+		return claims
+	}
+	return nil
+}
+
+// function to get the roles resolved for the caller
+func GetRoles(ctx context.Context) []string {
+	if roles, ok := ctx.Value(RolesContextKey).([]string); ok {
+		return roles
+	}
+	return nil
+}
+
+// function to check the roles of the caller grant the permission
+func HasPermission(ctx context.Context, permission Permission) bool {
+	permissions, _ := ctx.Value(PermissionsContextKey).([]Permission)
+	for _, granted := range permissions {
+		if granted == permission || granted == PermissionAll {
+			return true
+		}
+	}
+	return false
+}
+
+// function to get the workspace the request acts in, the default workspace when none was selected
+func GetWorkspace(ctx context.Context) string {
+	if workspace, ok := ctx.Value(WorkspaceContextKey).(string); ok && len(workspace) > 0 {
+		return workspace
+	}
+	return DefaultWorkspace
+}
+
+// function to get the roles the membership of the caller grants in the workspace
+func GetWorkspaceRoles(ctx context.Context) []string {
+	if roles, ok := ctx.Value(WorkspaceRolesContextKey).([]string); ok {
+		return roles
+	}
+	return nil
+}
+
+// function to set the workspace on a plain context, e.g. for background jobs
+func WithWorkspace(ctx context.Context, workspace string) context.Context {
+	return context.WithValue(ctx, WorkspaceContextKey, workspace) //nolint:staticcheck
+}
+
+// function to set the verified identity on a plain context, e.g. for background jobs
+func WithIdentity(ctx context.Context, subject string, claims jwt.MapClaims) context.Context {
+	ctx = context.WithValue(ctx, SubjectContextKey, subject) //nolint:staticcheck
+	return context.WithValue(ctx, ClaimsContextKey, claims)  //nolint:staticcheck
+}
+
+// function to set the roles and the permissions they grant on a plain context
+func WithAccess(ctx context.Context, roles []string, permissions []Permission) context.Context {
+	ctx = context.WithValue(ctx, RolesContextKey, roles)              //nolint:staticcheck
+	return context.WithValue(ctx, PermissionsContextKey, permissions) //nolint:staticcheck
+}
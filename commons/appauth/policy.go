@@ -0,0 +1,223 @@
+package appauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Permission string
+
+const (
+	PermissionTaskRead   Permission = "task:read"
+	PermissionTaskWrite  Permission = "task:write"
+	PermissionTaskDelete Permission = "task:delete"
+	// PermissionTaskManage lets the caller change and delete tasks they do not own
+	PermissionTaskManage Permission = "task:manage"
+	// PermissionProjectManage lets the caller create, change and delete the projects of the workspace
+	PermissionProjectManage Permission = "project:manage"
+	// PermissionLabelManage lets the caller rename and merge the labels of every task of the workspace
+	PermissionLabelManage Permission = "label:manage"
+	// PermissionFieldManage lets the caller define, change and delete the custom fields of the workspace
+	PermissionFieldManage Permission = "field:manage"
+	// PermissionTemplateManage lets the caller create, change and delete the task templates of the workspace
+	PermissionTemplateManage Permission = "template:manage"
+	// PermissionReportRead lets the caller read the time logged by every member of the workspace
+	PermissionReportRead Permission = "report:read"
+	// PermissionWorkspaceManage lets the caller create workspaces and manage the workspaces they own
+	PermissionWorkspaceManage Permission = "workspace:manage"
+	// PermissionWorkspaceAdmin lets the caller act in and manage every workspace without a membership
+	PermissionWorkspaceAdmin Permission = "workspace:admin"
+
+	// PermissionAll grants every permission
+	PermissionAll Permission = "*"
+)
+
+const (
+	ViewerRole     = "viewer"
+	MemberRole     = "member"
+	MaintainerRole = "maintainer"
+	AdminRole      = "admin"
+	// OwnerRole is the workspace role of the creator of a workspace, owners manage the workspace and its members
+	OwnerRole = "owner"
+)
+
+// Policy maps roles to permissions and resolves the roles of a caller,
+// from the roles claim of the token and from the roles assigned to the subject in the policy.
+// Callers without any known role get the DefaultRoles.
+// The roles of a workspace membership are defined apart in WorkspaceRoles, they only narrow the permissions
+// the caller has outside of the workspace so a membership never grants more than the roles of the caller.
+type Policy struct {
+	Roles          map[string][]Permission `json:"roles"`
+	WorkspaceRoles map[string][]Permission `json:"workspaceRoles"`
+	Subjects       map[string][]string     `json:"subjects"`
+	DefaultRoles   []string                `json:"defaultRoles"`
+	RolesClaim     string                  `json:"rolesClaim"`
+}
+
+// function to get the built-in policy, members work on their own tasks, maintainers on every task
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Roles: map[string][]Permission{
+			ViewerRole:     {PermissionTaskRead},
+			MemberRole:     {PermissionTaskRead, PermissionTaskWrite, PermissionTaskDelete, PermissionWorkspaceManage},
+			MaintainerRole: {PermissionTaskRead, PermissionTaskWrite, PermissionTaskDelete, PermissionTaskManage, PermissionProjectManage, PermissionFieldManage, PermissionTemplateManage, PermissionReportRead, PermissionWorkspaceManage},
+			AdminRole:      {PermissionAll},
+		},
+		WorkspaceRoles: defaultWorkspaceRoles(),
+		Subjects:       map[string][]string{},
+		DefaultRoles:   []string{MemberRole},
+		RolesClaim:     "roles",
+	}
+}
+
+// function to get the built-in workspace roles, owners keep every permission of their roles in the workspace
+func defaultWorkspaceRoles() map[string][]Permission {
+	return map[string][]Permission{
+		ViewerRole:     {PermissionTaskRead},
+		MemberRole:     {PermissionTaskRead, PermissionTaskWrite, PermissionTaskDelete},
+		MaintainerRole: {PermissionTaskRead, PermissionTaskWrite, PermissionTaskDelete, PermissionTaskManage, PermissionProjectManage, PermissionFieldManage, PermissionTemplateManage, PermissionReportRead},
+		OwnerRole:      {PermissionAll},
+	}
+}
+
+// function to load the policy from a json file, the built-in policy is used when path is empty
+func LoadPolicy(path string) (*Policy, error) {
+	if len(path) == 0 {
+		return DefaultPolicy(), nil
+	}
+	pbytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	policy := Policy{RolesClaim: "roles"}
+	if err := json.Unmarshal(pbytes, &policy); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %v", path, err)
+	}
+	if len(policy.WorkspaceRoles) == 0 {
+		policy.WorkspaceRoles = defaultWorkspaceRoles()
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %v", path, err)
+	}
+	return &policy, nil
+}
+
+func (p *Policy) validate() error {
+	if len(p.Roles) == 0 {
+		return fmt.Errorf("no roles")
+	}
+	if _, ok := p.WorkspaceRoles[OwnerRole]; !ok {
+		return fmt.Errorf("workspace role %s is not defined", OwnerRole)
+	}
+	for _, role := range p.DefaultRoles {
+		if _, ok := p.Roles[role]; !ok {
+			return fmt.Errorf("default role %s is not defined", role)
+		}
+	}
+	for subject, roles := range p.Subjects {
+		for _, role := range roles {
+			if _, ok := p.Roles[role]; !ok {
+				return fmt.Errorf("role %s of subject %s is not defined", role, subject)
+			}
+		}
+	}
+	return nil
+}
+
+// function to get the known roles of the caller, roles the policy does not define are ignored
+func (p *Policy) RolesFor(subject string, claims jwt.MapClaims) []string {
+	var roles []string
+	seen := map[string]bool{}
+	for _, role := range append(claimedRoles(claims, p.RolesClaim), p.Subjects[subject]...) {
+		if _, ok := p.Roles[role]; ok && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return p.DefaultRoles
+	}
+	return roles
+}
+
+// function to get the permissions granted by the roles
+func (p *Policy) PermissionsOf(roles []string) []Permission {
+	var permissions []Permission
+	seen := map[Permission]bool{}
+	for _, role := range roles {
+		for _, permission := range p.Roles[role] {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+// function to get the permissions of the roles in a workspace, the workspace roles of the membership
+// keep only the permissions the roles already grant, callers administering every workspace keep them all
+func (p *Policy) PermissionsIn(roles []string, workspaceRoles []string) []Permission {
+	granted := p.PermissionsOf(roles)
+	if len(workspaceRoles) == 0 || grantsPermission(granted, PermissionWorkspaceAdmin) {
+		return granted
+	}
+	var permissions []Permission
+	seen := map[Permission]bool{}
+	for _, role := range workspaceRoles {
+		for _, permission := range p.WorkspaceRoles[role] {
+			if permission == PermissionAll {
+				return granted
+			}
+			if !seen[permission] && grantsPermission(granted, permission) {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+// function to check the role is defined by the policy
+func (p *Policy) IsRole(role string) bool {
+	_, ok := p.Roles[role]
+	return ok
+}
+
+// function to check the role can be given to the member of a workspace
+func (p *Policy) IsWorkspaceRole(role string) bool {
+	_, ok := p.WorkspaceRoles[role]
+	return ok
+}
+
+func grantsPermission(permissions []Permission, permission Permission) bool {
+	for _, granted := range permissions {
+		if granted == permission || granted == PermissionAll {
+			return true
+		}
+	}
+	return false
+}
+
+// function to read the roles claim, it holds a list or a single space separated string
+func claimedRoles(claims jwt.MapClaims, name string) []string {
+	switch roles := claims[name].(type) {
+	case string:
+		return strings.Fields(roles)
+	case []interface{}:
+		values := make([]string, 0, len(roles))
+		for _, role := range roles {
+			if value, ok := role.(string); ok {
+				values = append(values, value)
+			}
+		}
+		return values
+	case []string:
+		return roles
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package appauth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (jwt.MapClaims, error)
+}
+
+type VerifierConfig struct {
+	HmacSecret string
+	JwksFile   string
+	Issuer     string
+	Audience   string
+	Leeway     time.Duration
+}
+
+type tokenVerifier struct {
+	hmacSecret []byte
+	keys       *keySet
+	parser     *jwt.Parser
+}
+
+func NewTokenVerifier(config VerifierConfig) (TokenVerifier, error) {
+	if len(config.HmacSecret) == 0 && len(config.JwksFile) == 0 {
+		return nil, fmt.Errorf("no jwt verification keys configured")
+	}
+
+	verifier := &tokenVerifier{}
+	if len(config.HmacSecret) > 0 {
+		verifier.hmacSecret = []byte(config.HmacSecret)
+	}
+	if len(config.JwksFile) > 0 {
+		keys, err := newKeySet(config.JwksFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keys = keys
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "RS256", "ES256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(config.Leeway),
+	}
+	if len(config.Issuer) > 0 {
+		opts = append(opts, jwt.WithIssuer(config.Issuer))
+	}
+	if len(config.Audience) > 0 {
+		opts = append(opts, jwt.WithAudience(config.Audience))
+	}
+	verifier.parser = jwt.NewParser(opts...)
+	return verifier, nil
+}
+
+// function to verify the token signature and the exp, nbf, iss and aud claims
+func (v *tokenVerifier) Verify(ctx context.Context, token string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.keyFunc); err != nil {
+		return nil, err
+	}
+	subject, err := claims.GetSubject()
+	if err != nil || len(subject) == 0 {
+		return nil, fmt.Errorf("token has no subject")
+	}
+	return claims, nil
+}
+
+func (v *tokenVerifier) keyFunc(token *jwt.Token) (interface{}, error) {
+	alg := token.Method.Alg()
+	kid, _ := token.Header["kid"].(string)
+
+	// shared secret tokens may omit the kid, asymmetric ones are always looked up in the jwks file
+	if len(kid) == 0 {
+		if alg == "HS256" && v.hmacSecret != nil {
+			return v.hmacSecret, nil
+		}
+		return nil, fmt.Errorf("token has no key id")
+	}
+	if v.keys == nil {
+		return nil, fmt.Errorf("unknown key id: %s", kid)
+	}
+
+	key, err := v.keys.lookup(kid)
+	if err != nil {
+		return nil, err
+	}
+	if len(key.alg) > 0 && key.alg != alg {
+		return nil, fmt.Errorf("key %s is not valid for %s", kid, alg)
+	}
+	return key.key, nil
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type DatabaseClient interface {
 	GetDbName() string
 	Disconnect(ctx context.Context)
 	Collection(collection string) DatabaseCollection
+	GridFSBucket(name string) (*gridfs.Bucket, error)
 }
 
 type dbclient struct {
@@ -38,3 +41,8 @@ func (d *dbclient) Collection(collection string) DatabaseCollection {
 func (d *dbclient) GetDbName() string {
 	return d.databaseName
 }
+
+// function to get a gridfs bucket of the database, the bucket collections are prefixed with its name
+func (d *dbclient) GridFSBucket(name string) (*gridfs.Bucket, error) {
+	return gridfs.NewBucket(d.client.Database(d.databaseName), options.GridFSBucket().SetName(name))
+}
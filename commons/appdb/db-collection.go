@@ -9,7 +9,7 @@ import (
 
 type DatabaseCollection interface {
 	FindOne(ctx context.Context, filter interface{}, document interface{}) error
-	FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}) error
+	FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, document interface{}, opts ...*options.FindOneAndUpdateOptions) error
 	InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error)
 	UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
 	UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error)
@@ -18,9 +18,10 @@ type DatabaseCollection interface {
 	Aggregate(ctx context.Context, pipeline interface{}, response interface{}) error
 	DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
 	DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error)
-	Distinct(ctx context.Context, field string, response interface{}) ([]interface{}, error)
+	Distinct(ctx context.Context, field string, filter interface{}) ([]interface{}, error)
 	Drop(ctx context.Context) error
 	InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error)
+	CreateIndexes(ctx context.Context, models []mongo.IndexModel) ([]string, error)
 }
 
 type dbcollection struct {
@@ -45,11 +46,15 @@ func (d *dbcollection) FindOne(ctx context.Context, filter interface{}, document
 	return d.collection.FindOne(ctx, filter).Decode(document)
 }
 
-func (d *dbcollection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}) error {
-	result := d.collection.FindOneAndUpdate(ctx, filter, update)
+// function to update a document and decode it, document can be nil when the result is not needed
+func (d *dbcollection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, document interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	result := d.collection.FindOneAndUpdate(ctx, filter, update, opts...)
 	if result.Err() != nil {
 		return result.Err()
 	}
+	if document != nil {
+		return result.Decode(document)
+	}
 	return nil
 }
 
@@ -85,6 +90,11 @@ func (d *dbcollection) Drop(ctx context.Context) error {
 	return d.collection.Drop(ctx)
 }
 
+// function to create the indexes, indexes that already exist with the same options are left as they are
+func (d *dbcollection) CreateIndexes(ctx context.Context, models []mongo.IndexModel) ([]string, error) {
+	return d.collection.Indexes().CreateMany(ctx, models)
+}
+
 func (d *dbcollection) Aggregate(ctx context.Context, pipeline interface{}, response interface{}) error {
 	results, dberror := d.collection.Aggregate(ctx, pipeline)
 	if dberror != nil {
@@ -94,9 +104,8 @@ func (d *dbcollection) Aggregate(ctx context.Context, pipeline interface{}, resp
 	return results.All(ctx, response)
 }
 
-func (d *dbcollection) Distinct(
-	ctx context.Context, field string, response interface{}) ([]interface{}, error) {
-	return d.collection.Distinct(ctx, field, response)
+func (d *dbcollection) Distinct(ctx context.Context, field string, filter interface{}) ([]interface{}, error) {
+	return d.collection.Distinct(ctx, field, filter)
 }
 
 func (d *dbcollection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
@@ -0,0 +1,92 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Code string
+
+const (
+	NotFound   Code = "NOT_FOUND"
+	InvalidID  Code = "INVALID_ID"
+	BadRequest Code = "BAD_REQUEST"
+	Conflict   Code = "CONFLICT"
+	Validation Code = "VALIDATION_FAILED"
+	Forbidden  Code = "FORBIDDEN"
+	Internal   Code = "INTERNAL_ERROR"
+
+	PreconditionFailed   Code = "PRECONDITION_FAILED"
+	UnsupportedMediaType Code = "UNSUPPORTED_MEDIA_TYPE"
+	PayloadTooLarge      Code = "PAYLOAD_TOO_LARGE"
+)
+
+// AppError is a domain error raised by the db and service layers,
+// the code tells the api layer how to report it
+type AppError struct {
+	Code    Code
+	Message string
+	Details map[string]interface{}
+	Err     error
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+func NewNotFoundError(message string) *AppError {
+	return &AppError{Code: NotFound, Message: message}
+}
+
+func NewInvalidIdError(id string, err error) *AppError {
+	return &AppError{Code: InvalidID, Message: fmt.Sprintf("invalid id: %s", id), Err: err}
+}
+
+func NewBadRequestError(message string) *AppError {
+	return &AppError{Code: BadRequest, Message: message}
+}
+
+func NewConflictError(message string, err error) *AppError {
+	return &AppError{Code: Conflict, Message: message, Err: err}
+}
+
+func NewForbiddenError(message string) *AppError {
+	return &AppError{Code: Forbidden, Message: message}
+}
+
+func NewPreconditionFailedError(message string) *AppError {
+	return &AppError{Code: PreconditionFailed, Message: message}
+}
+
+func NewUnsupportedMediaTypeError(message string, details map[string]interface{}) *AppError {
+	return &AppError{Code: UnsupportedMediaType, Message: message, Details: details}
+}
+
+func NewPayloadTooLargeError(message string, details map[string]interface{}) *AppError {
+	return &AppError{Code: PayloadTooLarge, Message: message, Details: details}
+}
+
+func NewValidationError(message string, details map[string]interface{}) *AppError {
+	return &AppError{Code: Validation, Message: message, Details: details}
+}
+
+// function to get the code of the error, Internal for errors that are not an AppError
+func CodeOf(err error) Code {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return Internal
+}
+
+// function to check if the error, or any error it wraps, has the code
+func Is(err error, code Code) bool {
+	return CodeOf(err) == code
+}
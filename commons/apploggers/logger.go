@@ -18,6 +18,9 @@ func (c corelationId) String() string {
 
 var corelationIdContext = corelationId("id")
 
+// fallbackLogger is used for contexts without a logger, it is built once
+var fallbackLogger = NewZapLogger().Sugar()
+
 // function to return new logger instance, with correlationid
 func NewLoggerWithCorrelationid(ctx context.Context, correlationid string) (context.Context, *zap.SugaredLogger) {
 	ctx = SetCorrelation(ctx, correlationid)
@@ -31,11 +34,12 @@ func NewLoggerWithCorrelationid(ctx context.Context, correlationid string) (cont
 }
 
 // function to get logger instance from the context
+// function will return the shared fallback logger, if the context has none
 func GetLoggerWithCorrelationid(ctx context.Context) *zap.SugaredLogger {
 	if logger, _ := ctx.Value(loggerKey{}).(*zap.Logger); logger != nil {
 		return logger.Sugar()
 	}
-	return nil
+	return fallbackLogger
 }
 
 // function to return new logger instance
@@ -0,0 +1,16 @@
+package commons
+
+import "time"
+
+// Clock is the source of the current time, injected so tests can pin it
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+var SystemClock Clock = systemClock{}
@@ -1,11 +1,13 @@
 package commons
 
 import (
+	"TaskSvc/commons/apperrors"
 	"encoding/json"
 )
 
 type ApiErrorResponsePayload struct {
 	Status         string                 `json:"status"`
+	Code           apperrors.Code         `json:"code,omitempty"`
 	Message        string                 `json:"message"`
 	AdditionalInfo map[string]interface{} `json:"additional_info,omitempty"`
 }
@@ -15,9 +17,10 @@ func PrintStruct(payload interface{}) string {
 	return string(pbytes)
 }
 
-func ApiErrorResponse(message string, additionalInfo map[string]interface{}) *ApiErrorResponsePayload {
+func ApiErrorResponse(code apperrors.Code, message string, additionalInfo map[string]interface{}) *ApiErrorResponsePayload {
 	response := &ApiErrorResponsePayload{
 		Status:  "Error",
+		Code:    code,
 		Message: message,
 	}
 	if len(additionalInfo) > 0 {
@@ -3,6 +3,8 @@ package commons
 import (
 	dbmodels "TaskSvc/internals/db/models"
 	"TaskSvc/internals/models"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func MapToModel(taskSchema *dbmodels.TaskSchema) *models.Task {
@@ -11,7 +13,293 @@ func MapToModel(taskSchema *dbmodels.TaskSchema) *models.Task {
 		Title:       taskSchema.Title,
 		Description: taskSchema.Description,
 		Status:      taskSchema.Status,
+		Priority:    taskSchema.Priority,
+		StartDate:   taskSchema.StartDate,
+		DueDate:     taskSchema.DueDate,
+		TimeSpent:   taskSchema.TimeSpent,
+		CreatedBy:   taskSchema.CreatedBy,
+		Reporter:    taskSchema.Reporter,
+		Assignees:   taskSchema.Assignees,
+		Labels:      taskSchema.Labels,
+		ProjectID:   taskSchema.ProjectID,
+		ParentID:    taskSchema.ParentID,
+		BlockedBy:   HexIds(taskSchema.BlockedBy),
+		Attachments: MapToAttachmentModels(taskSchema.Attachments),
+		Checklist:   MapToChecklistModels(taskSchema.Checklist),
+		Key:         taskSchema.Key,
+		WorkspaceID: taskSchema.WorkspaceID,
 		CreatedAt:   taskSchema.CreatedAt,
 		UpdatedAt:   taskSchema.UpdatedAt,
+		Version:     taskSchema.Version,
+
+		OriginalEstimate:  taskSchema.OriginalEstimate,
+		RemainingEstimate: taskSchema.RemainingEstimate,
+		CustomFields:      MapToCustomFieldValues(taskSchema.CustomFields),
+		ChecklistProgress: checklistProgress(taskSchema.Checklist),
+
+		Recurrence:       taskSchema.Recurrence,
+		RecurrenceOf:     taskSchema.RecurrenceOf,
+		NextOccurrenceID: taskSchema.NextOccurrenceID,
+
+		DeletedAt: taskSchema.DeletedAt,
+		DeletedBy: taskSchema.DeletedBy,
+	}
+}
+
+// function to map the checklist of a task, nil when it has no items
+func MapToChecklistModels(itemSchemas []dbmodels.ChecklistItemSchema) []models.ChecklistItem {
+	if len(itemSchemas) == 0 {
+		return nil
+	}
+	items := make([]models.ChecklistItem, len(itemSchemas))
+	for i, itemSchema := range itemSchemas {
+		items[i] = models.ChecklistItem{
+			ID:     itemSchema.ID,
+			Text:   itemSchema.Text,
+			Done:   itemSchema.Done,
+			DoneBy: itemSchema.DoneBy,
+			DoneAt: itemSchema.DoneAt,
+		}
+	}
+	return items
+}
+
+// function to count the items of the checklist that are done, nil when it has no items
+func checklistProgress(itemSchemas []dbmodels.ChecklistItemSchema) *models.ChecklistProgress {
+	if len(itemSchemas) == 0 {
+		return nil
+	}
+	progress := &models.ChecklistProgress{Total: len(itemSchemas)}
+	for _, itemSchema := range itemSchemas {
+		if itemSchema.Done {
+			progress.Done++
+		}
+	}
+	return progress
+}
+
+// function to map the attachments of a task, nil when there are none
+func MapToAttachmentModels(attachmentSchemas []dbmodels.AttachmentSchema) []models.Attachment {
+	if len(attachmentSchemas) == 0 {
+		return nil
+	}
+	attachments := make([]models.Attachment, len(attachmentSchemas))
+	for i, attachmentSchema := range attachmentSchemas {
+		attachments[i] = models.Attachment{
+			ID:          attachmentSchema.ID,
+			Name:        attachmentSchema.Name,
+			Size:        attachmentSchema.Size,
+			ContentType: attachmentSchema.ContentType,
+			SHA256:      attachmentSchema.SHA256,
+			UploadedBy:  attachmentSchema.UploadedBy,
+			UploadedAt:  attachmentSchema.UploadedAt,
+		}
+	}
+	return attachments
+}
+
+// function to get the hex form of the ids, nil when there are none
+func HexIds(ids []primitive.ObjectID) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	hex := make([]string, len(ids))
+	for i, id := range ids {
+		hex[i] = id.Hex()
+	}
+	return hex
+}
+
+func MapToSchema(task *models.Task) *dbmodels.TaskSchema {
+	return &dbmodels.TaskSchema{
+		ID:           task.ID,
+		Title:        task.Title,
+		Description:  task.Description,
+		Status:       task.Status,
+		Priority:     task.Priority,
+		PriorityRank: models.PriorityRank(task.Priority),
+		StartDate:    task.StartDate,
+		DueDate:      task.DueDate,
+		CreatedBy:    task.CreatedBy,
+		Reporter:     task.Reporter,
+		Assignees:    task.Assignees,
+		Labels:       task.Labels,
+		CustomFields: task.CustomFields,
+		ProjectID:    task.ProjectID,
+		ParentID:     task.ParentID,
+		CreatedAt:    task.CreatedAt,
+		UpdatedAt:    task.UpdatedAt,
+		Version:      task.Version,
+
+		// the time spent is only changed by the worklogs
+		OriginalEstimate:  task.OriginalEstimate,
+		RemainingEstimate: task.RemainingEstimate,
+
+		// the links between the occurrences are only set by the scheduler
+		Recurrence: task.Recurrence,
+	}
+}
+
+func MapToWorkspaceModel(workspaceSchema *dbmodels.WorkspaceSchema) *models.Workspace {
+	return &models.Workspace{
+		ID:        workspaceSchema.ID,
+		Name:      workspaceSchema.Name,
+		CreatedBy: workspaceSchema.CreatedBy,
+		CreatedAt: workspaceSchema.CreatedAt,
+		UpdatedAt: workspaceSchema.UpdatedAt,
+	}
+}
+
+func MapToMembershipModel(membershipSchema *dbmodels.MembershipSchema) *models.Membership {
+	return &models.Membership{
+		WorkspaceID: membershipSchema.WorkspaceID,
+		Subject:     membershipSchema.Subject,
+		Role:        membershipSchema.Role,
+		CreatedAt:   membershipSchema.CreatedAt,
+		UpdatedAt:   membershipSchema.UpdatedAt,
+	}
+}
+
+func MapToProjectModel(projectSchema *dbmodels.ProjectSchema) *models.Project {
+	return &models.Project{
+		ID:          projectSchema.ID,
+		Key:         projectSchema.Key,
+		Name:        projectSchema.Name,
+		Description: projectSchema.Description,
+		Workflow:    projectSchema.Workflow,
+		TaskCount:   projectSchema.TaskCounter,
+		CreatedBy:   projectSchema.CreatedBy,
+		CreatedAt:   projectSchema.CreatedAt,
+		UpdatedAt:   projectSchema.UpdatedAt,
+	}
+}
+
+func MapToCustomFieldModel(fieldSchema *dbmodels.CustomFieldSchema) *models.CustomField {
+	return &models.CustomField{
+		ID:        fieldSchema.ID,
+		Key:       fieldSchema.Key,
+		Name:      fieldSchema.Name,
+		Type:      fieldSchema.Type,
+		Required:  fieldSchema.Required,
+		Default:   MapToCustomFieldValue(fieldSchema.Default),
+		Options:   fieldSchema.Options,
+		CreatedBy: fieldSchema.CreatedBy,
+		CreatedAt: fieldSchema.CreatedAt,
+		UpdatedAt: fieldSchema.UpdatedAt,
+	}
+}
+
+// function to map the custom field values of a task, nil when it has none
+func MapToCustomFieldValues(values map[string]interface{}) map[string]interface{} {
+	if len(values) == 0 {
+		return nil
+	}
+	mapped := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		mapped[key] = MapToCustomFieldValue(value)
+	}
+	return mapped
+}
+
+// function to turn the dates read back from bson into time values, the other types decode as they were written
+func MapToCustomFieldValue(value interface{}) interface{} {
+	if date, ok := value.(primitive.DateTime); ok {
+		return date.Time().UTC()
+	}
+	return value
+}
+
+func MapToCommentModel(commentSchema *dbmodels.CommentSchema) *models.Comment {
+	comment := &models.Comment{
+		ID:        commentSchema.ID,
+		TaskID:    commentSchema.TaskID,
+		Author:    commentSchema.Author,
+		Body:      commentSchema.Body,
+		CreatedAt: commentSchema.CreatedAt,
+		UpdatedAt: commentSchema.UpdatedAt,
+		Version:   commentSchema.Version,
+	}
+	for _, revision := range commentSchema.History {
+		comment.History = append(comment.History, models.CommentRevision{Body: revision.Body, EditedAt: revision.EditedAt})
+	}
+	return comment
+}
+
+func MapToWorklogModel(worklogSchema *dbmodels.WorklogSchema) *models.Worklog {
+	return &models.Worklog{
+		ID:        worklogSchema.ID,
+		TaskID:    worklogSchema.TaskID,
+		ProjectID: worklogSchema.ProjectID,
+		Author:    worklogSchema.Author,
+		Started:   worklogSchema.Started,
+		Duration:  worklogSchema.Duration,
+		Note:      worklogSchema.Note,
+		CreatedAt: worklogSchema.CreatedAt,
+	}
+}
+
+func MapToTimerModel(timerSchema *dbmodels.TimerSchema) *models.Timer {
+	return &models.Timer{
+		ID:        timerSchema.ID,
+		TaskID:    timerSchema.TaskID,
+		Subject:   timerSchema.Subject,
+		StartedAt: timerSchema.StartedAt,
+	}
+}
+
+func MapToTemplateModel(templateSchema *dbmodels.TemplateSchema) *models.Template {
+	return &models.Template{
+		ID:          templateSchema.ID,
+		Name:        templateSchema.Name,
+		Description: templateSchema.Description,
+		Task:        MapToTemplateTaskModel(templateSchema.Task),
+		Variables:   templateSchema.Variables,
+		CreatedBy:   templateSchema.CreatedBy,
+		CreatedAt:   templateSchema.CreatedAt,
+		UpdatedAt:   templateSchema.UpdatedAt,
+	}
+}
+
+// function to map a task of a template along with its subtasks
+func MapToTemplateTaskModel(taskSchema *dbmodels.TemplateTaskSchema) *models.TemplateTask {
+	if taskSchema == nil {
+		return nil
+	}
+	task := &models.TemplateTask{
+		Title:            taskSchema.Title,
+		Description:      taskSchema.Description,
+		Priority:         taskSchema.Priority,
+		Assignees:        taskSchema.Assignees,
+		Labels:           taskSchema.Labels,
+		Checklist:        taskSchema.Checklist,
+		StartInDays:      taskSchema.StartInDays,
+		DueInDays:        taskSchema.DueInDays,
+		OriginalEstimate: taskSchema.OriginalEstimate,
+	}
+	for _, subtask := range taskSchema.Subtasks {
+		task.Subtasks = append(task.Subtasks, MapToTemplateTaskModel(subtask))
+	}
+	return task
+}
+
+// function to map a task of a template to its schema along with its subtasks
+func MapToTemplateTaskSchema(task *models.TemplateTask) *dbmodels.TemplateTaskSchema {
+	if task == nil {
+		return nil
+	}
+	taskSchema := &dbmodels.TemplateTaskSchema{
+		Title:            task.Title,
+		Description:      task.Description,
+		Priority:         task.Priority,
+		Assignees:        task.Assignees,
+		Labels:           task.Labels,
+		Checklist:        task.Checklist,
+		StartInDays:      task.StartInDays,
+		DueInDays:        task.DueInDays,
+		OriginalEstimate: task.OriginalEstimate,
+	}
+	for _, subtask := range task.Subtasks {
+		taskSchema.Subtasks = append(taskSchema.Subtasks, MapToTemplateTaskSchema(subtask))
 	}
+	return taskSchema
 }
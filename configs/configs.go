@@ -1,10 +1,17 @@
 package configs
 
 import (
+	"TaskSvc/commons/appauth"
 	"TaskSvc/commons/appdb"
 	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/workflow"
 	"context"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -16,8 +23,23 @@ var (
 )
 
 type ApplicationConfig struct {
-	HttpPort string
-	DbClient appdb.DatabaseClient
+	HttpPort       string
+	StorageBackend string
+	BoltFile       string
+	DbClient       appdb.DatabaseClient
+	// BlobStore keeps the content of the attachments, gridfs by default on the mongo backend and local otherwise
+	BlobStore         string
+	AttachmentDir     string
+	AttachmentMaxSize int64
+	AttachmentTypes   []string
+	TokenVerifier     appauth.TokenVerifier
+	Policy            *appauth.Policy
+	Workflow          *workflow.Workflow
+	// RecurrenceInterval is how often the scheduler looks for recurring tasks to create the next occurrence of
+	RecurrenceInterval time.Duration
+	// TrashRetention is how long a deleted task stays in the trash, PurgeInterval how often the expired ones are purged
+	TrashRetention time.Duration
+	PurgeInterval  time.Duration
 }
 
 func NewApplicationConfig(context context.Context) error {
@@ -26,20 +48,126 @@ func NewApplicationConfig(context context.Context) error {
 		return err
 	}
 
-	// Create new mongo client and connect to the server
-	serverAPI := options.ServerAPI(options.ServerAPIVersion1)
-	opts := options.Client().ApplyURI(os.Getenv(MONGO_URI)).SetServerAPIOptions(serverAPI)
-	client, cerror := mongo.Connect(context, opts)
-	if cerror != nil {
-		logger.Errorf("Error while connecting db, error: ", cerror)
-		panic(cerror)
+	storageBackend := os.Getenv(STORAGE_BACKEND)
+	if len(storageBackend) == 0 {
+		storageBackend = STORAGE_MONGO
+	}
+
+	var dbClient appdb.DatabaseClient
+	switch storageBackend {
+	case STORAGE_MONGO:
+		// Create new mongo client and connect to the server
+		serverAPI := options.ServerAPI(options.ServerAPIVersion1)
+		opts := options.Client().ApplyURI(os.Getenv(MONGO_URI)).SetServerAPIOptions(serverAPI)
+		client, cerror := mongo.Connect(context, opts)
+		if cerror != nil {
+			logger.Errorf("Error while connecting db, error: ", cerror)
+			panic(cerror)
+		}
+
+		logger.Info("You successfully connected to MongoDB!")
+		dbClient = appdb.NewDatabaseClient(os.Getenv(MONGO_DATABASE), client)
+	case STORAGE_MEMORY:
+		logger.Warn("Using the in-memory storage backend, data is lost on restart")
+	case STORAGE_BOLT:
+		if len(os.Getenv(BOLT_FILE)) == 0 {
+			return fmt.Errorf("%s is required for the %s storage backend", BOLT_FILE, STORAGE_BOLT)
+		}
+		logger.Infof("Using the bolt storage backend at %s", os.Getenv(BOLT_FILE))
+	default:
+		return fmt.Errorf("unknown %s: %s", STORAGE_BACKEND, storageBackend)
+	}
+
+	tokenVerifier, err := appauth.NewTokenVerifier(appauth.VerifierConfig{
+		HmacSecret: os.Getenv(JWT_HS256_SECRET),
+		JwksFile:   os.Getenv(JWT_JWKS_FILE),
+		Issuer:     os.Getenv(JWT_ISSUER),
+		Audience:   os.Getenv(JWT_AUDIENCE),
+		Leeway:     30 * time.Second,
+	})
+	if err != nil {
+		logger.Errorf("Error while loading jwt keys, error: ", err)
+		return err
+	}
+
+	policy, err := appauth.LoadPolicy(os.Getenv(RBAC_POLICY_FILE))
+	if err != nil {
+		logger.Errorf("Error while loading the rbac policy, error: ", err)
+		return err
+	}
+
+	taskWorkflow, err := workflow.Load(os.Getenv(WORKFLOW_FILE))
+	if err != nil {
+		logger.Errorf("Error while loading the workflow, error: ", err)
+		return err
+	}
+
+	blobStore := os.Getenv(BLOB_STORE)
+	if len(blobStore) == 0 {
+		blobStore = BLOB_STORE_LOCAL
+		if storageBackend == STORAGE_MONGO {
+			blobStore = BLOB_STORE_GRIDFS
+		}
+	}
+	if blobStore == BLOB_STORE_GRIDFS && storageBackend != STORAGE_MONGO {
+		return fmt.Errorf("the %s blob store requires the %s storage backend", BLOB_STORE_GRIDFS, STORAGE_MONGO)
+	}
+	attachmentDir := os.Getenv(ATTACHMENT_DIR)
+	if len(attachmentDir) == 0 {
+		attachmentDir = "attachments"
+	}
+	attachmentMaxSize := int64(models.DefaultMaxAttachmentSize)
+	if value := os.Getenv(ATTACHMENT_MAX_BYTES); len(value) > 0 {
+		if attachmentMaxSize, err = strconv.ParseInt(value, 10, 64); err != nil || attachmentMaxSize < 1 {
+			return fmt.Errorf("%s must be a positive number of bytes: %s", ATTACHMENT_MAX_BYTES, value)
+		}
+	}
+	attachmentTypes := models.DefaultAttachmentTypes
+	if value := os.Getenv(ATTACHMENT_TYPES); len(value) > 0 {
+		attachmentTypes = nil
+		for _, contentType := range strings.Split(value, ",") {
+			if contentType = strings.TrimSpace(contentType); len(contentType) > 0 {
+				attachmentTypes = append(attachmentTypes, strings.ToLower(contentType))
+			}
+		}
+	}
+
+	recurrenceInterval := time.Minute
+	if value := os.Getenv(RECURRENCE_INTERVAL); len(value) > 0 {
+		if recurrenceInterval, err = time.ParseDuration(value); err != nil || recurrenceInterval <= 0 {
+			return fmt.Errorf("%s must be a positive duration: %s", RECURRENCE_INTERVAL, value)
+		}
+	}
+
+	trashRetention := 30 * 24 * time.Hour
+	if value := os.Getenv(TRASH_RETENTION); len(value) > 0 {
+		if trashRetention, err = time.ParseDuration(value); err != nil || trashRetention <= 0 {
+			return fmt.Errorf("%s must be a positive duration: %s", TRASH_RETENTION, value)
+		}
+	}
+	purgeInterval := time.Hour
+	if value := os.Getenv(PURGE_INTERVAL); len(value) > 0 {
+		if purgeInterval, err = time.ParseDuration(value); err != nil || purgeInterval <= 0 {
+			return fmt.Errorf("%s must be a positive duration: %s", PURGE_INTERVAL, value)
+		}
 	}
 
-	logger.Info("You successfully connected to MongoDB!")
-	dbClient := appdb.NewDatabaseClient(os.Getenv(MONGO_DATABASE), client)
 	AppConfig = &ApplicationConfig{
-		HttpPort: os.Getenv(HTTP_PORT),
-		DbClient: dbClient,
+		HttpPort:          os.Getenv(HTTP_PORT),
+		StorageBackend:    storageBackend,
+		BoltFile:          os.Getenv(BOLT_FILE),
+		DbClient:          dbClient,
+		BlobStore:         blobStore,
+		AttachmentDir:     attachmentDir,
+		AttachmentMaxSize: attachmentMaxSize,
+		AttachmentTypes:   attachmentTypes,
+		TokenVerifier:     tokenVerifier,
+		Policy:            policy,
+		Workflow:          taskWorkflow,
+
+		RecurrenceInterval: recurrenceInterval,
+		TrashRetention:     trashRetention,
+		PurgeInterval:      purgeInterval,
 	}
 	return nil
 }
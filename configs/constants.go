@@ -3,10 +3,47 @@ package configs
 const (
 	HTTP_PORT = "HTTP_PORT"
 
+	STORAGE_BACKEND = "STORAGE_BACKEND"
+	STORAGE_MONGO   = "mongo"
+	STORAGE_MEMORY  = "memory"
+	STORAGE_BOLT    = "bolt"
+	BOLT_FILE       = "BOLT_FILE"
+
 	MONGO_URI      = "MONGO_URI"
 	MONGO_USER     = "MONGO_USER"
 	MONGO_PASSWORD = "MONGO_PASSWORD"
 	MONGO_DATABASE = "MONGO_DATABASE"
 
-	MONGO_TASK_COLLECTION = "tasks"
+	MONGO_TASK_COLLECTION         = "tasks"
+	MONGO_WORKSPACE_COLLECTION    = "workspaces"
+	MONGO_MEMBERSHIP_COLLECTION   = "memberships"
+	MONGO_PROJECT_COLLECTION      = "projects"
+	MONGO_COMMENT_COLLECTION      = "comments"
+	MONGO_CUSTOM_FIELD_COLLECTION = "customFields"
+	MONGO_WORKLOG_COLLECTION      = "worklogs"
+	MONGO_TIMER_COLLECTION        = "timers"
+	MONGO_LEASE_COLLECTION        = "leases"
+	MONGO_TEMPLATE_COLLECTION     = "templates"
+	MONGO_ATTACHMENT_BUCKET       = "attachments"
+
+	BLOB_STORE           = "BLOB_STORE"
+	BLOB_STORE_LOCAL     = "local"
+	BLOB_STORE_GRIDFS    = "gridfs"
+	ATTACHMENT_DIR       = "ATTACHMENT_DIR"
+	ATTACHMENT_MAX_BYTES = "ATTACHMENT_MAX_BYTES"
+	ATTACHMENT_TYPES     = "ATTACHMENT_TYPES"
+
+	WORKFLOW_FILE = "WORKFLOW_FILE"
+
+	RECURRENCE_INTERVAL = "RECURRENCE_INTERVAL"
+
+	TRASH_RETENTION = "TRASH_RETENTION"
+	PURGE_INTERVAL  = "PURGE_INTERVAL"
+
+	JWT_HS256_SECRET = "JWT_HS256_SECRET"
+	JWT_JWKS_FILE    = "JWT_JWKS_FILE"
+	JWT_ISSUER       = "JWT_ISSUER"
+	JWT_AUDIENCE     = "JWT_AUDIENCE"
+
+	RBAC_POLICY_FILE = "RBAC_POLICY_FILE"
 )
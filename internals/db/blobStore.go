@@ -0,0 +1,17 @@
+package db
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStore keeps the content of the task attachments, their metadata is stored on the task.
+// keys are the hex ids of the attachments and are unique across workspaces
+type BlobStore interface {
+	// Put stores the content under the key, nothing is left behind when the content fails to be read
+	Put(context context.Context, key string, content io.Reader) error
+	// Open returns the content stored under the key, seekable so downloads can serve ranges
+	Open(context context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete removes the content stored under the key, a missing key is not an error
+	Delete(context context.Context, key string) error
+}
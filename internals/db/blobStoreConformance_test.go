@@ -0,0 +1,120 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing/iotest"
+
+	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ = Describe("Local BlobStore", func() {
+	describeBlobStoreConformance(func() (BlobStore, func()) {
+		store, err := NewLocalBlobStore(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		return store, func() {}
+	})
+
+	It("rejects keys leaving the directory", func() {
+		store, err := NewLocalBlobStore(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		err = store.Put(context.Background(), "../escape", bytes.NewReader([]byte("content")))
+		Expect(apperrors.Is(err, apperrors.BadRequest)).To(BeTrue())
+	})
+})
+
+var _ = Describe("GridFS BlobStore", func() {
+	if len(os.Getenv(mongoTestUri)) == 0 {
+		It("is skipped without "+mongoTestUri, func() {
+			Skip(mongoTestUri + " is not set")
+		})
+		return
+	}
+	describeBlobStoreConformance(func() (BlobStore, func()) {
+		ctx := context.Background()
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv(mongoTestUri)))
+		Expect(err).NotTo(HaveOccurred())
+		database := fmt.Sprintf("task-svc-test-%s", primitive.NewObjectID().Hex())
+		store, err := NewGridFSBlobStore(appdb.NewDatabaseClient(database, client))
+		Expect(err).NotTo(HaveOccurred())
+		return store, func() {
+			Expect(client.Database(database).Drop(ctx)).To(Succeed())
+			Expect(client.Disconnect(ctx)).To(Succeed())
+		}
+	})
+})
+
+// function to register the behaviour every BlobStore implementation must share
+func describeBlobStoreConformance(newStore func() (BlobStore, func())) {
+	var (
+		ctx     context.Context
+		store   BlobStore
+		key     string
+		content []byte
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var cleanup func()
+		store, cleanup = newStore()
+		DeferCleanup(cleanup)
+		key = primitive.NewObjectID().Hex()
+		// larger than a gridfs chunk so seeks cross chunks
+		content = bytes.Repeat([]byte("0123456789"), 30000)
+	})
+
+	It("stores, reads and deletes a blob", func() {
+		Expect(store.Put(ctx, key, bytes.NewReader(content))).To(Succeed())
+
+		blob, err := store.Open(ctx, key)
+		Expect(err).NotTo(HaveOccurred())
+		read, err := io.ReadAll(blob)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(blob.Close()).To(Succeed())
+		Expect(read).To(Equal(content))
+
+		Expect(store.Delete(ctx, key)).To(Succeed())
+		_, err = store.Open(ctx, key)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		Expect(store.Delete(ctx, key)).To(Succeed())
+	})
+
+	It("seeks forward and back", func() {
+		Expect(store.Put(ctx, key, bytes.NewReader(content))).To(Succeed())
+		blob, err := store.Open(ctx, key)
+		Expect(err).NotTo(HaveOccurred())
+		defer blob.Close()
+
+		size, err := blob.Seek(0, io.SeekEnd)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size).To(Equal(int64(len(content))))
+
+		for _, offset := range []int64{270005, 12, 0, 299995} {
+			_, err := blob.Seek(offset, io.SeekStart)
+			Expect(err).NotTo(HaveOccurred())
+			read := make([]byte, 5)
+			_, err = io.ReadFull(blob, read)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(read).To(Equal(content[offset : offset+5]))
+		}
+	})
+
+	It("leaves nothing behind when the content fails", func() {
+		failing := io.MultiReader(bytes.NewReader(content[:1000]), iotest.ErrReader(errors.New("read failed")))
+		Expect(store.Put(ctx, key, failing)).NotTo(Succeed())
+
+		_, err := store.Open(ctx, key)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+	})
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltStore keeps every collection in a bucket of a local bbolt file
+type boltStore struct {
+	db *bolt.DB
+}
+
+func NewBoltStore(path string) (KVStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	return &boltStore{db: db}, nil
+}
+
+func (b *boltStore) View(fn func(tx KVTx) error) error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+func (b *boltStore) Update(fn func(tx KVTx) error) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return fn(&boltTx{tx: tx})
+	})
+}
+
+func (b *boltStore) Close() error {
+	return b.db.Close()
+}
+
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+// function to get a copy of the value, bbolt values are only valid during the transaction
+func (t *boltTx) Get(collection string, key string) []byte {
+	bucket := t.tx.Bucket([]byte(collection))
+	if bucket == nil {
+		return nil
+	}
+	value := bucket.Get([]byte(key))
+	if value == nil {
+		return nil
+	}
+	return append([]byte(nil), value...)
+}
+
+func (t *boltTx) Put(collection string, key string, value []byte) error {
+	bucket, err := t.tx.CreateBucketIfNotExists([]byte(collection))
+	if err != nil {
+		return err
+	}
+	return bucket.Put([]byte(key), value)
+}
+
+func (t *boltTx) Delete(collection string, key string) error {
+	bucket := t.tx.Bucket([]byte(collection))
+	if bucket == nil {
+		return nil
+	}
+	return bucket.Delete([]byte(key))
+}
+
+func (t *boltTx) ForEach(collection string, fn func(key string, value []byte) error) error {
+	bucket := t.tx.Bucket([]byte(collection))
+	if bucket == nil {
+		return nil
+	}
+	return bucket.ForEach(func(key, value []byte) error {
+		return fn(string(key), append([]byte(nil), value...))
+	})
+}
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+	apimodels "TaskSvc/internals/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ = Describe("Memory CommentDbService", func() {
+	describeCommentDbServiceConformance(func() (CommentDbService, func()) {
+		return NewKVCommentDbService(NewMemoryStore()), func() {}
+	})
+})
+
+var _ = Describe("Bolt CommentDbService", func() {
+	describeCommentDbServiceConformance(func() (CommentDbService, func()) {
+		store, err := NewBoltStore(filepath.Join(GinkgoT().TempDir(), "tasks.db"))
+		Expect(err).NotTo(HaveOccurred())
+		return NewKVCommentDbService(store), func() { Expect(store.Close()).To(Succeed()) }
+	})
+})
+
+var _ = Describe("Mongo CommentDbService", func() {
+	if len(os.Getenv(mongoTestUri)) == 0 {
+		It("is skipped without "+mongoTestUri, func() {
+			Skip(mongoTestUri + " is not set")
+		})
+		return
+	}
+	describeCommentDbServiceConformance(func() (CommentDbService, func()) {
+		ctx := context.Background()
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv(mongoTestUri)))
+		Expect(err).NotTo(HaveOccurred())
+		database := fmt.Sprintf("task-svc-test-%s", primitive.NewObjectID().Hex())
+		dbclient := appdb.NewDatabaseClient(database, client)
+		Expect(ensureCommentIndexes(ctx, dbclient.Collection(configs.MONGO_COMMENT_COLLECTION))).To(Succeed())
+		return NewCommentDbService(dbclient), func() {
+			Expect(client.Database(database).Drop(ctx)).To(Succeed())
+			Expect(client.Disconnect(ctx)).To(Succeed())
+		}
+	})
+})
+
+// function to register the behaviour every CommentDbService implementation must share
+func describeCommentDbServiceConformance(newService func() (CommentDbService, func())) {
+	var (
+		ctx     context.Context
+		service CommentDbService
+		base    time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		var cleanup func()
+		service, cleanup = newService()
+		DeferCleanup(cleanup)
+	})
+
+	save := func(ctx context.Context, taskId string, body string, offset time.Duration) string {
+		id, err := service.SaveComment(ctx, &models.CommentSchema{TaskID: taskId, Author: "user-1", Body: body, CreatedAt: base.Add(offset), UpdatedAt: base.Add(offset)})
+		Expect(err).NotTo(HaveOccurred())
+		return id
+	}
+
+	bodies := func(page *models.CommentPage) []string {
+		var result []string
+		for _, comment := range page.Comments {
+			result = append(result, comment.Body)
+		}
+		return result
+	}
+
+	It("pages through the comments of the task, the oldest first", func() {
+		for i := 1; i <= 5; i++ {
+			save(ctx, "task-1", fmt.Sprintf("Comment %d", i), time.Duration(i)*time.Minute)
+		}
+		save(ctx, "task-2", "Elsewhere", 0)
+
+		var seen []string
+		query := &apimodels.CommentQuery{Limit: 2}
+		for {
+			page, err := service.GetComments(ctx, "task-1", query)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Total).To(Equal(int64(5)))
+			seen = append(seen, bodies(page)...)
+			if len(page.NextCursor) == 0 {
+				break
+			}
+			query.Cursor = page.NextCursor
+		}
+		Expect(seen).To(Equal([]string{"Comment 1", "Comment 2", "Comment 3", "Comment 4", "Comment 5"}))
+
+		count, err := service.CountComments(ctx, "task-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(int64(5)))
+	})
+
+	It("keeps the history of the edits and checks the version", func() {
+		id := save(ctx, "task-1", "First", 0)
+		comment, err := service.GetCommentById(ctx, "task-1", id)
+		Expect(err).NotTo(HaveOccurred())
+
+		comment.Body = "Second"
+		comment.UpdatedAt = base.Add(time.Hour)
+		updated, err := service.UpdateComment(ctx, comment, models.CommentRevision{Body: "First", EditedAt: base.Add(time.Hour)}, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.Body).To(Equal("Second"))
+		Expect(updated.Version).To(Equal(int64(2)))
+		Expect(updated.History).To(HaveLen(1))
+		Expect(updated.History[0].Body).To(Equal("First"))
+
+		_, err = service.UpdateComment(ctx, comment, models.CommentRevision{Body: "Second"}, 1)
+		Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+	})
+
+	It("finds a comment through its task only", func() {
+		id := save(ctx, "task-1", "Comment", 0)
+
+		_, err := service.GetCommentById(ctx, "task-2", id)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		err = service.DeleteCommentById(ctx, "task-2", id)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		_, err = service.GetCommentById(appauth.WithWorkspace(ctx, "team-a"), "task-1", id)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+
+		Expect(service.DeleteCommentById(ctx, "task-1", id)).To(Succeed())
+		_, err = service.GetCommentById(ctx, "task-1", id)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+	})
+
+	It("deletes every comment of the task", func() {
+		save(ctx, "task-1", "One", 0)
+		save(ctx, "task-1", "Two", time.Minute)
+		save(ctx, "task-2", "Other", 0)
+
+		Expect(service.DeleteComments(ctx, "task-1")).To(Succeed())
+
+		count, err := service.CountComments(ctx, "task-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(BeZero())
+		count, err = service.CountComments(ctx, "task-2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(int64(1)))
+	})
+}
@@ -0,0 +1,160 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+	apimodels "TaskSvc/internals/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CommentDbService stores the comments of the tasks of the workspace of the context,
+// a comment is only found through the task it was written on
+type CommentDbService interface {
+	GetComments(context context.Context, taskId string, query *apimodels.CommentQuery) (*models.CommentPage, error)
+	GetCommentById(context context.Context, taskId string, commentId string) (*models.CommentSchema, error)
+	SaveComment(context context.Context, comment *models.CommentSchema) (string, error)
+	// UpdateComment replaces the body and appends the revision to the history, conditional on the version
+	UpdateComment(context context.Context, comment *models.CommentSchema, revision models.CommentRevision, version int64) (*models.CommentSchema, error)
+	DeleteCommentById(context context.Context, taskId string, commentId string) error
+	DeleteComments(context context.Context, taskId string) error
+	CountComments(context context.Context, taskId string) (int64, error)
+}
+
+type commentDbService struct {
+	collection appdb.DatabaseCollection
+}
+
+func NewCommentDbService(dbclient appdb.DatabaseClient) CommentDbService {
+	return &commentDbService{
+		collection: newTenantCollection(dbclient.Collection(configs.MONGO_COMMENT_COLLECTION)),
+	}
+}
+
+func (d *commentDbService) GetComments(ctx context.Context, taskId string, query *apimodels.CommentQuery) (*models.CommentPage, error) {
+	filter := bson.M{"taskId": taskId}
+	total, err := d.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count comments: %v", err)
+	}
+	if len(query.Cursor) > 0 {
+		cursor, err := decodeCursor(query.Cursor, apimodels.SortByCreatedAt, false)
+		if err != nil {
+			return nil, apperrors.NewBadRequestError(err.Error())
+		}
+		filter = bson.M{"$and": bson.A{filter, cursorFilter("createdAt", 1, cursor)}}
+	}
+
+	// fetch one extra comment to know if there is a next page
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(query.Limit + 1)
+	comments := []*models.CommentSchema{}
+	if err := d.collection.Find(ctx, filter, findOptions, &comments); err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %v", err)
+	}
+	return newCommentPage(comments, total, query)
+}
+
+func (d *commentDbService) GetCommentById(ctx context.Context, taskId string, commentId string) (*models.CommentSchema, error) {
+	id, err := parseObjectId(commentId)
+	if err != nil {
+		return nil, err
+	}
+	var comment models.CommentSchema
+	if err := d.collection.FindOne(ctx, bson.M{"_id": id, "taskId": taskId}, &comment); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("comment %s not found", commentId))
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (d *commentDbService) SaveComment(ctx context.Context, comment *models.CommentSchema) (string, error) {
+	comment.Version = 1
+	comment.WorkspaceID = appauth.GetWorkspace(ctx)
+	result, err := d.collection.InsertOne(ctx, comment)
+	if err != nil {
+		return "", err
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (d *commentDbService) UpdateComment(ctx context.Context, comment *models.CommentSchema, revision models.CommentRevision, version int64) (*models.CommentSchema, error) {
+	filter := bson.M{"_id": comment.ID, "taskId": comment.TaskID, "version": version}
+	update := bson.M{
+		"$set":  bson.M{"body": comment.Body, "updatedAt": comment.UpdatedAt},
+		"$push": bson.M{"history": revision},
+		"$inc":  bson.M{"version": 1},
+	}
+	var updated models.CommentSchema
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if err := d.collection.FindOneAndUpdate(ctx, filter, update, &updated, opts); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, err := d.GetCommentById(ctx, comment.TaskID, comment.ID.Hex()); err != nil {
+				return nil, err
+			}
+			return nil, apperrors.NewPreconditionFailedError(fmt.Sprintf("comment %s has been modified", comment.ID.Hex()))
+		}
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (d *commentDbService) DeleteCommentById(ctx context.Context, taskId string, commentId string) error {
+	id, err := parseObjectId(commentId)
+	if err != nil {
+		return err
+	}
+	result, err := d.collection.DeleteOne(ctx, bson.M{"_id": id, "taskId": taskId})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("comment %s not found", commentId))
+	}
+	return nil
+}
+
+func (d *commentDbService) DeleteComments(ctx context.Context, taskId string) error {
+	if _, err := d.collection.DeleteMany(ctx, bson.M{"taskId": taskId}); err != nil {
+		return fmt.Errorf("failed to delete the comments of task %s: %v", taskId, err)
+	}
+	return nil
+}
+
+func (d *commentDbService) CountComments(ctx context.Context, taskId string) (int64, error) {
+	count, err := d.collection.CountDocuments(ctx, bson.M{"taskId": taskId})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count comments: %v", err)
+	}
+	return count, nil
+}
+
+// function to cut the page to the limit and issue the cursor of the next one, like newTaskPage
+func newCommentPage(comments []*models.CommentSchema, total int64, query *apimodels.CommentQuery) (*models.CommentPage, error) {
+	page := &models.CommentPage{Comments: comments, Total: total}
+	if int64(len(comments)) <= query.Limit {
+		return page, nil
+	}
+
+	page.Comments = comments[:query.Limit]
+	last := page.Comments[len(page.Comments)-1]
+	nextCursor, err := encodeCursor(pageCursor{SortBy: apimodels.SortByCreatedAt, Value: last.CreatedAt, ID: last.ID})
+	if err != nil {
+		return nil, err
+	}
+	page.NextCursor = nextCursor
+	return page, nil
+}
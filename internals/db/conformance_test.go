@@ -0,0 +1,953 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+	apimodels "TaskSvc/internals/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MONGO_TEST_URI enables the conformance suite against a real MongoDB,
+// every run uses a fresh database that is dropped afterwards
+const mongoTestUri = "MONGO_TEST_URI"
+
+var _ = Describe("Memory DbService", func() {
+	describeDbServiceConformance(func() (DbService, func()) {
+		return NewKVDbService(NewMemoryStore()), func() {}
+	})
+})
+
+var _ = Describe("Bolt DbService", func() {
+	describeDbServiceConformance(func() (DbService, func()) {
+		store, err := NewBoltStore(filepath.Join(GinkgoT().TempDir(), "tasks.db"))
+		Expect(err).NotTo(HaveOccurred())
+		return NewKVDbService(store), func() { Expect(store.Close()).To(Succeed()) }
+	})
+
+	It("keeps the tasks after the file is reopened", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "tasks.db")
+		store, err := NewBoltStore(path)
+		Expect(err).NotTo(HaveOccurred())
+		id, err := NewKVDbService(store).SaveTask(context.Background(), &models.TaskSchema{Title: "Task 1"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(store.Close()).To(Succeed())
+
+		store, err = NewBoltStore(path)
+		Expect(err).NotTo(HaveOccurred())
+		defer store.Close()
+		task, err := NewKVDbService(store).GetTaskById(context.Background(), id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Title).To(Equal("Task 1"))
+	})
+})
+
+var _ = Describe("Mongo DbService", func() {
+	if len(os.Getenv(mongoTestUri)) == 0 {
+		It("is skipped without "+mongoTestUri, func() {
+			Skip(mongoTestUri + " is not set")
+		})
+		return
+	}
+	describeDbServiceConformance(func() (DbService, func()) {
+		ctx := context.Background()
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv(mongoTestUri)))
+		Expect(err).NotTo(HaveOccurred())
+		database := fmt.Sprintf("task-svc-test-%s", primitive.NewObjectID().Hex())
+		dbclient := appdb.NewDatabaseClient(database, client)
+		Expect(ensureTaskIndexes(ctx, dbclient.Collection(configs.MONGO_TASK_COLLECTION))).To(Succeed())
+		return NewDbService(dbclient), func() {
+			Expect(client.Database(database).Drop(ctx)).To(Succeed())
+			Expect(client.Disconnect(ctx)).To(Succeed())
+		}
+	})
+})
+
+// function to register the behaviour every DbService implementation must share
+func describeDbServiceConformance(newService func() (DbService, func())) {
+	var (
+		ctx     context.Context
+		service DbService
+		base    time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		base = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		var cleanup func()
+		service, cleanup = newService()
+		DeferCleanup(cleanup)
+	})
+
+	save := func(title, status string, offset time.Duration) string {
+		id, err := service.SaveTask(ctx, &models.TaskSchema{
+			Title:       title,
+			Description: title + " description",
+			Status:      status,
+			CreatedAt:   base.Add(offset),
+			UpdatedAt:   base.Add(offset),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return id
+	}
+
+	query := func(modify func(query *apimodels.TaskQuery)) *apimodels.TaskQuery {
+		query := &apimodels.TaskQuery{Limit: apimodels.DefaultTaskLimit, SortBy: apimodels.SortByCreatedAt}
+		if modify != nil {
+			modify(query)
+		}
+		return query
+	}
+
+	titles := func(page *models.TaskPage) []string {
+		var result []string
+		for _, task := range page.Tasks {
+			result = append(result, task.Title)
+		}
+		return result
+	}
+
+	Describe("dependencies", func() {
+		link := func(title string, blockers ...string) string {
+			task := &models.TaskSchema{Title: title}
+			for _, blocker := range blockers {
+				id, err := primitive.ObjectIDFromHex(blocker)
+				Expect(err).NotTo(HaveOccurred())
+				task.BlockedBy = append(task.BlockedBy, id)
+			}
+			id, err := service.SaveTask(ctx, task)
+			Expect(err).NotTo(HaveOccurred())
+			return id
+		}
+
+		graphTitles := func(tasks []*models.TaskSchema) []string {
+			var result []string
+			for _, task := range tasks {
+				result = append(result, task.Title)
+			}
+			return result
+		}
+
+		It("walks the blockers and the dependents transitively", func() {
+			design := link("Design")
+			build := link("Build", design)
+			link("Ship", build)
+			link("Unrelated")
+			link("Docs", design)
+
+			tasks, err := service.GetDependencyGraph(ctx, build)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tasks[0].Title).To(Equal("Build"))
+			Expect(graphTitles(tasks)).To(ConsistOf("Build", "Design", "Ship"))
+
+			tasks, err = service.GetDependencyGraph(ctx, design)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(graphTitles(tasks)).To(ConsistOf("Design", "Build", "Ship", "Docs"))
+		})
+
+		It("stays in the workspace", func() {
+			design := link("Design")
+			teamA := appauth.WithWorkspace(ctx, "team-a")
+			_, err := service.GetDependencyGraph(teamA, design)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+
+		It("unlinks a deleted blocker", func() {
+			design := link("Design")
+			build := link("Build", design)
+
+			Expect(service.RemoveBlocker(ctx, design)).To(Succeed())
+
+			task, err := service.GetTaskById(ctx, build)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.BlockedBy).To(BeEmpty())
+			Expect(task.Version).To(Equal(int64(2)))
+		})
+	})
+
+	Describe("SaveTask and GetTaskById", func() {
+		It("stores the task with an ObjectID and version 1", func() {
+			id := save("Task 1", "Pending", 0)
+			_, err := primitive.ObjectIDFromHex(id)
+			Expect(err).NotTo(HaveOccurred())
+
+			task, err := service.GetTaskById(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.ID.Hex()).To(Equal(id))
+			Expect(task.Title).To(Equal("Task 1"))
+			Expect(task.Status).To(Equal("Pending"))
+			Expect(task.CreatedAt.Equal(base)).To(BeTrue())
+			Expect(task.Version).To(Equal(int64(1)))
+		})
+
+		It("rejects an invalid id", func() {
+			_, err := service.GetTaskById(ctx, "not-an-id")
+			Expect(apperrors.Is(err, apperrors.InvalidID)).To(BeTrue())
+		})
+
+		It("reports a missing task", func() {
+			_, err := service.GetTaskById(ctx, primitive.NewObjectID().Hex())
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("SaveTasks", func() {
+		It("stores the tasks in order with the ids they were given", func() {
+			parentId := primitive.NewObjectID()
+			ids, err := service.SaveTasks(ctx, []*models.TaskSchema{
+				{ID: parentId, Title: "Parent", Status: "Pending", CreatedAt: base},
+				{Title: "Child", Status: "Pending", ParentID: parentId.Hex(), CreatedAt: base},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids).To(HaveLen(2))
+			Expect(ids[0]).To(Equal(parentId.Hex()))
+
+			task, err := service.GetTaskById(ctx, ids[1])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.ParentID).To(Equal(parentId.Hex()))
+			Expect(task.Version).To(Equal(int64(1)))
+		})
+
+		It("stores none of the tasks when one of them exists", func() {
+			existing := save("Task 1", "Pending", 0)
+			existingId, _ := primitive.ObjectIDFromHex(existing)
+			newId := primitive.NewObjectID()
+			_, err := service.SaveTasks(ctx, []*models.TaskSchema{
+				{ID: newId, Title: "Task 2", Status: "Pending", CreatedAt: base},
+				{ID: existingId, Title: "Again", Status: "Pending", CreatedAt: base},
+			})
+			Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+
+			_, err = service.GetTaskById(ctx, newId.Hex())
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("UpdateTask", func() {
+		It("replaces the fields and bumps the version", func() {
+			id := save("Task 1", "Pending", 0)
+			err := service.UpdateTask(ctx, &models.TaskSchema{
+				Title: "Task 1 updated", Description: "Updated", Status: "Done", UpdatedAt: base.Add(time.Hour),
+			}, id, 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			task, err := service.GetTaskById(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Title).To(Equal("Task 1 updated"))
+			Expect(task.Status).To(Equal("Done"))
+			Expect(task.CreatedAt.Equal(base)).To(BeTrue())
+			Expect(task.UpdatedAt.Equal(base.Add(time.Hour))).To(BeTrue())
+			Expect(task.Version).To(Equal(int64(2)))
+		})
+
+		It("replaces and clears the planning fields", func() {
+			dueDate := base.Add(time.Hour)
+			id, err := service.SaveTask(ctx, &models.TaskSchema{Title: "Task 1", Priority: "high", PriorityRank: 3, DueDate: &dueDate})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(service.UpdateTask(ctx, &models.TaskSchema{Title: "Task 1", Priority: "low", PriorityRank: 1}, id, 0)).To(Succeed())
+
+			task, err := service.GetTaskById(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Priority).To(Equal("low"))
+			Expect(task.PriorityRank).To(Equal(1))
+			Expect(task.DueDate).To(BeNil())
+		})
+
+		It("fails the precondition on a stale version", func() {
+			id := save("Task 1", "Pending", 0)
+			err := service.UpdateTask(ctx, &models.TaskSchema{Title: "Task 1"}, id, 2)
+			Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+		})
+
+		It("reports a missing task", func() {
+			err := service.UpdateTask(ctx, &models.TaskSchema{Title: "Task 1"}, primitive.NewObjectID().Hex(), 1)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("PatchTask", func() {
+		It("sets only the given fields and returns the updated task", func() {
+			id := save("Task 1", "Pending", 0)
+			task, err := service.PatchTask(ctx, id, map[string]interface{}{"status": "Done"}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Title).To(Equal("Task 1"))
+			Expect(task.Status).To(Equal("Done"))
+			Expect(task.Version).To(Equal(int64(2)))
+		})
+
+		It("fails the precondition on a stale version", func() {
+			id := save("Task 1", "Pending", 0)
+			_, err := service.PatchTask(ctx, id, map[string]interface{}{"status": "Done"}, 3)
+			Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+		})
+	})
+
+	Describe("DeleteTaskById", func() {
+		It("deletes the task", func() {
+			id := save("Task 1", "Pending", 0)
+			Expect(service.TrashTask(ctx, id, 1, base, "user-1")).To(Succeed())
+			Expect(service.DeleteTaskById(ctx, id, 2)).To(Succeed())
+			_, err := service.GetDeletedTaskById(ctx, id)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+
+		It("keeps the task on a stale version", func() {
+			id := save("Task 1", "Pending", 0)
+			Expect(service.TrashTask(ctx, id, 1, base, "user-1")).To(Succeed())
+			err := service.DeleteTaskById(ctx, id, 1)
+			Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+			_, err = service.GetDeletedTaskById(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("only deletes a task in the trash", func() {
+			id := save("Task 1", "Pending", 0)
+			err := service.DeleteTaskById(ctx, id, 0)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			_, err = service.GetTaskById(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reports a missing task", func() {
+			err := service.DeleteTaskById(ctx, primitive.NewObjectID().Hex(), 0)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("GetTasks", func() {
+		BeforeEach(func() {
+			save("Task 1", "Pending", 1*time.Hour)
+			save("Task 2", "Done", 2*time.Hour)
+			save("Task 3", "Pending", 3*time.Hour)
+			save("Task 4", "InProgress", 4*time.Hour)
+			save("Task 5", "Pending", 5*time.Hour)
+		})
+
+		It("sorts and counts all tasks", func() {
+			page, err := service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) { query.SortDesc = true }))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Total).To(Equal(int64(5)))
+			Expect(titles(page)).To(Equal([]string{"Task 5", "Task 4", "Task 3", "Task 2", "Task 1"}))
+			Expect(page.NextCursor).To(BeEmpty())
+		})
+
+		It("filters by status and dates", func() {
+			after, before := base.Add(2*time.Hour), base.Add(5*time.Hour)
+			page, err := service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+				query.Status = []string{"Pending", "Done"}
+				query.CreatedAfter = &after
+				query.CreatedBefore = &before
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Total).To(Equal(int64(2)))
+			Expect(titles(page)).To(Equal([]string{"Task 2", "Task 3"}))
+		})
+
+		It("pages with the cursor", func() {
+			var seen []string
+			cursor := ""
+			for pages := 0; pages < 5; pages++ {
+				page, err := service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+					query.Limit = 2
+					query.SortBy = apimodels.SortByStatus
+					query.Cursor = cursor
+				}))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(page.Total).To(Equal(int64(5)))
+				seen = append(seen, titles(page)...)
+				if cursor = page.NextCursor; len(cursor) == 0 {
+					break
+				}
+			}
+			Expect(seen).To(HaveLen(5))
+			Expect(seen[0]).To(Equal("Task 2"))
+			Expect(seen[1]).To(Equal("Task 4"))
+			Expect(seen[2:]).To(Equal([]string{"Task 1", "Task 3", "Task 5"}))
+		})
+
+		It("sorts by the priority rank and not alphabetically", func() {
+			id, err := service.SaveTask(ctx, &models.TaskSchema{Title: "Urgent", Priority: "urgent", PriorityRank: 4, CreatedAt: base})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = service.SaveTask(ctx, &models.TaskSchema{Title: "Low", Priority: "low", PriorityRank: 1, CreatedAt: base})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = service.PatchTask(ctx, id, map[string]interface{}{"priority": "high", "priorityRank": 3}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = service.SaveTask(ctx, &models.TaskSchema{Title: "Medium", Priority: "medium", PriorityRank: 2, CreatedAt: base})
+			Expect(err).NotTo(HaveOccurred())
+
+			var seen []string
+			cursor := ""
+			for pages := 0; pages < 10; pages++ {
+				page, err := service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+					query.Limit = 2
+					query.SortBy = apimodels.SortByPriority
+					query.SortDesc = true
+					query.Cursor = cursor
+				}))
+				Expect(err).NotTo(HaveOccurred())
+				seen = append(seen, titles(page)...)
+				if cursor = page.NextCursor; len(cursor) == 0 {
+					break
+				}
+			}
+			Expect(seen).To(HaveLen(8))
+			Expect(seen[:3]).To(Equal([]string{"Urgent", "Medium", "Low"}))
+		})
+
+		It("filters by due date and overdue", func() {
+			due := func(title, status string, offset time.Duration) {
+				dueDate := base.Add(offset)
+				_, err := service.SaveTask(ctx, &models.TaskSchema{Title: title, Status: status, DueDate: &dueDate, CreatedAt: base})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			due("Due 1", "Pending", 24*time.Hour)
+			due("Due 2", "Done", 48*time.Hour)
+			due("Due 3", "Pending", 72*time.Hour)
+
+			after, before := base.Add(24*time.Hour), base.Add(72*time.Hour)
+			page, err := service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+				query.SortDesc = false
+				query.SortBy = apimodels.SortByTitle
+				query.DueAfter = &after
+				query.DueBefore = &before
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(Equal([]string{"Due 1", "Due 2"}))
+
+			page, err = service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+				query.SortBy = apimodels.SortByTitle
+				query.Overdue = true
+				query.OverdueAt = base.Add(60 * time.Hour)
+				query.DoneStatuses = []string{"Done"}
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Total).To(Equal(int64(1)))
+			Expect(titles(page)).To(Equal([]string{"Due 1"}))
+		})
+
+		It("filters by assignee and creator", func() {
+			_, err := service.SaveTask(ctx, &models.TaskSchema{Title: "Mine", CreatedBy: "user-1", Assignees: []string{"user-1", "user-2"}})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = service.SaveTask(ctx, &models.TaskSchema{Title: "Theirs", CreatedBy: "user-2", Assignees: []string{"user-2"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			page, err := service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+				query.SortBy = apimodels.SortByTitle
+				query.Assignee = "user-2"
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(Equal([]string{"Mine", "Theirs"}))
+
+			page, err = service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+				query.Assignee = "user-2"
+				query.CreatedBy = "user-1"
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(Equal([]string{"Mine"}))
+		})
+
+		It("filters by parent and moves a subtask", func() {
+			parentId, err := service.SaveTask(ctx, &models.TaskSchema{Title: "Parent"})
+			Expect(err).NotTo(HaveOccurred())
+			childId, err := service.SaveTask(ctx, &models.TaskSchema{Title: "Child", ParentID: parentId})
+			Expect(err).NotTo(HaveOccurred())
+
+			page, err := service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) { query.ParentID = parentId }))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(Equal([]string{"Child"}))
+
+			_, err = service.PatchTask(ctx, childId, map[string]interface{}{"parentId": ""}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			page, err = service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) { query.ParentID = parentId }))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(page.Total).To(BeZero())
+		})
+
+		It("rejects a cursor issued for another sort", func() {
+			page, err := service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) { query.Limit = 2 }))
+			Expect(err).NotTo(HaveOccurred())
+			_, err = service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+				query.Limit = 2
+				query.SortDesc = true
+				query.Cursor = page.NextCursor
+			}))
+			Expect(apperrors.Is(err, apperrors.BadRequest)).To(BeTrue())
+		})
+	})
+
+	Describe("labels", func() {
+		label := func(ctx context.Context, title string, labels ...string) string {
+			id, err := service.SaveTask(ctx, &models.TaskSchema{Title: title, Labels: labels, CreatedAt: base, UpdatedAt: base})
+			Expect(err).NotTo(HaveOccurred())
+			return id
+		}
+
+		It("filters by any or all of the labels", func() {
+			label(ctx, "Crash", "bug", "ios")
+			label(ctx, "Typo", "bug")
+			label(ctx, "Dark mode", "feature", "ios")
+
+			page, err := service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+				query.Labels = []string{"bug", "ios"}
+				query.LabelMatch = apimodels.LabelMatchAny
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(ConsistOf("Crash", "Typo", "Dark mode"))
+
+			page, err = service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+				query.Labels = []string{"bug", "ios"}
+				query.LabelMatch = apimodels.LabelMatchAll
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(ConsistOf("Crash"))
+		})
+
+		It("counts the labels of the workspace, the most used first", func() {
+			label(ctx, "Crash", "bug", "ios")
+			label(ctx, "Typo", "bug", "docs")
+			label(ctx, "Dark mode", "feature")
+			label(appauth.WithWorkspace(ctx, "team-a"), "Elsewhere", "bug", "billing")
+
+			labels, err := service.GetLabels(ctx, "", 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(labels).To(HaveLen(4))
+			Expect(*labels[0]).To(Equal(models.LabelCount{Name: "bug", Count: 2}))
+			Expect(*labels[1]).To(Equal(models.LabelCount{Name: "docs", Count: 1}))
+
+			labels, err = service.GetLabels(ctx, "b", 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(labels).To(HaveLen(1))
+			Expect(labels[0].Name).To(Equal("bug"))
+
+			labels, err = service.GetLabels(ctx, "", 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(labels).To(HaveLen(2))
+		})
+
+		It("renames a label and merges it into one in use", func() {
+			renamed := label(ctx, "Crash", "ios", "bug")
+			merged := label(ctx, "Typo", "bug", "defect")
+			untouched := label(ctx, "Dark mode", "feature")
+			teamA := appauth.WithWorkspace(ctx, "team-a")
+			elsewhere := label(teamA, "Elsewhere", "bug")
+
+			changed, err := service.RenameLabel(ctx, "bug", "defect", base.Add(time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(Equal(int64(2)))
+
+			task, err := service.GetTaskById(ctx, renamed)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Labels).To(Equal([]string{"ios", "defect"}))
+			Expect(task.Version).To(Equal(int64(2)))
+			Expect(task.UpdatedAt).To(BeTemporally("==", base.Add(time.Hour)))
+			task, err = service.GetTaskById(ctx, merged)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Labels).To(Equal([]string{"defect"}))
+			task, err = service.GetTaskById(ctx, untouched)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Version).To(Equal(int64(1)))
+			task, err = service.GetTaskById(teamA, elsewhere)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Labels).To(Equal([]string{"bug"}))
+		})
+	})
+
+	Describe("checklist", func() {
+		texts := func(task *models.TaskSchema) []string {
+			var result []string
+			for _, item := range task.Checklist {
+				result = append(result, item.Text)
+			}
+			return result
+		}
+
+		add := func(taskId string, text string, position int) *models.TaskSchema {
+			task, err := service.AddChecklistItem(ctx, taskId, models.ChecklistItemSchema{ID: primitive.NewObjectID(), Text: text}, position, 0, base)
+			Expect(err).NotTo(HaveOccurred())
+			return task
+		}
+
+		It("adds the items in order or at a position", func() {
+			id := save("Release", "pending", 0)
+			add(id, "Tag", -1)
+			add(id, "Publish", -1)
+			task := add(id, "Build", 0)
+			Expect(texts(task)).To(Equal([]string{"Build", "Tag", "Publish"}))
+			Expect(task.Version).To(Equal(int64(4)))
+
+			task = add(id, "Announce", 10)
+			Expect(texts(task)).To(Equal([]string{"Build", "Tag", "Publish", "Announce"}))
+		})
+
+		It("ticks off an item without touching the others", func() {
+			id := save("Release", "pending", 0)
+			first := add(id, "Build", -1).Checklist[0].ID.Hex()
+			second := add(id, "Tag", -1).Checklist[1].ID.Hex()
+
+			done, doneAt := true, base.Add(time.Hour)
+			_, err := service.UpdateChecklistItem(ctx, id, first, models.ChecklistItemChange{Done: &done, DoneBy: "alice", DoneAt: &doneAt}, 0, doneAt)
+			Expect(err).NotTo(HaveOccurred())
+			text := "Tag v2"
+			task, err := service.UpdateChecklistItem(ctx, id, second, models.ChecklistItemChange{Text: &text}, 0, doneAt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Checklist[0].Done).To(BeTrue())
+			Expect(task.Checklist[0].DoneBy).To(Equal("alice"))
+			Expect(*task.Checklist[0].DoneAt).To(BeTemporally("==", doneAt))
+			Expect(task.Checklist[1].Text).To(Equal("Tag v2"))
+			Expect(task.Checklist[1].Done).To(BeFalse())
+
+			done = false
+			task, err = service.UpdateChecklistItem(ctx, id, first, models.ChecklistItemChange{Done: &done}, task.Version, doneAt)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Checklist[0].Done).To(BeFalse())
+			Expect(task.Checklist[0].DoneBy).To(BeEmpty())
+			Expect(task.Checklist[0].DoneAt).To(BeNil())
+		})
+
+		It("moves and removes items", func() {
+			id := save("Release", "pending", 0)
+			add(id, "Build", -1)
+			add(id, "Tag", -1)
+			task := add(id, "Publish", -1)
+			publish := task.Checklist[2].ID.Hex()
+			build := task.Checklist[0].ID.Hex()
+
+			task, err := service.MoveChecklistItem(ctx, id, publish, 0, 0, base)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(texts(task)).To(Equal([]string{"Publish", "Build", "Tag"}))
+			task, err = service.MoveChecklistItem(ctx, id, publish, 1, 0, base)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(texts(task)).To(Equal([]string{"Build", "Publish", "Tag"}))
+			task, err = service.MoveChecklistItem(ctx, id, build, 10, 0, base)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(texts(task)).To(Equal([]string{"Publish", "Tag", "Build"}))
+
+			task, err = service.RemoveChecklistItem(ctx, id, publish, task.Version, base)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(texts(task)).To(Equal([]string{"Tag", "Build"}))
+		})
+
+		It("reports a missing item, a stale version and a full checklist", func() {
+			id := save("Release", "pending", 0)
+			task := add(id, "Build", -1)
+
+			_, err := service.RemoveChecklistItem(ctx, id, primitive.NewObjectID().Hex(), 0, base)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			_, err = service.MoveChecklistItem(ctx, id, task.Checklist[0].ID.Hex(), 0, task.Version+1, base)
+			Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+			_, err = service.RemoveChecklistItem(ctx, id, "not-an-id", 0, base)
+			Expect(apperrors.Is(err, apperrors.InvalidID)).To(BeTrue())
+
+			for i := 1; i < apimodels.MaxChecklistItems; i++ {
+				add(id, fmt.Sprintf("Step %d", i), -1)
+			}
+			_, err = service.AddChecklistItem(ctx, id, models.ChecklistItemSchema{ID: primitive.NewObjectID(), Text: "One more"}, -1, 0, base)
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("stays in the workspace", func() {
+			id := save("Release", "pending", 0)
+			_, err := service.AddChecklistItem(appauth.WithWorkspace(ctx, "team-a"), id,
+				models.ChecklistItemSchema{ID: primitive.NewObjectID(), Text: "Build"}, -1, 0, base)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("custom fields", func() {
+		estimate := func(title string, offset time.Duration, values map[string]interface{}) string {
+			id, err := service.SaveTask(ctx, &models.TaskSchema{Title: title, CustomFields: values, CreatedAt: base.Add(offset), UpdatedAt: base})
+			Expect(err).NotTo(HaveOccurred())
+			return id
+		}
+
+		BeforeEach(func() {
+			estimate("Small", 0, map[string]interface{}{"points": float64(1), "env": "dev"})
+			estimate("Large", time.Minute, map[string]interface{}{"points": float64(8), "env": "prod"})
+			estimate("Unestimated", 2*time.Minute, nil)
+			estimate("Medium", 3*time.Minute, map[string]interface{}{"points": float64(3), "env": "prod"})
+		})
+
+		It("filters by values and ranges", func() {
+			page, err := service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+				query.CustomFields = []*apimodels.CustomFieldFilter{{Key: "env", Values: []interface{}{"prod"}}}
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(ConsistOf("Large", "Medium"))
+
+			page, err = service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+				query.CustomFields = []*apimodels.CustomFieldFilter{{Key: "points", Gte: float64(2), Lt: float64(8)}}
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(ConsistOf("Medium"))
+		})
+
+		It("sorts the tasks without a value first and pages through them", func() {
+			for _, desc := range []bool{false, true} {
+				var seen []string
+				cursor := ""
+				for {
+					page, err := service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+						query.SortBy = "field.points"
+						query.SortDesc = desc
+						query.Limit = 1
+						query.Cursor = cursor
+					}))
+					Expect(err).NotTo(HaveOccurred())
+					seen = append(seen, titles(page)...)
+					if cursor = page.NextCursor; len(cursor) == 0 {
+						break
+					}
+				}
+				if desc {
+					Expect(seen).To(Equal([]string{"Large", "Medium", "Small", "Unestimated"}))
+				} else {
+					Expect(seen).To(Equal([]string{"Unestimated", "Small", "Medium", "Large"}))
+				}
+			}
+		})
+
+		It("unsets a field on every task of the workspace", func() {
+			teamA := appauth.WithWorkspace(ctx, "team-a")
+			elsewhere, err := service.SaveTask(teamA, &models.TaskSchema{Title: "Elsewhere", CustomFields: map[string]interface{}{"points": float64(2)}})
+			Expect(err).NotTo(HaveOccurred())
+
+			changed, err := service.UnsetCustomField(ctx, "points", base.Add(time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(changed).To(Equal(int64(3)))
+
+			page, err := service.GetTasks(ctx, query(nil))
+			Expect(err).NotTo(HaveOccurred())
+			for _, task := range page.Tasks {
+				Expect(task.CustomFields).NotTo(HaveKey("points"))
+			}
+			task, err := service.GetTaskById(teamA, elsewhere)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.CustomFields).To(HaveKey("points"))
+		})
+	})
+
+	Describe("workspaces", func() {
+		var teamA context.Context
+
+		BeforeEach(func() {
+			teamA = appauth.WithWorkspace(ctx, "team-a")
+		})
+
+		It("stamps the workspace of the context on new tasks", func() {
+			id, err := service.SaveTask(teamA, &models.TaskSchema{Title: "Team A"})
+			Expect(err).NotTo(HaveOccurred())
+			task, err := service.GetTaskById(teamA, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.WorkspaceID).To(Equal("team-a"))
+		})
+
+		It("keeps the tasks of one workspace out of every other", func() {
+			id, err := service.SaveTask(teamA, &models.TaskSchema{Title: "Team A", Status: "New", CreatedAt: base, UpdatedAt: base})
+			Expect(err).NotTo(HaveOccurred())
+			save("Default", "New", time.Minute)
+
+			_, err = service.GetTaskById(ctx, id)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			_, err = service.GetTaskById(appauth.WithWorkspace(ctx, "team-b"), id)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+
+			err = service.UpdateTask(ctx, &models.TaskSchema{Title: "Stolen", Status: "New"}, id, 0)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			_, err = service.PatchTask(ctx, id, map[string]interface{}{"title": "Stolen"}, 1)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			err = service.DeleteTaskById(ctx, id, 0)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+
+			page, err := service.GetTasks(ctx, query(nil))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(Equal([]string{"Default"}))
+			page, err = service.GetTasks(teamA, query(nil))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(Equal([]string{"Team A"}))
+			Expect(page.Total).To(Equal(int64(1)))
+
+			task, err := service.GetTaskById(teamA, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Title).To(Equal("Team A"))
+		})
+
+		It("serves the tasks saved without a workspace from the default workspace", func() {
+			id := save("Legacy", "New", 0)
+			_, err := service.GetTaskById(appauth.WithWorkspace(ctx, appauth.DefaultWorkspace), id)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = service.GetTaskById(teamA, id)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("trash", func() {
+		It("hides a task in the trash from the other methods", func() {
+			id, err := service.SaveTask(ctx, &models.TaskSchema{Title: "Task 1", Labels: []string{"ops"}, Recurrence: "FREQ=DAILY"})
+			Expect(err).NotTo(HaveOccurred())
+			other := save("Task 2", "Pending", 0)
+
+			err = service.TrashTask(ctx, id, 2, base, "user-1")
+			Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+			Expect(service.TrashTask(ctx, id, 1, base, "user-1")).To(Succeed())
+
+			_, err = service.GetTaskById(ctx, id)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			_, err = service.PatchTask(ctx, id, map[string]interface{}{"status": "Done"}, 0)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			_, err = service.GetDependencyGraph(ctx, id)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			page, err := service.GetTasks(ctx, query(nil))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(Equal([]string{"Task 2"}))
+			Expect(page.Total).To(Equal(int64(1)))
+			labels, err := service.GetLabels(ctx, "", 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(labels).To(BeEmpty())
+			recurring, err := service.GetRecurringTasks(ctx, "", 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recurring).To(BeEmpty())
+
+			task, err := service.GetDeletedTaskById(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.DeletedAt.Equal(base)).To(BeTrue())
+			Expect(task.DeletedBy).To(Equal("user-1"))
+			Expect(task.Version).To(Equal(int64(2)))
+			_, err = service.GetDeletedTaskById(ctx, other)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			_, err = service.GetDeletedTaskById(appauth.WithWorkspace(ctx, "team-a"), id)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+
+		It("lists the trash, the latest deleted first", func() {
+			first := save("Task 1", "Pending", 0)
+			second := save("Task 2", "Done", 0)
+			third := save("Task 3", "Pending", 0)
+			save("Task 4", "Pending", 0)
+			Expect(service.TrashTask(ctx, first, 0, base.Add(time.Hour), "user-1")).To(Succeed())
+			Expect(service.TrashTask(ctx, second, 0, base.Add(3*time.Hour), "user-1")).To(Succeed())
+			Expect(service.TrashTask(ctx, third, 0, base.Add(2*time.Hour), "user-1")).To(Succeed())
+
+			trash := query(func(query *apimodels.TaskQuery) {
+				query.Deleted = true
+				query.SortBy = apimodels.SortByDeletedAt
+				query.SortDesc = true
+				query.Limit = 2
+			})
+			page, err := service.GetTasks(ctx, trash)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(Equal([]string{"Task 2", "Task 3"}))
+			Expect(page.Total).To(Equal(int64(3)))
+			trash.Cursor = page.NextCursor
+			page, err = service.GetTasks(ctx, trash)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(Equal([]string{"Task 1"}))
+
+			page, err = service.GetTasks(ctx, query(func(query *apimodels.TaskQuery) {
+				query.Deleted = true
+				query.Status = []string{"Pending"}
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(titles(page)).To(Equal([]string{"Task 1", "Task 3"}))
+		})
+
+		It("restores a task", func() {
+			id := save("Task 1", "Pending", 0)
+			err := service.RestoreTask(ctx, id, 0)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			Expect(service.TrashTask(ctx, id, 0, base, "user-1")).To(Succeed())
+			err = service.RestoreTask(ctx, id, 1)
+			Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+
+			Expect(service.RestoreTask(ctx, id, 2)).To(Succeed())
+			task, err := service.GetTaskById(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.DeletedAt).To(BeNil())
+			Expect(task.DeletedBy).To(BeEmpty())
+			Expect(task.Version).To(Equal(int64(3)))
+		})
+
+		It("keeps the tasks in the trash consistent with the workspace", func() {
+			design := save("Design", "Pending", 0)
+			designId, _ := primitive.ObjectIDFromHex(design)
+			build, err := service.SaveTask(ctx, &models.TaskSchema{Title: "Build", Labels: []string{"backend"},
+				BlockedBy: []primitive.ObjectID{designId}, CustomFields: map[string]interface{}{"points": int32(3)}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(service.TrashTask(ctx, build, 0, base, "user-1")).To(Succeed())
+
+			Expect(service.RemoveBlocker(ctx, design)).To(Succeed())
+			_, err = service.RenameLabel(ctx, "backend", "server", base)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = service.UnsetCustomField(ctx, "points", base)
+			Expect(err).NotTo(HaveOccurred())
+
+			task, err := service.GetDeletedTaskById(ctx, build)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.BlockedBy).To(BeEmpty())
+			Expect(task.Labels).To(Equal([]string{"server"}))
+			Expect(task.CustomFields).NotTo(HaveKey("points"))
+		})
+
+		It("walks the tasks deleted before a time in every workspace in the order of their ids", func() {
+			first := save("Task 1", "Pending", 0)
+			save("Task 2", "Pending", 0)
+			second, err := service.SaveTask(appauth.WithWorkspace(ctx, "team-a"), &models.TaskSchema{Title: "Task 3"})
+			Expect(err).NotTo(HaveOccurred())
+			recent := save("Task 4", "Pending", 0)
+			Expect(service.TrashTask(ctx, first, 0, base, "user-1")).To(Succeed())
+			Expect(service.TrashTask(appauth.WithWorkspace(ctx, "team-a"), second, 0, base, "user-1")).To(Succeed())
+			Expect(service.TrashTask(ctx, recent, 0, base.Add(time.Hour), "user-1")).To(Succeed())
+
+			tasks, err := service.GetDeletedTasks(ctx, base.Add(time.Minute), "", 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tasks).To(HaveLen(1))
+			Expect(tasks[0].ID.Hex()).To(Equal(first))
+
+			tasks, err = service.GetDeletedTasks(ctx, base.Add(time.Minute), first, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tasks).To(HaveLen(1))
+			Expect(tasks[0].ID.Hex()).To(Equal(second))
+			Expect(tasks[0].WorkspaceID).To(Equal("team-a"))
+		})
+	})
+
+	Describe("GetRecurringTasks", func() {
+		It("walks the latest occurrences of every workspace in the order of their ids", func() {
+			first, err := service.SaveTask(ctx, &models.TaskSchema{Title: "Daily", Recurrence: "FREQ=DAILY"})
+			Expect(err).NotTo(HaveOccurred())
+			save("Once", "New", 0)
+			linked, err := service.SaveTask(ctx, &models.TaskSchema{Title: "Linked", Recurrence: "FREQ=DAILY"})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = service.PatchTask(ctx, linked, map[string]interface{}{"nextOccurrenceId": first}, 0)
+			Expect(err).NotTo(HaveOccurred())
+			second, err := service.SaveTask(appauth.WithWorkspace(ctx, "team-a"), &models.TaskSchema{Title: "Weekly", Recurrence: "FREQ=WEEKLY"})
+			Expect(err).NotTo(HaveOccurred())
+			third, err := service.SaveTask(ctx, &models.TaskSchema{Title: "Monthly", Recurrence: "FREQ=MONTHLY"})
+			Expect(err).NotTo(HaveOccurred())
+
+			tasks, err := service.GetRecurringTasks(ctx, "", 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tasks).To(HaveLen(2))
+			Expect(tasks[0].ID.Hex()).To(Equal(first))
+			Expect(tasks[1].ID.Hex()).To(Equal(second))
+			Expect(tasks[1].WorkspaceID).To(Equal("team-a"))
+
+			tasks, err = service.GetRecurringTasks(ctx, second, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tasks).To(HaveLen(1))
+			Expect(tasks[0].ID.Hex()).To(Equal(third))
+		})
+	})
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// pageCursor is the position after the last task of a page, it is bound to the sort
+// it was issued for so it cannot be replayed against a different ordering
+type pageCursor struct {
+	SortBy   string             `bson:"s"`
+	SortDesc bool               `bson:"d"`
+	Value    interface{}        `bson:"v"`
+	ID       primitive.ObjectID `bson:"id"`
+}
+
+func encodeCursor(cursor pageCursor) (string, error) {
+	pbytes, err := bson.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(pbytes), nil
+}
+
+func decodeCursor(value string, sortBy string, sortDesc bool) (*pageCursor, error) {
+	pbytes, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var cursor pageCursor
+	if err := bson.Unmarshal(pbytes, &cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if cursor.SortBy != sortBy || cursor.SortDesc != sortDesc {
+		return nil, fmt.Errorf("cursor does not match the requested sort")
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	models "TaskSvc/internals/db/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Memory CustomFieldDbService", func() {
+	describeCustomFieldDbServiceConformance(func() (CustomFieldDbService, func()) {
+		return NewKVCustomFieldDbService(NewMemoryStore()), func() {}
+	})
+})
+
+var _ = Describe("Bolt CustomFieldDbService", func() {
+	describeCustomFieldDbServiceConformance(func() (CustomFieldDbService, func()) {
+		store, err := NewBoltStore(filepath.Join(GinkgoT().TempDir(), "tasks.db"))
+		Expect(err).NotTo(HaveOccurred())
+		return NewKVCustomFieldDbService(store), func() { Expect(store.Close()).To(Succeed()) }
+	})
+})
+
+// function to register the behaviour every CustomFieldDbService implementation must share
+func describeCustomFieldDbServiceConformance(newService func() (CustomFieldDbService, func())) {
+	var (
+		ctx     context.Context
+		service CustomFieldDbService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var cleanup func()
+		service, cleanup = newService()
+		DeferCleanup(cleanup)
+	})
+
+	save := func(ctx context.Context, key string) string {
+		id, err := service.SaveCustomField(ctx, &models.CustomFieldSchema{Key: key, Name: key, Type: "number", Default: float64(1)})
+		Expect(err).NotTo(HaveOccurred())
+		return id
+	}
+
+	It("stores, lists, updates and deletes a custom field", func() {
+		id := save(ctx, "points")
+		save(ctx, "effort")
+
+		fields, err := service.GetCustomFields(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fields).To(HaveLen(2))
+		Expect(fields[0].Key).To(Equal("effort"))
+
+		field, err := service.GetCustomFieldById(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(field.Default).To(Equal(float64(1)))
+		field.Name = "Story points"
+		field.Required = true
+		field.Default = nil
+		Expect(service.UpdateCustomField(ctx, field)).To(Succeed())
+
+		field, err = service.GetCustomFieldById(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(field.Name).To(Equal("Story points"))
+		Expect(field.Required).To(BeTrue())
+		Expect(field.Default).To(BeNil())
+
+		Expect(service.DeleteCustomFieldById(ctx, id)).To(Succeed())
+		_, err = service.GetCustomFieldById(ctx, id)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		Expect(apperrors.Is(service.DeleteCustomFieldById(ctx, id), apperrors.NotFound)).To(BeTrue())
+	})
+
+	It("keeps the key unique in the workspace", func() {
+		save(ctx, "points")
+		_, err := service.SaveCustomField(ctx, &models.CustomFieldSchema{Key: "points", Name: "Again", Type: "text"})
+		Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+		save(appauth.WithWorkspace(ctx, "team-a"), "points")
+	})
+
+	It("hides the custom fields of other workspaces", func() {
+		id := save(ctx, "points")
+		teamA := appauth.WithWorkspace(ctx, "team-a")
+
+		_, err := service.GetCustomFieldById(teamA, id)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		Expect(apperrors.Is(service.DeleteCustomFieldById(teamA, id), apperrors.NotFound)).To(BeTrue())
+		fields, err := service.GetCustomFields(teamA)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fields).To(BeEmpty())
+	})
+}
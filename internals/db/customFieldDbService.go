@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CustomFieldDbService stores the custom field definitions of the workspace of the context
+type CustomFieldDbService interface {
+	// GetCustomFields returns the definitions ordered by key
+	GetCustomFields(context context.Context) ([]*models.CustomFieldSchema, error)
+	GetCustomFieldById(context context.Context, fieldId string) (*models.CustomFieldSchema, error)
+	SaveCustomField(context context.Context, field *models.CustomFieldSchema) (string, error)
+	// UpdateCustomField replaces the name, the required flag, the default and the options, the key and the type never change
+	UpdateCustomField(context context.Context, field *models.CustomFieldSchema) error
+	DeleteCustomFieldById(context context.Context, fieldId string) error
+}
+
+type customFieldDbService struct {
+	collection appdb.DatabaseCollection
+}
+
+// function to build the mongo custom field db service, the definitions are scoped to the workspace of the context
+func NewCustomFieldDbService(dbclient appdb.DatabaseClient) CustomFieldDbService {
+	return &customFieldDbService{
+		collection: newTenantCollection(dbclient.Collection(configs.MONGO_CUSTOM_FIELD_COLLECTION)),
+	}
+}
+
+func (d *customFieldDbService) GetCustomFields(ctx context.Context) ([]*models.CustomFieldSchema, error) {
+	fields := []*models.CustomFieldSchema{}
+	if err := d.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "key", Value: 1}}), &fields); err != nil {
+		return nil, fmt.Errorf("failed to fetch custom fields: %v", err)
+	}
+	return fields, nil
+}
+
+func (d *customFieldDbService) GetCustomFieldById(ctx context.Context, fieldId string) (*models.CustomFieldSchema, error) {
+	id, err := parseObjectId(fieldId)
+	if err != nil {
+		return nil, err
+	}
+	var field models.CustomFieldSchema
+	if err := d.collection.FindOne(ctx, bson.M{"_id": id}, &field); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("custom field %s not found", fieldId))
+		}
+		return nil, err
+	}
+	return &field, nil
+}
+
+// function to save the definition, the unique index on the workspace and the key rejects a key already in use
+func (d *customFieldDbService) SaveCustomField(ctx context.Context, field *models.CustomFieldSchema) (string, error) {
+	field.WorkspaceID = appauth.GetWorkspace(ctx)
+	result, err := d.collection.InsertOne(ctx, field)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", apperrors.NewConflictError(fmt.Sprintf("custom field key %s is already in use", field.Key), err)
+		}
+		return "", err
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (d *customFieldDbService) UpdateCustomField(ctx context.Context, field *models.CustomFieldSchema) error {
+	set := bson.M{
+		"name":      field.Name,
+		"required":  field.Required,
+		"options":   field.Options,
+		"updatedAt": field.UpdatedAt,
+	}
+	update := bson.M{"$set": set}
+	if field.Default != nil {
+		set["default"] = field.Default
+	} else {
+		update["$unset"] = bson.M{"default": ""}
+	}
+	result, err := d.collection.UpdateOne(ctx, bson.M{"_id": field.ID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("custom field %s not found", field.ID.Hex()))
+	}
+	return nil
+}
+
+func (d *customFieldDbService) DeleteCustomFieldById(ctx context.Context, fieldId string) error {
+	id, err := parseObjectId(fieldId)
+	if err != nil {
+		return err
+	}
+	result, err := d.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("custom field %s not found", fieldId))
+	}
+	return nil
+}
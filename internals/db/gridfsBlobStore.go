@@ -0,0 +1,110 @@
+package db
+
+import (
+	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+)
+
+// gridfsBlobStore keeps every blob in a gridfs file whose id is the key
+type gridfsBlobStore struct {
+	bucket *gridfs.Bucket
+}
+
+// function to build the blob store on the attachments gridfs bucket of the database
+func NewGridFSBlobStore(dbclient appdb.DatabaseClient) (BlobStore, error) {
+	bucket, err := dbclient.GridFSBucket(configs.MONGO_ATTACHMENT_BUCKET)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the %s bucket: %v", configs.MONGO_ATTACHMENT_BUCKET, err)
+	}
+	return &gridfsBlobStore{bucket: bucket}, nil
+}
+
+// function to upload the content, the driver removes the chunks already written when reading the content fails
+func (g *gridfsBlobStore) Put(ctx context.Context, key string, content io.Reader) error {
+	if err := g.bucket.UploadFromStreamWithID(key, key, content); err != nil {
+		return fmt.Errorf("failed to store blob %s: %w", key, err)
+	}
+	return nil
+}
+
+func (g *gridfsBlobStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	stream, err := g.bucket.OpenDownloadStream(key)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("blob %s not found", key))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob %s: %v", key, err)
+	}
+	return &gridfsBlob{bucket: g.bucket, key: key, stream: stream, size: stream.GetFile().Length}, nil
+}
+
+func (g *gridfsBlobStore) Delete(ctx context.Context, key string) error {
+	if err := g.bucket.DeleteContext(ctx, key); err != nil && !errors.Is(err, gridfs.ErrFileNotFound) {
+		return fmt.Errorf("failed to delete blob %s: %v", key, err)
+	}
+	return nil
+}
+
+// gridfsBlob makes a download stream seekable, the stream skips forward to the offset and is reopened to go back
+type gridfsBlob struct {
+	bucket *gridfs.Bucket
+	key    string
+	stream *gridfs.DownloadStream
+	size   int64
+	// position is where the stream is, offset where the next read starts
+	position int64
+	offset   int64
+}
+
+func (b *gridfsBlob) Read(p []byte) (int, error) {
+	if b.offset >= b.size {
+		return 0, io.EOF
+	}
+	if b.offset < b.position {
+		b.stream.Close()
+		stream, err := b.bucket.OpenDownloadStream(b.key)
+		if err != nil {
+			return 0, fmt.Errorf("failed to reopen blob %s: %v", b.key, err)
+		}
+		b.stream, b.position = stream, 0
+	}
+	if b.offset > b.position {
+		skipped, err := b.stream.Skip(b.offset - b.position)
+		b.position += skipped
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err := b.stream.Read(p)
+	b.position += int64(n)
+	b.offset = b.position
+	return n, err
+}
+
+func (b *gridfsBlob) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += b.offset
+	case io.SeekEnd:
+		offset += b.size
+	default:
+		return 0, fmt.Errorf("invalid whence: %d", whence)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("negative position: %d", offset)
+	}
+	b.offset = offset
+	return offset, nil
+}
+
+func (b *gridfsBlob) Close() error {
+	return b.stream.Close()
+}
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"TaskSvc/commons/appdb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// indexes backing the task filters and the (sort field, _id) orderings of the listing,
+// every query is scoped to a workspace so they all start with workspaceId
+var taskIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}},
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "updatedAt", Value: 1}, {Key: "_id", Value: 1}}},
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "priorityRank", Value: 1}, {Key: "_id", Value: 1}}},
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "status", Value: 1}, {Key: "dueDate", Value: 1}}},
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "dueDate", Value: 1}}},
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "assignees", Value: 1}}},
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "createdBy", Value: 1}}},
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "labels", Value: 1}}},
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "projectId", Value: 1}, {Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}},
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "parentId", Value: 1}, {Key: "status", Value: 1}}},
+	// $graphLookup matches connectToField alone, restrictSearchWithMatch is applied afterwards
+	{Keys: bson.D{{Key: "blockedBy", Value: 1}}},
+	// the custom fields are defined at runtime, a wildcard index covers the filters and sorts on any of them
+	{Keys: bson.D{{Key: "customFields.$**", Value: 1}}},
+	// the scheduler walks the recurring tasks of every workspace, only those are indexed
+	{Keys: bson.D{{Key: "nextOccurrenceId", Value: 1}, {Key: "_id", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(bson.M{"recurrence": bson.M{"$gt": ""}})},
+	// the trash is listed per workspace, the latest deletions first, and purged across the workspaces
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "deletedAt", Value: 1}, {Key: "_id", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(trashFilter())},
+	{Keys: bson.D{{Key: "deletedAt", Value: 1}, {Key: "_id", Value: 1}},
+		Options: options.Index().SetPartialFilterExpression(trashFilter())},
+}
+
+// a project key is unique in its workspace
+var projectIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+}
+
+// comments are listed and counted per task, the oldest first
+var commentIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "taskId", Value: 1}, {Key: "createdAt", Value: 1}, {Key: "_id", Value: 1}}},
+}
+
+// worklogs are listed per task and reported over a range of start dates
+var worklogIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "taskId", Value: 1}, {Key: "started", Value: 1}}},
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "started", Value: 1}}},
+}
+
+// a subject runs one timer per task
+var timerIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "subject", Value: 1}, {Key: "taskId", Value: 1}}, Options: options.Index().SetUnique(true)},
+}
+
+// a custom field key is unique in its workspace
+var customFieldIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "key", Value: 1}}, Options: options.Index().SetUnique(true)},
+}
+
+// templates are listed by name
+var templateIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "workspaceId", Value: 1}, {Key: "name", Value: 1}, {Key: "_id", Value: 1}}},
+}
+
+// a subject has one membership per workspace, the _id enforces it, this index lists the workspaces of a subject
+var membershipIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "subject", Value: 1}, {Key: "workspaceId", Value: 1}}},
+}
+
+// function to create the task indexes and rank the tasks stored before priorities existed,
+// so they sort with the tasks without a priority
+func ensureTaskIndexes(ctx context.Context, collection appdb.DatabaseCollection) error {
+	if _, err := collection.CreateIndexes(ctx, taskIndexes); err != nil {
+		return fmt.Errorf("failed to create task indexes: %v", err)
+	}
+	_, err := collection.UpdateMany(ctx,
+		bson.M{"priorityRank": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"priorityRank": 0}})
+	if err != nil {
+		return fmt.Errorf("failed to rank tasks: %v", err)
+	}
+	return nil
+}
+
+func ensureMembershipIndexes(ctx context.Context, collection appdb.DatabaseCollection) error {
+	if _, err := collection.CreateIndexes(ctx, membershipIndexes); err != nil {
+		return fmt.Errorf("failed to create membership indexes: %v", err)
+	}
+	return nil
+}
+
+func ensureProjectIndexes(ctx context.Context, collection appdb.DatabaseCollection) error {
+	if _, err := collection.CreateIndexes(ctx, projectIndexes); err != nil {
+		return fmt.Errorf("failed to create project indexes: %v", err)
+	}
+	return nil
+}
+
+func ensureCommentIndexes(ctx context.Context, collection appdb.DatabaseCollection) error {
+	if _, err := collection.CreateIndexes(ctx, commentIndexes); err != nil {
+		return fmt.Errorf("failed to create comment indexes: %v", err)
+	}
+	return nil
+}
+
+func ensureCustomFieldIndexes(ctx context.Context, collection appdb.DatabaseCollection) error {
+	if _, err := collection.CreateIndexes(ctx, customFieldIndexes); err != nil {
+		return fmt.Errorf("failed to create custom field indexes: %v", err)
+	}
+	return nil
+}
+
+func ensureTemplateIndexes(ctx context.Context, collection appdb.DatabaseCollection) error {
+	if _, err := collection.CreateIndexes(ctx, templateIndexes); err != nil {
+		return fmt.Errorf("failed to create template indexes: %v", err)
+	}
+	return nil
+}
+
+func ensureWorklogIndexes(ctx context.Context, worklogs appdb.DatabaseCollection, timers appdb.DatabaseCollection) error {
+	if _, err := worklogs.CreateIndexes(ctx, worklogIndexes); err != nil {
+		return fmt.Errorf("failed to create worklog indexes: %v", err)
+	}
+	if _, err := timers.CreateIndexes(ctx, timerIndexes); err != nil {
+		return fmt.Errorf("failed to create timer indexes: %v", err)
+	}
+	return nil
+}
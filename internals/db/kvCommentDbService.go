@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+	apimodels "TaskSvc/internals/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// kvCommentDbService implements CommentDbService on a KVStore, scoped to the workspace of the context like kvDbService
+type kvCommentDbService struct {
+	store KVStore
+}
+
+func NewKVCommentDbService(store KVStore) CommentDbService {
+	return &kvCommentDbService{store: store}
+}
+
+func (d *kvCommentDbService) GetComments(ctx context.Context, taskId string, query *apimodels.CommentQuery) (*models.CommentPage, error) {
+	var cursor *pageCursor
+	if len(query.Cursor) > 0 {
+		var err error
+		if cursor, err = decodeCursor(query.Cursor, apimodels.SortByCreatedAt, false); err != nil {
+			return nil, apperrors.NewBadRequestError(err.Error())
+		}
+	}
+
+	var comments []*models.CommentSchema
+	err := d.store.View(func(tx KVTx) error {
+		var err error
+		comments, err = kvTaskComments(ctx, tx, taskId)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch comments: %v", err)
+	}
+
+	total := int64(len(comments))
+	sort.SliceStable(comments, func(i, j int) bool {
+		return compareSortPosition(comments[i].CreatedAt, comments[i].ID, comments[j].CreatedAt, comments[j].ID, false) < 0
+	})
+	if cursor != nil {
+		position := sort.Search(len(comments), func(i int) bool {
+			return compareSortPosition(comments[i].CreatedAt, comments[i].ID, cursor.Value, cursor.ID, false) > 0
+		})
+		comments = comments[position:]
+	}
+	if int64(len(comments)) > query.Limit+1 {
+		comments = comments[:query.Limit+1]
+	}
+	return newCommentPage(comments, total, query)
+}
+
+func (d *kvCommentDbService) GetCommentById(ctx context.Context, taskId string, commentId string) (*models.CommentSchema, error) {
+	id, err := parseObjectId(commentId)
+	if err != nil {
+		return nil, err
+	}
+	var comment *models.CommentSchema
+	err = d.store.View(func(tx KVTx) error {
+		comment, err = kvGetComment(ctx, tx, taskId, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (d *kvCommentDbService) SaveComment(ctx context.Context, comment *models.CommentSchema) (string, error) {
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+	comment.Version = 1
+	comment.WorkspaceID = appauth.GetWorkspace(ctx)
+	err := d.store.Update(func(tx KVTx) error {
+		return kvPut(tx, configs.MONGO_COMMENT_COLLECTION, comment.ID.Hex(), comment)
+	})
+	if err != nil {
+		return "", err
+	}
+	return comment.ID.Hex(), nil
+}
+
+func (d *kvCommentDbService) UpdateComment(ctx context.Context, comment *models.CommentSchema, revision models.CommentRevision, version int64) (*models.CommentSchema, error) {
+	var updated *models.CommentSchema
+	err := d.store.Update(func(tx KVTx) error {
+		current, err := kvGetComment(ctx, tx, comment.TaskID, comment.ID)
+		if err != nil {
+			return err
+		}
+		if current.Version != version {
+			return apperrors.NewPreconditionFailedError(fmt.Sprintf("comment %s has been modified", comment.ID.Hex()))
+		}
+		current.Body = comment.Body
+		current.UpdatedAt = comment.UpdatedAt
+		current.History = append(current.History, revision)
+		current.Version++
+		updated = current
+		return kvPut(tx, configs.MONGO_COMMENT_COLLECTION, current.ID.Hex(), current)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func (d *kvCommentDbService) DeleteCommentById(ctx context.Context, taskId string, commentId string) error {
+	id, err := parseObjectId(commentId)
+	if err != nil {
+		return err
+	}
+	return d.store.Update(func(tx KVTx) error {
+		if _, err := kvGetComment(ctx, tx, taskId, id); err != nil {
+			return err
+		}
+		return tx.Delete(configs.MONGO_COMMENT_COLLECTION, id.Hex())
+	})
+}
+
+func (d *kvCommentDbService) DeleteComments(ctx context.Context, taskId string) error {
+	return d.store.Update(func(tx KVTx) error {
+		comments, err := kvTaskComments(ctx, tx, taskId)
+		if err != nil {
+			return err
+		}
+		for _, comment := range comments {
+			if err := tx.Delete(configs.MONGO_COMMENT_COLLECTION, comment.ID.Hex()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *kvCommentDbService) CountComments(ctx context.Context, taskId string) (int64, error) {
+	var count int64
+	err := d.store.View(func(tx KVTx) error {
+		comments, err := kvTaskComments(ctx, tx, taskId)
+		count = int64(len(comments))
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count comments: %v", err)
+	}
+	return count, nil
+}
+
+// function to load the comment of the task, comments of other tasks and workspaces are reported as missing
+func kvGetComment(ctx context.Context, tx KVTx, taskId string, id primitive.ObjectID) (*models.CommentSchema, error) {
+	var comment models.CommentSchema
+	found, err := kvGet(tx, configs.MONGO_COMMENT_COLLECTION, id.Hex(), &comment)
+	if err != nil {
+		return nil, err
+	}
+	if !found || comment.TaskID != taskId || !inWorkspace(ctx, comment.WorkspaceID) {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("comment %s not found", id.Hex()))
+	}
+	return &comment, nil
+}
+
+func kvTaskComments(ctx context.Context, tx KVTx, taskId string) ([]*models.CommentSchema, error) {
+	comments := []*models.CommentSchema{}
+	err := tx.ForEach(configs.MONGO_COMMENT_COLLECTION, func(key string, value []byte) error {
+		var comment models.CommentSchema
+		if err := bson.Unmarshal(value, &comment); err != nil {
+			return fmt.Errorf("failed to decode comment %s: %v", key, err)
+		}
+		if comment.TaskID == taskId && inWorkspace(ctx, comment.WorkspaceID) {
+			comments = append(comments, &comment)
+		}
+		return nil
+	})
+	return comments, err
+}
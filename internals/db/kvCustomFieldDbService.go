@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// kvCustomFieldDbService implements CustomFieldDbService on a KVStore, scoped to the workspace of the context like kvDbService
+type kvCustomFieldDbService struct {
+	store KVStore
+}
+
+func NewKVCustomFieldDbService(store KVStore) CustomFieldDbService {
+	return &kvCustomFieldDbService{store: store}
+}
+
+func (d *kvCustomFieldDbService) GetCustomFields(ctx context.Context) ([]*models.CustomFieldSchema, error) {
+	var fields []*models.CustomFieldSchema
+	err := d.store.View(func(tx KVTx) error {
+		var err error
+		fields, err = kvWorkspaceCustomFields(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch custom fields: %v", err)
+	}
+	sort.SliceStable(fields, func(i, j int) bool { return fields[i].Key < fields[j].Key })
+	return fields, nil
+}
+
+func (d *kvCustomFieldDbService) GetCustomFieldById(ctx context.Context, fieldId string) (*models.CustomFieldSchema, error) {
+	id, err := parseObjectId(fieldId)
+	if err != nil {
+		return nil, err
+	}
+	var field *models.CustomFieldSchema
+	err = d.store.View(func(tx KVTx) error {
+		field, err = kvGetCustomField(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+func (d *kvCustomFieldDbService) SaveCustomField(ctx context.Context, field *models.CustomFieldSchema) (string, error) {
+	if field.ID.IsZero() {
+		field.ID = primitive.NewObjectID()
+	}
+	field.WorkspaceID = appauth.GetWorkspace(ctx)
+	err := d.store.Update(func(tx KVTx) error {
+		fields, err := kvWorkspaceCustomFields(ctx, tx)
+		if err != nil {
+			return err
+		}
+		for _, existing := range fields {
+			if existing.Key == field.Key {
+				return apperrors.NewConflictError(fmt.Sprintf("custom field key %s is already in use", field.Key), nil)
+			}
+		}
+		return kvPut(tx, configs.MONGO_CUSTOM_FIELD_COLLECTION, field.ID.Hex(), field)
+	})
+	if err != nil {
+		return "", err
+	}
+	return field.ID.Hex(), nil
+}
+
+func (d *kvCustomFieldDbService) UpdateCustomField(ctx context.Context, field *models.CustomFieldSchema) error {
+	return d.store.Update(func(tx KVTx) error {
+		current, err := kvGetCustomField(ctx, tx, field.ID)
+		if err != nil {
+			return err
+		}
+		current.Name = field.Name
+		current.Required = field.Required
+		current.Default = field.Default
+		current.Options = field.Options
+		current.UpdatedAt = field.UpdatedAt
+		return kvPut(tx, configs.MONGO_CUSTOM_FIELD_COLLECTION, field.ID.Hex(), current)
+	})
+}
+
+func (d *kvCustomFieldDbService) DeleteCustomFieldById(ctx context.Context, fieldId string) error {
+	id, err := parseObjectId(fieldId)
+	if err != nil {
+		return err
+	}
+	return d.store.Update(func(tx KVTx) error {
+		if _, err := kvGetCustomField(ctx, tx, id); err != nil {
+			return err
+		}
+		return tx.Delete(configs.MONGO_CUSTOM_FIELD_COLLECTION, id.Hex())
+	})
+}
+
+// function to load the definition, definitions of other workspaces are reported as missing
+func kvGetCustomField(ctx context.Context, tx KVTx, id primitive.ObjectID) (*models.CustomFieldSchema, error) {
+	var field models.CustomFieldSchema
+	found, err := kvGet(tx, configs.MONGO_CUSTOM_FIELD_COLLECTION, id.Hex(), &field)
+	if err != nil {
+		return nil, err
+	}
+	if !found || !inWorkspace(ctx, field.WorkspaceID) {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("custom field %s not found", id.Hex()))
+	}
+	return &field, nil
+}
+
+func kvWorkspaceCustomFields(ctx context.Context, tx KVTx) ([]*models.CustomFieldSchema, error) {
+	fields := []*models.CustomFieldSchema{}
+	err := tx.ForEach(configs.MONGO_CUSTOM_FIELD_COLLECTION, func(key string, value []byte) error {
+		var field models.CustomFieldSchema
+		if err := bson.Unmarshal(value, &field); err != nil {
+			return fmt.Errorf("failed to decode custom field %s: %v", key, err)
+		}
+		if inWorkspace(ctx, field.WorkspaceID) {
+			fields = append(fields, &field)
+		}
+		return nil
+	})
+	return fields, err
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// function to decode the document stored under the key, returns false when there is none
+func kvGet(tx KVTx, collection string, key string, document interface{}) (bool, error) {
+	value := tx.Get(collection, key)
+	if value == nil {
+		return false, nil
+	}
+	if err := bson.Unmarshal(value, document); err != nil {
+		return false, fmt.Errorf("failed to decode %s/%s: %v", collection, key, err)
+	}
+	return true, nil
+}
+
+func kvPut(tx KVTx, collection string, key string, document interface{}) error {
+	value, err := bson.Marshal(document)
+	if err != nil {
+		return err
+	}
+	return tx.Put(collection, key, value)
+}
+
+// function to $set the fields, keyed by their bson path, on a copy of the document
+func kvSetFields(document interface{}, fields map[string]interface{}, result interface{}) error {
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return err
+	}
+	var values bson.M
+	if err := bson.Unmarshal(raw, &values); err != nil {
+		return err
+	}
+	for path, value := range fields {
+		setPath(values, strings.Split(path, "."), value)
+	}
+	if raw, err = bson.Marshal(values); err != nil {
+		return err
+	}
+	return bson.Unmarshal(raw, result)
+}
+
+func setPath(values bson.M, path []string, value interface{}) {
+	if len(path) == 1 {
+		values[path[0]] = value
+		return
+	}
+	var child bson.M
+	switch nested := values[path[0]].(type) {
+	case bson.M:
+		child = nested
+	case bson.D:
+		child = nested.Map()
+	default:
+		child = bson.M{}
+	}
+	setPath(child, path[1:], value)
+	values[path[0]] = child
+}
+
+// type ranks following the mongo sort order of bson types
+func typeRank(value interface{}) int {
+	switch value.(type) {
+	case nil, primitive.Null, primitive.Undefined:
+		return 1
+	case int, int32, int64, float64:
+		return 2
+	case string:
+		return 3
+	case bson.M, bson.D:
+		return 4
+	case bson.A, []interface{}:
+		return 5
+	case primitive.ObjectID:
+		return 7
+	case bool:
+		return 8
+	case time.Time, primitive.DateTime:
+		return 9
+	default:
+		return 10
+	}
+}
+
+// function to compare two bson values like mongo sorts them, values of different types order by type
+func compareValues(a, b interface{}) int {
+	rankA, rankB := typeRank(a), typeRank(b)
+	if rankA != rankB {
+		return rankA - rankB
+	}
+	switch rankA {
+	case 2:
+		return compareFloats(toFloat(a), toFloat(b))
+	case 3:
+		return strings.Compare(a.(string), b.(string))
+	case 7:
+		idA, idB := a.(primitive.ObjectID), b.(primitive.ObjectID)
+		return bytes.Compare(idA[:], idB[:])
+	case 8:
+		if a.(bool) == b.(bool) {
+			return 0
+		} else if b.(bool) {
+			return -1
+		}
+		return 1
+	case 9:
+		return toTime(a).Compare(toTime(b))
+	}
+	return 0
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func toFloat(value interface{}) float64 {
+	switch number := value.(type) {
+	case int:
+		return float64(number)
+	case int32:
+		return float64(number)
+	case int64:
+		return float64(number)
+	case float64:
+		return number
+	}
+	return math.NaN()
+}
+
+// function to normalise dates to the millisecond precision mongo stores
+func toTime(value interface{}) time.Time {
+	switch date := value.(type) {
+	case time.Time:
+		return date.UTC().Truncate(time.Millisecond)
+	case primitive.DateTime:
+		return date.Time().UTC()
+	}
+	return time.Time{}
+}
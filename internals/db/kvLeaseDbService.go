@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+)
+
+// kvLeaseDbService implements LeaseDbService on a KVStore, the store serializes the writes
+// so checking and taking the lease in one transaction is enough
+type kvLeaseDbService struct {
+	store KVStore
+}
+
+func NewKVLeaseDbService(store KVStore) LeaseDbService {
+	return &kvLeaseDbService{store: store}
+}
+
+func (d *kvLeaseDbService) AcquireLease(ctx context.Context, name string, holder string, now time.Time, expiresAt time.Time) (bool, error) {
+	acquired := false
+	err := d.store.Update(func(tx KVTx) error {
+		var lease models.LeaseSchema
+		found, err := kvGet(tx, configs.MONGO_LEASE_COLLECTION, name, &lease)
+		if err != nil {
+			return err
+		}
+		if found && lease.Holder != holder && lease.ExpiresAt.After(now) {
+			return nil
+		}
+		acquired = true
+		return kvPut(tx, configs.MONGO_LEASE_COLLECTION, name, &models.LeaseSchema{Name: name, Holder: holder, ExpiresAt: expiresAt})
+	})
+	if err != nil {
+		return false, err
+	}
+	return acquired, nil
+}
+
+func (d *kvLeaseDbService) ReleaseLease(ctx context.Context, name string, holder string) error {
+	return d.store.Update(func(tx KVTx) error {
+		var lease models.LeaseSchema
+		found, err := kvGet(tx, configs.MONGO_LEASE_COLLECTION, name, &lease)
+		if err != nil || !found || lease.Holder != holder {
+			return err
+		}
+		return tx.Delete(configs.MONGO_LEASE_COLLECTION, name)
+	})
+}
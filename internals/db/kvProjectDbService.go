@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// kvProjectDbService implements ProjectDbService on a KVStore, scoped to the workspace of the context like kvDbService
+type kvProjectDbService struct {
+	store KVStore
+}
+
+func NewKVProjectDbService(store KVStore) ProjectDbService {
+	return &kvProjectDbService{store: store}
+}
+
+func (d *kvProjectDbService) GetProjectById(ctx context.Context, projectId string) (*models.ProjectSchema, error) {
+	id, err := parseObjectId(projectId)
+	if err != nil {
+		return nil, err
+	}
+	var project *models.ProjectSchema
+	err = d.store.View(func(tx KVTx) error {
+		project, err = kvGetProject(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+func (d *kvProjectDbService) GetProjects(ctx context.Context) ([]*models.ProjectSchema, error) {
+	var projects []*models.ProjectSchema
+	err := d.store.View(func(tx KVTx) error {
+		var err error
+		projects, err = kvWorkspaceProjects(ctx, tx)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %v", err)
+	}
+	sort.SliceStable(projects, func(i, j int) bool { return projects[i].Key < projects[j].Key })
+	return projects, nil
+}
+
+func (d *kvProjectDbService) SaveProject(ctx context.Context, project *models.ProjectSchema) (string, error) {
+	if project.ID.IsZero() {
+		project.ID = primitive.NewObjectID()
+	}
+	project.TaskCounter = 0
+	project.WorkspaceID = appauth.GetWorkspace(ctx)
+	err := d.store.Update(func(tx KVTx) error {
+		projects, err := kvWorkspaceProjects(ctx, tx)
+		if err != nil {
+			return err
+		}
+		for _, existing := range projects {
+			if existing.Key == project.Key {
+				return apperrors.NewConflictError(fmt.Sprintf("project key %s is already in use", project.Key), nil)
+			}
+		}
+		return kvPut(tx, configs.MONGO_PROJECT_COLLECTION, project.ID.Hex(), project)
+	})
+	if err != nil {
+		return "", err
+	}
+	return project.ID.Hex(), nil
+}
+
+func (d *kvProjectDbService) UpdateProject(ctx context.Context, project *models.ProjectSchema) error {
+	return d.store.Update(func(tx KVTx) error {
+		current, err := kvGetProject(ctx, tx, project.ID)
+		if err != nil {
+			return err
+		}
+		current.Name = project.Name
+		current.Description = project.Description
+		current.Workflow = project.Workflow
+		current.UpdatedAt = project.UpdatedAt
+		return kvPut(tx, configs.MONGO_PROJECT_COLLECTION, project.ID.Hex(), current)
+	})
+}
+
+func (d *kvProjectDbService) DeleteProjectById(ctx context.Context, projectId string) error {
+	id, err := parseObjectId(projectId)
+	if err != nil {
+		return err
+	}
+	return d.store.Update(func(tx KVTx) error {
+		if _, err := kvGetProject(ctx, tx, id); err != nil {
+			return err
+		}
+		return tx.Delete(configs.MONGO_PROJECT_COLLECTION, id.Hex())
+	})
+}
+
+// function to allocate the next task key, the read and the increment share one write transaction
+func (d *kvProjectDbService) AllocateTaskKey(ctx context.Context, projectId string) (string, error) {
+	id, err := parseObjectId(projectId)
+	if err != nil {
+		return "", err
+	}
+	var key string
+	err = d.store.Update(func(tx KVTx) error {
+		project, err := kvGetProject(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		project.TaskCounter++
+		key = formatTaskKey(project.Key, project.TaskCounter)
+		return kvPut(tx, configs.MONGO_PROJECT_COLLECTION, id.Hex(), project)
+	})
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// function to load the project, projects of other workspaces are reported as missing
+func kvGetProject(ctx context.Context, tx KVTx, id primitive.ObjectID) (*models.ProjectSchema, error) {
+	var project models.ProjectSchema
+	found, err := kvGet(tx, configs.MONGO_PROJECT_COLLECTION, id.Hex(), &project)
+	if err != nil {
+		return nil, err
+	}
+	if !found || !inWorkspace(ctx, project.WorkspaceID) {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("project %s not found", id.Hex()))
+	}
+	return &project, nil
+}
+
+func kvWorkspaceProjects(ctx context.Context, tx KVTx) ([]*models.ProjectSchema, error) {
+	projects := []*models.ProjectSchema{}
+	err := tx.ForEach(configs.MONGO_PROJECT_COLLECTION, func(key string, value []byte) error {
+		var project models.ProjectSchema
+		if err := bson.Unmarshal(value, &project); err != nil {
+			return fmt.Errorf("failed to decode project %s: %v", key, err)
+		}
+		if inWorkspace(ctx, project.WorkspaceID) {
+			projects = append(projects, &project)
+		}
+		return nil
+	})
+	return projects, err
+}
@@ -0,0 +1,122 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// KVStore is a transactional store of bson documents grouped in collections,
+// it backs the DbService implementations that run without MongoDB
+type KVStore interface {
+	View(fn func(tx KVTx) error) error
+	Update(fn func(tx KVTx) error) error
+	Close() error
+}
+
+// KVTx reads and writes inside one transaction, ForEach visits the keys in ascending order
+type KVTx interface {
+	Get(collection string, key string) []byte
+	Put(collection string, key string, value []byte) error
+	Delete(collection string, key string) error
+	ForEach(collection string, fn func(key string, value []byte) error) error
+}
+
+type memoryStore struct {
+	mu          sync.RWMutex
+	collections map[string]map[string][]byte
+}
+
+func NewMemoryStore() KVStore {
+	return &memoryStore{collections: map[string]map[string][]byte{}}
+}
+
+func (m *memoryStore) View(fn func(tx KVTx) error) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return fn(&memoryTx{store: m})
+}
+
+// function to run fn with exclusive access, the collections it writes are copied
+// and only swapped in when fn succeeds so a failed transaction leaves no trace
+func (m *memoryStore) Update(fn func(tx KVTx) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tx := &memoryTx{store: m, writable: true, written: map[string]map[string][]byte{}}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	for name, collection := range tx.written {
+		m.collections[name] = collection
+	}
+	return nil
+}
+
+func (m *memoryStore) Close() error {
+	return nil
+}
+
+type memoryTx struct {
+	store    *memoryStore
+	writable bool
+	written  map[string]map[string][]byte
+}
+
+func (t *memoryTx) collection(name string) map[string][]byte {
+	if collection, ok := t.written[name]; ok {
+		return collection
+	}
+	return t.store.collections[name]
+}
+
+func (t *memoryTx) writableCollection(name string) (map[string][]byte, error) {
+	if !t.writable {
+		return nil, fmt.Errorf("write in a read-only transaction")
+	}
+	if collection, ok := t.written[name]; ok {
+		return collection, nil
+	}
+	collection := make(map[string][]byte, len(t.store.collections[name])+1)
+	for key, value := range t.store.collections[name] {
+		collection[key] = value
+	}
+	t.written[name] = collection
+	return collection, nil
+}
+
+func (t *memoryTx) Get(collection string, key string) []byte {
+	return t.collection(collection)[key]
+}
+
+func (t *memoryTx) Put(collection string, key string, value []byte) error {
+	documents, err := t.writableCollection(collection)
+	if err != nil {
+		return err
+	}
+	documents[key] = append([]byte(nil), value...)
+	return nil
+}
+
+func (t *memoryTx) Delete(collection string, key string) error {
+	documents, err := t.writableCollection(collection)
+	if err != nil {
+		return err
+	}
+	delete(documents, key)
+	return nil
+}
+
+func (t *memoryTx) ForEach(collection string, fn func(key string, value []byte) error) error {
+	documents := t.collection(collection)
+	keys := make([]string, 0, len(documents))
+	for key := range documents {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := fn(key, documents[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
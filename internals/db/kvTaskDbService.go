@@ -0,0 +1,633 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+	apimodels "TaskSvc/internals/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// kvDbService implements DbService on a KVStore, filtering and sorting in memory
+// with the same semantics as the mongo queries of dbService
+type kvDbService struct {
+	store KVStore
+}
+
+func NewKVDbService(store KVStore) DbService {
+	return &kvDbService{store: store}
+}
+
+func (d *kvDbService) GetTaskById(ctx context.Context, taskId string) (*models.TaskSchema, error) {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return nil, err
+	}
+	var task models.TaskSchema
+	err = d.store.View(func(tx KVTx) error {
+		found, err := kvGet(tx, configs.MONGO_TASK_COLLECTION, id.Hex(), &task)
+		if err == nil && (!found || !inWorkspace(ctx, task.WorkspaceID) || task.DeletedAt != nil) {
+			return apperrors.NewNotFoundError(fmt.Sprintf("task %s not found", taskId))
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (d *kvDbService) GetDeletedTaskById(ctx context.Context, taskId string) (*models.TaskSchema, error) {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return nil, err
+	}
+	var task *models.TaskSchema
+	err = d.store.View(func(tx KVTx) error {
+		task, err = kvGetTrashedTaskForWrite(ctx, tx, id, 0)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (d *kvDbService) GetTasks(ctx context.Context, query *apimodels.TaskQuery) (*models.TaskPage, error) {
+	var cursor *pageCursor
+	if len(query.Cursor) > 0 {
+		var err error
+		if cursor, err = decodeCursor(query.Cursor, query.SortBy, query.SortDesc); err != nil {
+			return nil, apperrors.NewBadRequestError(err.Error())
+		}
+	}
+
+	var tasks []*models.TaskSchema
+	err := d.store.View(func(tx KVTx) error {
+		return tx.ForEach(configs.MONGO_TASK_COLLECTION, func(key string, value []byte) error {
+			var task models.TaskSchema
+			if err := bson.Unmarshal(value, &task); err != nil {
+				return fmt.Errorf("failed to decode task %s: %v", key, err)
+			}
+			if inWorkspace(ctx, task.WorkspaceID) && (task.DeletedAt != nil) == query.Deleted && matchesTaskQuery(&task, query) {
+				tasks = append(tasks, &task)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch tasks: %v", err)
+	}
+
+	total := int64(len(tasks))
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return compareTasks(tasks[i], tasks[j], query) < 0
+	})
+	if cursor != nil {
+		position := sort.Search(len(tasks), func(i int) bool {
+			return compareToCursor(tasks[i], cursor, query) > 0
+		})
+		tasks = tasks[position:]
+	}
+	if int64(len(tasks)) > query.Limit+1 {
+		tasks = tasks[:query.Limit+1]
+	}
+	return newTaskPage(tasks, total, query)
+}
+
+func (d *kvDbService) SaveTask(ctx context.Context, task *models.TaskSchema) (string, error) {
+	if task.ID.IsZero() {
+		task.ID = primitive.NewObjectID()
+	}
+	task.Version = 1
+	task.WorkspaceID = appauth.GetWorkspace(ctx)
+	err := d.store.Update(func(tx KVTx) error {
+		if tx.Get(configs.MONGO_TASK_COLLECTION, task.ID.Hex()) != nil {
+			return apperrors.NewConflictError("task already exists", nil)
+		}
+		return kvPut(tx, configs.MONGO_TASK_COLLECTION, task.ID.Hex(), task)
+	})
+	if err != nil {
+		return "", err
+	}
+	return task.ID.Hex(), nil
+}
+
+// function to save the tasks in a single transaction, a conflict on any of them saves none
+func (d *kvDbService) SaveTasks(ctx context.Context, tasks []*models.TaskSchema) ([]string, error) {
+	taskIds := make([]string, len(tasks))
+	err := d.store.Update(func(tx KVTx) error {
+		for i, task := range tasks {
+			if task.ID.IsZero() {
+				task.ID = primitive.NewObjectID()
+			}
+			task.Version = 1
+			task.WorkspaceID = appauth.GetWorkspace(ctx)
+			if tx.Get(configs.MONGO_TASK_COLLECTION, task.ID.Hex()) != nil {
+				return apperrors.NewConflictError("task already exists", nil)
+			}
+			if err := kvPut(tx, configs.MONGO_TASK_COLLECTION, task.ID.Hex(), task); err != nil {
+				return err
+			}
+			taskIds[i] = task.ID.Hex()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return taskIds, nil
+}
+
+func (d *kvDbService) UpdateTask(ctx context.Context, task *models.TaskSchema, taskId string, version int64) error {
+	_, err := d.PatchTask(ctx, taskId, taskUpdateFields(task), version)
+	return err
+}
+
+func (d *kvDbService) PatchTask(ctx context.Context, taskId string, fields map[string]interface{}, version int64) (*models.TaskSchema, error) {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return nil, err
+	}
+	var updated models.TaskSchema
+	err = d.store.Update(func(tx KVTx) error {
+		current, err := kvGetTaskForWrite(ctx, tx, id, version)
+		if err != nil {
+			return err
+		}
+		if err := kvSetFields(current, fields, &updated); err != nil {
+			return err
+		}
+		updated.Version = current.Version + 1
+		return kvPut(tx, configs.MONGO_TASK_COLLECTION, id.Hex(), &updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (d *kvDbService) TrashTask(ctx context.Context, taskId string, version int64, deletedAt time.Time, deletedBy string) error {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return err
+	}
+	return d.store.Update(func(tx KVTx) error {
+		task, err := kvGetTaskForWrite(ctx, tx, id, version)
+		if err != nil {
+			return err
+		}
+		task.DeletedAt, task.DeletedBy = &deletedAt, deletedBy
+		task.Version++
+		return kvPut(tx, configs.MONGO_TASK_COLLECTION, id.Hex(), task)
+	})
+}
+
+func (d *kvDbService) RestoreTask(ctx context.Context, taskId string, version int64) error {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return err
+	}
+	return d.store.Update(func(tx KVTx) error {
+		task, err := kvGetTrashedTaskForWrite(ctx, tx, id, version)
+		if err != nil {
+			return err
+		}
+		task.DeletedAt, task.DeletedBy = nil, ""
+		task.Version++
+		return kvPut(tx, configs.MONGO_TASK_COLLECTION, id.Hex(), task)
+	})
+}
+
+func (d *kvDbService) DeleteTaskById(ctx context.Context, taskId string, version int64) error {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return err
+	}
+	return d.store.Update(func(tx KVTx) error {
+		if _, err := kvGetTrashedTaskForWrite(ctx, tx, id, version); err != nil {
+			return err
+		}
+		return tx.Delete(configs.MONGO_TASK_COLLECTION, id.Hex())
+	})
+}
+
+func (d *kvDbService) GetDeletedTasks(ctx context.Context, deletedBefore time.Time, afterId string, limit int64) ([]*models.TaskSchema, error) {
+	return d.walkTasks(afterId, limit, "deleted tasks", func(task *models.TaskSchema) bool {
+		return task.DeletedAt != nil && task.DeletedAt.Before(deletedBefore)
+	})
+}
+
+func (d *kvDbService) GetRecurringTasks(ctx context.Context, afterId string, limit int64) ([]*models.TaskSchema, error) {
+	return d.walkTasks(afterId, limit, "recurring tasks", func(task *models.TaskSchema) bool {
+		return len(task.Recurrence) > 0 && len(task.NextOccurrenceID) == 0 && task.DeletedAt == nil
+	})
+}
+
+// function to list the tasks of every workspace matching the filter, in the order of their ids after afterId
+func (d *kvDbService) walkTasks(afterId string, limit int64, name string, matches func(task *models.TaskSchema) bool) ([]*models.TaskSchema, error) {
+	if len(afterId) > 0 {
+		id, err := parseObjectId(afterId)
+		if err != nil {
+			return nil, err
+		}
+		afterId = id.Hex()
+	}
+	var tasks []*models.TaskSchema
+	err := d.store.View(func(tx KVTx) error {
+		return tx.ForEach(configs.MONGO_TASK_COLLECTION, func(key string, value []byte) error {
+			if key <= afterId {
+				return nil
+			}
+			var task models.TaskSchema
+			if err := bson.Unmarshal(value, &task); err != nil {
+				return fmt.Errorf("failed to decode task %s: %v", key, err)
+			}
+			if matches(&task) {
+				tasks = append(tasks, &task)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %v", name, err)
+	}
+	// the keys are the hex ids, bolt and the memory store walk them in order
+	if int64(len(tasks)) > limit {
+		tasks = tasks[:limit]
+	}
+	return tasks, nil
+}
+
+// function to walk the blockedBy links both ways like the $graphLookup stages of dbService
+func (d *kvDbService) GetDependencyGraph(ctx context.Context, taskId string) ([]*models.TaskSchema, error) {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return nil, err
+	}
+	byId := map[primitive.ObjectID]*models.TaskSchema{}
+	dependents := map[primitive.ObjectID][]primitive.ObjectID{}
+	err = d.store.View(func(tx KVTx) error {
+		return tx.ForEach(configs.MONGO_TASK_COLLECTION, func(key string, value []byte) error {
+			var task models.TaskSchema
+			if err := bson.Unmarshal(value, &task); err != nil {
+				return fmt.Errorf("failed to decode task %s: %v", key, err)
+			}
+			if !inWorkspace(ctx, task.WorkspaceID) || task.DeletedAt != nil {
+				return nil
+			}
+			byId[task.ID] = &task
+			for _, blockerId := range task.BlockedBy {
+				dependents[blockerId] = append(dependents[blockerId], task.ID)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the dependency graph: %v", err)
+	}
+	root, found := byId[id]
+	if !found {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("task %s not found", taskId))
+	}
+
+	tasks := []*models.TaskSchema{root}
+	seen := map[primitive.ObjectID]bool{id: true}
+	walk := func(next func(task *models.TaskSchema) []primitive.ObjectID) {
+		queue := []*models.TaskSchema{root}
+		for len(queue) > 0 {
+			task := queue[0]
+			queue = queue[1:]
+			for _, linkedId := range next(task) {
+				linked, found := byId[linkedId]
+				if !found || seen[linkedId] {
+					continue
+				}
+				seen[linkedId] = true
+				tasks = append(tasks, linked)
+				queue = append(queue, linked)
+			}
+		}
+	}
+	walk(func(task *models.TaskSchema) []primitive.ObjectID { return task.BlockedBy })
+	walk(func(task *models.TaskSchema) []primitive.ObjectID { return dependents[task.ID] })
+	return tasks, nil
+}
+
+func (d *kvDbService) RemoveBlocker(ctx context.Context, blockerId string) error {
+	id, err := parseObjectId(blockerId)
+	if err != nil {
+		return err
+	}
+	return d.store.Update(func(tx KVTx) error {
+		var blocked []*models.TaskSchema
+		err := tx.ForEach(configs.MONGO_TASK_COLLECTION, func(key string, value []byte) error {
+			var task models.TaskSchema
+			if err := bson.Unmarshal(value, &task); err != nil {
+				return fmt.Errorf("failed to decode task %s: %v", key, err)
+			}
+			if inWorkspace(ctx, task.WorkspaceID) && containsObjectId(task.BlockedBy, id) {
+				blocked = append(blocked, &task)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, task := range blocked {
+			remaining := []primitive.ObjectID{}
+			for _, linkedId := range task.BlockedBy {
+				if linkedId != id {
+					remaining = append(remaining, linkedId)
+				}
+			}
+			task.BlockedBy = remaining
+			task.Version++
+			if err := kvPut(tx, configs.MONGO_TASK_COLLECTION, task.ID.Hex(), task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// function to count the labels of the workspace like the Distinct and aggregation of dbService
+func (d *kvDbService) GetLabels(ctx context.Context, prefix string, limit int64) ([]*models.LabelCount, error) {
+	counts := map[string]int64{}
+	err := d.store.View(func(tx KVTx) error {
+		return tx.ForEach(configs.MONGO_TASK_COLLECTION, func(key string, value []byte) error {
+			var task models.TaskSchema
+			if err := bson.Unmarshal(value, &task); err != nil {
+				return fmt.Errorf("failed to decode task %s: %v", key, err)
+			}
+			if !inWorkspace(ctx, task.WorkspaceID) || task.DeletedAt != nil {
+				return nil
+			}
+			for _, label := range task.Labels {
+				if strings.HasPrefix(label, prefix) {
+					counts[label]++
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	labels := []*models.LabelCount{}
+	for name, count := range counts {
+		labels = append(labels, &models.LabelCount{Name: name, Count: count})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Count != labels[j].Count {
+			return labels[i].Count > labels[j].Count
+		}
+		return labels[i].Name < labels[j].Name
+	})
+	if int64(len(labels)) > limit {
+		labels = labels[:limit]
+	}
+	return labels, nil
+}
+
+func (d *kvDbService) RenameLabel(ctx context.Context, from string, to string, updatedAt time.Time) (int64, error) {
+	var changed int64
+	err := d.store.Update(func(tx KVTx) error {
+		var labelled []*models.TaskSchema
+		err := tx.ForEach(configs.MONGO_TASK_COLLECTION, func(key string, value []byte) error {
+			var task models.TaskSchema
+			if err := bson.Unmarshal(value, &task); err != nil {
+				return fmt.Errorf("failed to decode task %s: %v", key, err)
+			}
+			if inWorkspace(ctx, task.WorkspaceID) && containsString(task.Labels, from) {
+				labelled = append(labelled, &task)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, task := range labelled {
+			merge := containsString(task.Labels, to)
+			labels := []string{}
+			for _, label := range task.Labels {
+				if label != from {
+					labels = append(labels, label)
+				} else if !merge {
+					labels = append(labels, to)
+				}
+			}
+			task.Labels = labels
+			task.UpdatedAt = updatedAt
+			task.Version++
+			if err := kvPut(tx, configs.MONGO_TASK_COLLECTION, task.ID.Hex(), task); err != nil {
+				return err
+			}
+		}
+		changed = int64(len(labelled))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+func (d *kvDbService) AddChecklistItem(ctx context.Context, taskId string, item models.ChecklistItemSchema, position int, version int64, updatedAt time.Time) (*models.TaskSchema, error) {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return nil, err
+	}
+	return d.updateChecklist(ctx, id, version, updatedAt, func(task *models.TaskSchema) error {
+		if len(task.Checklist) >= apimodels.MaxChecklistItems {
+			return checklistError(task, primitive.NilObjectID)
+		}
+		// like $position, a position past the end appends
+		if position < 0 || position > len(task.Checklist) {
+			position = len(task.Checklist)
+		}
+		checklist := append(append([]models.ChecklistItemSchema{}, task.Checklist[:position]...), item)
+		task.Checklist = append(checklist, task.Checklist[position:]...)
+		return nil
+	})
+}
+
+func (d *kvDbService) UpdateChecklistItem(ctx context.Context, taskId string, itemId string, change models.ChecklistItemChange, version int64, updatedAt time.Time) (*models.TaskSchema, error) {
+	id, itemObjectId, err := parseChecklistItemIds(taskId, itemId)
+	if err != nil {
+		return nil, err
+	}
+	return d.updateChecklist(ctx, id, version, updatedAt, func(task *models.TaskSchema) error {
+		index := checklistItemIndex(task, itemObjectId)
+		if index < 0 {
+			return checklistError(task, itemObjectId)
+		}
+		item := &task.Checklist[index]
+		if change.Text != nil {
+			item.Text = *change.Text
+		}
+		if change.Done != nil {
+			item.Done, item.DoneBy, item.DoneAt = *change.Done, "", nil
+			if *change.Done {
+				item.DoneBy, item.DoneAt = change.DoneBy, change.DoneAt
+			}
+		}
+		return nil
+	})
+}
+
+func (d *kvDbService) MoveChecklistItem(ctx context.Context, taskId string, itemId string, position int, version int64, updatedAt time.Time) (*models.TaskSchema, error) {
+	id, itemObjectId, err := parseChecklistItemIds(taskId, itemId)
+	if err != nil {
+		return nil, err
+	}
+	return d.updateChecklist(ctx, id, version, updatedAt, func(task *models.TaskSchema) error {
+		index := checklistItemIndex(task, itemObjectId)
+		if index < 0 {
+			return checklistError(task, itemObjectId)
+		}
+		item := task.Checklist[index]
+		others := append(append([]models.ChecklistItemSchema{}, task.Checklist[:index]...), task.Checklist[index+1:]...)
+		if position > len(others) {
+			position = len(others)
+		}
+		checklist := append(append([]models.ChecklistItemSchema{}, others[:position]...), item)
+		task.Checklist = append(checklist, others[position:]...)
+		return nil
+	})
+}
+
+func (d *kvDbService) RemoveChecklistItem(ctx context.Context, taskId string, itemId string, version int64, updatedAt time.Time) (*models.TaskSchema, error) {
+	id, itemObjectId, err := parseChecklistItemIds(taskId, itemId)
+	if err != nil {
+		return nil, err
+	}
+	return d.updateChecklist(ctx, id, version, updatedAt, func(task *models.TaskSchema) error {
+		index := checklistItemIndex(task, itemObjectId)
+		if index < 0 {
+			return checklistError(task, itemObjectId)
+		}
+		task.Checklist = append(append([]models.ChecklistItemSchema{}, task.Checklist[:index]...), task.Checklist[index+1:]...)
+		return nil
+	})
+}
+
+// function to apply the change to the checklist within a single transaction, the kv counterpart of the array operators
+func (d *kvDbService) updateChecklist(ctx context.Context, id primitive.ObjectID, version int64, updatedAt time.Time, change func(task *models.TaskSchema) error) (*models.TaskSchema, error) {
+	var updated *models.TaskSchema
+	err := d.store.Update(func(tx KVTx) error {
+		task, err := kvGetTaskForWrite(ctx, tx, id, version)
+		if err != nil {
+			return err
+		}
+		if err := change(task); err != nil {
+			return err
+		}
+		task.UpdatedAt = updatedAt
+		task.Version++
+		updated = task
+		return kvPut(tx, configs.MONGO_TASK_COLLECTION, id.Hex(), task)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+func checklistItemIndex(task *models.TaskSchema, itemId primitive.ObjectID) int {
+	for i, item := range task.Checklist {
+		if item.ID == itemId {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *kvDbService) UnsetCustomField(ctx context.Context, key string, updatedAt time.Time) (int64, error) {
+	var changed int64
+	err := d.store.Update(func(tx KVTx) error {
+		var tasks []*models.TaskSchema
+		err := tx.ForEach(configs.MONGO_TASK_COLLECTION, func(id string, value []byte) error {
+			var task models.TaskSchema
+			if err := bson.Unmarshal(value, &task); err != nil {
+				return fmt.Errorf("failed to decode task %s: %v", id, err)
+			}
+			if _, found := task.CustomFields[key]; found && inWorkspace(ctx, task.WorkspaceID) {
+				tasks = append(tasks, &task)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			delete(task.CustomFields, key)
+			task.UpdatedAt = updatedAt
+			task.Version++
+			if err := kvPut(tx, configs.MONGO_TASK_COLLECTION, task.ID.Hex(), task); err != nil {
+				return err
+			}
+		}
+		changed = int64(len(tasks))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+func containsObjectId(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// function to load the live task a write applies to, checking the workspace and the version when it is not 0
+func kvGetTaskForWrite(ctx context.Context, tx KVTx, id primitive.ObjectID, version int64) (*models.TaskSchema, error) {
+	return kvGetTaskIn(ctx, tx, id, version, false)
+}
+
+// function to load the task of the trash a write applies to, like kvGetTaskForWrite
+func kvGetTrashedTaskForWrite(ctx context.Context, tx KVTx, id primitive.ObjectID, version int64) (*models.TaskSchema, error) {
+	return kvGetTaskIn(ctx, tx, id, version, true)
+}
+
+func kvGetTaskIn(ctx context.Context, tx KVTx, id primitive.ObjectID, version int64, trashed bool) (*models.TaskSchema, error) {
+	var task models.TaskSchema
+	found, err := kvGet(tx, configs.MONGO_TASK_COLLECTION, id.Hex(), &task)
+	if err != nil {
+		return nil, err
+	}
+	if !found || !inWorkspace(ctx, task.WorkspaceID) || (task.DeletedAt != nil) != trashed {
+		if trashed {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("task %s not found in the trash", id.Hex()))
+		}
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("task %s not found", id.Hex()))
+	}
+	if version > 0 && task.Version != version {
+		return nil, apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", id.Hex()))
+	}
+	return &task, nil
+}
+
+// function to check the task belongs to the workspace of the context, the Go-side mirror of workspaceFilter
+func inWorkspace(ctx context.Context, workspaceId string) bool {
+	if len(workspaceId) == 0 {
+		workspaceId = appauth.DefaultWorkspace
+	}
+	return workspaceId == appauth.GetWorkspace(ctx)
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// kvTemplateDbService implements TemplateDbService on a KVStore, scoped to the workspace of the context like kvDbService
+type kvTemplateDbService struct {
+	store KVStore
+}
+
+func NewKVTemplateDbService(store KVStore) TemplateDbService {
+	return &kvTemplateDbService{store: store}
+}
+
+func (d *kvTemplateDbService) GetTemplates(ctx context.Context) ([]*models.TemplateSchema, error) {
+	templates := []*models.TemplateSchema{}
+	err := d.store.View(func(tx KVTx) error {
+		return tx.ForEach(configs.MONGO_TEMPLATE_COLLECTION, func(key string, value []byte) error {
+			var template models.TemplateSchema
+			if err := bson.Unmarshal(value, &template); err != nil {
+				return fmt.Errorf("failed to decode template %s: %v", key, err)
+			}
+			if inWorkspace(ctx, template.WorkspaceID) {
+				templates = append(templates, &template)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch templates: %v", err)
+	}
+	// the keys are walked in the order of the ids, which breaks the ties
+	sort.SliceStable(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+func (d *kvTemplateDbService) GetTemplateById(ctx context.Context, templateId string) (*models.TemplateSchema, error) {
+	id, err := parseObjectId(templateId)
+	if err != nil {
+		return nil, err
+	}
+	var template *models.TemplateSchema
+	err = d.store.View(func(tx KVTx) error {
+		template, err = kvGetTemplate(ctx, tx, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+func (d *kvTemplateDbService) SaveTemplate(ctx context.Context, template *models.TemplateSchema) (string, error) {
+	if template.ID.IsZero() {
+		template.ID = primitive.NewObjectID()
+	}
+	template.WorkspaceID = appauth.GetWorkspace(ctx)
+	err := d.store.Update(func(tx KVTx) error {
+		return kvPut(tx, configs.MONGO_TEMPLATE_COLLECTION, template.ID.Hex(), template)
+	})
+	if err != nil {
+		return "", err
+	}
+	return template.ID.Hex(), nil
+}
+
+func (d *kvTemplateDbService) UpdateTemplate(ctx context.Context, template *models.TemplateSchema) error {
+	return d.store.Update(func(tx KVTx) error {
+		current, err := kvGetTemplate(ctx, tx, template.ID)
+		if err != nil {
+			return err
+		}
+		current.Name = template.Name
+		current.Description = template.Description
+		current.Task = template.Task
+		current.Variables = template.Variables
+		current.UpdatedAt = template.UpdatedAt
+		return kvPut(tx, configs.MONGO_TEMPLATE_COLLECTION, template.ID.Hex(), current)
+	})
+}
+
+func (d *kvTemplateDbService) DeleteTemplateById(ctx context.Context, templateId string) error {
+	id, err := parseObjectId(templateId)
+	if err != nil {
+		return err
+	}
+	return d.store.Update(func(tx KVTx) error {
+		if _, err := kvGetTemplate(ctx, tx, id); err != nil {
+			return err
+		}
+		return tx.Delete(configs.MONGO_TEMPLATE_COLLECTION, id.Hex())
+	})
+}
+
+// function to load the template, templates of other workspaces are reported as missing
+func kvGetTemplate(ctx context.Context, tx KVTx, id primitive.ObjectID) (*models.TemplateSchema, error) {
+	var template models.TemplateSchema
+	found, err := kvGet(tx, configs.MONGO_TEMPLATE_COLLECTION, id.Hex(), &template)
+	if err != nil {
+		return nil, err
+	}
+	if !found || !inWorkspace(ctx, template.WorkspaceID) {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("template %s not found", id.Hex()))
+	}
+	return &template, nil
+}
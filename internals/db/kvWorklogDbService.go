@@ -0,0 +1,245 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+	apimodels "TaskSvc/internals/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// kvWorklogDbService implements WorklogDbService on a KVStore, scoped to the workspace of the context like kvDbService
+type kvWorklogDbService struct {
+	store KVStore
+}
+
+func NewKVWorklogDbService(store KVStore) WorklogDbService {
+	return &kvWorklogDbService{store: store}
+}
+
+func (d *kvWorklogDbService) GetWorklogs(ctx context.Context, taskId string) ([]*models.WorklogSchema, error) {
+	var worklogs []*models.WorklogSchema
+	err := d.store.View(func(tx KVTx) error {
+		var err error
+		worklogs, err = kvWorklogs(ctx, tx, func(worklog *models.WorklogSchema) bool { return worklog.TaskID == taskId })
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch worklogs: %v", err)
+	}
+	sort.SliceStable(worklogs, func(i, j int) bool {
+		return compareSortPosition(worklogs[i].Started, worklogs[i].ID, worklogs[j].Started, worklogs[j].ID, false) < 0
+	})
+	return worklogs, nil
+}
+
+func (d *kvWorklogDbService) SaveWorklog(ctx context.Context, worklog *models.WorklogSchema) (string, error) {
+	if worklog.ID.IsZero() {
+		worklog.ID = primitive.NewObjectID()
+	}
+	worklog.WorkspaceID = appauth.GetWorkspace(ctx)
+	err := d.store.Update(func(tx KVTx) error {
+		return kvPut(tx, configs.MONGO_WORKLOG_COLLECTION, worklog.ID.Hex(), worklog)
+	})
+	if err != nil {
+		return "", err
+	}
+	return worklog.ID.Hex(), nil
+}
+
+func (d *kvWorklogDbService) DeleteWorklogById(ctx context.Context, taskId string, worklogId string) error {
+	id, err := parseObjectId(worklogId)
+	if err != nil {
+		return err
+	}
+	return d.store.Update(func(tx KVTx) error {
+		worklogs, err := kvWorklogs(ctx, tx, func(worklog *models.WorklogSchema) bool {
+			return worklog.ID == id && worklog.TaskID == taskId
+		})
+		if err != nil {
+			return err
+		}
+		if len(worklogs) == 0 {
+			return apperrors.NewNotFoundError(fmt.Sprintf("worklog %s not found", worklogId))
+		}
+		return tx.Delete(configs.MONGO_WORKLOG_COLLECTION, id.Hex())
+	})
+}
+
+func (d *kvWorklogDbService) DeleteWorklogs(ctx context.Context, taskId string) error {
+	return d.store.Update(func(tx KVTx) error {
+		worklogs, err := kvWorklogs(ctx, tx, func(worklog *models.WorklogSchema) bool { return worklog.TaskID == taskId })
+		if err != nil {
+			return err
+		}
+		for _, worklog := range worklogs {
+			if err := tx.Delete(configs.MONGO_WORKLOG_COLLECTION, worklog.ID.Hex()); err != nil {
+				return err
+			}
+		}
+		timers, err := kvTimers(ctx, tx, func(timer *models.TimerSchema) bool { return timer.TaskID == taskId })
+		if err != nil {
+			return err
+		}
+		for _, timer := range timers {
+			if err := tx.Delete(configs.MONGO_TIMER_COLLECTION, timer.ID.Hex()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *kvWorklogDbService) StartTimer(ctx context.Context, timer *models.TimerSchema) (string, error) {
+	if timer.ID.IsZero() {
+		timer.ID = primitive.NewObjectID()
+	}
+	timer.WorkspaceID = appauth.GetWorkspace(ctx)
+	err := d.store.Update(func(tx KVTx) error {
+		running, err := kvTimers(ctx, tx, func(existing *models.TimerSchema) bool {
+			return existing.Subject == timer.Subject && existing.TaskID == timer.TaskID
+		})
+		if err != nil {
+			return err
+		}
+		if len(running) > 0 {
+			return apperrors.NewConflictError(fmt.Sprintf("a timer is already running on task %s", timer.TaskID), nil)
+		}
+		return kvPut(tx, configs.MONGO_TIMER_COLLECTION, timer.ID.Hex(), timer)
+	})
+	if err != nil {
+		return "", err
+	}
+	return timer.ID.Hex(), nil
+}
+
+func (d *kvWorklogDbService) StopTimer(ctx context.Context, subject string, taskId string) (*models.TimerSchema, error) {
+	var stopped *models.TimerSchema
+	err := d.store.Update(func(tx KVTx) error {
+		running, err := kvTimers(ctx, tx, func(timer *models.TimerSchema) bool {
+			return timer.Subject == subject && timer.TaskID == taskId
+		})
+		if err != nil {
+			return err
+		}
+		if len(running) == 0 {
+			return apperrors.NewNotFoundError(fmt.Sprintf("no timer is running on task %s", taskId))
+		}
+		stopped = running[0]
+		return tx.Delete(configs.MONGO_TIMER_COLLECTION, stopped.ID.Hex())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stopped, nil
+}
+
+func (d *kvWorklogDbService) GetTimers(ctx context.Context, subject string) ([]*models.TimerSchema, error) {
+	var timers []*models.TimerSchema
+	err := d.store.View(func(tx KVTx) error {
+		var err error
+		timers, err = kvTimers(ctx, tx, func(timer *models.TimerSchema) bool { return timer.Subject == subject })
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch timers: %v", err)
+	}
+	sort.SliceStable(timers, func(i, j int) bool {
+		return compareSortPosition(timers[i].StartedAt, timers[i].ID, timers[j].StartedAt, timers[j].ID, false) < 0
+	})
+	return timers, nil
+}
+
+// function to group the worklogs like the aggregation of worklogDbService, rows are ordered by their dimensions
+func (d *kvWorklogDbService) GetTimeReport(ctx context.Context, query *apimodels.TimeReportQuery) ([]*models.TimeReportRow, error) {
+	var worklogs []*models.WorklogSchema
+	err := d.store.View(func(tx KVTx) error {
+		var err error
+		worklogs, err = kvWorklogs(ctx, tx, func(worklog *models.WorklogSchema) bool {
+			return inDateRange(worklog.Started, query.From, query.To) &&
+				(len(query.User) == 0 || worklog.Author == query.User) &&
+				(len(query.ProjectID) == 0 || worklog.ProjectID == query.ProjectID)
+		})
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build the time report: %v", err)
+	}
+
+	groups := map[models.TimeReportKey]*models.TimeReportRow{}
+	rows := []*models.TimeReportRow{}
+	for _, worklog := range worklogs {
+		var key models.TimeReportKey
+		for _, dimension := range query.GroupBy {
+			switch dimension {
+			case apimodels.TimeReportByUser:
+				key.User = worklog.Author
+			case apimodels.TimeReportByProject:
+				key.ProjectID = worklog.ProjectID
+			case apimodels.TimeReportByTask:
+				key.TaskID = worklog.TaskID
+			case apimodels.TimeReportByDay:
+				key.Day = worklog.Started.UTC().Format("2006-01-02")
+			}
+		}
+		row, ok := groups[key]
+		if !ok {
+			row = &models.TimeReportRow{Key: key}
+			groups[key] = row
+			rows = append(rows, row)
+		}
+		row.Duration += worklog.Duration
+		row.Worklogs++
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		a, b := rows[i].Key, rows[j].Key
+		if a.User != b.User {
+			return a.User < b.User
+		}
+		if a.ProjectID != b.ProjectID {
+			return a.ProjectID < b.ProjectID
+		}
+		if a.TaskID != b.TaskID {
+			return a.TaskID < b.TaskID
+		}
+		return a.Day < b.Day
+	})
+	return rows, nil
+}
+
+func kvWorklogs(ctx context.Context, tx KVTx, match func(*models.WorklogSchema) bool) ([]*models.WorklogSchema, error) {
+	worklogs := []*models.WorklogSchema{}
+	err := tx.ForEach(configs.MONGO_WORKLOG_COLLECTION, func(key string, value []byte) error {
+		var worklog models.WorklogSchema
+		if err := bson.Unmarshal(value, &worklog); err != nil {
+			return fmt.Errorf("failed to decode worklog %s: %v", key, err)
+		}
+		if inWorkspace(ctx, worklog.WorkspaceID) && match(&worklog) {
+			worklogs = append(worklogs, &worklog)
+		}
+		return nil
+	})
+	return worklogs, err
+}
+
+func kvTimers(ctx context.Context, tx KVTx, match func(*models.TimerSchema) bool) ([]*models.TimerSchema, error) {
+	timers := []*models.TimerSchema{}
+	err := tx.ForEach(configs.MONGO_TIMER_COLLECTION, func(key string, value []byte) error {
+		var timer models.TimerSchema
+		if err := bson.Unmarshal(value, &timer); err != nil {
+			return fmt.Errorf("failed to decode timer %s: %v", key, err)
+		}
+		if inWorkspace(ctx, timer.WorkspaceID) && match(&timer) {
+			timers = append(timers, &timer)
+		}
+		return nil
+	})
+	return timers, err
+}
@@ -0,0 +1,216 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// kvWorkspaceDbService implements WorkspaceDbService on a KVStore,
+// memberships are keyed by their id so the keys of a workspace are adjacent
+type kvWorkspaceDbService struct {
+	store KVStore
+}
+
+func NewKVWorkspaceDbService(store KVStore) WorkspaceDbService {
+	return &kvWorkspaceDbService{store: store}
+}
+
+func (d *kvWorkspaceDbService) GetWorkspaceById(ctx context.Context, workspaceId string) (*models.WorkspaceSchema, error) {
+	var workspace models.WorkspaceSchema
+	err := d.store.View(func(tx KVTx) error {
+		found, err := kvGet(tx, configs.MONGO_WORKSPACE_COLLECTION, workspaceId, &workspace)
+		if err == nil && !found {
+			return apperrors.NewNotFoundError(fmt.Sprintf("workspace %s not found", workspaceId))
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+func (d *kvWorkspaceDbService) GetWorkspaces(ctx context.Context, workspaceIds []string) ([]*models.WorkspaceSchema, error) {
+	wanted := map[string]bool{}
+	for _, id := range workspaceIds {
+		wanted[id] = true
+	}
+	workspaces := []*models.WorkspaceSchema{}
+	err := d.store.View(func(tx KVTx) error {
+		return tx.ForEach(configs.MONGO_WORKSPACE_COLLECTION, func(key string, value []byte) error {
+			if workspaceIds != nil && !wanted[key] {
+				return nil
+			}
+			var workspace models.WorkspaceSchema
+			if err := bson.Unmarshal(value, &workspace); err != nil {
+				return fmt.Errorf("failed to decode workspace %s: %v", key, err)
+			}
+			workspaces = append(workspaces, &workspace)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch workspaces: %v", err)
+	}
+	return workspaces, nil
+}
+
+func (d *kvWorkspaceDbService) SaveWorkspace(ctx context.Context, workspace *models.WorkspaceSchema) error {
+	return d.store.Update(func(tx KVTx) error {
+		if tx.Get(configs.MONGO_WORKSPACE_COLLECTION, workspace.ID) != nil {
+			return apperrors.NewConflictError(fmt.Sprintf("workspace %s already exists", workspace.ID), nil)
+		}
+		return kvPut(tx, configs.MONGO_WORKSPACE_COLLECTION, workspace.ID, workspace)
+	})
+}
+
+func (d *kvWorkspaceDbService) UpdateWorkspace(ctx context.Context, workspace *models.WorkspaceSchema) error {
+	return d.store.Update(func(tx KVTx) error {
+		var current models.WorkspaceSchema
+		found, err := kvGet(tx, configs.MONGO_WORKSPACE_COLLECTION, workspace.ID, &current)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return apperrors.NewNotFoundError(fmt.Sprintf("workspace %s not found", workspace.ID))
+		}
+		current.Name = workspace.Name
+		current.UpdatedAt = workspace.UpdatedAt
+		return kvPut(tx, configs.MONGO_WORKSPACE_COLLECTION, workspace.ID, &current)
+	})
+}
+
+func (d *kvWorkspaceDbService) DeleteWorkspaceById(ctx context.Context, workspaceId string) error {
+	return d.store.Update(func(tx KVTx) error {
+		if tx.Get(configs.MONGO_WORKSPACE_COLLECTION, workspaceId) == nil {
+			return apperrors.NewNotFoundError(fmt.Sprintf("workspace %s not found", workspaceId))
+		}
+		return tx.Delete(configs.MONGO_WORKSPACE_COLLECTION, workspaceId)
+	})
+}
+
+func (d *kvWorkspaceDbService) GetMembership(ctx context.Context, workspaceId string, subject string) (*models.MembershipSchema, error) {
+	var membership models.MembershipSchema
+	err := d.store.View(func(tx KVTx) error {
+		found, err := kvGet(tx, configs.MONGO_MEMBERSHIP_COLLECTION, membershipId(workspaceId, subject), &membership)
+		if err == nil && !found {
+			return apperrors.NewNotFoundError(fmt.Sprintf("%s is not a member of workspace %s", subject, workspaceId))
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (d *kvWorkspaceDbService) GetMemberships(ctx context.Context, workspaceId string) ([]*models.MembershipSchema, error) {
+	memberships, err := d.findMemberships(func(membership *models.MembershipSchema) bool {
+		return membership.WorkspaceID == workspaceId
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(memberships, func(i, j int) bool { return memberships[i].Subject < memberships[j].Subject })
+	return memberships, nil
+}
+
+func (d *kvWorkspaceDbService) GetMembershipsOf(ctx context.Context, subject string) ([]*models.MembershipSchema, error) {
+	memberships, err := d.findMemberships(func(membership *models.MembershipSchema) bool {
+		return membership.Subject == subject
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(memberships, func(i, j int) bool { return memberships[i].WorkspaceID < memberships[j].WorkspaceID })
+	return memberships, nil
+}
+
+func (d *kvWorkspaceDbService) findMemberships(matches func(membership *models.MembershipSchema) bool) ([]*models.MembershipSchema, error) {
+	memberships := []*models.MembershipSchema{}
+	err := d.store.View(func(tx KVTx) error {
+		return tx.ForEach(configs.MONGO_MEMBERSHIP_COLLECTION, func(key string, value []byte) error {
+			var membership models.MembershipSchema
+			if err := bson.Unmarshal(value, &membership); err != nil {
+				return fmt.Errorf("failed to decode membership %s: %v", key, err)
+			}
+			if matches(&membership) {
+				memberships = append(memberships, &membership)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch memberships: %v", err)
+	}
+	return memberships, nil
+}
+
+func (d *kvWorkspaceDbService) SaveMembership(ctx context.Context, membership *models.MembershipSchema) error {
+	membership.ID = membershipId(membership.WorkspaceID, membership.Subject)
+	return d.store.Update(func(tx KVTx) error {
+		if tx.Get(configs.MONGO_MEMBERSHIP_COLLECTION, membership.ID) != nil {
+			return apperrors.NewConflictError(fmt.Sprintf("%s is already a member of workspace %s", membership.Subject, membership.WorkspaceID), nil)
+		}
+		return kvPut(tx, configs.MONGO_MEMBERSHIP_COLLECTION, membership.ID, membership)
+	})
+}
+
+func (d *kvWorkspaceDbService) UpdateMembership(ctx context.Context, membership *models.MembershipSchema) error {
+	id := membershipId(membership.WorkspaceID, membership.Subject)
+	return d.store.Update(func(tx KVTx) error {
+		var current models.MembershipSchema
+		found, err := kvGet(tx, configs.MONGO_MEMBERSHIP_COLLECTION, id, &current)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return apperrors.NewNotFoundError(fmt.Sprintf("%s is not a member of workspace %s", membership.Subject, membership.WorkspaceID))
+		}
+		current.Role = membership.Role
+		current.UpdatedAt = membership.UpdatedAt
+		return kvPut(tx, configs.MONGO_MEMBERSHIP_COLLECTION, id, &current)
+	})
+}
+
+func (d *kvWorkspaceDbService) DeleteMembership(ctx context.Context, workspaceId string, subject string) error {
+	id := membershipId(workspaceId, subject)
+	return d.store.Update(func(tx KVTx) error {
+		if tx.Get(configs.MONGO_MEMBERSHIP_COLLECTION, id) == nil {
+			return apperrors.NewNotFoundError(fmt.Sprintf("%s is not a member of workspace %s", subject, workspaceId))
+		}
+		return tx.Delete(configs.MONGO_MEMBERSHIP_COLLECTION, id)
+	})
+}
+
+func (d *kvWorkspaceDbService) DeleteMemberships(ctx context.Context, workspaceId string) error {
+	return d.store.Update(func(tx KVTx) error {
+		var keys []string
+		err := tx.ForEach(configs.MONGO_MEMBERSHIP_COLLECTION, func(key string, value []byte) error {
+			var membership models.MembershipSchema
+			if err := bson.Unmarshal(value, &membership); err != nil {
+				return fmt.Errorf("failed to decode membership %s: %v", key, err)
+			}
+			if membership.WorkspaceID == workspaceId {
+				keys = append(keys, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if err := tx.Delete(configs.MONGO_MEMBERSHIP_COLLECTION, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"TaskSvc/commons/appdb"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ = Describe("Memory LeaseDbService", func() {
+	describeLeaseDbServiceConformance(func() (LeaseDbService, func()) {
+		return NewKVLeaseDbService(NewMemoryStore()), func() {}
+	})
+})
+
+var _ = Describe("Bolt LeaseDbService", func() {
+	describeLeaseDbServiceConformance(func() (LeaseDbService, func()) {
+		store, err := NewBoltStore(filepath.Join(GinkgoT().TempDir(), "tasks.db"))
+		Expect(err).NotTo(HaveOccurred())
+		return NewKVLeaseDbService(store), func() { Expect(store.Close()).To(Succeed()) }
+	})
+})
+
+var _ = Describe("Mongo LeaseDbService", func() {
+	if len(os.Getenv(mongoTestUri)) == 0 {
+		It("is skipped without "+mongoTestUri, func() {
+			Skip(mongoTestUri + " is not set")
+		})
+		return
+	}
+	describeLeaseDbServiceConformance(func() (LeaseDbService, func()) {
+		ctx := context.Background()
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv(mongoTestUri)))
+		Expect(err).NotTo(HaveOccurred())
+		database := fmt.Sprintf("task-svc-test-%s", primitive.NewObjectID().Hex())
+		return NewLeaseDbService(appdb.NewDatabaseClient(database, client)), func() {
+			Expect(client.Database(database).Drop(ctx)).To(Succeed())
+			Expect(client.Disconnect(ctx)).To(Succeed())
+		}
+	})
+})
+
+// function to register the behaviour every LeaseDbService implementation must share
+func describeLeaseDbServiceConformance(newService func() (LeaseDbService, func())) {
+	var (
+		ctx     context.Context
+		service LeaseDbService
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		var cleanup func()
+		service, cleanup = newService()
+		DeferCleanup(cleanup)
+	})
+
+	It("gives the lease to one holder until it expires", func() {
+		Expect(service.AcquireLease(ctx, "recurrence", "replica-1", now, now.Add(time.Minute))).To(BeTrue())
+		Expect(service.AcquireLease(ctx, "recurrence", "replica-2", now, now.Add(time.Minute))).To(BeFalse())
+		Expect(service.AcquireLease(ctx, "purge", "replica-2", now, now.Add(time.Minute))).To(BeTrue())
+
+		// the holder extends it
+		Expect(service.AcquireLease(ctx, "recurrence", "replica-1", now.Add(30*time.Second), now.Add(2*time.Minute))).To(BeTrue())
+		Expect(service.AcquireLease(ctx, "recurrence", "replica-2", now.Add(time.Minute), now.Add(2*time.Minute))).To(BeFalse())
+
+		Expect(service.AcquireLease(ctx, "recurrence", "replica-2", now.Add(2*time.Minute), now.Add(3*time.Minute))).To(BeTrue())
+		Expect(service.AcquireLease(ctx, "recurrence", "replica-1", now.Add(2*time.Minute), now.Add(3*time.Minute))).To(BeFalse())
+	})
+
+	It("frees the lease when its holder releases it", func() {
+		Expect(service.AcquireLease(ctx, "recurrence", "replica-1", now, now.Add(time.Minute))).To(BeTrue())
+		Expect(service.ReleaseLease(ctx, "recurrence", "replica-2")).To(Succeed())
+		Expect(service.AcquireLease(ctx, "recurrence", "replica-2", now, now.Add(time.Minute))).To(BeFalse())
+
+		Expect(service.ReleaseLease(ctx, "recurrence", "replica-1")).To(Succeed())
+		Expect(service.AcquireLease(ctx, "recurrence", "replica-2", now, now.Add(time.Minute))).To(BeTrue())
+		Expect(service.ReleaseLease(ctx, "unknown", "replica-2")).To(Succeed())
+	})
+}
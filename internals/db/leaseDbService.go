@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"TaskSvc/commons/appdb"
+	"TaskSvc/configs"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LeaseDbService hands out named leases so a background job runs on a single replica at a time,
+// unlike tasks they are not scoped to the workspace of the context
+type LeaseDbService interface {
+	// AcquireLease takes the lease for the holder until expiresAt, or extends it when the holder has it already.
+	// it returns false when another holder has the lease and it has not expired at now
+	AcquireLease(context context.Context, name string, holder string, now time.Time, expiresAt time.Time) (bool, error)
+	// ReleaseLease gives the lease up if the holder still has it, so another replica can take it at once
+	ReleaseLease(context context.Context, name string, holder string) error
+}
+
+type leaseDbService struct {
+	leases appdb.DatabaseCollection
+}
+
+func NewLeaseDbService(dbclient appdb.DatabaseClient) LeaseDbService {
+	return &leaseDbService{
+		leases: dbclient.Collection(configs.MONGO_LEASE_COLLECTION),
+	}
+}
+
+// function to take the lease with a single upsert, when another holder has a lease that has not expired the filter
+// matches nothing and the insert fails on the _id, which is how the lease is refused
+func (d *leaseDbService) AcquireLease(ctx context.Context, name string, holder string, now time.Time, expiresAt time.Time) (bool, error) {
+	filter := bson.M{"_id": name, "$or": bson.A{
+		bson.M{"holder": holder},
+		bson.M{"expiresAt": bson.M{"$lte": now}},
+	}}
+	update := bson.M{"$set": bson.M{"holder": holder, "expiresAt": expiresAt}}
+	_, err := d.leases.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (d *leaseDbService) ReleaseLease(ctx context.Context, name string, holder string) error {
+	_, err := d.leases.DeleteOne(ctx, bson.M{"_id": name, "holder": holder})
+	return err
+}
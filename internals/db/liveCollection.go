@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"TaskSvc/commons/appdb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// liveCollection leaves the tasks in the trash out of every read, write and delete, so the default queries of the
+// db service only see live tasks. the trash itself is reached through the collection it wraps
+type liveCollection struct {
+	collection appdb.DatabaseCollection
+}
+
+func newLiveCollection(collection appdb.DatabaseCollection) appdb.DatabaseCollection {
+	return &liveCollection{collection: collection}
+}
+
+// function to get the filter matching the live tasks, those without a deletedAt
+func liveFilter() bson.M {
+	return bson.M{"deletedAt": nil}
+}
+
+// function to get the filter matching the tasks in the trash
+func trashFilter() bson.M {
+	return bson.M{"deletedAt": bson.M{"$exists": true}}
+}
+
+// function to get the filter matching the live tasks of the workspace of the context
+func liveWorkspaceFilter(ctx context.Context) bson.M {
+	return bson.M{"$and": bson.A{workspaceFilter(ctx), liveFilter()}}
+}
+
+func liveScope(filter interface{}) bson.M {
+	if filter == nil {
+		return liveFilter()
+	}
+	return bson.M{"$and": bson.A{filter, liveFilter()}}
+}
+
+func (l *liveCollection) FindOne(ctx context.Context, filter interface{}, document interface{}) error {
+	return l.collection.FindOne(ctx, liveScope(filter), document)
+}
+
+func (l *liveCollection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, document interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	return l.collection.FindOneAndUpdate(ctx, liveScope(filter), update, document, opts...)
+}
+
+func (l *liveCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	return l.collection.InsertOne(ctx, document, opts...)
+}
+
+func (l *liveCollection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	return l.collection.InsertMany(ctx, documents, opts...)
+}
+
+func (l *liveCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return l.collection.UpdateOne(ctx, liveScope(filter), update, opts...)
+}
+
+func (l *liveCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return l.collection.UpdateMany(ctx, liveScope(filter), update, opts...)
+}
+
+func (l *liveCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return l.collection.CountDocuments(ctx, liveScope(filter), opts...)
+}
+
+func (l *liveCollection) Find(ctx context.Context, filter interface{}, options *options.FindOptions, response interface{}) error {
+	return l.collection.Find(ctx, liveScope(filter), options, response)
+}
+
+// function to run the pipeline on the live tasks only, a $match is put in front of it.
+// the stages looking up other tasks have to leave the trash out themselves
+func (l *liveCollection) Aggregate(ctx context.Context, pipeline interface{}, response interface{}) error {
+	stages, ok := pipeline.(bson.A)
+	if !ok {
+		return fmt.Errorf("unsupported pipeline type %T", pipeline)
+	}
+	return l.collection.Aggregate(ctx, append(bson.A{bson.M{"$match": liveFilter()}}, stages...), response)
+}
+
+func (l *liveCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	return l.collection.DeleteOne(ctx, liveScope(filter), opts...)
+}
+
+func (l *liveCollection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	return l.collection.DeleteMany(ctx, liveScope(filter), opts...)
+}
+
+func (l *liveCollection) Distinct(ctx context.Context, field string, filter interface{}) ([]interface{}, error) {
+	return l.collection.Distinct(ctx, field, liveScope(filter))
+}
+
+// function to refuse dropping the collection, it holds the trash too
+func (l *liveCollection) Drop(ctx context.Context) error {
+	return fmt.Errorf("the collection holds the trash too and cannot be dropped")
+}
+
+func (l *liveCollection) CreateIndexes(ctx context.Context, models []mongo.IndexModel) ([]string, error) {
+	return l.collection.CreateIndexes(ctx, models)
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"TaskSvc/commons/apperrors"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// localBlobStore keeps every blob in a file of the directory named after its key
+type localBlobStore struct {
+	dir string
+}
+
+// function to build the blob store on the local filesystem, the directory is created when it does not exist
+func NewLocalBlobStore(dir string) (BlobStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create the attachment directory %s: %v", dir, err)
+	}
+	return &localBlobStore{dir: dir}, nil
+}
+
+// function to write the content to a temporary file first, so a failed upload never leaves a partial blob under the key
+func (l *localBlobStore) Put(ctx context.Context, key string, content io.Reader) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(l.dir, "."+key+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to store blob %s: %w", key, err)
+	}
+	_, err = io.Copy(file, content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return fmt.Errorf("failed to store blob %s: %w", key, err)
+	}
+	return nil
+}
+
+func (l *localBlobStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("blob %s not found", key))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob %s: %v", key, err)
+	}
+	return file, nil
+}
+
+func (l *localBlobStore) Delete(ctx context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob %s: %v", key, err)
+	}
+	return nil
+}
+
+// function to get the file of the key, a key that is not a plain file name is rejected so it cannot escape the directory
+func (l *localBlobStore) path(key string) (string, error) {
+	if len(key) == 0 || key != filepath.Base(key) || key == "." || key == ".." || key[0] == '.' {
+		return "", apperrors.NewBadRequestError(fmt.Sprintf("invalid blob key: %s", key))
+	}
+	return filepath.Join(l.dir, key), nil
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+)
+
+type MockCommentDbService struct {
+	FakeGetComments       func(ctx context.Context, taskId string, query *models.CommentQuery) (*dbmodels.CommentPage, error)
+	FakeGetCommentById    func(ctx context.Context, taskId string, commentId string) (*dbmodels.CommentSchema, error)
+	FakeSaveComment       func(ctx context.Context, comment *dbmodels.CommentSchema) (string, error)
+	FakeUpdateComment     func(ctx context.Context, comment *dbmodels.CommentSchema, revision dbmodels.CommentRevision, version int64) (*dbmodels.CommentSchema, error)
+	FakeDeleteCommentById func(ctx context.Context, taskId string, commentId string) error
+	FakeDeleteComments    func(ctx context.Context, taskId string) error
+	FakeCountComments     func(ctx context.Context, taskId string) (int64, error)
+}
+
+func (m MockCommentDbService) GetComments(ctx context.Context, taskId string, query *models.CommentQuery) (*dbmodels.CommentPage, error) {
+	if m.FakeGetComments != nil {
+		return m.FakeGetComments(ctx, taskId, query)
+	}
+	return nil, fmt.Errorf("GetComments-error")
+}
+
+func (m MockCommentDbService) GetCommentById(ctx context.Context, taskId string, commentId string) (*dbmodels.CommentSchema, error) {
+	if m.FakeGetCommentById != nil {
+		return m.FakeGetCommentById(ctx, taskId, commentId)
+	}
+	return nil, fmt.Errorf("GetCommentById-error")
+}
+
+func (m MockCommentDbService) SaveComment(ctx context.Context, comment *dbmodels.CommentSchema) (string, error) {
+	if m.FakeSaveComment != nil {
+		return m.FakeSaveComment(ctx, comment)
+	}
+	return "", fmt.Errorf("SaveComment-error")
+}
+
+func (m MockCommentDbService) UpdateComment(ctx context.Context, comment *dbmodels.CommentSchema, revision dbmodels.CommentRevision, version int64) (*dbmodels.CommentSchema, error) {
+	if m.FakeUpdateComment != nil {
+		return m.FakeUpdateComment(ctx, comment, revision, version)
+	}
+	return nil, fmt.Errorf("UpdateComment-error")
+}
+
+func (m MockCommentDbService) DeleteCommentById(ctx context.Context, taskId string, commentId string) error {
+	if m.FakeDeleteCommentById != nil {
+		return m.FakeDeleteCommentById(ctx, taskId, commentId)
+	}
+	return fmt.Errorf("DeleteCommentById-error")
+}
+
+func (m MockCommentDbService) DeleteComments(ctx context.Context, taskId string) error {
+	if m.FakeDeleteComments != nil {
+		return m.FakeDeleteComments(ctx, taskId)
+	}
+	return fmt.Errorf("DeleteComments-error")
+}
+
+func (m MockCommentDbService) CountComments(ctx context.Context, taskId string) (int64, error) {
+	if m.FakeCountComments != nil {
+		return m.FakeCountComments(ctx, taskId)
+	}
+	return 0, fmt.Errorf("CountComments-error")
+}
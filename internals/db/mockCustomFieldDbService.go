@@ -0,0 +1,50 @@
+package db
+
+import (
+	dbmodels "TaskSvc/internals/db/models"
+	"context"
+	"fmt"
+)
+
+type MockCustomFieldDbService struct {
+	FakeGetCustomFields       func(ctx context.Context) ([]*dbmodels.CustomFieldSchema, error)
+	FakeGetCustomFieldById    func(ctx context.Context, fieldId string) (*dbmodels.CustomFieldSchema, error)
+	FakeSaveCustomField       func(ctx context.Context, field *dbmodels.CustomFieldSchema) (string, error)
+	FakeUpdateCustomField     func(ctx context.Context, field *dbmodels.CustomFieldSchema) error
+	FakeDeleteCustomFieldById func(ctx context.Context, fieldId string) error
+}
+
+func (m MockCustomFieldDbService) GetCustomFields(ctx context.Context) ([]*dbmodels.CustomFieldSchema, error) {
+	if m.FakeGetCustomFields != nil {
+		return m.FakeGetCustomFields(ctx)
+	}
+	return nil, fmt.Errorf("GetCustomFields-error")
+}
+
+func (m MockCustomFieldDbService) GetCustomFieldById(ctx context.Context, fieldId string) (*dbmodels.CustomFieldSchema, error) {
+	if m.FakeGetCustomFieldById != nil {
+		return m.FakeGetCustomFieldById(ctx, fieldId)
+	}
+	return nil, fmt.Errorf("GetCustomFieldById-error")
+}
+
+func (m MockCustomFieldDbService) SaveCustomField(ctx context.Context, field *dbmodels.CustomFieldSchema) (string, error) {
+	if m.FakeSaveCustomField != nil {
+		return m.FakeSaveCustomField(ctx, field)
+	}
+	return "", fmt.Errorf("SaveCustomField-error")
+}
+
+func (m MockCustomFieldDbService) UpdateCustomField(ctx context.Context, field *dbmodels.CustomFieldSchema) error {
+	if m.FakeUpdateCustomField != nil {
+		return m.FakeUpdateCustomField(ctx, field)
+	}
+	return fmt.Errorf("UpdateCustomField-error")
+}
+
+func (m MockCustomFieldDbService) DeleteCustomFieldById(ctx context.Context, fieldId string) error {
+	if m.FakeDeleteCustomFieldById != nil {
+		return m.FakeDeleteCustomFieldById(ctx, fieldId)
+	}
+	return fmt.Errorf("DeleteCustomFieldById-error")
+}
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type MockLeaseDbService struct {
+	FakeAcquireLease func(ctx context.Context, name string, holder string, now time.Time, expiresAt time.Time) (bool, error)
+	FakeReleaseLease func(ctx context.Context, name string, holder string) error
+}
+
+func (m MockLeaseDbService) AcquireLease(ctx context.Context, name string, holder string, now time.Time, expiresAt time.Time) (bool, error) {
+	if m.FakeAcquireLease != nil {
+		return m.FakeAcquireLease(ctx, name, holder, now, expiresAt)
+	}
+	return false, fmt.Errorf("AcquireLease-error")
+}
+
+func (m MockLeaseDbService) ReleaseLease(ctx context.Context, name string, holder string) error {
+	if m.FakeReleaseLease != nil {
+		return m.FakeReleaseLease(ctx, name, holder)
+	}
+	return fmt.Errorf("ReleaseLease-error")
+}
@@ -0,0 +1,58 @@
+package db
+
+import (
+	dbmodels "TaskSvc/internals/db/models"
+	"context"
+	"fmt"
+)
+
+type MockProjectDbService struct {
+	FakeGetProjectById    func(ctx context.Context, projectId string) (*dbmodels.ProjectSchema, error)
+	FakeGetProjects       func(ctx context.Context) ([]*dbmodels.ProjectSchema, error)
+	FakeSaveProject       func(ctx context.Context, project *dbmodels.ProjectSchema) (string, error)
+	FakeUpdateProject     func(ctx context.Context, project *dbmodels.ProjectSchema) error
+	FakeDeleteProjectById func(ctx context.Context, projectId string) error
+	FakeAllocateTaskKey   func(ctx context.Context, projectId string) (string, error)
+}
+
+func (m MockProjectDbService) GetProjectById(ctx context.Context, projectId string) (*dbmodels.ProjectSchema, error) {
+	if m.FakeGetProjectById != nil {
+		return m.FakeGetProjectById(ctx, projectId)
+	}
+	return nil, fmt.Errorf("GetProjectById-error")
+}
+
+func (m MockProjectDbService) GetProjects(ctx context.Context) ([]*dbmodels.ProjectSchema, error) {
+	if m.FakeGetProjects != nil {
+		return m.FakeGetProjects(ctx)
+	}
+	return nil, fmt.Errorf("GetProjects-error")
+}
+
+func (m MockProjectDbService) SaveProject(ctx context.Context, project *dbmodels.ProjectSchema) (string, error) {
+	if m.FakeSaveProject != nil {
+		return m.FakeSaveProject(ctx, project)
+	}
+	return "", fmt.Errorf("SaveProject-error")
+}
+
+func (m MockProjectDbService) UpdateProject(ctx context.Context, project *dbmodels.ProjectSchema) error {
+	if m.FakeUpdateProject != nil {
+		return m.FakeUpdateProject(ctx, project)
+	}
+	return fmt.Errorf("UpdateProject-error")
+}
+
+func (m MockProjectDbService) DeleteProjectById(ctx context.Context, projectId string) error {
+	if m.FakeDeleteProjectById != nil {
+		return m.FakeDeleteProjectById(ctx, projectId)
+	}
+	return fmt.Errorf("DeleteProjectById-error")
+}
+
+func (m MockProjectDbService) AllocateTaskKey(ctx context.Context, projectId string) (string, error) {
+	if m.FakeAllocateTaskKey != nil {
+		return m.FakeAllocateTaskKey(ctx, projectId)
+	}
+	return "", fmt.Errorf("AllocateTaskKey-error")
+}
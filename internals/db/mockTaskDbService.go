@@ -2,16 +2,38 @@ package db
 
 import (
 	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
 	"context"
 	"fmt"
+	"time"
 )
 
 type MockDbService struct {
 	FakeGetTaskById    func(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error)
 	FakeSaveTask       func(ctx context.Context, task *dbmodels.TaskSchema) (string, error)
-	FakeUpdateTask     func(ctx context.Context, task *dbmodels.TaskSchema, taskId string) error
-	FakeDeleteTaskById func(ctx context.Context, taskId string) error
-	FakeGetTasks       func(ctx context.Context) ([]*dbmodels.TaskSchema, error)
+	FakeSaveTasks      func(ctx context.Context, tasks []*dbmodels.TaskSchema) ([]string, error)
+	FakeUpdateTask     func(ctx context.Context, task *dbmodels.TaskSchema, taskId string, version int64) error
+	FakeDeleteTaskById func(ctx context.Context, taskId string, version int64) error
+	FakeGetTasks       func(ctx context.Context, query *models.TaskQuery) (*dbmodels.TaskPage, error)
+	FakePatchTask      func(ctx context.Context, taskId string, fields map[string]interface{}, version int64) (*dbmodels.TaskSchema, error)
+
+	FakeGetDependencyGraph func(ctx context.Context, taskId string) ([]*dbmodels.TaskSchema, error)
+	FakeRemoveBlocker      func(ctx context.Context, blockerId string) error
+	FakeGetLabels          func(ctx context.Context, prefix string, limit int64) ([]*dbmodels.LabelCount, error)
+	FakeRenameLabel        func(ctx context.Context, from string, to string, updatedAt time.Time) (int64, error)
+	FakeUnsetCustomField   func(ctx context.Context, key string, updatedAt time.Time) (int64, error)
+
+	FakeAddChecklistItem    func(ctx context.Context, taskId string, item dbmodels.ChecklistItemSchema, position int, version int64, updatedAt time.Time) (*dbmodels.TaskSchema, error)
+	FakeUpdateChecklistItem func(ctx context.Context, taskId string, itemId string, change dbmodels.ChecklistItemChange, version int64, updatedAt time.Time) (*dbmodels.TaskSchema, error)
+	FakeMoveChecklistItem   func(ctx context.Context, taskId string, itemId string, position int, version int64, updatedAt time.Time) (*dbmodels.TaskSchema, error)
+	FakeRemoveChecklistItem func(ctx context.Context, taskId string, itemId string, version int64, updatedAt time.Time) (*dbmodels.TaskSchema, error)
+
+	FakeGetRecurringTasks func(ctx context.Context, afterId string, limit int64) ([]*dbmodels.TaskSchema, error)
+
+	FakeTrashTask          func(ctx context.Context, taskId string, version int64, deletedAt time.Time, deletedBy string) error
+	FakeRestoreTask        func(ctx context.Context, taskId string, version int64) error
+	FakeGetDeletedTaskById func(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error)
+	FakeGetDeletedTasks    func(ctx context.Context, deletedBefore time.Time, afterId string, limit int64) ([]*dbmodels.TaskSchema, error)
 }
 
 func (m MockDbService) GetTaskById(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error) {
@@ -28,23 +50,135 @@ func (m MockDbService) SaveTask(ctx context.Context, task *dbmodels.TaskSchema)
 	return "", fmt.Errorf("SaveTask-error")
 }
 
-func (m MockDbService) UpdateTask(ctx context.Context, task *dbmodels.TaskSchema, taskId string) error {
+func (m MockDbService) SaveTasks(ctx context.Context, tasks []*dbmodels.TaskSchema) ([]string, error) {
+	if m.FakeSaveTasks != nil {
+		return m.FakeSaveTasks(ctx, tasks)
+	}
+	return nil, fmt.Errorf("SaveTasks-error")
+}
+
+func (m MockDbService) UpdateTask(ctx context.Context, task *dbmodels.TaskSchema, taskId string, version int64) error {
 	if m.FakeUpdateTask != nil {
-		return m.FakeUpdateTask(ctx, task, taskId)
+		return m.FakeUpdateTask(ctx, task, taskId, version)
 	}
 	return fmt.Errorf("UpdateTask-error")
 }
 
-func (m MockDbService) DeleteTaskById(ctx context.Context, taskId string) error {
+func (m MockDbService) DeleteTaskById(ctx context.Context, taskId string, version int64) error {
 	if m.FakeDeleteTaskById != nil {
-		return m.FakeDeleteTaskById(ctx, taskId)
+		return m.FakeDeleteTaskById(ctx, taskId, version)
 	}
 	return fmt.Errorf("DeleteTaskById-error")
 }
 
-func (m MockDbService) GetTasks(ctx context.Context) ([]*dbmodels.TaskSchema, error) {
+func (m MockDbService) GetTasks(ctx context.Context, query *models.TaskQuery) (*dbmodels.TaskPage, error) {
 	if m.FakeGetTasks != nil {
-		return m.FakeGetTasks(ctx)
+		return m.FakeGetTasks(ctx, query)
 	}
 	return nil, fmt.Errorf("GetTasks-error")
 }
+
+func (m MockDbService) PatchTask(ctx context.Context, taskId string, fields map[string]interface{}, version int64) (*dbmodels.TaskSchema, error) {
+	if m.FakePatchTask != nil {
+		return m.FakePatchTask(ctx, taskId, fields, version)
+	}
+	return nil, fmt.Errorf("PatchTask-error")
+}
+
+func (m MockDbService) GetDependencyGraph(ctx context.Context, taskId string) ([]*dbmodels.TaskSchema, error) {
+	if m.FakeGetDependencyGraph != nil {
+		return m.FakeGetDependencyGraph(ctx, taskId)
+	}
+	return nil, fmt.Errorf("GetDependencyGraph-error")
+}
+
+func (m MockDbService) RemoveBlocker(ctx context.Context, blockerId string) error {
+	if m.FakeRemoveBlocker != nil {
+		return m.FakeRemoveBlocker(ctx, blockerId)
+	}
+	return fmt.Errorf("RemoveBlocker-error")
+}
+
+func (m MockDbService) GetLabels(ctx context.Context, prefix string, limit int64) ([]*dbmodels.LabelCount, error) {
+	if m.FakeGetLabels != nil {
+		return m.FakeGetLabels(ctx, prefix, limit)
+	}
+	return nil, fmt.Errorf("GetLabels-error")
+}
+
+func (m MockDbService) RenameLabel(ctx context.Context, from string, to string, updatedAt time.Time) (int64, error) {
+	if m.FakeRenameLabel != nil {
+		return m.FakeRenameLabel(ctx, from, to, updatedAt)
+	}
+	return 0, fmt.Errorf("RenameLabel-error")
+}
+
+func (m MockDbService) UnsetCustomField(ctx context.Context, key string, updatedAt time.Time) (int64, error) {
+	if m.FakeUnsetCustomField != nil {
+		return m.FakeUnsetCustomField(ctx, key, updatedAt)
+	}
+	return 0, fmt.Errorf("UnsetCustomField-error")
+}
+
+func (m MockDbService) AddChecklistItem(ctx context.Context, taskId string, item dbmodels.ChecklistItemSchema, position int, version int64, updatedAt time.Time) (*dbmodels.TaskSchema, error) {
+	if m.FakeAddChecklistItem != nil {
+		return m.FakeAddChecklistItem(ctx, taskId, item, position, version, updatedAt)
+	}
+	return nil, fmt.Errorf("AddChecklistItem-error")
+}
+
+func (m MockDbService) UpdateChecklistItem(ctx context.Context, taskId string, itemId string, change dbmodels.ChecklistItemChange, version int64, updatedAt time.Time) (*dbmodels.TaskSchema, error) {
+	if m.FakeUpdateChecklistItem != nil {
+		return m.FakeUpdateChecklistItem(ctx, taskId, itemId, change, version, updatedAt)
+	}
+	return nil, fmt.Errorf("UpdateChecklistItem-error")
+}
+
+func (m MockDbService) MoveChecklistItem(ctx context.Context, taskId string, itemId string, position int, version int64, updatedAt time.Time) (*dbmodels.TaskSchema, error) {
+	if m.FakeMoveChecklistItem != nil {
+		return m.FakeMoveChecklistItem(ctx, taskId, itemId, position, version, updatedAt)
+	}
+	return nil, fmt.Errorf("MoveChecklistItem-error")
+}
+
+func (m MockDbService) RemoveChecklistItem(ctx context.Context, taskId string, itemId string, version int64, updatedAt time.Time) (*dbmodels.TaskSchema, error) {
+	if m.FakeRemoveChecklistItem != nil {
+		return m.FakeRemoveChecklistItem(ctx, taskId, itemId, version, updatedAt)
+	}
+	return nil, fmt.Errorf("RemoveChecklistItem-error")
+}
+
+func (m MockDbService) GetRecurringTasks(ctx context.Context, afterId string, limit int64) ([]*dbmodels.TaskSchema, error) {
+	if m.FakeGetRecurringTasks != nil {
+		return m.FakeGetRecurringTasks(ctx, afterId, limit)
+	}
+	return nil, fmt.Errorf("GetRecurringTasks-error")
+}
+
+func (m MockDbService) TrashTask(ctx context.Context, taskId string, version int64, deletedAt time.Time, deletedBy string) error {
+	if m.FakeTrashTask != nil {
+		return m.FakeTrashTask(ctx, taskId, version, deletedAt, deletedBy)
+	}
+	return fmt.Errorf("TrashTask-error")
+}
+
+func (m MockDbService) RestoreTask(ctx context.Context, taskId string, version int64) error {
+	if m.FakeRestoreTask != nil {
+		return m.FakeRestoreTask(ctx, taskId, version)
+	}
+	return fmt.Errorf("RestoreTask-error")
+}
+
+func (m MockDbService) GetDeletedTaskById(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error) {
+	if m.FakeGetDeletedTaskById != nil {
+		return m.FakeGetDeletedTaskById(ctx, taskId)
+	}
+	return nil, fmt.Errorf("GetDeletedTaskById-error")
+}
+
+func (m MockDbService) GetDeletedTasks(ctx context.Context, deletedBefore time.Time, afterId string, limit int64) ([]*dbmodels.TaskSchema, error) {
+	if m.FakeGetDeletedTasks != nil {
+		return m.FakeGetDeletedTasks(ctx, deletedBefore, afterId, limit)
+	}
+	return nil, fmt.Errorf("GetDeletedTasks-error")
+}
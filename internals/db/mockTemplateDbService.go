@@ -0,0 +1,50 @@
+package db
+
+import (
+	dbmodels "TaskSvc/internals/db/models"
+	"context"
+	"fmt"
+)
+
+type MockTemplateDbService struct {
+	FakeGetTemplates       func(ctx context.Context) ([]*dbmodels.TemplateSchema, error)
+	FakeGetTemplateById    func(ctx context.Context, templateId string) (*dbmodels.TemplateSchema, error)
+	FakeSaveTemplate       func(ctx context.Context, template *dbmodels.TemplateSchema) (string, error)
+	FakeUpdateTemplate     func(ctx context.Context, template *dbmodels.TemplateSchema) error
+	FakeDeleteTemplateById func(ctx context.Context, templateId string) error
+}
+
+func (m MockTemplateDbService) GetTemplates(ctx context.Context) ([]*dbmodels.TemplateSchema, error) {
+	if m.FakeGetTemplates != nil {
+		return m.FakeGetTemplates(ctx)
+	}
+	return nil, fmt.Errorf("GetTemplates-error")
+}
+
+func (m MockTemplateDbService) GetTemplateById(ctx context.Context, templateId string) (*dbmodels.TemplateSchema, error) {
+	if m.FakeGetTemplateById != nil {
+		return m.FakeGetTemplateById(ctx, templateId)
+	}
+	return nil, fmt.Errorf("GetTemplateById-error")
+}
+
+func (m MockTemplateDbService) SaveTemplate(ctx context.Context, template *dbmodels.TemplateSchema) (string, error) {
+	if m.FakeSaveTemplate != nil {
+		return m.FakeSaveTemplate(ctx, template)
+	}
+	return "", fmt.Errorf("SaveTemplate-error")
+}
+
+func (m MockTemplateDbService) UpdateTemplate(ctx context.Context, template *dbmodels.TemplateSchema) error {
+	if m.FakeUpdateTemplate != nil {
+		return m.FakeUpdateTemplate(ctx, template)
+	}
+	return fmt.Errorf("UpdateTemplate-error")
+}
+
+func (m MockTemplateDbService) DeleteTemplateById(ctx context.Context, templateId string) error {
+	if m.FakeDeleteTemplateById != nil {
+		return m.FakeDeleteTemplateById(ctx, templateId)
+	}
+	return fmt.Errorf("DeleteTemplateById-error")
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+)
+
+type MockWorklogDbService struct {
+	FakeGetWorklogs       func(ctx context.Context, taskId string) ([]*dbmodels.WorklogSchema, error)
+	FakeSaveWorklog       func(ctx context.Context, worklog *dbmodels.WorklogSchema) (string, error)
+	FakeDeleteWorklogById func(ctx context.Context, taskId string, worklogId string) error
+	FakeDeleteWorklogs    func(ctx context.Context, taskId string) error
+	FakeStartTimer        func(ctx context.Context, timer *dbmodels.TimerSchema) (string, error)
+	FakeStopTimer         func(ctx context.Context, subject string, taskId string) (*dbmodels.TimerSchema, error)
+	FakeGetTimers         func(ctx context.Context, subject string) ([]*dbmodels.TimerSchema, error)
+	FakeGetTimeReport     func(ctx context.Context, query *models.TimeReportQuery) ([]*dbmodels.TimeReportRow, error)
+}
+
+func (m MockWorklogDbService) GetWorklogs(ctx context.Context, taskId string) ([]*dbmodels.WorklogSchema, error) {
+	if m.FakeGetWorklogs != nil {
+		return m.FakeGetWorklogs(ctx, taskId)
+	}
+	return nil, fmt.Errorf("GetWorklogs-error")
+}
+
+func (m MockWorklogDbService) SaveWorklog(ctx context.Context, worklog *dbmodels.WorklogSchema) (string, error) {
+	if m.FakeSaveWorklog != nil {
+		return m.FakeSaveWorklog(ctx, worklog)
+	}
+	return "", fmt.Errorf("SaveWorklog-error")
+}
+
+func (m MockWorklogDbService) DeleteWorklogById(ctx context.Context, taskId string, worklogId string) error {
+	if m.FakeDeleteWorklogById != nil {
+		return m.FakeDeleteWorklogById(ctx, taskId, worklogId)
+	}
+	return fmt.Errorf("DeleteWorklogById-error")
+}
+
+func (m MockWorklogDbService) DeleteWorklogs(ctx context.Context, taskId string) error {
+	if m.FakeDeleteWorklogs != nil {
+		return m.FakeDeleteWorklogs(ctx, taskId)
+	}
+	return fmt.Errorf("DeleteWorklogs-error")
+}
+
+func (m MockWorklogDbService) StartTimer(ctx context.Context, timer *dbmodels.TimerSchema) (string, error) {
+	if m.FakeStartTimer != nil {
+		return m.FakeStartTimer(ctx, timer)
+	}
+	return "", fmt.Errorf("StartTimer-error")
+}
+
+func (m MockWorklogDbService) StopTimer(ctx context.Context, subject string, taskId string) (*dbmodels.TimerSchema, error) {
+	if m.FakeStopTimer != nil {
+		return m.FakeStopTimer(ctx, subject, taskId)
+	}
+	return nil, fmt.Errorf("StopTimer-error")
+}
+
+func (m MockWorklogDbService) GetTimers(ctx context.Context, subject string) ([]*dbmodels.TimerSchema, error) {
+	if m.FakeGetTimers != nil {
+		return m.FakeGetTimers(ctx, subject)
+	}
+	return nil, fmt.Errorf("GetTimers-error")
+}
+
+func (m MockWorklogDbService) GetTimeReport(ctx context.Context, query *models.TimeReportQuery) ([]*dbmodels.TimeReportRow, error) {
+	if m.FakeGetTimeReport != nil {
+		return m.FakeGetTimeReport(ctx, query)
+	}
+	return nil, fmt.Errorf("GetTimeReport-error")
+}
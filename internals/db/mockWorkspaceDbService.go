@@ -0,0 +1,106 @@
+package db
+
+import (
+	dbmodels "TaskSvc/internals/db/models"
+	"context"
+	"fmt"
+)
+
+type MockWorkspaceDbService struct {
+	FakeGetWorkspaceById    func(ctx context.Context, workspaceId string) (*dbmodels.WorkspaceSchema, error)
+	FakeGetWorkspaces       func(ctx context.Context, workspaceIds []string) ([]*dbmodels.WorkspaceSchema, error)
+	FakeSaveWorkspace       func(ctx context.Context, workspace *dbmodels.WorkspaceSchema) error
+	FakeUpdateWorkspace     func(ctx context.Context, workspace *dbmodels.WorkspaceSchema) error
+	FakeDeleteWorkspaceById func(ctx context.Context, workspaceId string) error
+	FakeGetMembership       func(ctx context.Context, workspaceId string, subject string) (*dbmodels.MembershipSchema, error)
+	FakeGetMemberships      func(ctx context.Context, workspaceId string) ([]*dbmodels.MembershipSchema, error)
+	FakeGetMembershipsOf    func(ctx context.Context, subject string) ([]*dbmodels.MembershipSchema, error)
+	FakeSaveMembership      func(ctx context.Context, membership *dbmodels.MembershipSchema) error
+	FakeUpdateMembership    func(ctx context.Context, membership *dbmodels.MembershipSchema) error
+	FakeDeleteMembership    func(ctx context.Context, workspaceId string, subject string) error
+	FakeDeleteMemberships   func(ctx context.Context, workspaceId string) error
+}
+
+func (m MockWorkspaceDbService) GetWorkspaceById(ctx context.Context, workspaceId string) (*dbmodels.WorkspaceSchema, error) {
+	if m.FakeGetWorkspaceById != nil {
+		return m.FakeGetWorkspaceById(ctx, workspaceId)
+	}
+	return nil, fmt.Errorf("GetWorkspaceById-error")
+}
+
+func (m MockWorkspaceDbService) GetWorkspaces(ctx context.Context, workspaceIds []string) ([]*dbmodels.WorkspaceSchema, error) {
+	if m.FakeGetWorkspaces != nil {
+		return m.FakeGetWorkspaces(ctx, workspaceIds)
+	}
+	return nil, fmt.Errorf("GetWorkspaces-error")
+}
+
+func (m MockWorkspaceDbService) SaveWorkspace(ctx context.Context, workspace *dbmodels.WorkspaceSchema) error {
+	if m.FakeSaveWorkspace != nil {
+		return m.FakeSaveWorkspace(ctx, workspace)
+	}
+	return fmt.Errorf("SaveWorkspace-error")
+}
+
+func (m MockWorkspaceDbService) UpdateWorkspace(ctx context.Context, workspace *dbmodels.WorkspaceSchema) error {
+	if m.FakeUpdateWorkspace != nil {
+		return m.FakeUpdateWorkspace(ctx, workspace)
+	}
+	return fmt.Errorf("UpdateWorkspace-error")
+}
+
+func (m MockWorkspaceDbService) DeleteWorkspaceById(ctx context.Context, workspaceId string) error {
+	if m.FakeDeleteWorkspaceById != nil {
+		return m.FakeDeleteWorkspaceById(ctx, workspaceId)
+	}
+	return fmt.Errorf("DeleteWorkspaceById-error")
+}
+
+func (m MockWorkspaceDbService) GetMembership(ctx context.Context, workspaceId string, subject string) (*dbmodels.MembershipSchema, error) {
+	if m.FakeGetMembership != nil {
+		return m.FakeGetMembership(ctx, workspaceId, subject)
+	}
+	return nil, fmt.Errorf("GetMembership-error")
+}
+
+func (m MockWorkspaceDbService) GetMemberships(ctx context.Context, workspaceId string) ([]*dbmodels.MembershipSchema, error) {
+	if m.FakeGetMemberships != nil {
+		return m.FakeGetMemberships(ctx, workspaceId)
+	}
+	return nil, fmt.Errorf("GetMemberships-error")
+}
+
+func (m MockWorkspaceDbService) GetMembershipsOf(ctx context.Context, subject string) ([]*dbmodels.MembershipSchema, error) {
+	if m.FakeGetMembershipsOf != nil {
+		return m.FakeGetMembershipsOf(ctx, subject)
+	}
+	return nil, fmt.Errorf("GetMembershipsOf-error")
+}
+
+func (m MockWorkspaceDbService) SaveMembership(ctx context.Context, membership *dbmodels.MembershipSchema) error {
+	if m.FakeSaveMembership != nil {
+		return m.FakeSaveMembership(ctx, membership)
+	}
+	return fmt.Errorf("SaveMembership-error")
+}
+
+func (m MockWorkspaceDbService) UpdateMembership(ctx context.Context, membership *dbmodels.MembershipSchema) error {
+	if m.FakeUpdateMembership != nil {
+		return m.FakeUpdateMembership(ctx, membership)
+	}
+	return fmt.Errorf("UpdateMembership-error")
+}
+
+func (m MockWorkspaceDbService) DeleteMembership(ctx context.Context, workspaceId string, subject string) error {
+	if m.FakeDeleteMembership != nil {
+		return m.FakeDeleteMembership(ctx, workspaceId, subject)
+	}
+	return fmt.Errorf("DeleteMembership-error")
+}
+
+func (m MockWorkspaceDbService) DeleteMemberships(ctx context.Context, workspaceId string) error {
+	if m.FakeDeleteMemberships != nil {
+		return m.FakeDeleteMemberships(ctx, workspaceId)
+	}
+	return fmt.Errorf("DeleteMemberships-error")
+}
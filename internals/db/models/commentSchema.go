@@ -0,0 +1,33 @@
+package dbmodels
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CommentSchema struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TaskID string             `json:"taskId" bson:"taskId"`
+	// Author is the token subject of the caller who wrote the comment, it never changes
+	Author string `json:"author" bson:"author"`
+	Body   string `json:"body" bson:"body"`
+	// History holds the previous bodies, the oldest first, one entry is appended on every edit
+	History     []CommentRevision `json:"history" bson:"history,omitempty"`
+	WorkspaceID string            `json:"workspaceId" bson:"workspaceId,omitempty"`
+	CreatedAt   time.Time         `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt" bson:"updatedAt"`
+	Version     int64             `json:"version" bson:"version"`
+}
+
+// CommentRevision is a body the comment had until EditedAt
+type CommentRevision struct {
+	Body     string    `json:"body" bson:"body"`
+	EditedAt time.Time `json:"editedAt" bson:"editedAt"`
+}
+
+type CommentPage struct {
+	Comments   []*CommentSchema
+	Total      int64
+	NextCursor string
+}
@@ -0,0 +1,23 @@
+package dbmodels
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CustomFieldSchema struct {
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	// Key names the value in the customFields of the tasks, it is unique in the workspace and never changes
+	Key      string `json:"key" bson:"key"`
+	Name     string `json:"name" bson:"name"`
+	Type     string `json:"type" bson:"type"`
+	Required bool   `json:"required" bson:"required"`
+	// Default is stored typed like the values of the tasks
+	Default     interface{} `json:"default" bson:"default,omitempty"`
+	Options     []string    `json:"options" bson:"options,omitempty"`
+	CreatedBy   string      `json:"createdBy" bson:"createdBy,omitempty"`
+	WorkspaceID string      `json:"workspaceId" bson:"workspaceId,omitempty"`
+	CreatedAt   time.Time   `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt" bson:"updatedAt"`
+}
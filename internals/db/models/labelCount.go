@@ -0,0 +1,7 @@
+package dbmodels
+
+// LabelCount is a label and the number of tasks having it, as grouped by the labels aggregation
+type LabelCount struct {
+	Name  string `json:"name" bson:"_id"`
+	Count int64  `json:"count" bson:"count"`
+}
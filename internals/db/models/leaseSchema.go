@@ -0,0 +1,10 @@
+package dbmodels
+
+import "time"
+
+// LeaseSchema is the lease of a background job, the job runs on the replica holding it until it expires
+type LeaseSchema struct {
+	Name      string    `json:"name" bson:"_id"`
+	Holder    string    `json:"holder" bson:"holder"`
+	ExpiresAt time.Time `json:"expiresAt" bson:"expiresAt"`
+}
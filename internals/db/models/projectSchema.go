@@ -0,0 +1,24 @@
+package dbmodels
+
+import (
+	"TaskSvc/internals/workflow"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ProjectSchema struct {
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	// Key prefixes the keys of the tasks of the project, it is unique in the workspace and never changes
+	Key         string `json:"key" bson:"key"`
+	Name        string `json:"name" bson:"name"`
+	Description string `json:"description" bson:"description"`
+	// Workflow replaces the workflow of the deployment for the tasks of the project when set
+	Workflow *workflow.Workflow `json:"workflow" bson:"workflow,omitempty"`
+	// TaskCounter is the number of the last task key allocated, it is only ever incremented
+	TaskCounter int64     `json:"taskCounter" bson:"taskCounter"`
+	CreatedBy   string    `json:"createdBy" bson:"createdBy"`
+	WorkspaceID string    `json:"workspaceId" bson:"workspaceId,omitempty"`
+	CreatedAt   time.Time `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt" bson:"updatedAt"`
+}
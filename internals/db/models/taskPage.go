@@ -0,0 +1,7 @@
+package dbmodels
+
+type TaskPage struct {
+	Tasks      []*TaskSchema
+	Total      int64
+	NextCursor string
+}
@@ -11,6 +11,75 @@ type TaskSchema struct {
 	Title       string             `json:"title" bson:"title"`
 	Description string             `json:"description" bson:"description"`
 	Status      string             `json:"status" bson:"status"`
-	CreatedAt   time.Time          `json:"createdAt" bson:"createdAt"`
-	UpdatedAt   time.Time          `json:"updatedAt" bson:"updatedAt"`
+	Priority    string             `json:"priority" bson:"priority,omitempty"`
+	// PriorityRank is the sortable rank of the priority, kept in sync with it on every write
+	PriorityRank int        `json:"priorityRank" bson:"priorityRank"`
+	StartDate    *time.Time `json:"startDate" bson:"startDate,omitempty"`
+	DueDate      *time.Time `json:"dueDate" bson:"dueDate,omitempty"`
+	// OriginalEstimate and RemainingEstimate are in seconds, every worklog adds to TimeSpent and
+	// takes its duration off the remaining estimate
+	OriginalEstimate  *int64 `json:"originalEstimate" bson:"originalEstimate,omitempty"`
+	RemainingEstimate *int64 `json:"remainingEstimate" bson:"remainingEstimate,omitempty"`
+	TimeSpent         int64  `json:"timeSpent" bson:"timeSpent,omitempty"`
+	// Recurrence is the canonical RRULE of a recurring task. the scheduler creates the next occurrence once the task
+	// is done or due, with RecurrenceOf set to this task, and records it in NextOccurrenceID so it is created once
+	Recurrence       string `json:"recurrence" bson:"recurrence,omitempty"`
+	RecurrenceOf     string `json:"recurrenceOf" bson:"recurrenceOf,omitempty"`
+	NextOccurrenceID string `json:"nextOccurrenceId" bson:"nextOccurrenceId,omitempty"`
+	// CreatedBy is the token subject of the creator, it never changes
+	CreatedBy string   `json:"createdBy" bson:"createdBy,omitempty"`
+	Reporter  string   `json:"reporter" bson:"reporter,omitempty"`
+	Assignees []string `json:"assignees" bson:"assignees,omitempty"`
+	// Labels are lower case, without duplicates, in the order they were added
+	Labels []string `json:"labels" bson:"labels,omitempty"`
+	// ProjectID is set on create only, Key is allocated from the task counter of the project
+	ProjectID string `json:"projectId" bson:"projectId,omitempty"`
+	Key       string `json:"key" bson:"key,omitempty"`
+	// ParentID is the task this one is a subtask of, empty for top level tasks
+	ParentID string `json:"parentId" bson:"parentId,omitempty"`
+	// BlockedBy holds the ids of the tasks blocking this one, kept as ObjectIDs so $graphLookup can join them on _id
+	BlockedBy []primitive.ObjectID `json:"blockedBy" bson:"blockedBy,omitempty"`
+	// Attachments is the metadata of the files attached to the task, the content is kept in the blob store
+	Attachments []AttachmentSchema `json:"attachments" bson:"attachments,omitempty"`
+	// Checklist is kept in order, its items are changed in place with the array operators so concurrent changes of
+	// different items do not overwrite each other
+	Checklist []ChecklistItemSchema `json:"checklist" bson:"checklist,omitempty"`
+	// CustomFields holds the values of the custom fields by their key, typed after the definition of the field,
+	// dates are stored as dates so they sort and filter as such
+	CustomFields map[string]interface{} `json:"customFields" bson:"customFields,omitempty"`
+	// DeletedAt and DeletedBy are set while the task is in the trash, every query but those of the trash leaves it out
+	DeletedAt *time.Time `json:"deletedAt" bson:"deletedAt,omitempty"`
+	DeletedBy string     `json:"deletedBy" bson:"deletedBy,omitempty"`
+	// WorkspaceID is set by the db layer from the request context, tasks without one belong to the default workspace
+	WorkspaceID string    `json:"workspaceId" bson:"workspaceId,omitempty"`
+	CreatedAt   time.Time `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt" bson:"updatedAt"`
+	Version     int64     `json:"version" bson:"version"`
+}
+
+// AttachmentSchema describes a file attached to a task, its content is stored under the hex form of the ID
+type AttachmentSchema struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id"`
+	Name        string             `json:"name" bson:"name"`
+	Size        int64              `json:"size" bson:"size"`
+	ContentType string             `json:"contentType" bson:"contentType"`
+	SHA256      string             `json:"sha256" bson:"sha256"`
+	UploadedBy  string             `json:"uploadedBy" bson:"uploadedBy,omitempty"`
+	UploadedAt  time.Time          `json:"uploadedAt" bson:"uploadedAt"`
+}
+
+type ChecklistItemSchema struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id"`
+	Text   string             `json:"text" bson:"text"`
+	Done   bool               `json:"done" bson:"done"`
+	DoneBy string             `json:"doneBy" bson:"doneBy,omitempty"`
+	DoneAt *time.Time         `json:"doneAt" bson:"doneAt,omitempty"`
+}
+
+// ChecklistItemChange holds the fields of an item to change, DoneBy and DoneAt are set with Done and removed when it is false
+type ChecklistItemChange struct {
+	Text   *string
+	Done   *bool
+	DoneBy string
+	DoneAt *time.Time
 }
@@ -0,0 +1,34 @@
+package dbmodels
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TemplateSchema struct {
+	ID          primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	Name        string              `json:"name" bson:"name"`
+	Description string              `json:"description" bson:"description,omitempty"`
+	Task        *TemplateTaskSchema `json:"task" bson:"task"`
+	// Variables are derived from the titles and descriptions whenever the template is written
+	Variables   []string  `json:"variables" bson:"variables,omitempty"`
+	CreatedBy   string    `json:"createdBy" bson:"createdBy,omitempty"`
+	WorkspaceID string    `json:"workspaceId" bson:"workspaceId,omitempty"`
+	CreatedAt   time.Time `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt" bson:"updatedAt"`
+}
+
+// TemplateTaskSchema is a task of a template, the subtasks are nested in it
+type TemplateTaskSchema struct {
+	Title            string                `json:"title" bson:"title"`
+	Description      string                `json:"description" bson:"description"`
+	Priority         string                `json:"priority" bson:"priority,omitempty"`
+	Assignees        []string              `json:"assignees" bson:"assignees,omitempty"`
+	Labels           []string              `json:"labels" bson:"labels,omitempty"`
+	Checklist        []string              `json:"checklist" bson:"checklist,omitempty"`
+	StartInDays      *int                  `json:"startInDays" bson:"startInDays,omitempty"`
+	DueInDays        *int                  `json:"dueInDays" bson:"dueInDays,omitempty"`
+	OriginalEstimate *int64                `json:"originalEstimate" bson:"originalEstimate,omitempty"`
+	Subtasks         []*TemplateTaskSchema `json:"subtasks" bson:"subtasks,omitempty"`
+}
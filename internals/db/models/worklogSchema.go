@@ -0,0 +1,45 @@
+package dbmodels
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WorklogSchema struct {
+	ID     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TaskID string             `json:"taskId" bson:"taskId"`
+	// ProjectID is copied from the task so the reports can group by project without reading the tasks
+	ProjectID string `json:"projectId" bson:"projectId,omitempty"`
+	// Author is the token subject of the caller who logged the work
+	Author  string    `json:"author" bson:"author"`
+	Started time.Time `json:"started" bson:"started"`
+	// Duration is in seconds
+	Duration    int64     `json:"duration" bson:"duration"`
+	Note        string    `json:"note" bson:"note,omitempty"`
+	WorkspaceID string    `json:"workspaceId" bson:"workspaceId,omitempty"`
+	CreatedAt   time.Time `json:"createdAt" bson:"createdAt"`
+}
+
+// TimerSchema is a running timer, a subject has at most one per task
+type TimerSchema struct {
+	ID          primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	TaskID      string             `json:"taskId" bson:"taskId"`
+	Subject     string             `json:"subject" bson:"subject"`
+	StartedAt   time.Time          `json:"startedAt" bson:"startedAt"`
+	WorkspaceID string             `json:"workspaceId" bson:"workspaceId,omitempty"`
+}
+
+// TimeReportKey holds the dimensions of a group of worklogs, the ones not grouped by are empty
+type TimeReportKey struct {
+	User      string `bson:"user,omitempty"`
+	ProjectID string `bson:"projectId,omitempty"`
+	TaskID    string `bson:"taskId,omitempty"`
+	Day       string `bson:"day,omitempty"`
+}
+
+type TimeReportRow struct {
+	Key      TimeReportKey `bson:"_id"`
+	Duration int64         `bson:"duration"`
+	Worklogs int64         `bson:"worklogs"`
+}
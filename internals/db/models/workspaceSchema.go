@@ -0,0 +1,22 @@
+package dbmodels
+
+import "time"
+
+type WorkspaceSchema struct {
+	ID        string    `json:"id" bson:"_id"`
+	Name      string    `json:"name" bson:"name"`
+	CreatedBy string    `json:"createdBy" bson:"createdBy"`
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+}
+
+// MembershipSchema grants the subject a role in the workspace,
+// the id is the workspace id and the subject so a subject has one membership per workspace
+type MembershipSchema struct {
+	ID          string    `json:"id" bson:"_id"`
+	WorkspaceID string    `json:"workspaceId" bson:"workspaceId"`
+	Subject     string    `json:"subject" bson:"subject"`
+	Role        string    `json:"role" bson:"role"`
+	CreatedAt   time.Time `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt" bson:"updatedAt"`
+}
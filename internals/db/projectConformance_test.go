@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	models "TaskSvc/internals/db/models"
+	"TaskSvc/internals/workflow"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Memory ProjectDbService", func() {
+	describeProjectDbServiceConformance(func() (ProjectDbService, func()) {
+		return NewKVProjectDbService(NewMemoryStore()), func() {}
+	})
+})
+
+var _ = Describe("Bolt ProjectDbService", func() {
+	describeProjectDbServiceConformance(func() (ProjectDbService, func()) {
+		store, err := NewBoltStore(filepath.Join(GinkgoT().TempDir(), "tasks.db"))
+		Expect(err).NotTo(HaveOccurred())
+		return NewKVProjectDbService(store), func() { Expect(store.Close()).To(Succeed()) }
+	})
+})
+
+// function to register the behaviour every ProjectDbService implementation must share
+func describeProjectDbServiceConformance(newService func() (ProjectDbService, func())) {
+	var (
+		ctx     context.Context
+		service ProjectDbService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var cleanup func()
+		service, cleanup = newService()
+		DeferCleanup(cleanup)
+	})
+
+	save := func(ctx context.Context, key string) string {
+		id, err := service.SaveProject(ctx, &models.ProjectSchema{Key: key, Name: key + " project"})
+		Expect(err).NotTo(HaveOccurred())
+		return id
+	}
+
+	It("stores, lists, updates and deletes a project", func() {
+		id := save(ctx, "OPS")
+		save(ctx, "DEV")
+
+		projects, err := service.GetProjects(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(projects).To(HaveLen(2))
+		Expect(projects[0].Key).To(Equal("DEV"))
+
+		project, err := service.GetProjectById(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		project.Name = "Operations"
+		project.Workflow = workflow.Default()
+		Expect(service.UpdateProject(ctx, project)).To(Succeed())
+
+		project, err = service.GetProjectById(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(project.Name).To(Equal("Operations"))
+		Expect(project.Workflow.Initial).To(Equal("New"))
+
+		Expect(service.DeleteProjectById(ctx, id)).To(Succeed())
+		_, err = service.GetProjectById(ctx, id)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+	})
+
+	It("keeps the key unique in the workspace", func() {
+		save(ctx, "OPS")
+		_, err := service.SaveProject(ctx, &models.ProjectSchema{Key: "OPS", Name: "Again"})
+		Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+
+		save(appauth.WithWorkspace(ctx, "team-a"), "OPS")
+	})
+
+	It("allocates consecutive task keys", func() {
+		id := save(ctx, "OPS")
+		var wg sync.WaitGroup
+		keys := make(chan string, 10)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				key, err := service.AllocateTaskKey(ctx, id)
+				Expect(err).NotTo(HaveOccurred())
+				keys <- key
+			}()
+		}
+		wg.Wait()
+		close(keys)
+		allocated := map[string]bool{}
+		for key := range keys {
+			allocated[key] = true
+		}
+		Expect(allocated).To(HaveLen(10))
+		Expect(allocated).To(HaveKey("OPS-1"))
+		Expect(allocated).To(HaveKey("OPS-10"))
+
+		project, err := service.GetProjectById(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(project.TaskCounter).To(Equal(int64(10)))
+	})
+
+	It("hides the projects of other workspaces", func() {
+		id := save(ctx, "OPS")
+		teamA := appauth.WithWorkspace(ctx, "team-a")
+
+		_, err := service.GetProjectById(teamA, id)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		_, err = service.AllocateTaskKey(teamA, id)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		projects, err := service.GetProjects(teamA)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(projects).To(BeEmpty())
+	})
+}
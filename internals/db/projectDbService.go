@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ProjectDbService stores the projects of the workspace of the context and allocates their task keys
+type ProjectDbService interface {
+	GetProjectById(context context.Context, projectId string) (*models.ProjectSchema, error)
+	GetProjects(context context.Context) ([]*models.ProjectSchema, error)
+	SaveProject(context context.Context, project *models.ProjectSchema) (string, error)
+	UpdateProject(context context.Context, project *models.ProjectSchema) error
+	DeleteProjectById(context context.Context, projectId string) error
+	AllocateTaskKey(context context.Context, projectId string) (string, error)
+}
+
+type projectDbService struct {
+	collection appdb.DatabaseCollection
+}
+
+// function to build the mongo project db service, like tasks the projects are scoped to the workspace of the context
+func NewProjectDbService(dbclient appdb.DatabaseClient) ProjectDbService {
+	return &projectDbService{
+		collection: newTenantCollection(dbclient.Collection(configs.MONGO_PROJECT_COLLECTION)),
+	}
+}
+
+// function to format the key of the numbered task of the project, e.g. OPS-123
+func formatTaskKey(projectKey string, number int64) string {
+	return fmt.Sprintf("%s-%d", projectKey, number)
+}
+
+func (d *projectDbService) GetProjectById(ctx context.Context, projectId string) (*models.ProjectSchema, error) {
+	id, err := parseObjectId(projectId)
+	if err != nil {
+		return nil, err
+	}
+	var project models.ProjectSchema
+	if err := d.collection.FindOne(ctx, bson.M{"_id": id}, &project); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("project %s not found", projectId))
+		}
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (d *projectDbService) GetProjects(ctx context.Context) ([]*models.ProjectSchema, error) {
+	projects := []*models.ProjectSchema{}
+	if err := d.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "key", Value: 1}}), &projects); err != nil {
+		return nil, fmt.Errorf("failed to fetch projects: %v", err)
+	}
+	return projects, nil
+}
+
+// function to save the project, the unique index on the workspace and the key rejects a key already in use
+func (d *projectDbService) SaveProject(ctx context.Context, project *models.ProjectSchema) (string, error) {
+	project.TaskCounter = 0
+	project.WorkspaceID = appauth.GetWorkspace(ctx)
+	result, err := d.collection.InsertOne(ctx, project)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", apperrors.NewConflictError(fmt.Sprintf("project key %s is already in use", project.Key), err)
+		}
+		return "", err
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (d *projectDbService) UpdateProject(ctx context.Context, project *models.ProjectSchema) error {
+	update := bson.M{"$set": bson.M{
+		"name":        project.Name,
+		"description": project.Description,
+		"workflow":    project.Workflow,
+		"updatedAt":   project.UpdatedAt,
+	}}
+	result, err := d.collection.UpdateOne(ctx, bson.M{"_id": project.ID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("project %s not found", project.ID.Hex()))
+	}
+	return nil
+}
+
+func (d *projectDbService) DeleteProjectById(ctx context.Context, projectId string) error {
+	id, err := parseObjectId(projectId)
+	if err != nil {
+		return err
+	}
+	result, err := d.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("project %s not found", projectId))
+	}
+	return nil
+}
+
+// function to allocate the next task key of the project, the counter is incremented atomically
+// so concurrent creates never get the same key
+func (d *projectDbService) AllocateTaskKey(ctx context.Context, projectId string) (string, error) {
+	id, err := parseObjectId(projectId)
+	if err != nil {
+		return "", err
+	}
+	var project models.ProjectSchema
+	update := bson.M{"$inc": bson.M{"taskCounter": 1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if err := d.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, update, &project, opts); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return "", apperrors.NewNotFoundError(fmt.Sprintf("project %s not found", projectId))
+		}
+		return "", err
+	}
+	return formatTaskKey(project.Key, project.TaskCounter), nil
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"TaskSvc/commons/appdb"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+	"TaskSvc/internals/workflow"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// function to rewrite the statuses stored in another spelling than the one of the workflow of the task,
+// e.g. done or DONE for Done, so the status filters can match the exact values on the indexes.
+// the tasks of a project with its own workflow follow it, the others the workflow of the deployment
+func ensureCanonicalStatuses(ctx context.Context, tasks appdb.DatabaseCollection, projects appdb.DatabaseCollection, taskWorkflow *workflow.Workflow) error {
+	var custom []*models.ProjectSchema
+	if err := projects.Find(ctx, bson.M{"workflow": bson.M{"$exists": true}}, nil, &custom); err != nil {
+		return fmt.Errorf("failed to read the project workflows: %v", err)
+	}
+	projectIds := []string{}
+	for _, project := range custom {
+		if project.Workflow == nil {
+			continue
+		}
+		projectIds = append(projectIds, project.ID.Hex())
+		if err := canonicalizeStatuses(ctx, tasks, bson.M{"projectId": project.ID.Hex()}, project.Workflow); err != nil {
+			return err
+		}
+	}
+	return canonicalizeStatuses(ctx, tasks, bson.M{"projectId": bson.M{"$nin": projectIds}}, taskWorkflow)
+}
+
+func canonicalizeStatuses(ctx context.Context, tasks appdb.DatabaseCollection, filter bson.M, taskWorkflow *workflow.Workflow) error {
+	for _, status := range taskWorkflow.Statuses {
+		misspelled := bson.M{"$regex": "^" + regexp.QuoteMeta(status) + "$", "$options": "i", "$ne": status}
+		_, err := tasks.UpdateMany(ctx,
+			bson.M{"$and": bson.A{filter, bson.M{"status": misspelled}}},
+			bson.M{"$set": bson.M{"status": status}})
+		if err != nil {
+			return fmt.Errorf("failed to rewrite the status %s: %v", status, err)
+		}
+	}
+	return nil
+}
+
+// function to rewrite the statuses stored in another spelling on a KVStore, like ensureCanonicalStatuses
+func kvEnsureCanonicalStatuses(store KVStore, taskWorkflow *workflow.Workflow) error {
+	return store.Update(func(tx KVTx) error {
+		workflows := map[string]*workflow.Workflow{}
+		err := tx.ForEach(configs.MONGO_PROJECT_COLLECTION, func(key string, value []byte) error {
+			var project models.ProjectSchema
+			if err := bson.Unmarshal(value, &project); err != nil {
+				return fmt.Errorf("failed to decode project %s: %v", key, err)
+			}
+			if project.Workflow != nil {
+				workflows[project.ID.Hex()] = project.Workflow
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// the tasks are written once the walk is over, a bolt bucket cannot change during ForEach
+		var misspelled []*models.TaskSchema
+		err = tx.ForEach(configs.MONGO_TASK_COLLECTION, func(key string, value []byte) error {
+			var task models.TaskSchema
+			if err := bson.Unmarshal(value, &task); err != nil {
+				return fmt.Errorf("failed to decode task %s: %v", key, err)
+			}
+			statuses, found := workflows[task.ProjectID]
+			if !found {
+				statuses = taskWorkflow
+			}
+			if canonical, ok := statuses.Canonical(task.Status); ok && canonical != task.Status {
+				task.Status = canonical
+				misspelled = append(misspelled, &task)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, task := range misspelled {
+			if err := kvPut(tx, configs.MONGO_TASK_COLLECTION, task.ID.Hex(), task); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
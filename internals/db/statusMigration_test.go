@@ -0,0 +1,37 @@
+package db
+
+import (
+	"context"
+
+	"TaskSvc/commons/appauth"
+	models "TaskSvc/internals/db/models"
+	"TaskSvc/internals/workflow"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EnsureIndexes", func() {
+	It("rewrites the statuses stored in another spelling than the one of the workflow of the task", func() {
+		store := NewMemoryStore()
+		ctx := appauth.WithWorkspace(context.Background(), "team-a")
+		projectId, err := NewKVProjectDbService(store).SaveProject(ctx, &models.ProjectSchema{Key: "OPS", Name: "Operations",
+			Workflow: &workflow.Workflow{Initial: "Open", Statuses: []string{"Open", "Closed"}, Done: []string{"Closed"}}})
+		Expect(err).NotTo(HaveOccurred())
+		tasks := NewKVDbService(store)
+		save := func(status string, projectId string) string {
+			id, err := tasks.SaveTask(ctx, &models.TaskSchema{Title: "Task", Status: status, ProjectID: projectId})
+			Expect(err).NotTo(HaveOccurred())
+			return id
+		}
+		done, closed, unknown, inProject := save("DONE", ""), save("closed", projectId), save("Pending", ""), save("done", projectId)
+
+		Expect(NewKVStorage(store).EnsureIndexes(ctx, workflow.Default())).To(Succeed())
+
+		for id, status := range map[string]string{done: "Done", closed: "Closed", unknown: "Pending", inProject: "done"} {
+			task, err := tasks.GetTaskById(ctx, id)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Status).To(Equal(status))
+		}
+	})
+})
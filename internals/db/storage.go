@@ -0,0 +1,158 @@
+package db
+
+import (
+	"TaskSvc/commons/appdb"
+	"TaskSvc/configs"
+	"TaskSvc/internals/workflow"
+	"context"
+	"fmt"
+)
+
+// Storage builds the db services on the configured backend,
+// either mongo through the DatabaseClient or an embedded KVStore, and the blob store of the attachments
+type Storage struct {
+	dbclient appdb.DatabaseClient
+	store    KVStore
+	blobs    BlobStore
+}
+
+func NewStorage(config *configs.ApplicationConfig) (*Storage, error) {
+	var storage *Storage
+	switch config.StorageBackend {
+	case configs.STORAGE_MONGO:
+		storage = &Storage{dbclient: config.DbClient}
+	case configs.STORAGE_MEMORY:
+		storage = NewKVStorage(NewMemoryStore())
+	case configs.STORAGE_BOLT:
+		store, err := NewBoltStore(config.BoltFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %v", config.BoltFile, err)
+		}
+		storage = NewKVStorage(store)
+	default:
+		return nil, fmt.Errorf("unknown storage backend: %s", config.StorageBackend)
+	}
+
+	var err error
+	switch config.BlobStore {
+	case configs.BLOB_STORE_GRIDFS:
+		if storage.dbclient == nil {
+			err = fmt.Errorf("the %s blob store requires the %s storage backend", configs.BLOB_STORE_GRIDFS, configs.STORAGE_MONGO)
+		} else {
+			storage.blobs, err = NewGridFSBlobStore(storage.dbclient)
+		}
+	case configs.BLOB_STORE_LOCAL:
+		storage.blobs, err = NewLocalBlobStore(config.AttachmentDir)
+	default:
+		err = fmt.Errorf("unknown blob store: %s", config.BlobStore)
+	}
+	if err != nil {
+		storage.Close(context.Background())
+		return nil, err
+	}
+	return storage, nil
+}
+
+func NewKVStorage(store KVStore) *Storage {
+	return &Storage{store: store}
+}
+
+func (s *Storage) Tasks() DbService {
+	if s.store != nil {
+		return NewKVDbService(s.store)
+	}
+	return NewDbService(s.dbclient)
+}
+
+func (s *Storage) Workspaces() WorkspaceDbService {
+	if s.store != nil {
+		return NewKVWorkspaceDbService(s.store)
+	}
+	return NewWorkspaceDbService(s.dbclient)
+}
+
+func (s *Storage) Projects() ProjectDbService {
+	if s.store != nil {
+		return NewKVProjectDbService(s.store)
+	}
+	return NewProjectDbService(s.dbclient)
+}
+
+func (s *Storage) Comments() CommentDbService {
+	if s.store != nil {
+		return NewKVCommentDbService(s.store)
+	}
+	return NewCommentDbService(s.dbclient)
+}
+
+func (s *Storage) CustomFields() CustomFieldDbService {
+	if s.store != nil {
+		return NewKVCustomFieldDbService(s.store)
+	}
+	return NewCustomFieldDbService(s.dbclient)
+}
+
+func (s *Storage) Worklogs() WorklogDbService {
+	if s.store != nil {
+		return NewKVWorklogDbService(s.store)
+	}
+	return NewWorklogDbService(s.dbclient)
+}
+
+func (s *Storage) Templates() TemplateDbService {
+	if s.store != nil {
+		return NewKVTemplateDbService(s.store)
+	}
+	return NewTemplateDbService(s.dbclient)
+}
+
+func (s *Storage) Leases() LeaseDbService {
+	if s.store != nil {
+		return NewKVLeaseDbService(s.store)
+	}
+	return NewLeaseDbService(s.dbclient)
+}
+
+// function to get the blob store of the attachments, nil for a storage built with NewKVStorage
+func (s *Storage) Blobs() BlobStore {
+	return s.blobs
+}
+
+// function to prepare the backend at startup, the kv backends filter in memory and need no indexes.
+// the statuses stored in another spelling than the one of the workflow are rewritten on every backend
+func (s *Storage) EnsureIndexes(ctx context.Context, taskWorkflow *workflow.Workflow) error {
+	if s.store != nil {
+		return kvEnsureCanonicalStatuses(s.store, taskWorkflow)
+	}
+	if err := ensureTaskIndexes(ctx, s.dbclient.Collection(configs.MONGO_TASK_COLLECTION)); err != nil {
+		return err
+	}
+	if err := ensureCanonicalStatuses(ctx, s.dbclient.Collection(configs.MONGO_TASK_COLLECTION),
+		s.dbclient.Collection(configs.MONGO_PROJECT_COLLECTION), taskWorkflow); err != nil {
+		return err
+	}
+	if err := ensureMembershipIndexes(ctx, s.dbclient.Collection(configs.MONGO_MEMBERSHIP_COLLECTION)); err != nil {
+		return err
+	}
+	if err := ensureProjectIndexes(ctx, s.dbclient.Collection(configs.MONGO_PROJECT_COLLECTION)); err != nil {
+		return err
+	}
+	if err := ensureCommentIndexes(ctx, s.dbclient.Collection(configs.MONGO_COMMENT_COLLECTION)); err != nil {
+		return err
+	}
+	if err := ensureCustomFieldIndexes(ctx, s.dbclient.Collection(configs.MONGO_CUSTOM_FIELD_COLLECTION)); err != nil {
+		return err
+	}
+	if err := ensureTemplateIndexes(ctx, s.dbclient.Collection(configs.MONGO_TEMPLATE_COLLECTION)); err != nil {
+		return err
+	}
+	return ensureWorklogIndexes(ctx, s.dbclient.Collection(configs.MONGO_WORKLOG_COLLECTION), s.dbclient.Collection(configs.MONGO_TIMER_COLLECTION))
+}
+
+func (s *Storage) Close(ctx context.Context) error {
+	if s.store != nil {
+		return s.store.Close()
+	}
+	s.dbclient.Disconnect(ctx)
+	return nil
+}
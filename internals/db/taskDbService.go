@@ -2,96 +2,676 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
+	"TaskSvc/commons/appauth"
 	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
 	"TaskSvc/configs"
 	models "TaskSvc/internals/db/models"
+	apimodels "TaskSvc/internals/models"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type dbService struct {
+	// collection only reaches the live tasks of the workspace, withTrash reaches the ones in its trash too
 	collection appdb.DatabaseCollection
+	withTrash  appdb.DatabaseCollection
+	// allTasks reaches the tasks of every workspace, it is only used by the background jobs
+	allTasks appdb.DatabaseCollection
 }
 
 type DbService interface {
 	GetTaskById(context context.Context, taskId string) (*models.TaskSchema, error)
 	SaveTask(context context.Context, task *models.TaskSchema) (string, error)
-	UpdateTask(context context.Context, task *models.TaskSchema, taskId string) error
-	DeleteTaskById(context context.Context, taskId string) error
-	GetTasks(context context.Context) ([]*models.TaskSchema, error)
+	// SaveTasks inserts the tasks in one operation, in order, and returns their ids. like SaveTask they keep the ids
+	// they have so they can refer to each other. when one of them cannot be inserted none of them is kept
+	SaveTasks(context context.Context, tasks []*models.TaskSchema) ([]string, error)
+	UpdateTask(context context.Context, task *models.TaskSchema, taskId string, version int64) error
+	// TrashTask moves the task to the trash, the other methods stop seeing it but those of the trash
+	TrashTask(context context.Context, taskId string, version int64, deletedAt time.Time, deletedBy string) error
+	// RestoreTask moves the task out of the trash
+	RestoreTask(context context.Context, taskId string, version int64) error
+	GetDeletedTaskById(context context.Context, taskId string) (*models.TaskSchema, error)
+	// DeleteTaskById removes the task for good, only a task in the trash can be removed
+	DeleteTaskById(context context.Context, taskId string, version int64) error
+	// GetTasks lists the live tasks, or the tasks in the trash when the query asks for them
+	GetTasks(context context.Context, query *apimodels.TaskQuery) (*models.TaskPage, error)
+	PatchTask(context context.Context, taskId string, fields map[string]interface{}, version int64) (*models.TaskSchema, error)
+	// GetDependencyGraph returns the task, every task blocking it and every task it blocks, transitively
+	GetDependencyGraph(context context.Context, taskId string) ([]*models.TaskSchema, error)
+	// RemoveBlocker unlinks the task from every task it blocks, once it has been deleted
+	RemoveBlocker(context context.Context, blockerId string) error
+	// GetLabels returns the labels starting with the prefix with the number of tasks having them, the most used first
+	GetLabels(context context.Context, prefix string, limit int64) ([]*models.LabelCount, error)
+	// RenameLabel replaces the label on every task having it, a task having both labels keeps the new one only.
+	// it returns the number of tasks changed
+	RenameLabel(context context.Context, from string, to string, updatedAt time.Time) (int64, error)
+	// UnsetCustomField removes the value of the custom field from every task having one, once its definition is deleted.
+	// it returns the number of tasks changed
+	UnsetCustomField(context context.Context, key string, updatedAt time.Time) (int64, error)
+	// AddChecklistItem inserts the item at the position of the checklist, or appends it when the position is negative
+	AddChecklistItem(context context.Context, taskId string, item models.ChecklistItemSchema, position int, version int64, updatedAt time.Time) (*models.TaskSchema, error)
+	UpdateChecklistItem(context context.Context, taskId string, itemId string, change models.ChecklistItemChange, version int64, updatedAt time.Time) (*models.TaskSchema, error)
+	// MoveChecklistItem moves the item to the position, a position past the end moves it last
+	MoveChecklistItem(context context.Context, taskId string, itemId string, position int, version int64, updatedAt time.Time) (*models.TaskSchema, error)
+	RemoveChecklistItem(context context.Context, taskId string, itemId string, version int64, updatedAt time.Time) (*models.TaskSchema, error)
+	// GetRecurringTasks returns the recurring tasks without a next occurrence yet, of every workspace unlike the other
+	// methods, in the order of their ids after afterId so they can be walked a page at a time
+	GetRecurringTasks(context context.Context, afterId string, limit int64) ([]*models.TaskSchema, error)
+	// GetDeletedTasks returns the tasks put in the trash before the time, of every workspace like GetRecurringTasks
+	// and in the same order
+	GetDeletedTasks(context context.Context, deletedBefore time.Time, afterId string, limit int64) ([]*models.TaskSchema, error)
 }
 
+// function to build the mongo db service, every query goes through a collection scoped to the workspace of the context
+// and, but for those of the trash, to the live tasks
 func NewDbService(dbclient appdb.DatabaseClient) DbService {
+	workspaceTasks := newTenantCollection(dbclient.Collection(configs.MONGO_TASK_COLLECTION))
 	return &dbService{
-		collection: dbclient.Collection(configs.MONGO_TASK_COLLECTION),
+		collection: newLiveCollection(workspaceTasks),
+		withTrash:  workspaceTasks,
+		allTasks:   dbclient.Collection(configs.MONGO_TASK_COLLECTION),
 	}
 }
 
 func (d *dbService) GetTaskById(ctx context.Context, taskId string) (*models.TaskSchema, error) {
 	var task models.TaskSchema
-	id, err := primitive.ObjectIDFromHex(taskId)
+	id, err := parseObjectId(taskId)
 	if err != nil {
-		return nil, fmt.Errorf("invalid taskId: %v", err)
+		return nil, err
 	}
 	err = d.collection.FindOne(ctx, bson.M{"_id": id}, &task)
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("task %s not found", taskId))
+		}
 		return nil, err
 	}
 	return &task, nil
 }
 
-func (d *dbService) GetTasks(ctx context.Context) ([]*models.TaskSchema, error) {
-	var tasks []*models.TaskSchema
-	err := d.collection.Find(ctx, bson.M{}, &options.FindOptions{}, &tasks)
+func (d *dbService) GetDeletedTaskById(ctx context.Context, taskId string) (*models.TaskSchema, error) {
+	var task models.TaskSchema
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return nil, err
+	}
+	err = d.withTrash.FindOne(ctx, trashedFilter(id, 0), &task)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("task %s not found in the trash", taskId))
+		}
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (d *dbService) GetTasks(ctx context.Context, query *apimodels.TaskQuery) (*models.TaskPage, error) {
+	filter := taskFilter(query)
+	collection := d.collection
+	if query.Deleted {
+		collection = d.withTrash
+		filter = bson.M{"$and": bson.A{filter, trashFilter()}}
+	}
+	total, err := collection.CountDocuments(ctx, filter)
 	if err != nil {
+		return nil, fmt.Errorf("failed to count tasks: %v", err)
+	}
+
+	direction := 1
+	if query.SortDesc {
+		direction = -1
+	}
+	if len(query.Cursor) > 0 {
+		cursor, err := decodeCursor(query.Cursor, query.SortBy, query.SortDesc)
+		if err != nil {
+			return nil, apperrors.NewBadRequestError(err.Error())
+		}
+		filter = bson.M{"$and": bson.A{filter, cursorFilter(taskSortField(query.SortBy), direction, cursor)}}
+	}
+
+	// fetch one extra task to know if there is a next page
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: taskSortField(query.SortBy), Value: direction}, {Key: "_id", Value: direction}}).
+		SetLimit(query.Limit + 1)
+	var tasks []*models.TaskSchema
+	if err := collection.Find(ctx, filter, findOptions, &tasks); err != nil {
 		return nil, fmt.Errorf("failed to fetch tasks: %v", err)
 	}
-	return tasks, nil
+	return newTaskPage(tasks, total, query)
 }
 
 func (d *dbService) SaveTask(ctx context.Context, task *models.TaskSchema) (string, error) {
+	task.Version = 1
+	task.WorkspaceID = appauth.GetWorkspace(ctx)
 	result, err := d.collection.InsertOne(ctx, task)
 	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", apperrors.NewConflictError("task already exists", err)
+		}
 		return "", err
 	}
 	taskID := result.InsertedID.(primitive.ObjectID).Hex()
 	return taskID, nil
 }
 
-func (d *dbService) UpdateTask(ctx context.Context, task *models.TaskSchema, taskId string) error {
-	id, err := primitive.ObjectIDFromHex(taskId)
+func (d *dbService) SaveTasks(ctx context.Context, tasks []*models.TaskSchema) ([]string, error) {
+	documents := make([]interface{}, len(tasks))
+	ids := make([]primitive.ObjectID, len(tasks))
+	taskIds := make([]string, len(tasks))
+	for i, task := range tasks {
+		if task.ID.IsZero() {
+			task.ID = primitive.NewObjectID()
+		}
+		task.Version = 1
+		task.WorkspaceID = appauth.GetWorkspace(ctx)
+		documents[i] = task
+		ids[i] = task.ID
+		taskIds[i] = task.ID.Hex()
+	}
+	if _, err := d.collection.InsertMany(ctx, documents); err != nil {
+		// the insert is ordered, it stops at the first task that fails and the ones before it are removed again
+		inserted := ids
+		var writeErr mongo.BulkWriteException
+		if errors.As(err, &writeErr) && len(writeErr.WriteErrors) > 0 {
+			inserted = ids[:writeErr.WriteErrors[0].Index]
+		}
+		if len(inserted) > 0 {
+			if _, deleteErr := d.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": inserted}}); deleteErr != nil {
+				return nil, fmt.Errorf("failed to save tasks: %v, and to remove the saved ones: %v", err, deleteErr)
+			}
+		}
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, apperrors.NewConflictError("task already exists", err)
+		}
+		return nil, err
+	}
+	return taskIds, nil
+}
+
+func (d *dbService) UpdateTask(ctx context.Context, task *models.TaskSchema, taskId string, version int64) error {
+	id, err := parseObjectId(taskId)
 	if err != nil {
-		return fmt.Errorf("invalid taskId: %v", err)
+		return err
 	}
 
 	update := bson.M{
-		"$set": bson.M{
-			"title":       task.Title,
-			"description": task.Description,
-			"status":      task.Status,
-			"updatedAt":   task.UpdatedAt,
-		},
+		"$set": taskUpdateFields(task),
+		"$inc": bson.M{"version": 1},
 	}
 
-	_, err = d.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	result, err := d.collection.UpdateOne(ctx, versionFilter(id, version), update)
 	if err != nil {
 		return err
 	}
+	if result.MatchedCount == 0 {
+		return d.missingTaskError(ctx, id, version)
+	}
 	return nil
 }
 
-func (d *dbService) DeleteTaskById(ctx context.Context, taskId string) error {
-	id, err := primitive.ObjectIDFromHex(taskId)
+// function to $set only the given fields and return the updated task
+func (d *dbService) PatchTask(ctx context.Context, taskId string, fields map[string]interface{}, version int64) (*models.TaskSchema, error) {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return nil, err
+	}
+
+	var task models.TaskSchema
+	update := bson.M{"$set": fields, "$inc": bson.M{"version": 1}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err = d.collection.FindOneAndUpdate(ctx, versionFilter(id, version), update, &task, opts)
 	if err != nil {
-		return fmt.Errorf("invalid taskId: %v", err)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, d.missingTaskError(ctx, id, version)
+		}
+		return nil, err
 	}
-	_, err = d.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return &task, nil
+}
+
+func (d *dbService) TrashTask(ctx context.Context, taskId string, version int64, deletedAt time.Time, deletedBy string) error {
+	id, err := parseObjectId(taskId)
 	if err != nil {
 		return err
 	}
+	update := bson.M{
+		"$set": bson.M{"deletedAt": deletedAt, "deletedBy": deletedBy},
+		"$inc": bson.M{"version": 1},
+	}
+	result, err := d.collection.UpdateOne(ctx, versionFilter(id, version), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return d.missingTaskError(ctx, id, version)
+	}
 	return nil
 }
+
+func (d *dbService) RestoreTask(ctx context.Context, taskId string, version int64) error {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return err
+	}
+	update := bson.M{
+		"$unset": bson.M{"deletedAt": "", "deletedBy": ""},
+		"$inc":   bson.M{"version": 1},
+	}
+	result, err := d.withTrash.UpdateOne(ctx, trashedFilter(id, version), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return d.missingTrashedTaskError(ctx, id, version)
+	}
+	return nil
+}
+
+func (d *dbService) DeleteTaskById(ctx context.Context, taskId string, version int64) error {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return err
+	}
+	result, err := d.withTrash.DeleteOne(ctx, trashedFilter(id, version))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return d.missingTrashedTaskError(ctx, id, version)
+	}
+	return nil
+}
+
+// function to build the filter for a write to a task of the trash, conditional on the version when it is not 0
+func trashedFilter(id primitive.ObjectID, version int64) bson.M {
+	filter := versionFilter(id, version)
+	for key, value := range trashFilter() {
+		filter[key] = value
+	}
+	return filter
+}
+
+// function to tell apart a task missing from the trash from a version mismatch
+func (d *dbService) missingTrashedTaskError(ctx context.Context, id primitive.ObjectID, version int64) error {
+	if version > 0 {
+		count, err := d.withTrash.CountDocuments(ctx, trashedFilter(id, 0))
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", id.Hex()))
+		}
+	}
+	return apperrors.NewNotFoundError(fmt.Sprintf("task %s not found in the trash", id.Hex()))
+}
+
+// dependencyGraph is the task with the tasks $graphLookup reached from it in both directions
+type dependencyGraph struct {
+	models.TaskSchema `bson:",inline"`
+	Blockers          []*models.TaskSchema `bson:"blockers"`
+	Dependents        []*models.TaskSchema `bson:"dependents"`
+}
+
+// function to walk the blockedBy links up to the blockers and down to the dependents with $graphLookup,
+// the lookups are restricted to the live tasks of the workspace since only the first stage is scoped by the collection
+func (d *dbService) GetDependencyGraph(ctx context.Context, taskId string) ([]*models.TaskSchema, error) {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return nil, err
+	}
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"_id": id}},
+		bson.M{"$graphLookup": bson.M{
+			"from":                    configs.MONGO_TASK_COLLECTION,
+			"startWith":               "$blockedBy",
+			"connectFromField":        "blockedBy",
+			"connectToField":          "_id",
+			"as":                      "blockers",
+			"restrictSearchWithMatch": liveWorkspaceFilter(ctx),
+		}},
+		bson.M{"$graphLookup": bson.M{
+			"from":                    configs.MONGO_TASK_COLLECTION,
+			"startWith":               "$_id",
+			"connectFromField":        "_id",
+			"connectToField":          "blockedBy",
+			"as":                      "dependents",
+			"restrictSearchWithMatch": liveWorkspaceFilter(ctx),
+		}},
+	}
+	var graphs []*dependencyGraph
+	if err := d.collection.Aggregate(ctx, pipeline, &graphs); err != nil {
+		return nil, fmt.Errorf("failed to fetch the dependency graph: %v", err)
+	}
+	if len(graphs) == 0 {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("task %s not found", taskId))
+	}
+
+	graph := graphs[0]
+	tasks := []*models.TaskSchema{&graph.TaskSchema}
+	seen := map[primitive.ObjectID]bool{graph.ID: true}
+	for _, task := range append(graph.Blockers, graph.Dependents...) {
+		if !seen[task.ID] {
+			seen[task.ID] = true
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, nil
+}
+
+func (d *dbService) RemoveBlocker(ctx context.Context, blockerId string) error {
+	id, err := parseObjectId(blockerId)
+	if err != nil {
+		return err
+	}
+	update := bson.M{"$pull": bson.M{"blockedBy": id}, "$inc": bson.M{"version": 1}}
+	// the tasks in the trash are unlinked too, they would be restored blocked by a task that is gone
+	if _, err := d.withTrash.UpdateMany(ctx, bson.M{"blockedBy": id}, update); err != nil {
+		return fmt.Errorf("failed to unlink task %s: %v", blockerId, err)
+	}
+	return nil
+}
+
+// function to list the labels of the workspace, Distinct finds the labels starting with the prefix on the labels index
+// and the aggregation counts the tasks having each of them
+func (d *dbService) GetLabels(ctx context.Context, prefix string, limit int64) ([]*models.LabelCount, error) {
+	filter := bson.M{"labels": bson.M{"$regex": "^" + regexp.QuoteMeta(prefix)}}
+	values, err := d.collection.Distinct(ctx, "labels", filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %v", err)
+	}
+	// the other labels of the matching tasks are returned too
+	names := bson.A{}
+	for _, value := range values {
+		if name, ok := value.(string); ok && strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	labels := []*models.LabelCount{}
+	if len(names) == 0 {
+		return labels, nil
+	}
+
+	pipeline := bson.A{
+		bson.M{"$match": bson.M{"labels": bson.M{"$in": names}}},
+		bson.M{"$unwind": "$labels"},
+		bson.M{"$match": bson.M{"labels": bson.M{"$in": names}}},
+		bson.M{"$group": bson.M{"_id": "$labels", "count": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": limit},
+	}
+	if err := d.collection.Aggregate(ctx, pipeline, &labels); err != nil {
+		return nil, fmt.Errorf("failed to count labels: %v", err)
+	}
+	return labels, nil
+}
+
+// function to rename the label in two UpdateMany, the tasks already having the new label drop the old one
+// and the others get it replaced in place. both are idempotent, a failure in between is fixed by running it again.
+// the tasks in the trash are renamed too so they come back with the current labels
+func (d *dbService) RenameLabel(ctx context.Context, from string, to string, updatedAt time.Time) (int64, error) {
+	merge := bson.M{"$pull": bson.M{"labels": from}, "$set": bson.M{"updatedAt": updatedAt}, "$inc": bson.M{"version": 1}}
+	merged, err := d.withTrash.UpdateMany(ctx, bson.M{"labels": bson.M{"$all": bson.A{from, to}}}, merge)
+	if err != nil {
+		return 0, fmt.Errorf("failed to merge label %s into %s: %v", from, to, err)
+	}
+
+	rename := bson.M{"$set": bson.M{"labels.$": to, "updatedAt": updatedAt}, "$inc": bson.M{"version": 1}}
+	renamed, err := d.withTrash.UpdateMany(ctx, bson.M{"labels": from}, rename)
+	if err != nil {
+		return 0, fmt.Errorf("failed to rename label %s to %s: %v", from, to, err)
+	}
+	return merged.ModifiedCount + renamed.ModifiedCount, nil
+}
+
+// function to remove the value from the tasks, those in the trash included
+func (d *dbService) UnsetCustomField(ctx context.Context, key string, updatedAt time.Time) (int64, error) {
+	path := "customFields." + key
+	update := bson.M{"$unset": bson.M{path: ""}, "$set": bson.M{"updatedAt": updatedAt}, "$inc": bson.M{"version": 1}}
+	result, err := d.withTrash.UpdateMany(ctx, bson.M{path: bson.M{"$exists": true}}, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to remove custom field %s: %v", key, err)
+	}
+	return result.ModifiedCount, nil
+}
+
+// function to $push the item, the filter refuses a checklist that is already full
+func (d *dbService) AddChecklistItem(ctx context.Context, taskId string, item models.ChecklistItemSchema, position int, version int64, updatedAt time.Time) (*models.TaskSchema, error) {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return nil, err
+	}
+	push := bson.M{"$each": bson.A{item}}
+	if position >= 0 {
+		push["$position"] = position
+	}
+	filter := versionFilter(id, version)
+	filter[fmt.Sprintf("checklist.%d", apimodels.MaxChecklistItems-1)] = bson.M{"$exists": false}
+	update := bson.M{"$push": bson.M{"checklist": push}, "$set": bson.M{"updatedAt": updatedAt}, "$inc": bson.M{"version": 1}}
+	return d.updateChecklist(ctx, id, primitive.NilObjectID, filter, update, version)
+}
+
+// function to $set the fields of the item through the positional operator, the other items are left untouched
+func (d *dbService) UpdateChecklistItem(ctx context.Context, taskId string, itemId string, change models.ChecklistItemChange, version int64, updatedAt time.Time) (*models.TaskSchema, error) {
+	id, itemObjectId, filter, err := checklistItemFilter(taskId, itemId, version)
+	if err != nil {
+		return nil, err
+	}
+	set := bson.M{"updatedAt": updatedAt}
+	update := bson.M{"$set": set, "$inc": bson.M{"version": 1}}
+	if change.Text != nil {
+		set["checklist.$.text"] = *change.Text
+	}
+	if change.Done != nil {
+		set["checklist.$.done"] = *change.Done
+		if *change.Done {
+			set["checklist.$.doneBy"] = change.DoneBy
+			set["checklist.$.doneAt"] = change.DoneAt
+		} else {
+			update["$unset"] = bson.M{"checklist.$.doneBy": "", "checklist.$.doneAt": ""}
+		}
+	}
+	return d.updateChecklist(ctx, id, itemObjectId, filter, update, version)
+}
+
+// function to move the item with a pipeline update, the checklist is rebuilt from the other items around it
+// within the single write so an item added or changed at the same time is not lost
+func (d *dbService) MoveChecklistItem(ctx context.Context, taskId string, itemId string, position int, version int64, updatedAt time.Time) (*models.TaskSchema, error) {
+	id, itemObjectId, filter, err := checklistItemFilter(taskId, itemId, version)
+	if err != nil {
+		return nil, err
+	}
+	item := bson.M{"$filter": bson.M{"input": "$checklist", "cond": bson.M{"$eq": bson.A{"$$this._id", itemObjectId}}}}
+	others := bson.M{"$filter": bson.M{"input": "$checklist", "cond": bson.M{"$ne": bson.A{"$$this._id", itemObjectId}}}}
+	// $slice takes a positive count, the head is empty when the item moves first
+	var head interface{} = bson.A{}
+	if position > 0 {
+		head = bson.M{"$slice": bson.A{"$$others", position}}
+	}
+	tail := bson.M{"$slice": bson.A{"$$others", position, bson.M{"$max": bson.A{1, bson.M{"$size": "$$others"}}}}}
+	checklist := bson.M{"$let": bson.M{
+		"vars": bson.M{"others": others},
+		"in":   bson.M{"$concatArrays": bson.A{head, item, tail}},
+	}}
+	update := bson.A{bson.M{"$set": bson.M{
+		"checklist": checklist,
+		"updatedAt": updatedAt,
+		"version":   bson.M{"$add": bson.A{"$version", 1}},
+	}}}
+	return d.updateChecklist(ctx, id, itemObjectId, filter, update, version)
+}
+
+func (d *dbService) RemoveChecklistItem(ctx context.Context, taskId string, itemId string, version int64, updatedAt time.Time) (*models.TaskSchema, error) {
+	id, itemObjectId, filter, err := checklistItemFilter(taskId, itemId, version)
+	if err != nil {
+		return nil, err
+	}
+	update := bson.M{
+		"$pull": bson.M{"checklist": bson.M{"_id": itemObjectId}},
+		"$set":  bson.M{"updatedAt": updatedAt},
+		"$inc":  bson.M{"version": 1},
+	}
+	return d.updateChecklist(ctx, id, itemObjectId, filter, update, version)
+}
+
+// function to build the filter of a write to an item, it only matches while the task has the item
+func checklistItemFilter(taskId string, itemId string, version int64) (primitive.ObjectID, primitive.ObjectID, bson.M, error) {
+	id, itemObjectId, err := parseChecklistItemIds(taskId, itemId)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, nil, err
+	}
+	filter := versionFilter(id, version)
+	filter["checklist._id"] = itemObjectId
+	return id, itemObjectId, filter, nil
+}
+
+// function to write the checklist and return the updated task, a write that matched nothing is explained
+// by reading the task again
+func (d *dbService) updateChecklist(ctx context.Context, id primitive.ObjectID, itemId primitive.ObjectID, filter bson.M, update interface{}, version int64) (*models.TaskSchema, error) {
+	var task models.TaskSchema
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := d.collection.FindOneAndUpdate(ctx, filter, update, &task, opts)
+	if err == nil {
+		return &task, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if err := d.collection.FindOne(ctx, bson.M{"_id": id}, &task); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("task %s not found", id.Hex()))
+		}
+		return nil, err
+	}
+	if version > 0 && task.Version != version {
+		return nil, apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", id.Hex()))
+	}
+	return nil, checklistError(&task, itemId)
+}
+
+// function to get the error of a checklist write that the task refused, the item is missing or, when adding one
+// with a nil item id, the checklist is full
+func checklistError(task *models.TaskSchema, itemId primitive.ObjectID) error {
+	if !itemId.IsZero() {
+		return apperrors.NewNotFoundError(fmt.Sprintf("checklist item %s not found on task %s", itemId.Hex(), task.ID.Hex()))
+	}
+	return apperrors.NewValidationError(fmt.Sprintf("A checklist cannot have more than %d items", apimodels.MaxChecklistItems),
+		map[string]interface{}{"field": "checklist"})
+}
+
+func (d *dbService) GetRecurringTasks(ctx context.Context, afterId string, limit int64) ([]*models.TaskSchema, error) {
+	filter := recurringTaskFilter()
+	if len(afterId) > 0 {
+		id, err := parseObjectId(afterId)
+		if err != nil {
+			return nil, err
+		}
+		filter["_id"] = bson.M{"$gt": id}
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	var tasks []*models.TaskSchema
+	if err := d.allTasks.Find(ctx, filter, findOptions, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to fetch recurring tasks: %v", err)
+	}
+	return tasks, nil
+}
+
+// function to get the filter matching the tasks the scheduler has to look at, the latest occurrence of every series
+// that is not in the trash
+func recurringTaskFilter() bson.M {
+	return bson.M{"recurrence": bson.M{"$gt": ""}, "nextOccurrenceId": nil, "deletedAt": nil}
+}
+
+func (d *dbService) GetDeletedTasks(ctx context.Context, deletedBefore time.Time, afterId string, limit int64) ([]*models.TaskSchema, error) {
+	filter := bson.M{"deletedAt": bson.M{"$lt": deletedBefore}}
+	if len(afterId) > 0 {
+		id, err := parseObjectId(afterId)
+		if err != nil {
+			return nil, err
+		}
+		filter["_id"] = bson.M{"$gt": id}
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit)
+	var tasks []*models.TaskSchema
+	if err := d.allTasks.Find(ctx, filter, findOptions, &tasks); err != nil {
+		return nil, fmt.Errorf("failed to fetch deleted tasks: %v", err)
+	}
+	return tasks, nil
+}
+
+// function to get the fields a full update of the task replaces, the custom fields only when they are set
+func taskUpdateFields(task *models.TaskSchema) bson.M {
+	fields := bson.M{
+		"title":             task.Title,
+		"description":       task.Description,
+		"status":            task.Status,
+		"priority":          task.Priority,
+		"priorityRank":      task.PriorityRank,
+		"startDate":         task.StartDate,
+		"dueDate":           task.DueDate,
+		"originalEstimate":  task.OriginalEstimate,
+		"remainingEstimate": task.RemainingEstimate,
+		"recurrence":        task.Recurrence,
+		"reporter":          task.Reporter,
+		"assignees":         task.Assignees,
+		"labels":            task.Labels,
+		"parentId":          task.ParentID,
+		"updatedAt":         task.UpdatedAt,
+	}
+	if task.CustomFields != nil {
+		fields["customFields"] = task.CustomFields
+	}
+	return fields
+}
+
+// function to build the filter for a write, conditional on the version when it is not 0
+func versionFilter(id primitive.ObjectID, version int64) bson.M {
+	filter := bson.M{"_id": id}
+	if version > 0 {
+		filter["version"] = version
+	}
+	return filter
+}
+
+// function to tell apart a missing task from a version mismatch after a write matched nothing
+func (d *dbService) missingTaskError(ctx context.Context, id primitive.ObjectID, version int64) error {
+	if version > 0 {
+		count, err := d.collection.CountDocuments(ctx, bson.M{"_id": id})
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", id.Hex()))
+		}
+	}
+	return apperrors.NewNotFoundError(fmt.Sprintf("task %s not found", id.Hex()))
+}
+
+func parseObjectId(id string) (primitive.ObjectID, error) {
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, apperrors.NewInvalidIdError(id, err)
+	}
+	return objectId, nil
+}
+
+func parseChecklistItemIds(taskId string, itemId string) (primitive.ObjectID, primitive.ObjectID, error) {
+	id, err := parseObjectId(taskId)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, err
+	}
+	itemObjectId, err := parseObjectId(itemId)
+	if err != nil {
+		return primitive.NilObjectID, primitive.NilObjectID, err
+	}
+	return id, itemObjectId, nil
+}
@@ -0,0 +1,287 @@
+package db
+
+import (
+	models "TaskSvc/internals/db/models"
+	apimodels "TaskSvc/internals/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// function to build the mongo filter for the query, without the cursor position
+func taskFilter(query *apimodels.TaskQuery) bson.M {
+	filter := bson.M{}
+	status := bson.M{}
+	if len(query.Status) > 0 {
+		status["$in"] = query.Status
+	}
+	if query.Overdue && len(query.DoneStatuses) > 0 {
+		status["$nin"] = query.DoneStatuses
+	}
+	if len(status) > 0 {
+		filter["status"] = status
+	}
+	if createdAt := dateRange(query.CreatedAfter, query.CreatedBefore); createdAt != nil {
+		filter["createdAt"] = createdAt
+	}
+	if updatedAt := dateRange(query.UpdatedAfter, query.UpdatedBefore); updatedAt != nil {
+		filter["updatedAt"] = updatedAt
+	}
+	if dueDate := dateRange(query.DueAfter, dueBefore(query)); dueDate != nil {
+		filter["dueDate"] = dueDate
+	}
+	if len(query.Assignee) > 0 {
+		filter["assignees"] = query.Assignee
+	}
+	if len(query.CreatedBy) > 0 {
+		filter["createdBy"] = query.CreatedBy
+	}
+	if len(query.ProjectID) > 0 {
+		filter["projectId"] = query.ProjectID
+	}
+	if len(query.ParentID) > 0 {
+		filter["parentId"] = query.ParentID
+	}
+	if len(query.Labels) > 0 {
+		operator := "$in"
+		if query.LabelMatch == apimodels.LabelMatchAll {
+			operator = "$all"
+		}
+		filter["labels"] = bson.M{operator: query.Labels}
+	}
+	for _, field := range query.CustomFields {
+		condition := bson.M{}
+		if len(field.Values) > 0 {
+			condition["$in"] = field.Values
+		}
+		if field.Gte != nil {
+			condition["$gte"] = field.Gte
+		}
+		if field.Lt != nil {
+			condition["$lt"] = field.Lt
+		}
+		filter[customFieldPath(field.Key)] = condition
+	}
+	return filter
+}
+
+func customFieldPath(key string) string {
+	return "customFields." + key
+}
+
+// function to get the upper due date bound, the earlier of due_before and the overdue time
+func dueBefore(query *apimodels.TaskQuery) *time.Time {
+	if !query.Overdue || (query.DueBefore != nil && query.DueBefore.Before(query.OverdueAt)) {
+		return query.DueBefore
+	}
+	return &query.OverdueAt
+}
+
+func dateRange(after, before *time.Time) bson.M {
+	condition := bson.M{}
+	if after != nil {
+		condition["$gte"] = *after
+	}
+	if before != nil {
+		condition["$lt"] = *before
+	}
+	if len(condition) == 0 {
+		return nil
+	}
+	return condition
+}
+
+// function to match a task against the query like taskFilter does in mongo, for the kv backends
+func matchesTaskQuery(task *models.TaskSchema, query *apimodels.TaskQuery) bool {
+	if len(query.Status) > 0 && !containsString(query.Status, task.Status) {
+		return false
+	}
+	if query.Overdue && containsString(query.DoneStatuses, task.Status) {
+		return false
+	}
+	if len(query.Assignee) > 0 && !containsString(task.Assignees, query.Assignee) {
+		return false
+	}
+	if len(query.CreatedBy) > 0 && task.CreatedBy != query.CreatedBy {
+		return false
+	}
+	if len(query.ProjectID) > 0 && task.ProjectID != query.ProjectID {
+		return false
+	}
+	if len(query.ParentID) > 0 && task.ParentID != query.ParentID {
+		return false
+	}
+	if len(query.Labels) > 0 && !matchesLabels(task.Labels, query.Labels, query.LabelMatch) {
+		return false
+	}
+	for _, field := range query.CustomFields {
+		if !matchesCustomField(task.CustomFields[field.Key], field) {
+			return false
+		}
+	}
+	if before := dueBefore(query); query.DueAfter != nil || before != nil {
+		// like mongo, a range never matches a task without a due date
+		if task.DueDate == nil || !inDateRange(*task.DueDate, query.DueAfter, before) {
+			return false
+		}
+	}
+	return inDateRange(task.CreatedAt, query.CreatedAfter, query.CreatedBefore) &&
+		inDateRange(task.UpdatedAt, query.UpdatedAfter, query.UpdatedBefore)
+}
+
+func inDateRange(value time.Time, after, before *time.Time) bool {
+	if after != nil && compareValues(value, *after) < 0 {
+		return false
+	}
+	if before != nil && compareValues(value, *before) >= 0 {
+		return false
+	}
+	return true
+}
+
+func matchesLabels(labels []string, filter []string, match string) bool {
+	for _, label := range filter {
+		found := containsString(labels, label)
+		if found && match != apimodels.LabelMatchAll {
+			return true
+		}
+		if !found && match == apimodels.LabelMatchAll {
+			return false
+		}
+	}
+	return match == apimodels.LabelMatchAll
+}
+
+// function to match a custom field value like the mongo filter does, a range only matches values of its type
+func matchesCustomField(value interface{}, filter *apimodels.CustomFieldFilter) bool {
+	if len(filter.Values) > 0 {
+		found := false
+		for _, candidate := range filter.Values {
+			if compareValues(value, candidate) == 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if filter.Gte != nil && (typeRank(value) != typeRank(filter.Gte) || compareValues(value, filter.Gte) < 0) {
+		return false
+	}
+	if filter.Lt != nil && (typeRank(value) != typeRank(filter.Lt) || compareValues(value, filter.Lt) >= 0) {
+		return false
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// function to order two tasks by the query sort with the id as tie breaker, like the mongo sort
+func compareTasks(a, b *models.TaskSchema, query *apimodels.TaskQuery) int {
+	return compareSortPosition(taskSortValue(a, query.SortBy), a.ID, taskSortValue(b, query.SortBy), b.ID, query.SortDesc)
+}
+
+func compareToCursor(task *models.TaskSchema, cursor *pageCursor, query *apimodels.TaskQuery) int {
+	return compareSortPosition(taskSortValue(task, query.SortBy), task.ID, cursor.Value, cursor.ID, query.SortDesc)
+}
+
+func compareSortPosition(valueA interface{}, idA primitive.ObjectID, valueB interface{}, idB primitive.ObjectID, desc bool) int {
+	result := compareValues(valueA, valueB)
+	if result == 0 {
+		result = compareValues(idA, idB)
+	}
+	if desc {
+		return -result
+	}
+	return result
+}
+
+// function to build the filter selecting the tasks after the cursor in sort order.
+// tasks without a value, only possible for custom fields, sort first and no comparison operator matches them
+func cursorFilter(sortBy string, direction int, cursor *pageCursor) bson.M {
+	operator := "$gt"
+	if direction < 0 {
+		operator = "$lt"
+	}
+	if cursor.Value == nil {
+		after := bson.M{sortBy: nil, "_id": bson.M{operator: cursor.ID}}
+		if direction < 0 {
+			return after
+		}
+		return bson.M{"$or": bson.A{after, bson.M{sortBy: bson.M{"$ne": nil}}}}
+	}
+	after := bson.A{
+		bson.M{sortBy: bson.M{operator: cursor.Value}},
+		bson.M{sortBy: cursor.Value, "_id": bson.M{operator: cursor.ID}},
+	}
+	if direction < 0 {
+		after = append(after, bson.M{sortBy: nil})
+	}
+	return bson.M{"$or": after}
+}
+
+// function to get the document field a sort is applied on, priorities sort by their rank
+func taskSortField(sortBy string) string {
+	if sortBy == apimodels.SortByPriority {
+		return "priorityRank"
+	}
+	if strings.HasPrefix(sortBy, apimodels.CustomFieldParamPrefix) {
+		return customFieldPath(strings.TrimPrefix(sortBy, apimodels.CustomFieldParamPrefix))
+	}
+	return sortBy
+}
+
+func taskSortValue(task *models.TaskSchema, sortBy string) interface{} {
+	if strings.HasPrefix(sortBy, apimodels.CustomFieldParamPrefix) {
+		return task.CustomFields[strings.TrimPrefix(sortBy, apimodels.CustomFieldParamPrefix)]
+	}
+	switch sortBy {
+	case apimodels.SortByUpdatedAt:
+		return task.UpdatedAt
+	case apimodels.SortByTitle:
+		return task.Title
+	case apimodels.SortByStatus:
+		return task.Status
+	case apimodels.SortByPriority:
+		return task.PriorityRank
+	case apimodels.SortByDeletedAt:
+		if task.DeletedAt == nil {
+			return nil
+		}
+		return *task.DeletedAt
+	default:
+		return task.CreatedAt
+	}
+}
+
+// function to trim the extra task fetched past the limit and issue the cursor for the next page
+func newTaskPage(tasks []*models.TaskSchema, total int64, query *apimodels.TaskQuery) (*models.TaskPage, error) {
+	page := &models.TaskPage{Tasks: tasks, Total: total}
+	if int64(len(tasks)) <= query.Limit {
+		return page, nil
+	}
+
+	page.Tasks = tasks[:query.Limit]
+	last := page.Tasks[len(page.Tasks)-1]
+	nextCursor, err := encodeCursor(pageCursor{
+		SortBy:   query.SortBy,
+		SortDesc: query.SortDesc,
+		Value:    taskSortValue(last, query.SortBy),
+		ID:       last.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	page.NextCursor = nextCursor
+	return page, nil
+}
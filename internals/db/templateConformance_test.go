@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ = Describe("Memory TemplateDbService", func() {
+	describeTemplateDbServiceConformance(func() (TemplateDbService, func()) {
+		return NewKVTemplateDbService(NewMemoryStore()), func() {}
+	})
+})
+
+var _ = Describe("Bolt TemplateDbService", func() {
+	describeTemplateDbServiceConformance(func() (TemplateDbService, func()) {
+		store, err := NewBoltStore(filepath.Join(GinkgoT().TempDir(), "tasks.db"))
+		Expect(err).NotTo(HaveOccurred())
+		return NewKVTemplateDbService(store), func() { Expect(store.Close()).To(Succeed()) }
+	})
+})
+
+var _ = Describe("Mongo TemplateDbService", func() {
+	if len(os.Getenv(mongoTestUri)) == 0 {
+		It("is skipped without "+mongoTestUri, func() {
+			Skip(mongoTestUri + " is not set")
+		})
+		return
+	}
+	describeTemplateDbServiceConformance(func() (TemplateDbService, func()) {
+		ctx := context.Background()
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv(mongoTestUri)))
+		Expect(err).NotTo(HaveOccurred())
+		database := fmt.Sprintf("task-svc-test-%s", primitive.NewObjectID().Hex())
+		dbclient := appdb.NewDatabaseClient(database, client)
+		Expect(ensureTemplateIndexes(ctx, dbclient.Collection(configs.MONGO_TEMPLATE_COLLECTION))).To(Succeed())
+		return NewTemplateDbService(dbclient), func() {
+			Expect(client.Database(database).Drop(ctx)).To(Succeed())
+			Expect(client.Disconnect(ctx)).To(Succeed())
+		}
+	})
+})
+
+// function to register the behaviour every TemplateDbService implementation must share
+func describeTemplateDbServiceConformance(newService func() (TemplateDbService, func())) {
+	var (
+		ctx     context.Context
+		service TemplateDbService
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var cleanup func()
+		service, cleanup = newService()
+		DeferCleanup(cleanup)
+	})
+
+	save := func(ctx context.Context, name string) string {
+		id, err := service.SaveTemplate(ctx, &models.TemplateSchema{Name: name, Task: &models.TemplateTaskSchema{
+			Title:       "{{.Customer}} onboarding",
+			Description: "Onboard {{.Customer}}",
+			Subtasks:    []*models.TemplateTaskSchema{{Title: "Kickoff", Description: "Kickoff call", Checklist: []string{"Agenda"}}},
+		}, Variables: []string{"Customer"}})
+		Expect(err).NotTo(HaveOccurred())
+		return id
+	}
+
+	It("stores, lists, updates and deletes a template", func() {
+		id := save(ctx, "Onboarding")
+		save(ctx, "Hiring")
+
+		templates, err := service.GetTemplates(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(templates).To(HaveLen(2))
+		Expect(templates[0].Name).To(Equal("Hiring"))
+
+		template, err := service.GetTemplateById(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(template.Task.Subtasks).To(HaveLen(1))
+		Expect(template.Task.Subtasks[0].Checklist).To(Equal([]string{"Agenda"}))
+		Expect(template.Variables).To(Equal([]string{"Customer"}))
+		template.Name = "Customer onboarding"
+		template.Task.Subtasks = nil
+		template.Variables = nil
+		Expect(service.UpdateTemplate(ctx, template)).To(Succeed())
+
+		template, err = service.GetTemplateById(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(template.Name).To(Equal("Customer onboarding"))
+		Expect(template.Task.Subtasks).To(BeEmpty())
+		Expect(template.Variables).To(BeEmpty())
+
+		Expect(service.DeleteTemplateById(ctx, id)).To(Succeed())
+		_, err = service.GetTemplateById(ctx, id)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		Expect(apperrors.Is(service.DeleteTemplateById(ctx, id), apperrors.NotFound)).To(BeTrue())
+	})
+
+	It("hides the templates of other workspaces", func() {
+		id := save(ctx, "Onboarding")
+		teamA := appauth.WithWorkspace(ctx, "team-a")
+
+		_, err := service.GetTemplateById(teamA, id)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		Expect(apperrors.Is(service.DeleteTemplateById(teamA, id), apperrors.NotFound)).To(BeTrue())
+		templates, err := service.GetTemplates(teamA)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(templates).To(BeEmpty())
+	})
+}
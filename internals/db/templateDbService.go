@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TemplateDbService stores the task templates of the workspace of the context
+type TemplateDbService interface {
+	// GetTemplates returns the templates ordered by name
+	GetTemplates(context context.Context) ([]*models.TemplateSchema, error)
+	GetTemplateById(context context.Context, templateId string) (*models.TemplateSchema, error)
+	SaveTemplate(context context.Context, template *models.TemplateSchema) (string, error)
+	// UpdateTemplate replaces the name, the description, the tasks and the variables of the template
+	UpdateTemplate(context context.Context, template *models.TemplateSchema) error
+	DeleteTemplateById(context context.Context, templateId string) error
+}
+
+type templateDbService struct {
+	collection appdb.DatabaseCollection
+}
+
+// function to build the mongo template db service, the templates are scoped to the workspace of the context
+func NewTemplateDbService(dbclient appdb.DatabaseClient) TemplateDbService {
+	return &templateDbService{
+		collection: newTenantCollection(dbclient.Collection(configs.MONGO_TEMPLATE_COLLECTION)),
+	}
+}
+
+func (d *templateDbService) GetTemplates(ctx context.Context) ([]*models.TemplateSchema, error) {
+	templates := []*models.TemplateSchema{}
+	findOptions := options.Find().SetSort(bson.D{{Key: "name", Value: 1}, {Key: "_id", Value: 1}})
+	if err := d.collection.Find(ctx, bson.M{}, findOptions, &templates); err != nil {
+		return nil, fmt.Errorf("failed to fetch templates: %v", err)
+	}
+	return templates, nil
+}
+
+func (d *templateDbService) GetTemplateById(ctx context.Context, templateId string) (*models.TemplateSchema, error) {
+	id, err := parseObjectId(templateId)
+	if err != nil {
+		return nil, err
+	}
+	var template models.TemplateSchema
+	if err := d.collection.FindOne(ctx, bson.M{"_id": id}, &template); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("template %s not found", templateId))
+		}
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (d *templateDbService) SaveTemplate(ctx context.Context, template *models.TemplateSchema) (string, error) {
+	template.WorkspaceID = appauth.GetWorkspace(ctx)
+	result, err := d.collection.InsertOne(ctx, template)
+	if err != nil {
+		return "", err
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (d *templateDbService) UpdateTemplate(ctx context.Context, template *models.TemplateSchema) error {
+	update := bson.M{"$set": bson.M{
+		"name":        template.Name,
+		"description": template.Description,
+		"task":        template.Task,
+		"variables":   template.Variables,
+		"updatedAt":   template.UpdatedAt,
+	}}
+	result, err := d.collection.UpdateOne(ctx, bson.M{"_id": template.ID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("template %s not found", template.ID.Hex()))
+	}
+	return nil
+}
+
+func (d *templateDbService) DeleteTemplateById(ctx context.Context, templateId string) error {
+	id, err := parseObjectId(templateId)
+	if err != nil {
+		return err
+	}
+	result, err := d.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("template %s not found", templateId))
+	}
+	return nil
+}
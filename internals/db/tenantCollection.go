@@ -0,0 +1,140 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/appdb"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// tenantCollection scopes every read, write and delete to the workspace of the request context
+// and stamps the workspace on inserted documents, so no query of the db service can reach another workspace
+type tenantCollection struct {
+	collection appdb.DatabaseCollection
+}
+
+func newTenantCollection(collection appdb.DatabaseCollection) appdb.DatabaseCollection {
+	return &tenantCollection{collection: collection}
+}
+
+// function to get the filter matching the documents of the workspace of the context,
+// documents without a workspace belong to the default workspace
+func workspaceFilter(ctx context.Context) bson.M {
+	workspace := appauth.GetWorkspace(ctx)
+	if workspace == appauth.DefaultWorkspace {
+		return bson.M{"workspaceId": bson.M{"$in": bson.A{nil, appauth.DefaultWorkspace}}}
+	}
+	return bson.M{"workspaceId": workspace}
+}
+
+func scopeFilter(ctx context.Context, filter interface{}) bson.M {
+	if filter == nil {
+		return workspaceFilter(ctx)
+	}
+	return bson.M{"$and": bson.A{filter, workspaceFilter(ctx)}}
+}
+
+// function to copy the document with the workspace of the context set on it
+func scopeDocument(ctx context.Context, document interface{}) (bson.M, error) {
+	raw, err := bson.Marshal(document)
+	if err != nil {
+		return nil, err
+	}
+	var values bson.M
+	if err := bson.Unmarshal(raw, &values); err != nil {
+		return nil, err
+	}
+	values["workspaceId"] = appauth.GetWorkspace(ctx)
+	return values, nil
+}
+
+func (t *tenantCollection) FindOne(ctx context.Context, filter interface{}, document interface{}) error {
+	return t.collection.FindOne(ctx, scopeFilter(ctx, filter), document)
+}
+
+func (t *tenantCollection) FindOneAndUpdate(ctx context.Context, filter interface{}, update interface{}, document interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	return t.collection.FindOneAndUpdate(ctx, scopeFilter(ctx, filter), update, document, opts...)
+}
+
+func (t *tenantCollection) InsertOne(ctx context.Context, document interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	scoped, err := scopeDocument(ctx, document)
+	if err != nil {
+		return nil, err
+	}
+	return t.collection.InsertOne(ctx, scoped, opts...)
+}
+
+func (t *tenantCollection) InsertMany(ctx context.Context, documents []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	scoped := make([]interface{}, 0, len(documents))
+	for _, document := range documents {
+		values, err := scopeDocument(ctx, document)
+		if err != nil {
+			return nil, err
+		}
+		scoped = append(scoped, values)
+	}
+	return t.collection.InsertMany(ctx, scoped, opts...)
+}
+
+func (t *tenantCollection) UpdateOne(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return t.collection.UpdateOne(ctx, scopeFilter(ctx, filter), update, opts...)
+}
+
+func (t *tenantCollection) UpdateMany(ctx context.Context, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	return t.collection.UpdateMany(ctx, scopeFilter(ctx, filter), update, opts...)
+}
+
+func (t *tenantCollection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	return t.collection.CountDocuments(ctx, scopeFilter(ctx, filter), opts...)
+}
+
+func (t *tenantCollection) Find(ctx context.Context, filter interface{}, options *options.FindOptions, response interface{}) error {
+	return t.collection.Find(ctx, scopeFilter(ctx, filter), options, response)
+}
+
+// function to run the pipeline on the documents of the workspace only, a $match is put in front of it
+func (t *tenantCollection) Aggregate(ctx context.Context, pipeline interface{}, response interface{}) error {
+	stages, ok := pipeline.(bson.A)
+	if !ok {
+		if list, isList := pipeline.([]bson.M); isList {
+			for _, stage := range list {
+				stages = append(stages, stage)
+			}
+		} else if list, isList := pipeline.(mongo.Pipeline); isList {
+			for _, stage := range list {
+				stages = append(stages, stage)
+			}
+		} else {
+			return fmt.Errorf("unsupported pipeline type %T", pipeline)
+		}
+	}
+	scoped := append(bson.A{bson.M{"$match": workspaceFilter(ctx)}}, stages...)
+	return t.collection.Aggregate(ctx, scoped, response)
+}
+
+func (t *tenantCollection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	return t.collection.DeleteOne(ctx, scopeFilter(ctx, filter), opts...)
+}
+
+func (t *tenantCollection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	return t.collection.DeleteMany(ctx, scopeFilter(ctx, filter), opts...)
+}
+
+func (t *tenantCollection) Distinct(ctx context.Context, field string, filter interface{}) ([]interface{}, error) {
+	return t.collection.Distinct(ctx, field, scopeFilter(ctx, filter))
+}
+
+// function to refuse dropping the collection, it holds the documents of every workspace
+func (t *tenantCollection) Drop(ctx context.Context) error {
+	return fmt.Errorf("the collection is shared by every workspace and cannot be dropped")
+}
+
+// indexes span every workspace, they are created on the underlying collection
+func (t *tenantCollection) CreateIndexes(ctx context.Context, models []mongo.IndexModel) ([]string, error) {
+	return t.collection.CreateIndexes(ctx, models)
+}
@@ -0,0 +1,192 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+	apimodels "TaskSvc/internals/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ = Describe("Memory WorklogDbService", func() {
+	describeWorklogDbServiceConformance(func() (WorklogDbService, func()) {
+		return NewKVWorklogDbService(NewMemoryStore()), func() {}
+	})
+})
+
+var _ = Describe("Bolt WorklogDbService", func() {
+	describeWorklogDbServiceConformance(func() (WorklogDbService, func()) {
+		store, err := NewBoltStore(filepath.Join(GinkgoT().TempDir(), "tasks.db"))
+		Expect(err).NotTo(HaveOccurred())
+		return NewKVWorklogDbService(store), func() { Expect(store.Close()).To(Succeed()) }
+	})
+})
+
+var _ = Describe("Mongo WorklogDbService", func() {
+	if len(os.Getenv(mongoTestUri)) == 0 {
+		It("is skipped without "+mongoTestUri, func() {
+			Skip(mongoTestUri + " is not set")
+		})
+		return
+	}
+	describeWorklogDbServiceConformance(func() (WorklogDbService, func()) {
+		ctx := context.Background()
+		client, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv(mongoTestUri)))
+		Expect(err).NotTo(HaveOccurred())
+		database := fmt.Sprintf("task-svc-test-%s", primitive.NewObjectID().Hex())
+		dbclient := appdb.NewDatabaseClient(database, client)
+		Expect(ensureWorklogIndexes(ctx, dbclient.Collection(configs.MONGO_WORKLOG_COLLECTION),
+			dbclient.Collection(configs.MONGO_TIMER_COLLECTION))).To(Succeed())
+		return NewWorklogDbService(dbclient), func() {
+			Expect(client.Database(database).Drop(ctx)).To(Succeed())
+			Expect(client.Disconnect(ctx)).To(Succeed())
+		}
+	})
+})
+
+// function to register the behaviour every WorklogDbService implementation must share
+func describeWorklogDbServiceConformance(newService func() (WorklogDbService, func())) {
+	var (
+		ctx     context.Context
+		service WorklogDbService
+		day     time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		day = time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+		var cleanup func()
+		service, cleanup = newService()
+		DeferCleanup(cleanup)
+	})
+
+	log := func(ctx context.Context, taskId, projectId, author string, started time.Time, duration int64) string {
+		id, err := service.SaveWorklog(ctx, &models.WorklogSchema{TaskID: taskId, ProjectID: projectId, Author: author,
+			Started: started, Duration: duration, CreatedAt: started})
+		Expect(err).NotTo(HaveOccurred())
+		return id
+	}
+
+	It("lists the worklogs of a task in the order the work started", func() {
+		log(ctx, "task-1", "", "user-1", day.Add(2*time.Hour), 60)
+		first := log(ctx, "task-1", "", "user-2", day, 120)
+		log(ctx, "task-2", "", "user-1", day, 30)
+
+		worklogs, err := service.GetWorklogs(ctx, "task-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(worklogs).To(HaveLen(2))
+		Expect(worklogs[0].ID.Hex()).To(Equal(first))
+		Expect(worklogs[0].Author).To(Equal("user-2"))
+		Expect(worklogs[0].Started).To(BeTemporally("==", day))
+
+		Expect(apperrors.Is(service.DeleteWorklogById(ctx, "task-2", first), apperrors.NotFound)).To(BeTrue())
+		Expect(service.DeleteWorklogById(ctx, "task-1", first)).To(Succeed())
+		worklogs, err = service.GetWorklogs(ctx, "task-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(worklogs).To(HaveLen(1))
+	})
+
+	It("runs one timer per subject and task", func() {
+		_, err := service.StartTimer(ctx, &models.TimerSchema{TaskID: "task-1", Subject: "user-1", StartedAt: day})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = service.StartTimer(ctx, &models.TimerSchema{TaskID: "task-1", Subject: "user-1", StartedAt: day})
+		Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+		_, err = service.StartTimer(ctx, &models.TimerSchema{TaskID: "task-2", Subject: "user-1", StartedAt: day.Add(time.Hour)})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = service.StartTimer(ctx, &models.TimerSchema{TaskID: "task-1", Subject: "user-2", StartedAt: day})
+		Expect(err).NotTo(HaveOccurred())
+
+		timers, err := service.GetTimers(ctx, "user-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timers).To(HaveLen(2))
+		Expect(timers[0].TaskID).To(Equal("task-1"))
+
+		timer, err := service.StopTimer(ctx, "user-1", "task-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timer.StartedAt).To(BeTemporally("==", day))
+		_, err = service.StopTimer(ctx, "user-1", "task-1")
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		_, err = service.StopTimer(appauth.WithWorkspace(ctx, "team-a"), "user-1", "task-2")
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+	})
+
+	It("deletes the worklogs and the timers of a task", func() {
+		log(ctx, "task-1", "", "user-1", day, 60)
+		log(ctx, "task-2", "", "user-1", day, 60)
+		_, err := service.StartTimer(ctx, &models.TimerSchema{TaskID: "task-1", Subject: "user-1", StartedAt: day})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(service.DeleteWorklogs(ctx, "task-1")).To(Succeed())
+		worklogs, err := service.GetWorklogs(ctx, "task-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(worklogs).To(BeEmpty())
+		timers, err := service.GetTimers(ctx, "user-1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timers).To(BeEmpty())
+		worklogs, err = service.GetWorklogs(ctx, "task-2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(worklogs).To(HaveLen(1))
+	})
+
+	Describe("GetTimeReport", func() {
+		BeforeEach(func() {
+			log(ctx, "task-1", "project-1", "user-1", day, 60)
+			log(ctx, "task-1", "project-1", "user-1", day.Add(24*time.Hour), 120)
+			log(ctx, "task-2", "project-1", "user-2", day, 300)
+			log(ctx, "task-3", "", "user-1", day.Add(48*time.Hour), 600)
+			log(appauth.WithWorkspace(ctx, "team-a"), "task-9", "project-1", "user-1", day, 1000)
+		})
+
+		It("sums every worklog of the workspace without groups", func() {
+			rows, err := service.GetTimeReport(ctx, &apimodels.TimeReportQuery{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(HaveLen(1))
+			Expect(rows[0].Key).To(Equal(models.TimeReportKey{}))
+			Expect(rows[0].Duration).To(Equal(int64(1080)))
+			Expect(rows[0].Worklogs).To(Equal(int64(4)))
+		})
+
+		It("groups by the requested dimensions in their order", func() {
+			rows, err := service.GetTimeReport(ctx, &apimodels.TimeReportQuery{
+				GroupBy: []string{apimodels.TimeReportByUser, apimodels.TimeReportByProject}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(HaveLen(3))
+			Expect(*rows[0]).To(Equal(models.TimeReportRow{Key: models.TimeReportKey{User: "user-1"}, Duration: 600, Worklogs: 1}))
+			Expect(*rows[1]).To(Equal(models.TimeReportRow{Key: models.TimeReportKey{User: "user-1", ProjectID: "project-1"}, Duration: 180, Worklogs: 2}))
+			Expect(*rows[2]).To(Equal(models.TimeReportRow{Key: models.TimeReportKey{User: "user-2", ProjectID: "project-1"}, Duration: 300, Worklogs: 1}))
+
+			rows, err = service.GetTimeReport(ctx, &apimodels.TimeReportQuery{GroupBy: []string{apimodels.TimeReportByDay}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(HaveLen(3))
+			Expect(rows[0].Key.Day).To(Equal("2024-05-01"))
+			Expect(rows[0].Duration).To(Equal(int64(360)))
+		})
+
+		It("filters by user, project and the half open date range", func() {
+			from, to := day, day.Add(48*time.Hour)
+			rows, err := service.GetTimeReport(ctx, &apimodels.TimeReportQuery{From: &from, To: &to, User: "user-1",
+				GroupBy: []string{apimodels.TimeReportByTask}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(HaveLen(1))
+			Expect(rows[0].Key.TaskID).To(Equal("task-1"))
+			Expect(rows[0].Duration).To(Equal(int64(180)))
+
+			rows, err = service.GetTimeReport(ctx, &apimodels.TimeReportQuery{ProjectID: "project-2"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rows).To(BeEmpty())
+		})
+	})
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+	apimodels "TaskSvc/internals/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WorklogDbService stores the worklogs and the running timers of the workspace of the context
+type WorklogDbService interface {
+	// GetWorklogs returns the worklogs of the task in the order the work was started
+	GetWorklogs(context context.Context, taskId string) ([]*models.WorklogSchema, error)
+	SaveWorklog(context context.Context, worklog *models.WorklogSchema) (string, error)
+	DeleteWorklogById(context context.Context, taskId string, worklogId string) error
+	// DeleteWorklogs removes the worklogs and the running timers of the task
+	DeleteWorklogs(context context.Context, taskId string) error
+	// StartTimer saves the timer, a subject already running a timer on the task is a conflict
+	StartTimer(context context.Context, timer *models.TimerSchema) (string, error)
+	// StopTimer removes the timer of the subject on the task and returns it, only one of concurrent calls gets it
+	StopTimer(context context.Context, subject string, taskId string) (*models.TimerSchema, error)
+	GetTimers(context context.Context, subject string) ([]*models.TimerSchema, error)
+	// GetTimeReport sums the worklogs matching the query by group, ordered by the group dimensions
+	GetTimeReport(context context.Context, query *apimodels.TimeReportQuery) ([]*models.TimeReportRow, error)
+}
+
+type worklogDbService struct {
+	worklogs appdb.DatabaseCollection
+	timers   appdb.DatabaseCollection
+}
+
+func NewWorklogDbService(dbclient appdb.DatabaseClient) WorklogDbService {
+	return &worklogDbService{
+		worklogs: newTenantCollection(dbclient.Collection(configs.MONGO_WORKLOG_COLLECTION)),
+		timers:   newTenantCollection(dbclient.Collection(configs.MONGO_TIMER_COLLECTION)),
+	}
+}
+
+func (d *worklogDbService) GetWorklogs(ctx context.Context, taskId string) ([]*models.WorklogSchema, error) {
+	worklogs := []*models.WorklogSchema{}
+	findOptions := options.Find().SetSort(bson.D{{Key: "started", Value: 1}, {Key: "_id", Value: 1}})
+	if err := d.worklogs.Find(ctx, bson.M{"taskId": taskId}, findOptions, &worklogs); err != nil {
+		return nil, fmt.Errorf("failed to fetch worklogs: %v", err)
+	}
+	return worklogs, nil
+}
+
+func (d *worklogDbService) SaveWorklog(ctx context.Context, worklog *models.WorklogSchema) (string, error) {
+	worklog.WorkspaceID = appauth.GetWorkspace(ctx)
+	result, err := d.worklogs.InsertOne(ctx, worklog)
+	if err != nil {
+		return "", err
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+func (d *worklogDbService) DeleteWorklogById(ctx context.Context, taskId string, worklogId string) error {
+	id, err := parseObjectId(worklogId)
+	if err != nil {
+		return err
+	}
+	result, err := d.worklogs.DeleteOne(ctx, bson.M{"_id": id, "taskId": taskId})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("worklog %s not found", worklogId))
+	}
+	return nil
+}
+
+func (d *worklogDbService) DeleteWorklogs(ctx context.Context, taskId string) error {
+	if _, err := d.worklogs.DeleteMany(ctx, bson.M{"taskId": taskId}); err != nil {
+		return fmt.Errorf("failed to delete the worklogs of task %s: %v", taskId, err)
+	}
+	if _, err := d.timers.DeleteMany(ctx, bson.M{"taskId": taskId}); err != nil {
+		return fmt.Errorf("failed to delete the timers of task %s: %v", taskId, err)
+	}
+	return nil
+}
+
+// function to save the timer, the unique index on the workspace, the subject and the task rejects a second one
+func (d *worklogDbService) StartTimer(ctx context.Context, timer *models.TimerSchema) (string, error) {
+	timer.WorkspaceID = appauth.GetWorkspace(ctx)
+	result, err := d.timers.InsertOne(ctx, timer)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return "", apperrors.NewConflictError(fmt.Sprintf("a timer is already running on task %s", timer.TaskID), err)
+		}
+		return "", err
+	}
+	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+}
+
+// function to remove the timer, the delete of the timer that was read decides between concurrent calls
+func (d *worklogDbService) StopTimer(ctx context.Context, subject string, taskId string) (*models.TimerSchema, error) {
+	var timer models.TimerSchema
+	if err := d.timers.FindOne(ctx, bson.M{"subject": subject, "taskId": taskId}, &timer); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("no timer is running on task %s", taskId))
+		}
+		return nil, err
+	}
+	result, err := d.timers.DeleteOne(ctx, bson.M{"_id": timer.ID})
+	if err != nil {
+		return nil, err
+	}
+	if result.DeletedCount == 0 {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("no timer is running on task %s", taskId))
+	}
+	return &timer, nil
+}
+
+func (d *worklogDbService) GetTimers(ctx context.Context, subject string) ([]*models.TimerSchema, error) {
+	timers := []*models.TimerSchema{}
+	findOptions := options.Find().SetSort(bson.D{{Key: "startedAt", Value: 1}, {Key: "_id", Value: 1}})
+	if err := d.timers.Find(ctx, bson.M{"subject": subject}, findOptions, &timers); err != nil {
+		return nil, fmt.Errorf("failed to fetch timers: %v", err)
+	}
+	return timers, nil
+}
+
+// function to sum the worklogs in a single aggregation, the group _id only holds the requested dimensions
+func (d *worklogDbService) GetTimeReport(ctx context.Context, query *apimodels.TimeReportQuery) ([]*models.TimeReportRow, error) {
+	filter := bson.M{}
+	if started := dateRange(query.From, query.To); started != nil {
+		filter["started"] = started
+	}
+	if len(query.User) > 0 {
+		filter["author"] = query.User
+	}
+	if len(query.ProjectID) > 0 {
+		filter["projectId"] = query.ProjectID
+	}
+	group := bson.D{}
+	for _, dimension := range query.GroupBy {
+		switch dimension {
+		case apimodels.TimeReportByUser:
+			group = append(group, bson.E{Key: "user", Value: "$author"})
+		case apimodels.TimeReportByProject:
+			// worklogs outside of a project group under an empty id, which sorts first like in kvWorklogDbService
+			group = append(group, bson.E{Key: "projectId", Value: bson.M{"$ifNull": bson.A{"$projectId", ""}}})
+		case apimodels.TimeReportByTask:
+			group = append(group, bson.E{Key: "taskId", Value: "$taskId"})
+		case apimodels.TimeReportByDay:
+			group = append(group, bson.E{Key: "day", Value: bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$started"}}})
+		}
+	}
+	// without dimensions every worklog falls in the one group
+	var id interface{} = group
+	if len(group) == 0 {
+		id = nil
+	}
+	pipeline := bson.A{
+		bson.M{"$match": filter},
+		bson.M{"$group": bson.M{"_id": id, "duration": bson.M{"$sum": "$duration"}, "worklogs": bson.M{"$sum": 1}}},
+		bson.M{"$sort": bson.M{"_id": 1}},
+	}
+	rows := []*models.TimeReportRow{}
+	if err := d.worklogs.Aggregate(ctx, pipeline, &rows); err != nil {
+		return nil, fmt.Errorf("failed to build the time report: %v", err)
+	}
+	return rows, nil
+}
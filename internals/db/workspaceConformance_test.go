@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"TaskSvc/commons/apperrors"
+	models "TaskSvc/internals/db/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Memory WorkspaceDbService", func() {
+	describeWorkspaceDbServiceConformance(func() (WorkspaceDbService, func()) {
+		return NewKVWorkspaceDbService(NewMemoryStore()), func() {}
+	})
+})
+
+var _ = Describe("Bolt WorkspaceDbService", func() {
+	describeWorkspaceDbServiceConformance(func() (WorkspaceDbService, func()) {
+		store, err := NewBoltStore(filepath.Join(GinkgoT().TempDir(), "tasks.db"))
+		Expect(err).NotTo(HaveOccurred())
+		return NewKVWorkspaceDbService(store), func() { Expect(store.Close()).To(Succeed()) }
+	})
+})
+
+// function to register the behaviour every WorkspaceDbService implementation must share
+func describeWorkspaceDbServiceConformance(newService func() (WorkspaceDbService, func())) {
+	var (
+		ctx     context.Context
+		service WorkspaceDbService
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		var cleanup func()
+		service, cleanup = newService()
+		DeferCleanup(cleanup)
+	})
+
+	saveWorkspace := func(id string) {
+		Expect(service.SaveWorkspace(ctx, &models.WorkspaceSchema{ID: id, Name: id, CreatedAt: now, UpdatedAt: now})).To(Succeed())
+	}
+
+	saveMembership := func(workspaceId, subject, role string) {
+		Expect(service.SaveMembership(ctx, &models.MembershipSchema{
+			WorkspaceID: workspaceId, Subject: subject, Role: role, CreatedAt: now, UpdatedAt: now,
+		})).To(Succeed())
+	}
+
+	Describe("workspaces", func() {
+		It("stores, lists, renames and deletes a workspace", func() {
+			saveWorkspace("team-b")
+			saveWorkspace("team-a")
+
+			workspaces, err := service.GetWorkspaces(ctx, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(workspaces).To(HaveLen(2))
+			Expect(workspaces[0].ID).To(Equal("team-a"))
+
+			workspaces, err = service.GetWorkspaces(ctx, []string{"team-b"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(workspaces).To(HaveLen(1))
+			Expect(workspaces[0].ID).To(Equal("team-b"))
+
+			Expect(service.UpdateWorkspace(ctx, &models.WorkspaceSchema{ID: "team-a", Name: "Team A", UpdatedAt: now})).To(Succeed())
+			workspace, err := service.GetWorkspaceById(ctx, "team-a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(workspace.Name).To(Equal("Team A"))
+
+			Expect(service.DeleteWorkspaceById(ctx, "team-a")).To(Succeed())
+			_, err = service.GetWorkspaceById(ctx, "team-a")
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+
+		It("refuses a duplicate id", func() {
+			saveWorkspace("team-a")
+			err := service.SaveWorkspace(ctx, &models.WorkspaceSchema{ID: "team-a", Name: "Again"})
+			Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+		})
+
+		It("reports a missing workspace", func() {
+			err := service.UpdateWorkspace(ctx, &models.WorkspaceSchema{ID: "missing", Name: "Missing"})
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			Expect(apperrors.Is(service.DeleteWorkspaceById(ctx, "missing"), apperrors.NotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("memberships", func() {
+		BeforeEach(func() {
+			saveWorkspace("team-a")
+			saveWorkspace("team-b")
+			saveMembership("team-a", "user-2", "member")
+			saveMembership("team-a", "user-1", "admin")
+			saveMembership("team-b", "user-1", "viewer")
+		})
+
+		It("lists the members of a workspace and the workspaces of a subject", func() {
+			members, err := service.GetMemberships(ctx, "team-a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(HaveLen(2))
+			Expect(members[0].Subject).To(Equal("user-1"))
+			Expect(members[1].Subject).To(Equal("user-2"))
+
+			memberships, err := service.GetMembershipsOf(ctx, "user-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(memberships).To(HaveLen(2))
+			Expect(memberships[0].WorkspaceID).To(Equal("team-a"))
+			Expect(memberships[1].Role).To(Equal("viewer"))
+		})
+
+		It("keeps one membership per subject and workspace", func() {
+			err := service.SaveMembership(ctx, &models.MembershipSchema{WorkspaceID: "team-a", Subject: "user-1", Role: "viewer"})
+			Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+		})
+
+		It("changes the role and removes a member", func() {
+			Expect(service.UpdateMembership(ctx, &models.MembershipSchema{WorkspaceID: "team-a", Subject: "user-2", Role: "viewer", UpdatedAt: now})).To(Succeed())
+			membership, err := service.GetMembership(ctx, "team-a", "user-2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(membership.Role).To(Equal("viewer"))
+
+			Expect(service.DeleteMembership(ctx, "team-a", "user-2")).To(Succeed())
+			_, err = service.GetMembership(ctx, "team-a", "user-2")
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			Expect(apperrors.Is(service.DeleteMembership(ctx, "team-a", "user-2"), apperrors.NotFound)).To(BeTrue())
+		})
+
+		It("removes every membership of a workspace", func() {
+			Expect(service.DeleteMemberships(ctx, "team-a")).To(Succeed())
+			members, err := service.GetMemberships(ctx, "team-a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(BeEmpty())
+			members, err = service.GetMemberships(ctx, "team-b")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(members).To(HaveLen(1))
+		})
+	})
+}
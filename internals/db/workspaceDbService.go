@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"TaskSvc/commons/appdb"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/configs"
+	models "TaskSvc/internals/db/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// WorkspaceDbService stores the workspaces and the memberships granting subjects a role in them,
+// unlike tasks they are not scoped to the workspace of the context
+type WorkspaceDbService interface {
+	GetWorkspaceById(context context.Context, workspaceId string) (*models.WorkspaceSchema, error)
+	GetWorkspaces(context context.Context, workspaceIds []string) ([]*models.WorkspaceSchema, error)
+	SaveWorkspace(context context.Context, workspace *models.WorkspaceSchema) error
+	UpdateWorkspace(context context.Context, workspace *models.WorkspaceSchema) error
+	DeleteWorkspaceById(context context.Context, workspaceId string) error
+
+	GetMembership(context context.Context, workspaceId string, subject string) (*models.MembershipSchema, error)
+	GetMemberships(context context.Context, workspaceId string) ([]*models.MembershipSchema, error)
+	GetMembershipsOf(context context.Context, subject string) ([]*models.MembershipSchema, error)
+	SaveMembership(context context.Context, membership *models.MembershipSchema) error
+	UpdateMembership(context context.Context, membership *models.MembershipSchema) error
+	DeleteMembership(context context.Context, workspaceId string, subject string) error
+	DeleteMemberships(context context.Context, workspaceId string) error
+}
+
+type workspaceDbService struct {
+	workspaces  appdb.DatabaseCollection
+	memberships appdb.DatabaseCollection
+}
+
+func NewWorkspaceDbService(dbclient appdb.DatabaseClient) WorkspaceDbService {
+	return &workspaceDbService{
+		workspaces:  dbclient.Collection(configs.MONGO_WORKSPACE_COLLECTION),
+		memberships: dbclient.Collection(configs.MONGO_MEMBERSHIP_COLLECTION),
+	}
+}
+
+// function to build the id of a membership, a subject has one membership per workspace
+func membershipId(workspaceId string, subject string) string {
+	return workspaceId + "/" + subject
+}
+
+func (d *workspaceDbService) GetWorkspaceById(ctx context.Context, workspaceId string) (*models.WorkspaceSchema, error) {
+	var workspace models.WorkspaceSchema
+	if err := d.workspaces.FindOne(ctx, bson.M{"_id": workspaceId}, &workspace); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("workspace %s not found", workspaceId))
+		}
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// function to get the workspaces with the given ids, every workspace when ids is nil
+func (d *workspaceDbService) GetWorkspaces(ctx context.Context, workspaceIds []string) ([]*models.WorkspaceSchema, error) {
+	filter := bson.M{}
+	if workspaceIds != nil {
+		filter = bson.M{"_id": bson.M{"$in": workspaceIds}}
+	}
+	workspaces := []*models.WorkspaceSchema{}
+	if err := d.workspaces.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}), &workspaces); err != nil {
+		return nil, fmt.Errorf("failed to fetch workspaces: %v", err)
+	}
+	return workspaces, nil
+}
+
+func (d *workspaceDbService) SaveWorkspace(ctx context.Context, workspace *models.WorkspaceSchema) error {
+	if _, err := d.workspaces.InsertOne(ctx, workspace); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return apperrors.NewConflictError(fmt.Sprintf("workspace %s already exists", workspace.ID), err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *workspaceDbService) UpdateWorkspace(ctx context.Context, workspace *models.WorkspaceSchema) error {
+	update := bson.M{"$set": bson.M{"name": workspace.Name, "updatedAt": workspace.UpdatedAt}}
+	result, err := d.workspaces.UpdateOne(ctx, bson.M{"_id": workspace.ID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("workspace %s not found", workspace.ID))
+	}
+	return nil
+}
+
+func (d *workspaceDbService) DeleteWorkspaceById(ctx context.Context, workspaceId string) error {
+	result, err := d.workspaces.DeleteOne(ctx, bson.M{"_id": workspaceId})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("workspace %s not found", workspaceId))
+	}
+	return nil
+}
+
+func (d *workspaceDbService) GetMembership(ctx context.Context, workspaceId string, subject string) (*models.MembershipSchema, error) {
+	var membership models.MembershipSchema
+	if err := d.memberships.FindOne(ctx, bson.M{"_id": membershipId(workspaceId, subject)}, &membership); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("%s is not a member of workspace %s", subject, workspaceId))
+		}
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (d *workspaceDbService) GetMemberships(ctx context.Context, workspaceId string) ([]*models.MembershipSchema, error) {
+	return d.findMemberships(ctx, bson.M{"workspaceId": workspaceId}, "subject")
+}
+
+func (d *workspaceDbService) GetMembershipsOf(ctx context.Context, subject string) ([]*models.MembershipSchema, error) {
+	return d.findMemberships(ctx, bson.M{"subject": subject}, "workspaceId")
+}
+
+func (d *workspaceDbService) findMemberships(ctx context.Context, filter bson.M, sortField string) ([]*models.MembershipSchema, error) {
+	memberships := []*models.MembershipSchema{}
+	if err := d.memberships.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: sortField, Value: 1}}), &memberships); err != nil {
+		return nil, fmt.Errorf("failed to fetch memberships: %v", err)
+	}
+	return memberships, nil
+}
+
+func (d *workspaceDbService) SaveMembership(ctx context.Context, membership *models.MembershipSchema) error {
+	membership.ID = membershipId(membership.WorkspaceID, membership.Subject)
+	if _, err := d.memberships.InsertOne(ctx, membership); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return apperrors.NewConflictError(fmt.Sprintf("%s is already a member of workspace %s", membership.Subject, membership.WorkspaceID), err)
+		}
+		return err
+	}
+	return nil
+}
+
+func (d *workspaceDbService) UpdateMembership(ctx context.Context, membership *models.MembershipSchema) error {
+	update := bson.M{"$set": bson.M{"role": membership.Role, "updatedAt": membership.UpdatedAt}}
+	result, err := d.memberships.UpdateOne(ctx, bson.M{"_id": membershipId(membership.WorkspaceID, membership.Subject)}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("%s is not a member of workspace %s", membership.Subject, membership.WorkspaceID))
+	}
+	return nil
+}
+
+func (d *workspaceDbService) DeleteMembership(ctx context.Context, workspaceId string, subject string) error {
+	result, err := d.memberships.DeleteOne(ctx, bson.M{"_id": membershipId(workspaceId, subject)})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("%s is not a member of workspace %s", subject, workspaceId))
+	}
+	return nil
+}
+
+func (d *workspaceDbService) DeleteMemberships(ctx context.Context, workspaceId string) error {
+	_, err := d.memberships.DeleteMany(ctx, bson.M{"workspaceId": workspaceId})
+	return err
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"io"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// DefaultMaxAttachmentSize is the number of bytes an attachment can have, 10 MiB
+	DefaultMaxAttachmentSize = 10 << 20
+	// MaxAttachmentNameLength is the number of characters the file name of an attachment can have
+	MaxAttachmentNameLength = 255
+)
+
+// DefaultAttachmentTypes are the content types an attachment can have, a trailing /* matches every subtype
+var DefaultAttachmentTypes = []string{"image/*", "text/plain", "application/pdf", "application/json", "application/zip"}
+
+type Attachment struct {
+	ID          primitive.ObjectID `json:"id"`
+	Name        string             `json:"name"`
+	Size        int64              `json:"size"`
+	ContentType string             `json:"contentType"`
+	SHA256      string             `json:"sha256"`
+	UploadedBy  string             `json:"uploadedBy,omitempty"`
+	UploadedAt  time.Time          `json:"uploadedAt"`
+}
+
+// AttachmentUpload is a file being attached to a task, the content is streamed to the blob store
+type AttachmentUpload struct {
+	Name    string
+	Content io.Reader
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// MaxChecklistItems is the number of items the checklist of a task can have
+	MaxChecklistItems = 100
+	// MaxChecklistTextLength is the number of characters the text of an item can have
+	MaxChecklistTextLength = 500
+)
+
+type ChecklistItem struct {
+	ID     primitive.ObjectID `json:"id"`
+	Text   string             `json:"text"`
+	Done   bool               `json:"done"`
+	DoneBy string             `json:"doneBy,omitempty"`
+	DoneAt *time.Time         `json:"doneAt,omitempty"`
+}
+
+// ChecklistProgress is the number of items of the checklist that are done
+type ChecklistProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// ChecklistItemInput is the payload adding an item, it is appended when no position is given
+type ChecklistItemInput struct {
+	Text     string `json:"text"`
+	Position *int   `json:"position,omitempty"`
+}
+
+// ChecklistItemChange is the payload changing an item, the fields left out are kept
+type ChecklistItemChange struct {
+	Text *string `json:"text,omitempty"`
+	Done *bool   `json:"done,omitempty"`
+}
+
+// ChecklistItemMove is the payload moving an item to a zero based position, a position past the end moves it last
+type ChecklistItemMove struct {
+	Position *int `json:"position"`
+}
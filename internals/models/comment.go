@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	DefaultCommentLimit = 50
+	MaxCommentLimit     = 200
+
+	// MaxCommentLength is the number of characters a comment body can have
+	MaxCommentLength = 10000
+)
+
+type Comment struct {
+	ID        primitive.ObjectID `json:"id"`
+	TaskID    string             `json:"taskId"`
+	Author    string             `json:"author"`
+	Body      string             `json:"body"`
+	History   []CommentRevision  `json:"history,omitempty"`
+	CreatedAt time.Time          `json:"createdAt"`
+	UpdatedAt time.Time          `json:"updatedAt"`
+	Version   int64              `json:"version"`
+}
+
+type CommentRevision struct {
+	Body     string    `json:"body"`
+	EditedAt time.Time `json:"editedAt"`
+}
+
+// CommentQuery is a page of the comments of a task, the oldest first
+type CommentQuery struct {
+	Limit  int64
+	Cursor string
+}
+
+type CommentList struct {
+	Total      int64      `json:"total"`
+	Comments   []*Comment `json:"comments"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	CustomFieldText   = "text"
+	CustomFieldNumber = "number"
+	CustomFieldDate   = "date"
+	CustomFieldEnum   = "enum"
+	CustomFieldUser   = "user"
+	CustomFieldBool   = "bool"
+
+	// MaxCustomFields is the number of custom fields a workspace can define
+	MaxCustomFields = 50
+	// MaxCustomFieldOptions is the number of options an enum field can have
+	MaxCustomFieldOptions = 100
+	// MaxCustomTextLength is the number of characters of a text value, an enum option or a user
+	MaxCustomTextLength = 1000
+
+	// CustomFieldParamPrefix prefixes the key of a custom field in the sort and filter parameters of the task list,
+	// e.g. sort=-field.points or field.env=prod
+	CustomFieldParamPrefix = "field."
+)
+
+// CustomFieldTypes are the types a custom field can have
+var CustomFieldTypes = []string{CustomFieldText, CustomFieldNumber, CustomFieldDate, CustomFieldEnum, CustomFieldUser, CustomFieldBool}
+
+// CustomField defines a field the tasks of the workspace can have a value for in their customFields, under its key.
+// the key and the type never change, the value of a task stays valid for the field it was written for
+type CustomField struct {
+	ID       primitive.ObjectID `json:"id"`
+	Key      string             `json:"key"`
+	Name     string             `json:"name"`
+	Type     string             `json:"type"`
+	Required bool               `json:"required"`
+	// Default is the value of the field on tasks created without one
+	Default interface{} `json:"default,omitempty"`
+	// Options are the values an enum field can take
+	Options   []string  `json:"options,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CustomFieldFilter selects the tasks by the value of a custom field, the values are any of the given values and
+// the range is half open like the date ranges of TaskQuery. they are read as strings and typed by the service
+type CustomFieldFilter struct {
+	Key    string
+	Values []interface{}
+	Gte    interface{}
+	Lt     interface{}
+}
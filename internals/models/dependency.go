@@ -0,0 +1,26 @@
+package models
+
+// DependencyLink is the payload linking a task to a task that blocks it
+type DependencyLink struct {
+	BlockerID string `json:"blockerId"`
+}
+
+// DependencyGraph is the task with every task blocking it and every task it blocks, transitively.
+// an edge goes from the blocking task to the blocked task
+type DependencyGraph struct {
+	Nodes []*DependencyNode `json:"nodes"`
+	Edges []*DependencyEdge `json:"edges"`
+}
+
+type DependencyNode struct {
+	ID     string `json:"id"`
+	Key    string `json:"key,omitempty"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+	Done   bool   `json:"done"`
+}
+
+type DependencyEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
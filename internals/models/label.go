@@ -0,0 +1,39 @@
+package models
+
+const (
+	// MaxLabelsPerTask is the number of labels a task can have
+	MaxLabelsPerTask = 20
+	// MaxLabelLength is the number of characters a label can have
+	MaxLabelLength = 50
+
+	DefaultLabelLimit = 50
+	MaxLabelLimit     = 200
+
+	// LabelMatchAny selects the tasks having one of the labels of the filter, LabelMatchAll those having every one
+	LabelMatchAny = "any"
+	LabelMatchAll = "all"
+)
+
+// Label is a label in use in the workspace with the number of tasks having it
+type Label struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// LabelQuery selects the labels starting with the prefix, the most used first
+type LabelQuery struct {
+	Prefix string
+	Limit  int64
+}
+
+type LabelList struct {
+	Labels []*Label `json:"labels"`
+}
+
+// LabelRename replaces the label From by To on every task, the labels are merged when a task has both
+type LabelRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Tasks is the number of tasks that were changed
+	Tasks int64 `json:"tasks"`
+}
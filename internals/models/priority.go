@@ -0,0 +1,27 @@
+package models
+
+const (
+	PriorityLow    = "low"
+	PriorityMedium = "medium"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+// ranks of the priorities in ascending order, tasks without a priority rank 0
+var priorityRanks = map[string]int{
+	PriorityLow:    1,
+	PriorityMedium: 2,
+	PriorityHigh:   3,
+	PriorityUrgent: 4,
+}
+
+// function to check the priority is one of the enumerated values, empty means no priority
+func ValidPriority(priority string) bool {
+	_, ok := priorityRanks[priority]
+	return ok || len(priority) == 0
+}
+
+// function to get the rank tasks are sorted by, so priorities order by urgency and not alphabetically
+func PriorityRank(priority string) int {
+	return priorityRanks[priority]
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"TaskSvc/internals/workflow"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Project struct {
+	ID          primitive.ObjectID `json:"id"`
+	Key         string             `json:"key"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Workflow    *workflow.Workflow `json:"workflow,omitempty"`
+	// TaskCount is the number of task keys allocated in the project, deleted tasks included
+	TaskCount int64     `json:"taskCount"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -11,6 +11,47 @@ type Task struct {
 	Title       string             `json:"title" bson:"title"`
 	Description string             `json:"description" bson:"description"`
 	Status      string             `json:"status" bson:"status"`
-	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
-	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+	Priority    string             `json:"priority,omitempty" bson:"priority,omitempty"`
+	StartDate   *time.Time         `json:"startDate,omitempty" bson:"startDate,omitempty"`
+	DueDate     *time.Time         `json:"dueDate,omitempty" bson:"dueDate,omitempty"`
+	CreatedBy   string             `json:"createdBy,omitempty" bson:"createdBy,omitempty"`
+	Reporter    string             `json:"reporter,omitempty" bson:"reporter,omitempty"`
+	Assignees   []string           `json:"assignees,omitempty" bson:"assignees,omitempty"`
+	Labels      []string           `json:"labels,omitempty" bson:"labels,omitempty"`
+	ProjectID   string             `json:"projectId,omitempty" bson:"projectId,omitempty"`
+	Key         string             `json:"key,omitempty" bson:"key,omitempty"`
+	ParentID    string             `json:"parentId,omitempty" bson:"parentId,omitempty"`
+	BlockedBy   []string           `json:"blockedBy,omitempty" bson:"blockedBy,omitempty"`
+	Attachments []Attachment       `json:"attachments,omitempty" bson:"attachments,omitempty"`
+	Checklist   []ChecklistItem    `json:"checklist,omitempty" bson:"checklist,omitempty"`
+	// estimates and the time spent are in seconds, timeSpent is the sum of the worklogs and cannot be written
+	OriginalEstimate  *int64 `json:"originalEstimate,omitempty" bson:"originalEstimate,omitempty"`
+	RemainingEstimate *int64 `json:"remainingEstimate,omitempty" bson:"remainingEstimate,omitempty"`
+	TimeSpent         int64  `json:"timeSpent,omitempty" bson:"timeSpent,omitempty"`
+	// Recurrence is the RRULE the task repeats on from its due date, recurrenceOf and nextOccurrenceId link the
+	// occurrences of the series and cannot be written
+	Recurrence       string `json:"recurrence,omitempty" bson:"recurrence,omitempty"`
+	RecurrenceOf     string `json:"recurrenceOf,omitempty" bson:"recurrenceOf,omitempty"`
+	NextOccurrenceID string `json:"nextOccurrenceId,omitempty" bson:"nextOccurrenceId,omitempty"`
+	// CustomFields holds the values of the custom fields of the workspace by their key
+	CustomFields map[string]interface{} `json:"customFields,omitempty" bson:"customFields,omitempty"`
+	WorkspaceID  string                 `json:"workspaceId,omitempty" bson:"workspaceId,omitempty"`
+	// DeletedAt and DeletedBy are only set on the tasks of the trash, they cannot be written
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+	DeletedBy string     `json:"deletedBy,omitempty" bson:"deletedBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt" bson:"updatedAt"`
+	Version   int64      `json:"version" bson:"version"`
+	// Rollup is computed when a single task is read, it is never stored
+	Rollup       *SubtaskRollup `json:"rollup,omitempty" bson:"-"`
+	CommentCount *int64         `json:"commentCount,omitempty" bson:"-"`
+	// ChecklistProgress is computed from the checklist whenever the task is mapped, lists included
+	ChecklistProgress *ChecklistProgress `json:"checklistProgress,omitempty" bson:"-"`
+}
+
+// SubtaskRollup is the progress of the direct subtasks of a task
+type SubtaskRollup struct {
+	CompletedSubtasks int64 `json:"completedSubtasks"`
+	TotalSubtasks     int64 `json:"totalSubtasks"`
+	PercentComplete   int64 `json:"percentComplete"`
 }
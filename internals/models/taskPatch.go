@@ -0,0 +1,13 @@
+package models
+
+const (
+	MergePatchContentType = "application/merge-patch+json"
+	JsonPatchContentType  = "application/json-patch+json"
+)
+
+// TaskPatch is a RFC 7396 merge patch or RFC 6902 json patch document for a task,
+// told apart by the request content type
+type TaskPatch struct {
+	ContentType string
+	Document    []byte
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+const (
+	SortByCreatedAt = "createdAt"
+	SortByUpdatedAt = "updatedAt"
+	SortByTitle     = "title"
+	SortByStatus    = "status"
+	SortByPriority  = "priority"
+	// SortByDeletedAt only applies to the trash
+	SortByDeletedAt = "deletedAt"
+
+	// CurrentUser stands for the caller in the assignee and created_by filters
+	CurrentUser = "me"
+
+	DefaultTaskLimit = 50
+	MaxTaskLimit     = 200
+
+	// DefaultMaxSubtaskDepth is the number of levels a task hierarchy can have, top level tasks included
+	DefaultMaxSubtaskDepth = 5
+)
+
+// TaskQuery holds the filters, sort and page of a task listing.
+// Date ranges are half open, the After bound is inclusive and the Before bound exclusive.
+type TaskQuery struct {
+	Limit         int64
+	Cursor        string
+	SortBy        string
+	SortDesc      bool
+	Status        []string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	DueAfter      *time.Time
+	DueBefore     *time.Time
+	Assignee      string
+	CreatedBy     string
+	ProjectID     string
+	ParentID      string
+	// Labels selects the tasks having any or all of the labels, as told by LabelMatch
+	Labels     []string
+	LabelMatch string
+	// CustomFields select the tasks by their custom field values, every filter must match.
+	// SortBy can also be a custom field key prefixed with CustomFieldParamPrefix
+	CustomFields []*CustomFieldFilter
+	// Overdue selects the tasks due before OverdueAt that are not in one of the DoneStatuses,
+	// both are set by the service from its clock and workflow
+	Overdue      bool
+	OverdueAt    time.Time
+	DoneStatuses []string
+	// Deleted lists the tasks in the trash instead of the live ones, the other filters apply to them alike
+	Deleted bool
+}
+
+type TaskList struct {
+	Total      int64   `json:"total"`
+	Tasks      []*Task `json:"tasks"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
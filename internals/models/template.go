@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// MaxTemplateTasks is the number of tasks a template creates at most, its root task included
+	MaxTemplateTasks = 100
+	// MaxTemplateDays bounds the relative dates of a template, about ten years
+	MaxTemplateDays = 3650
+)
+
+// Template is a blueprint of a task and its subtasks, like an onboarding or a release checklist.
+// titles and descriptions can use variables like {{.Customer}}, they are given when the template is instantiated
+type Template struct {
+	ID          primitive.ObjectID `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Task        *TemplateTask      `json:"task"`
+	// Variables are the variables the titles and descriptions use, in order of first use, they are read-only
+	Variables []string  `json:"variables,omitempty"`
+	CreatedBy string    `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TemplateTask is a task of a template, its dates are a number of days after the date the template is instantiated on
+type TemplateTask struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Priority    string   `json:"priority,omitempty"`
+	Assignees   []string `json:"assignees,omitempty"`
+	Labels      []string `json:"labels,omitempty"`
+	// Checklist holds the text of the items, they start undone
+	Checklist        []string        `json:"checklist,omitempty"`
+	StartInDays      *int            `json:"startInDays,omitempty"`
+	DueInDays        *int            `json:"dueInDays,omitempty"`
+	OriginalEstimate *int64          `json:"originalEstimate,omitempty"`
+	Subtasks         []*TemplateTask `json:"subtasks,omitempty"`
+}
+
+// TemplateInstantiation holds the values of the variables of the template and the date the relative dates count from,
+// now by default. the tasks are created in the project when one is given
+type TemplateInstantiation struct {
+	Variables map[string]string `json:"variables"`
+	Start     *time.Time        `json:"start"`
+	ProjectID string            `json:"projectId"`
+}
+
+// TemplateInstance lists the tasks created from a template, the root task first then its subtasks level by level
+type TemplateInstance struct {
+	ID      string   `json:"id"`
+	TaskIDs []string `json:"taskIds"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// MaxWorklogNoteLength is the number of characters the note of a worklog can have
+	MaxWorklogNoteLength = 2000
+
+	TimeReportByUser    = "user"
+	TimeReportByProject = "project"
+	TimeReportByTask    = "task"
+	TimeReportByDay     = "day"
+)
+
+// TimeReportGroups are the dimensions a time report can be grouped by, in the order of the report columns
+var TimeReportGroups = []string{TimeReportByUser, TimeReportByProject, TimeReportByTask, TimeReportByDay}
+
+// Worklog records time spent on a task, durations are in seconds
+type Worklog struct {
+	ID        primitive.ObjectID `json:"id"`
+	TaskID    string             `json:"taskId"`
+	ProjectID string             `json:"projectId,omitempty"`
+	Author    string             `json:"author"`
+	Started   time.Time          `json:"started"`
+	Duration  int64              `json:"duration"`
+	Note      string             `json:"note,omitempty"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+// WorklogInput is the payload of a worklog, started defaults to the duration before now so the work ends now
+type WorklogInput struct {
+	Duration int64      `json:"duration"`
+	Started  *time.Time `json:"started"`
+	Note     string     `json:"note"`
+}
+
+// Timer is a running timer of a user on a task, stopping it records a worklog
+type Timer struct {
+	ID        primitive.ObjectID `json:"id"`
+	TaskID    string             `json:"taskId"`
+	Subject   string             `json:"subject"`
+	StartedAt time.Time          `json:"startedAt"`
+}
+
+// TimeReportQuery selects the worklogs started in the half open range [From, To) and groups them,
+// without groups the report is a single row with the totals
+type TimeReportQuery struct {
+	From      *time.Time
+	To        *time.Time
+	User      string
+	ProjectID string
+	GroupBy   []string
+}
+
+// TimeReportRow is the time logged in a group, only the grouped dimensions are set. day is in UTC
+type TimeReportRow struct {
+	User      string `json:"user,omitempty"`
+	ProjectID string `json:"projectId,omitempty"`
+	TaskID    string `json:"taskId,omitempty"`
+	Day       string `json:"day,omitempty"`
+	Duration  int64  `json:"duration"`
+	Worklogs  int64  `json:"worklogs"`
+}
+
+type TimeReport struct {
+	GroupBy  []string         `json:"groupBy"`
+	Rows     []*TimeReportRow `json:"rows"`
+	Duration int64            `json:"duration"`
+}
@@ -0,0 +1,13 @@
+package recurrence_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestRecurrence(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Recurrence Suite")
+}
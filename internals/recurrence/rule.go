@@ -0,0 +1,205 @@
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	Daily   = "DAILY"
+	Weekly  = "WEEKLY"
+	Monthly = "MONTHLY"
+
+	// MaxInterval bounds INTERVAL, a rule repeats at least every 365 periods
+	MaxInterval = 365
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+var weekdayNames = []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Rule is the subset of the RFC 5545 recurrence rules tasks can repeat on: daily, weekly on some weekdays and
+// monthly on some days of the month, every Interval periods. Weeks start on monday and a negative month day
+// counts from the end of the month, -1 is the last day. The occurrences keep the time of day of the first one
+type Rule struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+}
+
+// function to parse a rule like FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH, the RRULE: prefix is optional.
+// the parts outside of the subset are rejected rather than ignored
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "RRULE:")
+	if len(value) == 0 {
+		return nil, fmt.Errorf("the rule is empty")
+	}
+	rule := &Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		name, argument, ok := strings.Cut(part, "=")
+		if !ok || len(argument) == 0 {
+			return nil, fmt.Errorf("invalid rule part %s", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%s is given twice", name)
+		}
+		seen[name] = true
+		switch name {
+		case "FREQ":
+			if argument != Daily && argument != Weekly && argument != Monthly {
+				return nil, fmt.Errorf("FREQ must be DAILY, WEEKLY or MONTHLY")
+			}
+			rule.Freq = argument
+		case "INTERVAL":
+			interval, err := strconv.Atoi(argument)
+			if err != nil || interval < 1 || interval > MaxInterval {
+				return nil, fmt.Errorf("INTERVAL must be between 1 and %d", MaxInterval)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(argument, ",") {
+				weekday, ok := weekdays[day]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY weekday %s", day)
+				}
+				if !containsWeekday(rule.ByDay, weekday) {
+					rule.ByDay = append(rule.ByDay, weekday)
+				}
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(argument, ",") {
+				monthDay, err := strconv.Atoi(day)
+				if err != nil || monthDay == 0 || monthDay < -31 || monthDay > 31 {
+					return nil, fmt.Errorf("BYMONTHDAY must be between 1 and 31 or -31 and -1")
+				}
+				if !containsInt(rule.ByMonthDay, monthDay) {
+					rule.ByMonthDay = append(rule.ByMonthDay, monthDay)
+				}
+			}
+		default:
+			return nil, fmt.Errorf("%s is not supported", name)
+		}
+	}
+	if len(rule.Freq) == 0 {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if len(rule.ByDay) > 0 && rule.Freq != Weekly {
+		return nil, fmt.Errorf("BYDAY is only supported with FREQ=WEEKLY")
+	}
+	if len(rule.ByMonthDay) > 0 && rule.Freq != Monthly {
+		return nil, fmt.Errorf("BYMONTHDAY is only supported with FREQ=MONTHLY")
+	}
+	// monday first, like the weeks
+	sort.Slice(rule.ByDay, func(i, j int) bool { return weekdayIndex(rule.ByDay[i]) < weekdayIndex(rule.ByDay[j]) })
+	sort.Ints(rule.ByMonthDay)
+	return rule, nil
+}
+
+// function to format the rule in its canonical form, the one tasks store
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = weekdayNames[day]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	return strings.Join(parts, ";")
+}
+
+// function to get the first occurrence strictly after the given one, in UTC at the same time of day.
+// the given time is taken as an occurrence, the interval counts from its day, week or month
+func (r *Rule) Next(after time.Time) time.Time {
+	after = after.UTC()
+	switch r.Freq {
+	case Daily:
+		return after.AddDate(0, 0, r.Interval)
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return after.AddDate(0, 0, 7*r.Interval)
+		}
+		// a later day of the same week, or the first day of the week interval weeks later
+		for _, day := range r.ByDay {
+			if weekdayIndex(day) > weekdayIndex(after.Weekday()) {
+				return after.AddDate(0, 0, weekdayIndex(day)-weekdayIndex(after.Weekday()))
+			}
+		}
+		weekStart := after.AddDate(0, 0, -weekdayIndex(after.Weekday()))
+		return weekStart.AddDate(0, 0, 7*r.Interval+weekdayIndex(r.ByDay[0]))
+	default:
+		days := r.ByMonthDay
+		if len(days) == 0 {
+			days = []int{after.Day()}
+		}
+		// months without any of the days, like february for the 30th, are skipped. every day exists at least
+		// once in 12 years of months, whatever the interval
+		for months := 0; months <= 12*12*r.Interval; months += r.Interval {
+			year, month, _ := after.Date()
+			first := time.Date(year, month+time.Month(months), 1, after.Hour(), after.Minute(), after.Second(), after.Nanosecond(), time.UTC)
+			for _, day := range monthDays(first, days) {
+				if occurrence := first.AddDate(0, 0, day-1); occurrence.After(after) {
+					return occurrence
+				}
+			}
+		}
+		return time.Time{}
+	}
+}
+
+// function to resolve the days of the month the rule falls on in the month starting at first, in order
+func monthDays(first time.Time, days []int) []int {
+	length := first.AddDate(0, 1, -1).Day()
+	resolved := []int{}
+	for _, day := range days {
+		if day < 0 {
+			day = length + day + 1
+		}
+		if day >= 1 && day <= length && !containsInt(resolved, day) {
+			resolved = append(resolved, day)
+		}
+	}
+	sort.Ints(resolved)
+	return resolved
+}
+
+// function to number the weekdays from monday
+func weekdayIndex(day time.Weekday) int {
+	return (int(day) + 6) % 7
+}
+
+func containsWeekday(days []time.Weekday, day time.Weekday) bool {
+	for _, candidate := range days {
+		if candidate == day {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, value int) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+package recurrence_test
+
+import (
+	"TaskSvc/internals/recurrence"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rule", func() {
+	next := func(value string, after time.Time, count int) []time.Time {
+		rule, err := recurrence.Parse(value)
+		Expect(err).NotTo(HaveOccurred())
+		occurrences := []time.Time{}
+		for i := 0; i < count; i++ {
+			after = rule.Next(after)
+			occurrences = append(occurrences, after)
+		}
+		return occurrences
+	}
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 9, 30, 0, 0, time.UTC)
+	}
+
+	It("parses the supported subset into its canonical form", func() {
+		rule, err := recurrence.Parse("rrule:freq=weekly;byday=fr,mo,fr;interval=1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rule.String()).To(Equal("FREQ=WEEKLY;BYDAY=MO,FR"))
+		rule, err = recurrence.Parse("FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=-1,15")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rule.String()).To(Equal("FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=-1,15"))
+
+		for _, invalid := range []string{"", "FREQ=YEARLY", "INTERVAL=2", "FREQ=DAILY;INTERVAL=0", "FREQ=DAILY;BYDAY=MO",
+			"FREQ=WEEKLY;BYDAY=XX", "FREQ=MONTHLY;BYMONTHDAY=32", "FREQ=DAILY;COUNT=3", "FREQ=DAILY;FREQ=WEEKLY", "FREQ"} {
+			_, err := recurrence.Parse(invalid)
+			Expect(err).To(HaveOccurred(), invalid)
+		}
+	})
+
+	It("repeats every interval days", func() {
+		Expect(next("FREQ=DAILY;INTERVAL=2", day(2024, 2, 28), 2)).To(Equal([]time.Time{day(2024, 3, 1), day(2024, 3, 3)}))
+	})
+
+	It("repeats on the weekdays of every interval weeks", func() {
+		// 2024-05-01 is a wednesday
+		Expect(next("FREQ=WEEKLY;BYDAY=MO,FR", day(2024, 5, 1), 3)).To(Equal([]time.Time{day(2024, 5, 3), day(2024, 5, 6), day(2024, 5, 10)}))
+		Expect(next("FREQ=WEEKLY;INTERVAL=2;BYDAY=MO", day(2024, 5, 6), 2)).To(Equal([]time.Time{day(2024, 5, 20), day(2024, 6, 3)}))
+		Expect(next("FREQ=WEEKLY", day(2024, 5, 1), 1)).To(Equal([]time.Time{day(2024, 5, 8)}))
+	})
+
+	It("repeats on the days of every interval months and skips the months without them", func() {
+		Expect(next("FREQ=MONTHLY;BYMONTHDAY=1,15", day(2024, 5, 10), 3)).To(Equal([]time.Time{day(2024, 5, 15), day(2024, 6, 1), day(2024, 6, 15)}))
+		Expect(next("FREQ=MONTHLY;BYMONTHDAY=-1", day(2024, 1, 31), 2)).To(Equal([]time.Time{day(2024, 2, 29), day(2024, 3, 31)}))
+		Expect(next("FREQ=MONTHLY", day(2024, 1, 31), 2)).To(Equal([]time.Time{day(2024, 3, 31), day(2024, 5, 31)}))
+		Expect(next("FREQ=MONTHLY;INTERVAL=12;BYMONTHDAY=29", day(2024, 2, 29), 1)).To(Equal([]time.Time{day(2028, 2, 29)}))
+	})
+})
@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// a lease outlives a few missed runs, a replica that stops without releasing it hands the job over after that
+const leaseRuns = 3
+
+// Job is the work done on every run of a background job. it must be idempotent, a run can be cut short
+// by a restart and repeated by the next holder of the lease
+type Job func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      Job
+}
+
+// Scheduler runs background jobs on an interval in this process, each under a lease of its name so only one
+// of the replicas sharing the storage runs a job at a time
+type Scheduler struct {
+	leases db.LeaseDbService
+	holder string
+	clock  commons.Clock
+	jobs   []*job
+	wg     sync.WaitGroup
+}
+
+type Option func(*Scheduler)
+
+// option to replace the system clock the leases expire on
+func WithClock(clock commons.Clock) Option {
+	return func(s *Scheduler) {
+		s.clock = clock
+	}
+}
+
+func New(leases db.LeaseDbService, holder string, opts ...Option) *Scheduler {
+	scheduler := &Scheduler{leases: leases, holder: holder, clock: commons.SystemClock}
+	for _, opt := range opts {
+		opt(scheduler)
+	}
+	return scheduler
+}
+
+// function to name this process as a lease holder, the hostname with a random suffix
+// so two processes on the same host are told apart
+func Holder() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "task-svc"
+	}
+	return fmt.Sprintf("%s-%s", hostname, primitive.NewObjectID().Hex())
+}
+
+// function to register a job, before Start
+func (s *Scheduler) Add(name string, interval time.Duration, run Job) {
+	s.jobs = append(s.jobs, &job{name: name, interval: interval, run: run})
+}
+
+// function to run every job in its own goroutine, at once and then on its interval, until the context is done
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// function to wait for the jobs to stop once the context of Start is done, their leases are released by then
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job *job) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+	for {
+		runCtx, logger := apploggers.NewLoggerWithCorrelationid(ctx, "")
+		if _, err := s.runOnce(runCtx, job); err != nil {
+			logger.Errorf("job %s failed: %v", job.name, err)
+		}
+		select {
+		case <-ctx.Done():
+			// the context is done, the release must not be
+			releaseCtx, logger := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+			if err := s.leases.ReleaseLease(releaseCtx, job.name, s.holder); err != nil {
+				logger.Errorf("failed to release the lease of job %s: %v", job.name, err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// function to run the job when this process has its lease or can take it, every run extends the lease.
+// it returns whether the job ran
+func (s *Scheduler) runOnce(ctx context.Context, job *job) (bool, error) {
+	now := s.clock.Now().UTC()
+	acquired, err := s.leases.AcquireLease(ctx, job.name, s.holder, now, now.Add(leaseRuns*job.interval))
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire the lease: %v", err)
+	}
+	if !acquired {
+		return false, nil
+	}
+	return true, job.run(ctx)
+}
@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"TaskSvc/internals/db"
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+var _ = Describe("Scheduler", func() {
+	var (
+		ctx    context.Context
+		clock  *fakeClock
+		leases db.LeaseDbService
+		runs   map[string]int
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		clock = &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+		leases = db.NewKVLeaseDbService(db.NewMemoryStore())
+		runs = map[string]int{}
+	})
+
+	newJob := func(holder string) *job {
+		return &job{name: "recurrence", interval: time.Minute, run: func(ctx context.Context) error {
+			runs[holder]++
+			return nil
+		}}
+	}
+
+	It("runs a job on the replica holding its lease only", func() {
+		first := New(leases, "replica-1", WithClock(clock))
+		second := New(leases, "replica-2", WithClock(clock))
+
+		Expect(first.runOnce(ctx, newJob("replica-1"))).To(BeTrue())
+		Expect(second.runOnce(ctx, newJob("replica-2"))).To(BeFalse())
+		clock.now = clock.now.Add(2 * time.Minute)
+		Expect(first.runOnce(ctx, newJob("replica-1"))).To(BeTrue())
+		Expect(second.runOnce(ctx, newJob("replica-2"))).To(BeFalse())
+		Expect(runs).To(Equal(map[string]int{"replica-1": 2}))
+	})
+
+	It("hands the job over once the lease expires", func() {
+		first := New(leases, "replica-1", WithClock(clock))
+		second := New(leases, "replica-2", WithClock(clock))
+
+		Expect(first.runOnce(ctx, newJob("replica-1"))).To(BeTrue())
+		clock.now = clock.now.Add(leaseRuns * time.Minute)
+		Expect(second.runOnce(ctx, newJob("replica-2"))).To(BeTrue())
+		Expect(first.runOnce(ctx, newJob("replica-1"))).To(BeFalse())
+	})
+
+	It("reports the failure of a run and keeps the lease", func() {
+		scheduler := New(leases, "replica-1", WithClock(clock))
+		ran, err := scheduler.runOnce(ctx, &job{name: "recurrence", interval: time.Minute, run: func(ctx context.Context) error {
+			return fmt.Errorf("run-error")
+		}})
+		Expect(ran).To(BeTrue())
+		Expect(err).To(MatchError("run-error"))
+		Expect(New(leases, "replica-2", WithClock(clock)).runOnce(ctx, newJob("replica-2"))).To(BeFalse())
+	})
+
+	It("releases the leases when it stops", func() {
+		var count int32
+		scheduler := New(leases, "replica-1")
+		scheduler.Add("recurrence", time.Hour, func(ctx context.Context) error {
+			atomic.AddInt32(&count, 1)
+			return nil
+		})
+		runCtx, cancel := context.WithCancel(ctx)
+		scheduler.Start(runCtx)
+		Eventually(func() int32 { return atomic.LoadInt32(&count) }).Should(Equal(int32(1)))
+		cancel()
+		scheduler.Wait()
+
+		Expect(New(leases, "replica-2").runOnce(ctx, newJob("replica-2"))).To(BeTrue())
+	})
+})
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// number of times the metadata of an upload is written again when the task changed in the meantime
+const attachmentWriteAttempts = 3
+
+type AttachmentService interface {
+	// AddAttachment stores the content and records the attachment on the task, version is the If-Match precondition or 0
+	AddAttachment(context context.Context, taskId string, upload *models.AttachmentUpload, version int64) (*models.Attachment, error)
+	// GetAttachment returns the attachment and its content, the caller closes the content
+	GetAttachment(context context.Context, taskId string, attachmentId string) (*models.Attachment, io.ReadSeekCloser, error)
+	DeleteAttachment(context context.Context, taskId string, attachmentId string, version int64) error
+}
+
+type attachmentService struct {
+	tasks        db.DbService
+	blobs        db.BlobStore
+	clock        commons.Clock
+	maxSize      int64
+	contentTypes []string
+}
+
+type AttachmentServiceOption func(*attachmentService)
+
+// option to change the size and the content types of the attachments, a trailing /* matches every subtype
+func WithAttachmentLimits(maxSize int64, contentTypes []string) AttachmentServiceOption {
+	return func(s *attachmentService) {
+		s.maxSize = maxSize
+		s.contentTypes = contentTypes
+	}
+}
+
+func NewAttachmentService(tasks db.DbService, blobs db.BlobStore, opts ...AttachmentServiceOption) AttachmentService {
+	service := &attachmentService{
+		tasks:        tasks,
+		blobs:        blobs,
+		clock:        commons.SystemClock,
+		maxSize:      models.DefaultMaxAttachmentSize,
+		contentTypes: models.DefaultAttachmentTypes,
+	}
+	for _, opt := range opts {
+		opt(service)
+	}
+	return service
+}
+
+// function to stream the content to the blob store while it is hashed and counted, the content type is sniffed
+// from the first bytes rather than trusted from the client. the blob is removed again when the task cannot record it
+func (s *attachmentService) AddAttachment(ctx context.Context, taskId string, upload *models.AttachmentUpload, version int64) (*models.Attachment, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	name, err := attachmentName(upload.Name)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.tasks.GetTaskById(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if version > 0 && current.Version != version {
+		return nil, apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", taskId))
+	}
+	if err := authorizeTaskWrite(ctx, current); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(upload.Content, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, apperrors.NewBadRequestError(fmt.Sprintf("failed to read the attachment: %v", err))
+	}
+	if n == 0 {
+		return nil, apperrors.NewValidationError("The attachment is empty", map[string]interface{}{"field": "file"})
+	}
+	head = head[:n]
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	if !s.allowedType(contentType) {
+		return nil, apperrors.NewUnsupportedMediaTypeError(fmt.Sprintf("attachments of type %s are not allowed", contentType),
+			map[string]interface{}{"contentType": contentType, "allowed": s.contentTypes})
+	}
+
+	attachment := dbmodels.AttachmentSchema{
+		ID:          primitive.NewObjectID(),
+		Name:        name,
+		ContentType: contentType,
+		UploadedBy:  appauth.GetSubject(ctx),
+		UploadedAt:  s.now(),
+	}
+	hash := sha256.New()
+	content := &sizeLimitedReader{reader: io.MultiReader(bytes.NewReader(head), upload.Content), remaining: s.maxSize}
+	if err := s.blobs.Put(ctx, attachment.ID.Hex(), io.TeeReader(content, hash)); err != nil {
+		if errors.Is(err, errAttachmentTooLarge) {
+			return nil, s.tooLargeError()
+		}
+		logger.Error(err)
+		return nil, err
+	}
+	attachment.Size = s.maxSize - content.remaining
+	attachment.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	for attempt := 1; ; attempt++ {
+		attachments := append(append([]dbmodels.AttachmentSchema{}, current.Attachments...), attachment)
+		_, err = s.tasks.PatchTask(ctx, taskId, map[string]interface{}{"attachments": attachments, "updatedAt": s.now()}, current.Version)
+		// without a precondition a concurrent change of the task is not a reason to lose the upload
+		if err == nil || version > 0 || attempt == attachmentWriteAttempts || !apperrors.Is(err, apperrors.PreconditionFailed) {
+			break
+		}
+		if current, err = s.tasks.GetTaskById(ctx, taskId); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		logger.Error(err)
+		if deleteErr := s.blobs.Delete(ctx, attachment.ID.Hex()); deleteErr != nil {
+			logger.Error(deleteErr)
+		}
+		return nil, err
+	}
+	return &commons.MapToAttachmentModels([]dbmodels.AttachmentSchema{attachment})[0], nil
+}
+
+func (s *attachmentService) GetAttachment(ctx context.Context, taskId string, attachmentId string) (*models.Attachment, io.ReadSeekCloser, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	task, err := s.tasks.GetTaskById(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return nil, nil, err
+	}
+	index := attachmentIndex(task, attachmentId)
+	if index < 0 {
+		return nil, nil, apperrors.NewNotFoundError(fmt.Sprintf("attachment %s not found on task %s", attachmentId, taskId))
+	}
+	content, err := s.blobs.Open(ctx, attachmentId)
+	if err != nil {
+		logger.Error(err)
+		return nil, nil, err
+	}
+	return &commons.MapToAttachmentModels(task.Attachments[index : index+1])[0], content, nil
+}
+
+// function to remove the attachment from the task, its uploader or a caller allowed to change the task can.
+// the blob is deleted once the task no longer refers to it, a failure is only logged
+func (s *attachmentService) DeleteAttachment(ctx context.Context, taskId string, attachmentId string, version int64) error {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	current, err := s.tasks.GetTaskById(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if version > 0 && current.Version != version {
+		return apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", taskId))
+	}
+	index := attachmentIndex(current, attachmentId)
+	if index < 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("attachment %s not found on task %s", attachmentId, taskId))
+	}
+	subject := appauth.GetSubject(ctx)
+	if len(subject) == 0 || subject != current.Attachments[index].UploadedBy {
+		if err := authorizeTaskWrite(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	attachments := append(append([]dbmodels.AttachmentSchema{}, current.Attachments[:index]...), current.Attachments[index+1:]...)
+	if _, err := s.tasks.PatchTask(ctx, taskId, map[string]interface{}{"attachments": attachments, "updatedAt": s.now()}, current.Version); err != nil {
+		logger.Error(err)
+		return err
+	}
+	if err := s.blobs.Delete(ctx, attachmentId); err != nil {
+		logger.Error(err)
+	}
+	return nil
+}
+
+func (s *attachmentService) allowedType(contentType string) bool {
+	for _, allowed := range s.contentTypes {
+		if allowed == contentType || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(contentType, strings.TrimSuffix(allowed, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *attachmentService) tooLargeError() error {
+	return apperrors.NewPayloadTooLargeError(fmt.Sprintf("Attachments must not be larger than %d bytes", s.maxSize),
+		map[string]interface{}{"maxSize": s.maxSize})
+}
+
+func (s *attachmentService) now() time.Time {
+	return s.clock.Now().UTC().Truncate(time.Millisecond)
+}
+
+// function to keep only the file name of the uploaded path, browsers on windows send the full path
+func attachmentName(name string) (string, error) {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, "\\", "/")))
+	if len(name) == 0 || name == "." || name == "/" {
+		return "", apperrors.NewValidationError("The file name is required", map[string]interface{}{"field": "file"})
+	}
+	if utf8.RuneCountInString(name) > models.MaxAttachmentNameLength {
+		return "", apperrors.NewValidationError(fmt.Sprintf("The file name must not be longer than %d characters", models.MaxAttachmentNameLength),
+			map[string]interface{}{"field": "file"})
+	}
+	return name, nil
+}
+
+func attachmentIndex(task *dbmodels.TaskSchema, attachmentId string) int {
+	for i, attachment := range task.Attachments {
+		if attachment.ID.Hex() == attachmentId {
+			return i
+		}
+	}
+	return -1
+}
+
+var errAttachmentTooLarge = errors.New("attachment too large")
+
+// sizeLimitedReader fails the read that goes past the limit, so the blob store drops the upload
+type sizeLimitedReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (r *sizeLimitedReader) Read(p []byte) (int, error) {
+	if r.remaining <= 0 {
+		// the limit is reached, one more byte tells an upload of exactly the limit from a larger one
+		var extra [1]byte
+		n, err := r.reader.Read(extra[:])
+		if n > 0 {
+			return 0, errAttachmentTooLarge
+		}
+		return 0, err
+	}
+	if int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	return n, err
+}
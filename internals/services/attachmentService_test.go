@@ -0,0 +1,120 @@
+package services
+
+import (
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/db"
+	"TaskSvc/internals/models"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AttachmentService", func() {
+	var (
+		ctx     context.Context
+		tasks   TaskService
+		taskDb  db.DbService
+		blobs   db.BlobStore
+		service AttachmentService
+		taskId  string
+		png     []byte
+	)
+
+	BeforeEach(func() {
+		ctx = asUser("user-1")
+		taskDb = db.NewKVDbService(db.NewMemoryStore())
+		var err error
+		blobs, err = db.NewLocalBlobStore(GinkgoT().TempDir())
+		Expect(err).NotTo(HaveOccurred())
+		tasks = NewTaskService(taskDb, WithBlobStore(blobs))
+		service = NewAttachmentService(taskDb, blobs, WithAttachmentLimits(1024, []string{"image/*", "text/plain"}))
+
+		taskId, err = tasks.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description"})
+		Expect(err).NotTo(HaveOccurred())
+		png = append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 100)...)
+	})
+
+	upload := func(ctx context.Context, name string, content []byte) (*models.Attachment, error) {
+		return service.AddAttachment(ctx, taskId, &models.AttachmentUpload{Name: name, Content: bytes.NewReader(content)}, 0)
+	}
+
+	It("records the metadata on the task and stores the content", func() {
+		attachment, err := upload(ctx, `C:\screenshots\login.png`, png)
+		Expect(err).NotTo(HaveOccurred())
+		sum := sha256.Sum256(png)
+		Expect(attachment.Name).To(Equal("login.png"))
+		Expect(attachment.Size).To(Equal(int64(len(png))))
+		Expect(attachment.ContentType).To(Equal("image/png"))
+		Expect(attachment.SHA256).To(Equal(hex.EncodeToString(sum[:])))
+		Expect(attachment.UploadedBy).To(Equal("user-1"))
+
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Attachments).To(HaveLen(1))
+		Expect(task.Attachments[0].ID).To(Equal(attachment.ID))
+
+		_, content, err := service.GetAttachment(ctx, taskId, attachment.ID.Hex())
+		Expect(err).NotTo(HaveOccurred())
+		defer content.Close()
+		Expect(io.ReadAll(content)).To(Equal(png))
+	})
+
+	It("sniffs the content type and rejects the types that are not allowed", func() {
+		_, err := upload(ctx, "report.png", []byte("%PDF-1.4 not an image"))
+		Expect(apperrors.Is(err, apperrors.UnsupportedMediaType)).To(BeTrue())
+
+		attachment, err := upload(ctx, "server.log", []byte("started\nstopped\n"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attachment.ContentType).To(Equal("text/plain"))
+	})
+
+	It("rejects content over the size limit and empty content", func() {
+		_, err := upload(ctx, "big.log", bytes.Repeat([]byte("a"), 1025))
+		Expect(apperrors.Is(err, apperrors.PayloadTooLarge)).To(BeTrue())
+		_, err = upload(ctx, "empty.log", nil)
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+
+		_, err = upload(ctx, "exact.log", bytes.Repeat([]byte("a"), 1024))
+		Expect(err).NotTo(HaveOccurred())
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Attachments).To(HaveLen(1))
+	})
+
+	It("checks the caller can change the task", func() {
+		_, err := upload(asUser("user-2"), "login.png", png)
+		Expect(apperrors.Is(err, apperrors.Forbidden)).To(BeTrue())
+	})
+
+	It("deletes the attachment and its content", func() {
+		attachment, err := upload(ctx, "login.png", png)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = service.DeleteAttachment(asUser("user-2"), taskId, attachment.ID.Hex(), 0)
+		Expect(apperrors.Is(err, apperrors.Forbidden)).To(BeTrue())
+		Expect(service.DeleteAttachment(asUser("user-2", appauth.MaintainerRole), taskId, attachment.ID.Hex(), 0)).To(Succeed())
+
+		_, _, err = service.GetAttachment(ctx, taskId, attachment.ID.Hex())
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		_, err = blobs.Open(ctx, attachment.ID.Hex())
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+	})
+
+	It("deletes the content along with the task", func() {
+		attachment, err := upload(ctx, "login.png", png)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tasks.DeleteTaskById(ctx, taskId, 0, false)).To(Succeed())
+		Expect(NewTrashService(taskDb, time.Hour, WithBlobStore(blobs)).DeleteTrashedTask(ctx, taskId, 0)).To(Succeed())
+
+		_, err = blobs.Open(ctx, attachment.ID.Hex())
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+	})
+})
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChecklistService changes the checklist of a task one item at a time, every change is a single atomic write
+// so two people ticking off different items never undo each other. version is the If-Match precondition or 0
+type ChecklistService interface {
+	AddChecklistItem(context context.Context, taskId string, input *models.ChecklistItemInput, version int64) (*models.Task, error)
+	UpdateChecklistItem(context context.Context, taskId string, itemId string, change *models.ChecklistItemChange, version int64) (*models.Task, error)
+	MoveChecklistItem(context context.Context, taskId string, itemId string, position int, version int64) (*models.Task, error)
+	RemoveChecklistItem(context context.Context, taskId string, itemId string, version int64) (*models.Task, error)
+}
+
+type checklistService struct {
+	tasks db.DbService
+	clock commons.Clock
+}
+
+func NewChecklistService(tasks db.DbService) ChecklistService {
+	return &checklistService{tasks: tasks, clock: commons.SystemClock}
+}
+
+func (s *checklistService) AddChecklistItem(ctx context.Context, taskId string, input *models.ChecklistItemInput, version int64) (*models.Task, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	text, err := checklistText(input.Text)
+	if err != nil {
+		return nil, err
+	}
+	position := -1
+	if input.Position != nil {
+		if *input.Position < 0 {
+			return nil, apperrors.NewValidationError("The position must not be negative", map[string]interface{}{"field": "position"})
+		}
+		position = *input.Position
+	}
+	if err := s.authorize(ctx, taskId); err != nil {
+		return nil, err
+	}
+
+	item := dbmodels.ChecklistItemSchema{ID: primitive.NewObjectID(), Text: text}
+	updated, err := s.tasks.AddChecklistItem(ctx, taskId, item, position, version, s.now())
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToModel(updated), nil
+}
+
+// function to change the text of the item or tick it off, ticking records who did it and when.
+// a change that leaves the item as it is writes nothing, so ticking off an item twice keeps the first record
+func (s *checklistService) UpdateChecklistItem(ctx context.Context, taskId string, itemId string, change *models.ChecklistItemChange, version int64) (*models.Task, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if change.Text == nil && change.Done == nil {
+		return nil, apperrors.NewValidationError("text or done is required", nil)
+	}
+	var itemChange dbmodels.ChecklistItemChange
+	if change.Text != nil {
+		text, err := checklistText(*change.Text)
+		if err != nil {
+			return nil, err
+		}
+		itemChange.Text = &text
+	}
+	current, err := s.tasks.GetTaskById(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if version > 0 && current.Version != version {
+		return nil, apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", taskId))
+	}
+	if err := authorizeTaskWrite(ctx, current); err != nil {
+		return nil, err
+	}
+	item := checklistItem(current, itemId)
+	if item == nil {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("checklist item %s not found on task %s", itemId, taskId))
+	}
+
+	if itemChange.Text != nil && *itemChange.Text == item.Text {
+		itemChange.Text = nil
+	}
+	if change.Done != nil && *change.Done != item.Done {
+		itemChange.Done = change.Done
+		if *change.Done {
+			doneAt := s.now()
+			itemChange.DoneBy = appauth.GetSubject(ctx)
+			itemChange.DoneAt = &doneAt
+		}
+	}
+	if itemChange.Text == nil && itemChange.Done == nil {
+		return commons.MapToModel(current), nil
+	}
+	updated, err := s.tasks.UpdateChecklistItem(ctx, taskId, itemId, itemChange, version, s.now())
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToModel(updated), nil
+}
+
+func (s *checklistService) MoveChecklistItem(ctx context.Context, taskId string, itemId string, position int, version int64) (*models.Task, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if position < 0 {
+		return nil, apperrors.NewValidationError("The position must not be negative", map[string]interface{}{"field": "position"})
+	}
+	if err := s.authorize(ctx, taskId); err != nil {
+		return nil, err
+	}
+	updated, err := s.tasks.MoveChecklistItem(ctx, taskId, itemId, position, version, s.now())
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToModel(updated), nil
+}
+
+func (s *checklistService) RemoveChecklistItem(ctx context.Context, taskId string, itemId string, version int64) (*models.Task, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if err := s.authorize(ctx, taskId); err != nil {
+		return nil, err
+	}
+	updated, err := s.tasks.RemoveChecklistItem(ctx, taskId, itemId, version, s.now())
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToModel(updated), nil
+}
+
+// function to check the caller can change the task, the precondition is left to the write itself
+func (s *checklistService) authorize(ctx context.Context, taskId string) error {
+	current, err := s.tasks.GetTaskById(ctx, taskId)
+	if err != nil {
+		apploggers.GetLoggerWithCorrelationid(ctx).Error(err)
+		return err
+	}
+	return authorizeTaskWrite(ctx, current)
+}
+
+func (s *checklistService) now() time.Time {
+	return s.clock.Now().UTC().Truncate(time.Millisecond)
+}
+
+// function to build the checklist a task is created with, the items get new ids and
+// the ones already done are recorded as done by the creator
+func newChecklist(ctx context.Context, items []models.ChecklistItem, now time.Time) ([]dbmodels.ChecklistItemSchema, error) {
+	if len(items) > models.MaxChecklistItems {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("A checklist cannot have more than %d items", models.MaxChecklistItems),
+			map[string]interface{}{"field": "checklist"})
+	}
+	checklist := make([]dbmodels.ChecklistItemSchema, 0, len(items))
+	for _, item := range items {
+		text, err := checklistText(item.Text)
+		if err != nil {
+			return nil, err
+		}
+		itemSchema := dbmodels.ChecklistItemSchema{ID: primitive.NewObjectID(), Text: text, Done: item.Done}
+		if item.Done {
+			doneAt := now
+			itemSchema.DoneBy = appauth.GetSubject(ctx)
+			itemSchema.DoneAt = &doneAt
+		}
+		checklist = append(checklist, itemSchema)
+	}
+	return checklist, nil
+}
+
+func checklistText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if len(text) == 0 {
+		return "", apperrors.NewValidationError("The text of a checklist item is required", map[string]interface{}{"field": "text"})
+	}
+	if utf8.RuneCountInString(text) > models.MaxChecklistTextLength {
+		return "", apperrors.NewValidationError(fmt.Sprintf("The text of a checklist item must not be longer than %d characters", models.MaxChecklistTextLength),
+			map[string]interface{}{"field": "text"})
+	}
+	if strings.IndexFunc(text, unicode.IsControl) >= 0 {
+		return "", apperrors.NewValidationError("The text of a checklist item must not contain control characters", map[string]interface{}{"field": "text"})
+	}
+	return text, nil
+}
+
+func checklistItem(task *dbmodels.TaskSchema, itemId string) *dbmodels.ChecklistItemSchema {
+	for i := range task.Checklist {
+		if task.Checklist[i].ID.Hex() == itemId {
+			return &task.Checklist[i]
+		}
+	}
+	return nil
+}
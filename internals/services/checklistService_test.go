@@ -0,0 +1,105 @@
+package services
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/db"
+	"TaskSvc/internals/models"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ChecklistService", func() {
+	var (
+		ctx     context.Context
+		tasks   TaskService
+		service ChecklistService
+		taskId  string
+	)
+
+	BeforeEach(func() {
+		ctx = asUser("user-1")
+		taskDb := db.NewKVDbService(db.NewMemoryStore())
+		tasks = NewTaskService(taskDb)
+		service = NewChecklistService(taskDb)
+		var err error
+		taskId, err = tasks.CreateTask(ctx, &models.Task{Title: "Release", Description: "Ship it", Checklist: []models.ChecklistItem{
+			{Text: " Build "},
+			{Text: "Tag", Done: true},
+		}})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	done := func(value bool) *models.ChecklistItemChange {
+		return &models.ChecklistItemChange{Done: &value}
+	}
+
+	It("creates the task with its checklist and reports the progress in lists", func() {
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Checklist).To(HaveLen(2))
+		Expect(task.Checklist[0].Text).To(Equal("Build"))
+		Expect(task.Checklist[1].DoneBy).To(Equal("user-1"))
+		Expect(task.Checklist[1].DoneAt).NotTo(BeNil())
+
+		list, err := tasks.GetTasks(ctx, &models.TaskQuery{Limit: models.DefaultTaskLimit, SortBy: models.SortByCreatedAt})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*list.Tasks[0].ChecklistProgress).To(Equal(models.ChecklistProgress{Done: 1, Total: 2}))
+	})
+
+	It("adds, ticks off, moves and removes items", func() {
+		position := 0
+		task, err := service.AddChecklistItem(ctx, taskId, &models.ChecklistItemInput{Text: "Test", Position: &position}, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Checklist[0].Text).To(Equal("Test"))
+		testId := task.Checklist[0].ID.Hex()
+
+		task, err = service.UpdateChecklistItem(asUser("user-1"), taskId, testId, done(true), task.Version)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Checklist[0].Done).To(BeTrue())
+		Expect(task.Checklist[0].DoneBy).To(Equal("user-1"))
+		Expect(*task.ChecklistProgress).To(Equal(models.ChecklistProgress{Done: 2, Total: 3}))
+
+		task, err = service.MoveChecklistItem(ctx, taskId, testId, 2, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Checklist[2].ID.Hex()).To(Equal(testId))
+
+		task, err = service.RemoveChecklistItem(ctx, taskId, testId, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Checklist).To(HaveLen(2))
+	})
+
+	It("keeps the first record when an item is ticked off twice", func() {
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		tagId := task.Checklist[1].ID.Hex()
+
+		updated, err := service.UpdateChecklistItem(asUser("user-2", "maintainer"), taskId, tagId, done(true), 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.Version).To(Equal(task.Version))
+		Expect(updated.Checklist[1].DoneBy).To(Equal("user-1"))
+	})
+
+	It("validates the text and the position", func() {
+		_, err := service.AddChecklistItem(ctx, taskId, &models.ChecklistItemInput{Text: "  "}, 0)
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		position := -1
+		_, err = service.AddChecklistItem(ctx, taskId, &models.ChecklistItemInput{Text: "Test", Position: &position}, 0)
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = service.UpdateChecklistItem(ctx, taskId, "64b000000000000000000000", &models.ChecklistItemChange{}, 0)
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = service.UpdateChecklistItem(ctx, taskId, "64b000000000000000000000", done(true), 0)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+	})
+
+	It("only lets the people on the task change the checklist", func() {
+		_, err := service.AddChecklistItem(asUser("user-2"), taskId, &models.ChecklistItemInput{Text: "Test"}, 0)
+		Expect(apperrors.Is(err, apperrors.Forbidden)).To(BeTrue())
+	})
+
+	It("keeps the checklist out of task patches", func() {
+		_, err := tasks.PatchTask(ctx, taskId, mergePatch(`{"checklist": []}`), 0)
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+})
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CommentService interface {
+	GetComments(context context.Context, taskId string, query *models.CommentQuery) (*models.CommentList, error)
+	AddComment(context context.Context, taskId string, comment *models.Comment) (*models.Comment, error)
+	UpdateComment(context context.Context, taskId string, commentId string, comment *models.Comment, version int64) (*models.Comment, error)
+	DeleteComment(context context.Context, taskId string, commentId string) error
+}
+
+type commentService struct {
+	dbservice db.CommentDbService
+	tasks     db.DbService
+	clock     commons.Clock
+}
+
+// function to build the comment service, the tasks are read to check the task of the path exists in the workspace
+func NewCommentService(dbservice db.CommentDbService, tasks db.DbService) CommentService {
+	return &commentService{dbservice: dbservice, tasks: tasks, clock: commons.SystemClock}
+}
+
+func (s *commentService) GetComments(ctx context.Context, taskId string, query *models.CommentQuery) (*models.CommentList, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if _, err := s.tasks.GetTaskById(ctx, taskId); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	page, err := s.dbservice.GetComments(ctx, taskId, query)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	comments := make([]*models.Comment, len(page.Comments))
+	for i, commentSchema := range page.Comments {
+		comments[i] = commons.MapToCommentModel(commentSchema)
+	}
+	return &models.CommentList{Total: page.Total, Comments: comments, NextCursor: page.NextCursor}, nil
+}
+
+// function to add the comment to the task, the author is the caller whatever the client sent
+func (s *commentService) AddComment(ctx context.Context, taskId string, comment *models.Comment) (*models.Comment, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if err := validateComment(comment); err != nil {
+		return nil, err
+	}
+	if _, err := s.tasks.GetTaskById(ctx, taskId); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	now := s.now()
+	commentSchema := &dbmodels.CommentSchema{
+		TaskID:    taskId,
+		Author:    appauth.GetSubject(ctx),
+		Body:      comment.Body,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	commentId, err := s.dbservice.SaveComment(ctx, commentSchema)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	commentSchema.ID, _ = primitive.ObjectIDFromHex(commentId)
+	return commons.MapToCommentModel(commentSchema), nil
+}
+
+// function to replace the body of the comment, only its author can, the previous body is kept in the history
+func (s *commentService) UpdateComment(ctx context.Context, taskId string, commentId string, comment *models.Comment, version int64) (*models.Comment, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if err := validateComment(comment); err != nil {
+		return nil, err
+	}
+	current, err := s.dbservice.GetCommentById(ctx, taskId, commentId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if version > 0 && current.Version != version {
+		return nil, apperrors.NewPreconditionFailedError(fmt.Sprintf("comment %s has been modified", commentId))
+	}
+	if subject := appauth.GetSubject(ctx); len(subject) == 0 || subject != current.Author {
+		return nil, apperrors.NewForbiddenError(fmt.Sprintf("only the author can edit comment %s", commentId))
+	}
+	if comment.Body == current.Body {
+		return commons.MapToCommentModel(current), nil
+	}
+
+	now := s.now()
+	revision := dbmodels.CommentRevision{Body: current.Body, EditedAt: now}
+	current.Body = comment.Body
+	current.UpdatedAt = now
+	updated, err := s.dbservice.UpdateComment(ctx, current, revision, current.Version)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToCommentModel(updated), nil
+}
+
+// function to delete the comment, its author or a caller allowed to manage every task can
+func (s *commentService) DeleteComment(ctx context.Context, taskId string, commentId string) error {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	current, err := s.dbservice.GetCommentById(ctx, taskId, commentId)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	subject := appauth.GetSubject(ctx)
+	if !appauth.HasPermission(ctx, appauth.PermissionTaskManage) && (len(subject) == 0 || subject != current.Author) {
+		return apperrors.NewForbiddenError(fmt.Sprintf("only the author or a maintainer can delete comment %s", commentId))
+	}
+	if err := s.dbservice.DeleteCommentById(ctx, taskId, commentId); err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *commentService) now() time.Time {
+	return s.clock.Now().UTC().Truncate(time.Millisecond)
+}
+
+func validateComment(comment *models.Comment) error {
+	if len(strings.TrimSpace(comment.Body)) == 0 {
+		return apperrors.NewValidationError("Body is required", map[string]interface{}{"field": "body"})
+	}
+	if utf8.RuneCountInString(comment.Body) > models.MaxCommentLength {
+		return apperrors.NewValidationError(fmt.Sprintf("Body must not be longer than %d characters", models.MaxCommentLength),
+			map[string]interface{}{"field": "body"})
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/db"
+	"TaskSvc/internals/models"
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ = Describe("CommentService", func() {
+	var (
+		ctx      context.Context
+		tasks    TaskService
+		taskDb   db.DbService
+		service  CommentService
+		comments db.CommentDbService
+		taskId   string
+	)
+
+	BeforeEach(func() {
+		ctx = asUser("user-1")
+		store := db.NewMemoryStore()
+		taskDb = db.NewKVDbService(store)
+		comments = db.NewKVCommentDbService(store)
+		tasks = NewTaskService(taskDb, WithComments(comments))
+		service = NewCommentService(comments, taskDb)
+
+		var err error
+		taskId, err = tasks.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("records the caller as the author and counts the comments on the task", func() {
+		comment, err := service.AddComment(ctx, taskId, &models.Comment{Body: "Looks good", Author: "someone-else"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(comment.Author).To(Equal("user-1"))
+		Expect(comment.TaskID).To(Equal(taskId))
+
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*task.CommentCount).To(Equal(int64(1)))
+	})
+
+	It("rejects an empty or oversized body", func() {
+		_, err := service.AddComment(ctx, taskId, &models.Comment{Body: "  "})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = service.AddComment(ctx, taskId, &models.Comment{Body: strings.Repeat("a", models.MaxCommentLength+1)})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+
+	It("reports a missing task", func() {
+		_, err := service.AddComment(ctx, primitive.NewObjectID().Hex(), &models.Comment{Body: "Comment"})
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		_, err = service.GetComments(ctx, primitive.NewObjectID().Hex(), &models.CommentQuery{Limit: 10})
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+	})
+
+	It("lets only the author edit and keeps the previous bodies", func() {
+		comment, err := service.AddComment(ctx, taskId, &models.Comment{Body: "First"})
+		Expect(err).NotTo(HaveOccurred())
+		commentId := comment.ID.Hex()
+
+		_, err = service.UpdateComment(asUser("user-2", appauth.AdminRole), taskId, commentId, &models.Comment{Body: "Hijacked"}, 0)
+		Expect(apperrors.Is(err, apperrors.Forbidden)).To(BeTrue())
+
+		updated, err := service.UpdateComment(ctx, taskId, commentId, &models.Comment{Body: "Second"}, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updated.Body).To(Equal("Second"))
+		Expect(updated.History).To(HaveLen(1))
+		Expect(updated.History[0].Body).To(Equal("First"))
+
+		_, err = service.UpdateComment(ctx, taskId, commentId, &models.Comment{Body: "Third"}, 1)
+		Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+	})
+
+	It("lets the author or a maintainer delete", func() {
+		first, err := service.AddComment(ctx, taskId, &models.Comment{Body: "First"})
+		Expect(err).NotTo(HaveOccurred())
+		second, err := service.AddComment(ctx, taskId, &models.Comment{Body: "Second"})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = service.DeleteComment(asUser("user-2"), taskId, first.ID.Hex())
+		Expect(apperrors.Is(err, apperrors.Forbidden)).To(BeTrue())
+		Expect(service.DeleteComment(ctx, taskId, first.ID.Hex())).To(Succeed())
+		Expect(service.DeleteComment(asUser("user-2", appauth.MaintainerRole), taskId, second.ID.Hex())).To(Succeed())
+
+		list, err := service.GetComments(ctx, taskId, &models.CommentQuery{Limit: 10})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Total).To(BeZero())
+	})
+
+	It("keeps the comments of a task in the trash and deletes them along with it", func() {
+		_, err := service.AddComment(ctx, taskId, &models.Comment{Body: "Comment"})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tasks.DeleteTaskById(ctx, taskId, 0, false)).To(Succeed())
+		count, err := comments.CountComments(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(int64(1)))
+
+		Expect(NewTrashService(taskDb, time.Hour, WithComments(comments)).DeleteTrashedTask(ctx, taskId, 0)).To(Succeed())
+		count, err = comments.CountComments(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(BeZero())
+	})
+})
@@ -0,0 +1,308 @@
+package services
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// custom field keys are used in the query parameters of the task list, e.g. field.story_points
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+type CustomFieldService interface {
+	GetCustomFields(context context.Context) ([]*models.CustomField, error)
+	GetCustomFieldById(context context.Context, fieldId string) (*models.CustomField, error)
+	CreateCustomField(context context.Context, field *models.CustomField) (*models.CustomField, error)
+	UpdateCustomField(context context.Context, field *models.CustomField, fieldId string) (*models.CustomField, error)
+	// DeleteCustomField removes the definition and the values the tasks have for it
+	DeleteCustomField(context context.Context, fieldId string) error
+}
+
+type customFieldService struct {
+	dbservice db.CustomFieldDbService
+	tasks     db.DbService
+	clock     commons.Clock
+}
+
+func NewCustomFieldService(dbservice db.CustomFieldDbService, tasks db.DbService) CustomFieldService {
+	return &customFieldService{dbservice: dbservice, tasks: tasks, clock: commons.SystemClock}
+}
+
+func (s *customFieldService) GetCustomFields(ctx context.Context) ([]*models.CustomField, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	fieldSchemas, err := s.dbservice.GetCustomFields(ctx)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	fields := make([]*models.CustomField, len(fieldSchemas))
+	for i, fieldSchema := range fieldSchemas {
+		fields[i] = commons.MapToCustomFieldModel(fieldSchema)
+	}
+	return fields, nil
+}
+
+func (s *customFieldService) GetCustomFieldById(ctx context.Context, fieldId string) (*models.CustomField, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	fieldSchema, err := s.dbservice.GetCustomFieldById(ctx, fieldId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToCustomFieldModel(fieldSchema), nil
+}
+
+func (s *customFieldService) CreateCustomField(ctx context.Context, field *models.CustomField) (*models.CustomField, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	field.Key = strings.TrimSpace(field.Key)
+	if !customFieldKeyPattern.MatchString(field.Key) {
+		return nil, apperrors.NewValidationError("Key must be up to 40 lower case letters, digits and underscores starting with a letter",
+			map[string]interface{}{"field": "key"})
+	}
+	if !containsString(models.CustomFieldTypes, field.Type) {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("Type must be one of %s", strings.Join(models.CustomFieldTypes, ", ")),
+			map[string]interface{}{"field": "type"})
+	}
+	now := s.now()
+	fieldSchema := &dbmodels.CustomFieldSchema{
+		Key:       field.Key,
+		Type:      field.Type,
+		CreatedBy: appauth.GetSubject(ctx),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := setCustomFieldDefinition(fieldSchema, field); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.dbservice.GetCustomFields(ctx)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if len(existing) >= models.MaxCustomFields {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("A workspace can have %d custom fields at most", models.MaxCustomFields), nil)
+	}
+	fieldId, err := s.dbservice.SaveCustomField(ctx, fieldSchema)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	fieldSchema.ID, _ = primitive.ObjectIDFromHex(fieldId)
+	return commons.MapToCustomFieldModel(fieldSchema), nil
+}
+
+// function to replace the name, the required flag, the default and the options of the field.
+// the key and the type never change, so the values the tasks already have stay valid
+func (s *customFieldService) UpdateCustomField(ctx context.Context, field *models.CustomField, fieldId string) (*models.CustomField, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	current, err := s.dbservice.GetCustomFieldById(ctx, fieldId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if len(field.Key) > 0 && field.Key != current.Key {
+		return nil, apperrors.NewValidationError("Key is read-only", map[string]interface{}{"field": "key"})
+	}
+	if len(field.Type) > 0 && field.Type != current.Type {
+		return nil, apperrors.NewValidationError("Type is read-only", map[string]interface{}{"field": "type"})
+	}
+	if err := setCustomFieldDefinition(current, field); err != nil {
+		return nil, err
+	}
+	current.UpdatedAt = s.now()
+	if err := s.dbservice.UpdateCustomField(ctx, current); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToCustomFieldModel(current), nil
+}
+
+// function to delete the definition, then the values of the tasks. a failure to remove the values is returned
+// so the call can be repeated, the values of a field that no longer exists are never validated again
+func (s *customFieldService) DeleteCustomField(ctx context.Context, fieldId string) error {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	current, err := s.dbservice.GetCustomFieldById(ctx, fieldId)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if err := s.dbservice.DeleteCustomFieldById(ctx, fieldId); err != nil {
+		logger.Error(err)
+		return err
+	}
+	if _, err := s.tasks.UnsetCustomField(ctx, current.Key, s.now()); err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *customFieldService) now() time.Time {
+	return s.clock.Now().UTC().Truncate(time.Millisecond)
+}
+
+// function to check the rules shared by create and update and copy the changeable attributes to the schema,
+// whose key and type are already set
+func setCustomFieldDefinition(fieldSchema *dbmodels.CustomFieldSchema, field *models.CustomField) error {
+	name := strings.TrimSpace(field.Name)
+	if len(name) == 0 {
+		return apperrors.NewValidationError("Name is required", map[string]interface{}{"field": "name"})
+	}
+	options, err := customFieldOptions(fieldSchema.Type, field.Options)
+	if err != nil {
+		return err
+	}
+	fieldSchema.Name = name
+	fieldSchema.Required = field.Required
+	fieldSchema.Options = options
+	fieldSchema.Default = nil
+	if field.Default != nil {
+		if fieldSchema.Default, err = customFieldValue(fieldSchema, field.Default, "default"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// function to check the options of an enum field, the other types have none
+func customFieldOptions(fieldType string, options []string) ([]string, error) {
+	if fieldType != models.CustomFieldEnum {
+		if len(options) > 0 {
+			return nil, apperrors.NewValidationError("Only enum fields have options", map[string]interface{}{"field": "options"})
+		}
+		return nil, nil
+	}
+	if len(options) == 0 || len(options) > models.MaxCustomFieldOptions {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("An enum field must have 1 to %d options", models.MaxCustomFieldOptions),
+			map[string]interface{}{"field": "options"})
+	}
+	seen := map[string]bool{}
+	for _, option := range options {
+		if len(strings.TrimSpace(option)) == 0 || utf8.RuneCountInString(option) > models.MaxCustomTextLength {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("Options must be 1 to %d characters", models.MaxCustomTextLength),
+				map[string]interface{}{"field": "options"})
+		}
+		if seen[option] {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("Option %s is listed twice", option), map[string]interface{}{"field": "options"})
+		}
+		seen[option] = true
+	}
+	return options, nil
+}
+
+// function to check a value against the type of the field and convert it to the form it is stored in.
+// numbers are stored as doubles and dates as UTC timestamps at the millisecond precision mongo stores
+func customFieldValue(field *dbmodels.CustomFieldSchema, value interface{}, name string) (interface{}, error) {
+	invalid := func(message string) error {
+		return apperrors.NewValidationError(fmt.Sprintf("%s %s", name, message), map[string]interface{}{"field": name})
+	}
+	switch field.Type {
+	case models.CustomFieldText, models.CustomFieldUser:
+		text, ok := value.(string)
+		if !ok || len(strings.TrimSpace(text)) == 0 || utf8.RuneCountInString(text) > models.MaxCustomTextLength {
+			return nil, invalid(fmt.Sprintf("must be a string of 1 to %d characters", models.MaxCustomTextLength))
+		}
+		return text, nil
+	case models.CustomFieldNumber:
+		var number float64
+		switch typed := value.(type) {
+		case float64:
+			number = typed
+		case int:
+			number = float64(typed)
+		case int64:
+			number = float64(typed)
+		default:
+			return nil, invalid("must be a number")
+		}
+		if math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, invalid("must be a finite number")
+		}
+		return number, nil
+	case models.CustomFieldDate:
+		var date time.Time
+		switch typed := value.(type) {
+		case time.Time:
+			date = typed
+		case primitive.DateTime:
+			date = typed.Time()
+		case string:
+			parsed, err := time.Parse(time.RFC3339, typed)
+			if err != nil {
+				return nil, invalid("must be an RFC3339 timestamp")
+			}
+			date = parsed
+		default:
+			return nil, invalid("must be an RFC3339 timestamp")
+		}
+		return date.UTC().Truncate(time.Millisecond), nil
+	case models.CustomFieldEnum:
+		option, ok := value.(string)
+		if !ok || !containsString(field.Options, option) {
+			return nil, invalid(fmt.Sprintf("must be one of %s", strings.Join(field.Options, ", ")))
+		}
+		return option, nil
+	case models.CustomFieldBool:
+		flag, ok := value.(bool)
+		if !ok {
+			return nil, invalid("must be true or false")
+		}
+		return flag, nil
+	}
+	return nil, invalid(fmt.Sprintf("has an unknown type %s", field.Type))
+}
+
+// function to type a value of the task list query string like the values of the field are stored
+func customFieldParam(field *dbmodels.CustomFieldSchema, value interface{}, name string) (interface{}, error) {
+	text, ok := value.(string)
+	if !ok {
+		return customFieldValue(field, value, name)
+	}
+	switch field.Type {
+	case models.CustomFieldNumber:
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, apperrors.NewBadRequestError(fmt.Sprintf("%s must be a number", name))
+		}
+		value = number
+	case models.CustomFieldBool:
+		flag, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, apperrors.NewBadRequestError(fmt.Sprintf("%s must be true or false", name))
+		}
+		value = flag
+	}
+	typed, err := customFieldValue(field, value, name)
+	if err != nil {
+		// a filter on an option that does not exist matches nothing rather than failing
+		if field.Type == models.CustomFieldEnum {
+			return text, nil
+		}
+		return nil, apperrors.NewBadRequestError(err.Error())
+	}
+	return typed, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/db"
+	"TaskSvc/internals/models"
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CustomFieldService", func() {
+	var (
+		ctx     context.Context
+		tasks   TaskService
+		service CustomFieldService
+	)
+
+	BeforeEach(func() {
+		ctx = asUser("user-1")
+		store := db.NewMemoryStore()
+		taskDb := db.NewKVDbService(store)
+		fieldDb := db.NewKVCustomFieldDbService(store)
+		tasks = NewTaskService(taskDb, WithCustomFields(fieldDb))
+		service = NewCustomFieldService(fieldDb, taskDb)
+
+		_, err := service.CreateCustomField(ctx, &models.CustomField{Key: "points", Name: "Story points", Type: models.CustomFieldNumber})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = service.CreateCustomField(ctx, &models.CustomField{Key: "env", Name: "Environment", Type: models.CustomFieldEnum,
+			Options: []string{"dev", "prod"}, Required: true, Default: "dev"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	create := func(title string, values map[string]interface{}) (string, error) {
+		return tasks.CreateTask(ctx, &models.Task{Title: title, Description: "d", CustomFields: values})
+	}
+
+	It("validates the definitions", func() {
+		_, err := service.CreateCustomField(ctx, &models.CustomField{Key: "Points", Name: "Points", Type: models.CustomFieldNumber})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = service.CreateCustomField(ctx, &models.CustomField{Key: "size", Name: "Size", Type: "color"})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = service.CreateCustomField(ctx, &models.CustomField{Key: "size", Name: "Size", Type: models.CustomFieldEnum, Options: []string{"s", "s"}})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = service.CreateCustomField(ctx, &models.CustomField{Key: "size", Name: "Size", Type: models.CustomFieldNumber, Default: "big"})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = service.CreateCustomField(ctx, &models.CustomField{Key: "points", Name: "Points", Type: models.CustomFieldNumber})
+		Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+
+		fields, err := service.GetCustomFields(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = service.UpdateCustomField(ctx, &models.CustomField{Name: "Env", Type: models.CustomFieldText}, fields[0].ID.Hex())
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+
+	It("types the values, applies the defaults and enforces the required fields", func() {
+		taskId, err := create("typed", map[string]interface{}{"points": float64(3)})
+		Expect(err).NotTo(HaveOccurred())
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.CustomFields).To(Equal(map[string]interface{}{"points": float64(3), "env": "dev"}))
+
+		_, err = create("unknown", map[string]interface{}{"size": "big"})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = create("wrong type", map[string]interface{}{"points": "three"})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = create("wrong option", map[string]interface{}{"env": "qa"})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+
+		_, err = tasks.PatchTask(ctx, taskId, mergePatch(`{"customFields": {"env": null}}`), 0)
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		patched, err := tasks.PatchTask(ctx, taskId, mergePatch(`{"customFields": {"points": null, "env": "prod"}}`), 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched.CustomFields).To(Equal(map[string]interface{}{"env": "prod"}))
+		Expect(patched.Version).To(Equal(task.Version + 1))
+	})
+
+	It("stores dates in UTC at millisecond precision", func() {
+		_, err := service.CreateCustomField(ctx, &models.CustomField{Key: "release", Name: "Release", Type: models.CustomFieldDate})
+		Expect(err).NotTo(HaveOccurred())
+		taskId, err := create("dated", map[string]interface{}{"release": "2024-05-01T12:00:00.123456+02:00"})
+		Expect(err).NotTo(HaveOccurred())
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.CustomFields["release"]).To(Equal(time.Date(2024, 5, 1, 10, 0, 0, 123000000, time.UTC)))
+	})
+
+	It("filters and sorts the task list by custom field", func() {
+		for title, points := range map[string]float64{"small": 1, "medium": 3, "large": 8} {
+			_, err := create(title, map[string]interface{}{"points": points})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		_, err := create("unestimated", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		list, err := tasks.GetTasks(ctx, &models.TaskQuery{Limit: models.DefaultTaskLimit, SortBy: "field.points",
+			CustomFields: []*models.CustomFieldFilter{{Key: "points", Gte: "2"}}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Tasks).To(HaveLen(2))
+		Expect(list.Tasks[0].Title).To(Equal("medium"))
+		Expect(list.Tasks[1].Title).To(Equal("large"))
+
+		list, err = tasks.GetTasks(ctx, &models.TaskQuery{Limit: models.DefaultTaskLimit, SortBy: "field.points", SortDesc: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Tasks[3].Title).To(Equal("unestimated"))
+
+		_, err = tasks.GetTasks(ctx, &models.TaskQuery{Limit: models.DefaultTaskLimit, SortBy: "field.size"})
+		Expect(apperrors.Is(err, apperrors.BadRequest)).To(BeTrue())
+		_, err = tasks.GetTasks(ctx, &models.TaskQuery{Limit: models.DefaultTaskLimit, SortBy: models.SortByCreatedAt,
+			CustomFields: []*models.CustomFieldFilter{{Key: "points", Values: []interface{}{"many"}}}})
+		Expect(apperrors.Is(err, apperrors.BadRequest)).To(BeTrue())
+	})
+
+	It("removes the values of a deleted field from the tasks", func() {
+		taskId, err := create("estimated", map[string]interface{}{"points": float64(5)})
+		Expect(err).NotTo(HaveOccurred())
+		fields, err := service.GetCustomFields(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fields[1].Key).To(Equal("points"))
+
+		Expect(service.DeleteCustomField(ctx, fields[1].ID.Hex())).To(Succeed())
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.CustomFields).To(Equal(map[string]interface{}{"env": "dev"}))
+	})
+})
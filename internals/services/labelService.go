@@ -0,0 +1,107 @@
+package services
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+type LabelService interface {
+	GetLabels(context context.Context, query *models.LabelQuery) (*models.LabelList, error)
+	// RenameLabel replaces a label by another on every task of the workspace, merging them when the new label is in use
+	RenameLabel(context context.Context, rename *models.LabelRename) (*models.LabelRename, error)
+}
+
+type labelService struct {
+	tasks db.DbService
+	clock commons.Clock
+}
+
+func NewLabelService(tasks db.DbService) LabelService {
+	return &labelService{tasks: tasks, clock: commons.SystemClock}
+}
+
+func (s *labelService) GetLabels(ctx context.Context, query *models.LabelQuery) (*models.LabelList, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	prefix := strings.ToLower(strings.TrimSpace(query.Prefix))
+	counts, err := s.tasks.GetLabels(ctx, prefix, query.Limit)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	labels := make([]*models.Label, len(counts))
+	for i, count := range counts {
+		labels[i] = &models.Label{Name: count.Name, Count: count.Count}
+	}
+	return &models.LabelList{Labels: labels}, nil
+}
+
+func (s *labelService) RenameLabel(ctx context.Context, rename *models.LabelRename) (*models.LabelRename, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	from, err := normalizeLabel(rename.From, "from")
+	if err != nil {
+		return nil, err
+	}
+	to, err := normalizeLabel(rename.To, "to")
+	if err != nil {
+		return nil, err
+	}
+	if from == to {
+		return nil, apperrors.NewValidationError("The new label must differ from the old one", map[string]interface{}{"field": "to"})
+	}
+
+	changed, err := s.tasks.RenameLabel(ctx, from, to, s.clock.Now().UTC().Truncate(time.Millisecond))
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return &models.LabelRename{From: from, To: to, Tasks: changed}, nil
+}
+
+// function to normalize the labels of a task, duplicates are dropped and the first occurrence keeps its place
+func normalizeLabels(labels []string) ([]string, error) {
+	if len(labels) == 0 {
+		return nil, nil
+	}
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, label := range labels {
+		label, err := normalizeLabel(label, "labels")
+		if err != nil {
+			return nil, err
+		}
+		if !seen[label] {
+			seen[label] = true
+			normalized = append(normalized, label)
+		}
+	}
+	if len(normalized) > models.MaxLabelsPerTask {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("A task can have %d labels at most", models.MaxLabelsPerTask),
+			map[string]interface{}{"field": "labels"})
+	}
+	return normalized, nil
+}
+
+// function to get the stored form of a label, trimmed and in lower case so Bug and bug are the same label
+func normalizeLabel(label string, field string) (string, error) {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if len(label) == 0 {
+		return "", apperrors.NewValidationError("Labels must not be empty", map[string]interface{}{"field": field})
+	}
+	if utf8.RuneCountInString(label) > models.MaxLabelLength {
+		return "", apperrors.NewValidationError(fmt.Sprintf("Labels must not be longer than %d characters", models.MaxLabelLength),
+			map[string]interface{}{"field": field})
+	}
+	if strings.IndexFunc(label, unicode.IsControl) >= 0 {
+		return "", apperrors.NewValidationError("Labels must not contain control characters", map[string]interface{}{"field": field})
+	}
+	return label, nil
+}
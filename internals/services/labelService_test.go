@@ -0,0 +1,88 @@
+package services
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/db"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LabelService", func() {
+	var (
+		ctx     context.Context
+		tasks   TaskService
+		service LabelService
+	)
+
+	BeforeEach(func() {
+		ctx = asUser("user-1")
+		taskDb := db.NewKVDbService(db.NewMemoryStore())
+		tasks = NewTaskService(taskDb)
+		service = NewLabelService(taskDb)
+	})
+
+	create := func(labels ...string) (string, error) {
+		return tasks.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", Labels: labels})
+	}
+
+	It("stores the labels trimmed, in lower case and without duplicates", func() {
+		taskId, err := create(" Bug ", "iOS", "bug")
+		Expect(err).NotTo(HaveOccurred())
+
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Labels).To(Equal([]string{"bug", "ios"}))
+	})
+
+	It("rejects empty labels and too many labels", func() {
+		_, err := create("bug", " ")
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+
+		var labels []string
+		for i := 0; i <= models.MaxLabelsPerTask; i++ {
+			labels = append(labels, fmt.Sprintf("label-%d", i))
+		}
+		_, err = create(labels...)
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+
+	It("changes the labels with a patch", func() {
+		taskId, err := create("bug")
+		Expect(err).NotTo(HaveOccurred())
+
+		task, err := tasks.PatchTask(ctx, taskId, mergePatch(`{"labels": ["Bug", "Urgent"]}`), 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Labels).To(Equal([]string{"bug", "urgent"}))
+	})
+
+	It("lists the labels by prefix in any case", func() {
+		_, err := create("bug", "backend")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = create("bug")
+		Expect(err).NotTo(HaveOccurred())
+
+		list, err := service.GetLabels(ctx, &models.LabelQuery{Prefix: "B", Limit: 10})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Labels).To(Equal([]*models.Label{{Name: "bug", Count: 2}, {Name: "backend", Count: 1}}))
+	})
+
+	It("renames a label across the tasks", func() {
+		taskId, err := create("bug")
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := service.RenameLabel(ctx, &models.LabelRename{From: "Bug", To: "Defect"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*result).To(Equal(models.LabelRename{From: "bug", To: "defect", Tasks: 1}))
+
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Labels).To(Equal([]string{"defect"}))
+
+		_, err = service.RenameLabel(ctx, &models.LabelRename{From: "defect", To: " DEFECT"})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+})
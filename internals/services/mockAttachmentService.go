@@ -0,0 +1,35 @@
+package services
+
+import (
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+	"io"
+)
+
+type MockAttachmentService struct {
+	FakeAddAttachment    func(ctx context.Context, taskId string, upload *models.AttachmentUpload, version int64) (*models.Attachment, error)
+	FakeGetAttachment    func(ctx context.Context, taskId string, attachmentId string) (*models.Attachment, io.ReadSeekCloser, error)
+	FakeDeleteAttachment func(ctx context.Context, taskId string, attachmentId string, version int64) error
+}
+
+func (m MockAttachmentService) AddAttachment(ctx context.Context, taskId string, upload *models.AttachmentUpload, version int64) (*models.Attachment, error) {
+	if m.FakeAddAttachment != nil {
+		return m.FakeAddAttachment(ctx, taskId, upload, version)
+	}
+	return nil, fmt.Errorf("AddAttachment-error")
+}
+
+func (m MockAttachmentService) GetAttachment(ctx context.Context, taskId string, attachmentId string) (*models.Attachment, io.ReadSeekCloser, error) {
+	if m.FakeGetAttachment != nil {
+		return m.FakeGetAttachment(ctx, taskId, attachmentId)
+	}
+	return nil, nil, fmt.Errorf("GetAttachment-error")
+}
+
+func (m MockAttachmentService) DeleteAttachment(ctx context.Context, taskId string, attachmentId string, version int64) error {
+	if m.FakeDeleteAttachment != nil {
+		return m.FakeDeleteAttachment(ctx, taskId, attachmentId, version)
+	}
+	return fmt.Errorf("DeleteAttachment-error")
+}
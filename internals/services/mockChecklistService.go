@@ -0,0 +1,42 @@
+package services
+
+import (
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+)
+
+type MockChecklistService struct {
+	FakeAddChecklistItem    func(ctx context.Context, taskId string, input *models.ChecklistItemInput, version int64) (*models.Task, error)
+	FakeUpdateChecklistItem func(ctx context.Context, taskId string, itemId string, change *models.ChecklistItemChange, version int64) (*models.Task, error)
+	FakeMoveChecklistItem   func(ctx context.Context, taskId string, itemId string, position int, version int64) (*models.Task, error)
+	FakeRemoveChecklistItem func(ctx context.Context, taskId string, itemId string, version int64) (*models.Task, error)
+}
+
+func (m MockChecklistService) AddChecklistItem(ctx context.Context, taskId string, input *models.ChecklistItemInput, version int64) (*models.Task, error) {
+	if m.FakeAddChecklistItem != nil {
+		return m.FakeAddChecklistItem(ctx, taskId, input, version)
+	}
+	return nil, fmt.Errorf("AddChecklistItem-error")
+}
+
+func (m MockChecklistService) UpdateChecklistItem(ctx context.Context, taskId string, itemId string, change *models.ChecklistItemChange, version int64) (*models.Task, error) {
+	if m.FakeUpdateChecklistItem != nil {
+		return m.FakeUpdateChecklistItem(ctx, taskId, itemId, change, version)
+	}
+	return nil, fmt.Errorf("UpdateChecklistItem-error")
+}
+
+func (m MockChecklistService) MoveChecklistItem(ctx context.Context, taskId string, itemId string, position int, version int64) (*models.Task, error) {
+	if m.FakeMoveChecklistItem != nil {
+		return m.FakeMoveChecklistItem(ctx, taskId, itemId, position, version)
+	}
+	return nil, fmt.Errorf("MoveChecklistItem-error")
+}
+
+func (m MockChecklistService) RemoveChecklistItem(ctx context.Context, taskId string, itemId string, version int64) (*models.Task, error) {
+	if m.FakeRemoveChecklistItem != nil {
+		return m.FakeRemoveChecklistItem(ctx, taskId, itemId, version)
+	}
+	return nil, fmt.Errorf("RemoveChecklistItem-error")
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+)
+
+type MockCommentService struct {
+	FakeGetComments   func(ctx context.Context, taskId string, query *models.CommentQuery) (*models.CommentList, error)
+	FakeAddComment    func(ctx context.Context, taskId string, comment *models.Comment) (*models.Comment, error)
+	FakeUpdateComment func(ctx context.Context, taskId string, commentId string, comment *models.Comment, version int64) (*models.Comment, error)
+	FakeDeleteComment func(ctx context.Context, taskId string, commentId string) error
+}
+
+func (m MockCommentService) GetComments(ctx context.Context, taskId string, query *models.CommentQuery) (*models.CommentList, error) {
+	if m.FakeGetComments != nil {
+		return m.FakeGetComments(ctx, taskId, query)
+	}
+	return nil, fmt.Errorf("GetComments-error")
+}
+
+func (m MockCommentService) AddComment(ctx context.Context, taskId string, comment *models.Comment) (*models.Comment, error) {
+	if m.FakeAddComment != nil {
+		return m.FakeAddComment(ctx, taskId, comment)
+	}
+	return nil, fmt.Errorf("AddComment-error")
+}
+
+func (m MockCommentService) UpdateComment(ctx context.Context, taskId string, commentId string, comment *models.Comment, version int64) (*models.Comment, error) {
+	if m.FakeUpdateComment != nil {
+		return m.FakeUpdateComment(ctx, taskId, commentId, comment, version)
+	}
+	return nil, fmt.Errorf("UpdateComment-error")
+}
+
+func (m MockCommentService) DeleteComment(ctx context.Context, taskId string, commentId string) error {
+	if m.FakeDeleteComment != nil {
+		return m.FakeDeleteComment(ctx, taskId, commentId)
+	}
+	return fmt.Errorf("DeleteComment-error")
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+)
+
+type MockCustomFieldService struct {
+	FakeGetCustomFields    func(ctx context.Context) ([]*models.CustomField, error)
+	FakeGetCustomFieldById func(ctx context.Context, fieldId string) (*models.CustomField, error)
+	FakeCreateCustomField  func(ctx context.Context, field *models.CustomField) (*models.CustomField, error)
+	FakeUpdateCustomField  func(ctx context.Context, field *models.CustomField, fieldId string) (*models.CustomField, error)
+	FakeDeleteCustomField  func(ctx context.Context, fieldId string) error
+}
+
+func (m MockCustomFieldService) GetCustomFields(ctx context.Context) ([]*models.CustomField, error) {
+	if m.FakeGetCustomFields != nil {
+		return m.FakeGetCustomFields(ctx)
+	}
+	return nil, fmt.Errorf("GetCustomFields-error")
+}
+
+func (m MockCustomFieldService) GetCustomFieldById(ctx context.Context, fieldId string) (*models.CustomField, error) {
+	if m.FakeGetCustomFieldById != nil {
+		return m.FakeGetCustomFieldById(ctx, fieldId)
+	}
+	return nil, fmt.Errorf("GetCustomFieldById-error")
+}
+
+func (m MockCustomFieldService) CreateCustomField(ctx context.Context, field *models.CustomField) (*models.CustomField, error) {
+	if m.FakeCreateCustomField != nil {
+		return m.FakeCreateCustomField(ctx, field)
+	}
+	return nil, fmt.Errorf("CreateCustomField-error")
+}
+
+func (m MockCustomFieldService) UpdateCustomField(ctx context.Context, field *models.CustomField, fieldId string) (*models.CustomField, error) {
+	if m.FakeUpdateCustomField != nil {
+		return m.FakeUpdateCustomField(ctx, field, fieldId)
+	}
+	return nil, fmt.Errorf("UpdateCustomField-error")
+}
+
+func (m MockCustomFieldService) DeleteCustomField(ctx context.Context, fieldId string) error {
+	if m.FakeDeleteCustomField != nil {
+		return m.FakeDeleteCustomField(ctx, fieldId)
+	}
+	return fmt.Errorf("DeleteCustomField-error")
+}
@@ -0,0 +1,26 @@
+package services
+
+import (
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+)
+
+type MockLabelService struct {
+	FakeGetLabels   func(ctx context.Context, query *models.LabelQuery) (*models.LabelList, error)
+	FakeRenameLabel func(ctx context.Context, rename *models.LabelRename) (*models.LabelRename, error)
+}
+
+func (m MockLabelService) GetLabels(ctx context.Context, query *models.LabelQuery) (*models.LabelList, error) {
+	if m.FakeGetLabels != nil {
+		return m.FakeGetLabels(ctx, query)
+	}
+	return nil, fmt.Errorf("GetLabels-error")
+}
+
+func (m MockLabelService) RenameLabel(ctx context.Context, rename *models.LabelRename) (*models.LabelRename, error) {
+	if m.FakeRenameLabel != nil {
+		return m.FakeRenameLabel(ctx, rename)
+	}
+	return nil, fmt.Errorf("RenameLabel-error")
+}
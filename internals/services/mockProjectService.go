@@ -0,0 +1,59 @@
+package services
+
+import (
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/workflow"
+	"context"
+	"fmt"
+)
+
+type MockProjectService struct {
+	FakeGetProjects       func(ctx context.Context) ([]*models.Project, error)
+	FakeGetProjectById    func(ctx context.Context, projectId string) (*models.Project, error)
+	FakeCreateProject     func(ctx context.Context, project *models.Project) (*models.Project, error)
+	FakeUpdateProject     func(ctx context.Context, project *models.Project, projectId string) (*models.Project, error)
+	FakeDeleteProjectById func(ctx context.Context, projectId string) error
+	FakeGetWorkflow       func(ctx context.Context, projectId string) (*workflow.Workflow, error)
+}
+
+func (m MockProjectService) GetProjects(ctx context.Context) ([]*models.Project, error) {
+	if m.FakeGetProjects != nil {
+		return m.FakeGetProjects(ctx)
+	}
+	return nil, fmt.Errorf("GetProjects-error")
+}
+
+func (m MockProjectService) GetProjectById(ctx context.Context, projectId string) (*models.Project, error) {
+	if m.FakeGetProjectById != nil {
+		return m.FakeGetProjectById(ctx, projectId)
+	}
+	return nil, fmt.Errorf("GetProjectById-error")
+}
+
+func (m MockProjectService) CreateProject(ctx context.Context, project *models.Project) (*models.Project, error) {
+	if m.FakeCreateProject != nil {
+		return m.FakeCreateProject(ctx, project)
+	}
+	return nil, fmt.Errorf("CreateProject-error")
+}
+
+func (m MockProjectService) UpdateProject(ctx context.Context, project *models.Project, projectId string) (*models.Project, error) {
+	if m.FakeUpdateProject != nil {
+		return m.FakeUpdateProject(ctx, project, projectId)
+	}
+	return nil, fmt.Errorf("UpdateProject-error")
+}
+
+func (m MockProjectService) DeleteProjectById(ctx context.Context, projectId string) error {
+	if m.FakeDeleteProjectById != nil {
+		return m.FakeDeleteProjectById(ctx, projectId)
+	}
+	return fmt.Errorf("DeleteProjectById-error")
+}
+
+func (m MockProjectService) GetWorkflow(ctx context.Context, projectId string) (*workflow.Workflow, error) {
+	if m.FakeGetWorkflow != nil {
+		return m.FakeGetWorkflow(ctx, projectId)
+	}
+	return nil, fmt.Errorf("GetWorkflow-error")
+}
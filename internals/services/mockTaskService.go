@@ -8,10 +8,15 @@ import (
 
 type MockTaskService struct {
 	FakeGetTaskById    func(ctx context.Context, taskId string) (*models.Task, error)
-	FakeGetTasks       func(ctx context.Context) ([]*models.Task, error)
+	FakeGetTasks       func(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error)
 	FakeCreateTask     func(ctx context.Context, task *models.Task) (string, error)
-	FakeUpdateTask     func(ctx context.Context, task *models.Task, taskId string) error
-	FakeDeleteTaskById func(ctx context.Context, taskId string) error
+	FakeUpdateTask     func(ctx context.Context, task *models.Task, taskId string, version int64) error
+	FakeDeleteTaskById func(ctx context.Context, taskId string, version int64, cascade bool) error
+	FakePatchTask      func(ctx context.Context, taskId string, patch *models.TaskPatch, version int64) (*models.Task, error)
+
+	FakeAddDependency      func(ctx context.Context, taskId string, blockerId string, version int64) (*models.Task, error)
+	FakeRemoveDependency   func(ctx context.Context, taskId string, blockerId string, version int64) (*models.Task, error)
+	FakeGetDependencyGraph func(ctx context.Context, taskId string) (*models.DependencyGraph, error)
 }
 
 func (m MockTaskService) GetTaskById(ctx context.Context, taskId string) (*models.Task, error) {
@@ -21,9 +26,9 @@ func (m MockTaskService) GetTaskById(ctx context.Context, taskId string) (*model
 	return nil, fmt.Errorf("GetTaskById-error")
 }
 
-func (m MockTaskService) GetTasks(ctx context.Context) ([]*models.Task, error) {
+func (m MockTaskService) GetTasks(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
 	if m.FakeGetTasks != nil {
-		return m.FakeGetTasks(ctx)
+		return m.FakeGetTasks(ctx, query)
 	}
 	return nil, fmt.Errorf("GetTasks-error")
 }
@@ -35,16 +40,44 @@ func (m MockTaskService) CreateTask(ctx context.Context, task *models.Task) (str
 	return "", fmt.Errorf("CreateTask-error")
 }
 
-func (m MockTaskService) UpdateTask(ctx context.Context, task *models.Task, taskId string) error {
+func (m MockTaskService) UpdateTask(ctx context.Context, task *models.Task, taskId string, version int64) error {
 	if m.FakeUpdateTask != nil {
-		return m.FakeUpdateTask(ctx, task, taskId)
+		return m.FakeUpdateTask(ctx, task, taskId, version)
 	}
 	return fmt.Errorf("UpdateTask-error")
 }
 
-func (m MockTaskService) DeleteTaskById(ctx context.Context, taskId string) error {
+func (m MockTaskService) DeleteTaskById(ctx context.Context, taskId string, version int64, cascade bool) error {
 	if m.FakeDeleteTaskById != nil {
-		return m.FakeDeleteTaskById(ctx, taskId)
+		return m.FakeDeleteTaskById(ctx, taskId, version, cascade)
 	}
 	return fmt.Errorf("DeleteTaskById-error")
 }
+
+func (m MockTaskService) PatchTask(ctx context.Context, taskId string, patch *models.TaskPatch, version int64) (*models.Task, error) {
+	if m.FakePatchTask != nil {
+		return m.FakePatchTask(ctx, taskId, patch, version)
+	}
+	return nil, fmt.Errorf("PatchTask-error")
+}
+
+func (m MockTaskService) AddDependency(ctx context.Context, taskId string, blockerId string, version int64) (*models.Task, error) {
+	if m.FakeAddDependency != nil {
+		return m.FakeAddDependency(ctx, taskId, blockerId, version)
+	}
+	return nil, fmt.Errorf("AddDependency-error")
+}
+
+func (m MockTaskService) RemoveDependency(ctx context.Context, taskId string, blockerId string, version int64) (*models.Task, error) {
+	if m.FakeRemoveDependency != nil {
+		return m.FakeRemoveDependency(ctx, taskId, blockerId, version)
+	}
+	return nil, fmt.Errorf("RemoveDependency-error")
+}
+
+func (m MockTaskService) GetDependencyGraph(ctx context.Context, taskId string) (*models.DependencyGraph, error) {
+	if m.FakeGetDependencyGraph != nil {
+		return m.FakeGetDependencyGraph(ctx, taskId)
+	}
+	return nil, fmt.Errorf("GetDependencyGraph-error")
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+)
+
+type MockTemplateService struct {
+	FakeGetTemplates        func(ctx context.Context) ([]*models.Template, error)
+	FakeGetTemplateById     func(ctx context.Context, templateId string) (*models.Template, error)
+	FakeCreateTemplate      func(ctx context.Context, template *models.Template) (*models.Template, error)
+	FakeUpdateTemplate      func(ctx context.Context, template *models.Template, templateId string) (*models.Template, error)
+	FakeDeleteTemplate      func(ctx context.Context, templateId string) error
+	FakeInstantiateTemplate func(ctx context.Context, templateId string, instantiation *models.TemplateInstantiation) (*models.TemplateInstance, error)
+}
+
+func (m MockTemplateService) GetTemplates(ctx context.Context) ([]*models.Template, error) {
+	if m.FakeGetTemplates != nil {
+		return m.FakeGetTemplates(ctx)
+	}
+	return nil, fmt.Errorf("GetTemplates-error")
+}
+
+func (m MockTemplateService) GetTemplateById(ctx context.Context, templateId string) (*models.Template, error) {
+	if m.FakeGetTemplateById != nil {
+		return m.FakeGetTemplateById(ctx, templateId)
+	}
+	return nil, fmt.Errorf("GetTemplateById-error")
+}
+
+func (m MockTemplateService) CreateTemplate(ctx context.Context, template *models.Template) (*models.Template, error) {
+	if m.FakeCreateTemplate != nil {
+		return m.FakeCreateTemplate(ctx, template)
+	}
+	return nil, fmt.Errorf("CreateTemplate-error")
+}
+
+func (m MockTemplateService) UpdateTemplate(ctx context.Context, template *models.Template, templateId string) (*models.Template, error) {
+	if m.FakeUpdateTemplate != nil {
+		return m.FakeUpdateTemplate(ctx, template, templateId)
+	}
+	return nil, fmt.Errorf("UpdateTemplate-error")
+}
+
+func (m MockTemplateService) DeleteTemplate(ctx context.Context, templateId string) error {
+	if m.FakeDeleteTemplate != nil {
+		return m.FakeDeleteTemplate(ctx, templateId)
+	}
+	return fmt.Errorf("DeleteTemplate-error")
+}
+
+func (m MockTemplateService) InstantiateTemplate(ctx context.Context, templateId string, instantiation *models.TemplateInstantiation) (*models.TemplateInstance, error) {
+	if m.FakeInstantiateTemplate != nil {
+		return m.FakeInstantiateTemplate(ctx, templateId, instantiation)
+	}
+	return nil, fmt.Errorf("InstantiateTemplate-error")
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+)
+
+type MockTrashService struct {
+	FakeGetTrash          func(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error)
+	FakeRestoreTask       func(ctx context.Context, taskId string, version int64) (*models.Task, error)
+	FakeDeleteTrashedTask func(ctx context.Context, taskId string, version int64) error
+	FakePurgeExpiredTasks func(ctx context.Context) (int, error)
+}
+
+func (m MockTrashService) GetTrash(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
+	if m.FakeGetTrash != nil {
+		return m.FakeGetTrash(ctx, query)
+	}
+	return nil, fmt.Errorf("GetTrash-error")
+}
+
+func (m MockTrashService) RestoreTask(ctx context.Context, taskId string, version int64) (*models.Task, error) {
+	if m.FakeRestoreTask != nil {
+		return m.FakeRestoreTask(ctx, taskId, version)
+	}
+	return nil, fmt.Errorf("RestoreTask-error")
+}
+
+func (m MockTrashService) DeleteTrashedTask(ctx context.Context, taskId string, version int64) error {
+	if m.FakeDeleteTrashedTask != nil {
+		return m.FakeDeleteTrashedTask(ctx, taskId, version)
+	}
+	return fmt.Errorf("DeleteTrashedTask-error")
+}
+
+func (m MockTrashService) PurgeExpiredTasks(ctx context.Context) (int, error) {
+	if m.FakePurgeExpiredTasks != nil {
+		return m.FakePurgeExpiredTasks(ctx)
+	}
+	return 0, fmt.Errorf("PurgeExpiredTasks-error")
+}
@@ -0,0 +1,58 @@
+package services
+
+import (
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+)
+
+type MockWorklogService struct {
+	FakeGetWorklogs   func(ctx context.Context, taskId string) ([]*models.Worklog, error)
+	FakeLogWork       func(ctx context.Context, taskId string, input *models.WorklogInput, version int64) (*models.Worklog, error)
+	FakeStartTimer    func(ctx context.Context, taskId string) (*models.Timer, error)
+	FakeStopTimer     func(ctx context.Context, taskId string, note string) (*models.Worklog, error)
+	FakeGetTimers     func(ctx context.Context) ([]*models.Timer, error)
+	FakeGetTimeReport func(ctx context.Context, query *models.TimeReportQuery) (*models.TimeReport, error)
+}
+
+func (m MockWorklogService) GetWorklogs(ctx context.Context, taskId string) ([]*models.Worklog, error) {
+	if m.FakeGetWorklogs != nil {
+		return m.FakeGetWorklogs(ctx, taskId)
+	}
+	return nil, fmt.Errorf("GetWorklogs-error")
+}
+
+func (m MockWorklogService) LogWork(ctx context.Context, taskId string, input *models.WorklogInput, version int64) (*models.Worklog, error) {
+	if m.FakeLogWork != nil {
+		return m.FakeLogWork(ctx, taskId, input, version)
+	}
+	return nil, fmt.Errorf("LogWork-error")
+}
+
+func (m MockWorklogService) StartTimer(ctx context.Context, taskId string) (*models.Timer, error) {
+	if m.FakeStartTimer != nil {
+		return m.FakeStartTimer(ctx, taskId)
+	}
+	return nil, fmt.Errorf("StartTimer-error")
+}
+
+func (m MockWorklogService) StopTimer(ctx context.Context, taskId string, note string) (*models.Worklog, error) {
+	if m.FakeStopTimer != nil {
+		return m.FakeStopTimer(ctx, taskId, note)
+	}
+	return nil, fmt.Errorf("StopTimer-error")
+}
+
+func (m MockWorklogService) GetTimers(ctx context.Context) ([]*models.Timer, error) {
+	if m.FakeGetTimers != nil {
+		return m.FakeGetTimers(ctx)
+	}
+	return nil, fmt.Errorf("GetTimers-error")
+}
+
+func (m MockWorklogService) GetTimeReport(ctx context.Context, query *models.TimeReportQuery) (*models.TimeReport, error) {
+	if m.FakeGetTimeReport != nil {
+		return m.FakeGetTimeReport(ctx, query)
+	}
+	return nil, fmt.Errorf("GetTimeReport-error")
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+)
+
+type MockWorkspaceService struct {
+	FakeGetWorkspaces       func(ctx context.Context) ([]*models.Workspace, error)
+	FakeGetWorkspaceById    func(ctx context.Context, workspaceId string) (*models.Workspace, error)
+	FakeCreateWorkspace     func(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error)
+	FakeUpdateWorkspace     func(ctx context.Context, workspace *models.Workspace, workspaceId string) (*models.Workspace, error)
+	FakeDeleteWorkspaceById func(ctx context.Context, workspaceId string) error
+	FakeGetMembers          func(ctx context.Context, workspaceId string) ([]*models.Membership, error)
+	FakeAddMember           func(ctx context.Context, workspaceId string, membership *models.Membership) (*models.Membership, error)
+	FakeUpdateMember        func(ctx context.Context, workspaceId string, membership *models.Membership) (*models.Membership, error)
+	FakeRemoveMember        func(ctx context.Context, workspaceId string, subject string) error
+	FakeGetMembershipRole   func(ctx context.Context, workspaceId string, subject string) (string, error)
+}
+
+func (m MockWorkspaceService) GetWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+	if m.FakeGetWorkspaces != nil {
+		return m.FakeGetWorkspaces(ctx)
+	}
+	return nil, fmt.Errorf("GetWorkspaces-error")
+}
+
+func (m MockWorkspaceService) GetWorkspaceById(ctx context.Context, workspaceId string) (*models.Workspace, error) {
+	if m.FakeGetWorkspaceById != nil {
+		return m.FakeGetWorkspaceById(ctx, workspaceId)
+	}
+	return nil, fmt.Errorf("GetWorkspaceById-error")
+}
+
+func (m MockWorkspaceService) CreateWorkspace(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error) {
+	if m.FakeCreateWorkspace != nil {
+		return m.FakeCreateWorkspace(ctx, workspace)
+	}
+	return nil, fmt.Errorf("CreateWorkspace-error")
+}
+
+func (m MockWorkspaceService) UpdateWorkspace(ctx context.Context, workspace *models.Workspace, workspaceId string) (*models.Workspace, error) {
+	if m.FakeUpdateWorkspace != nil {
+		return m.FakeUpdateWorkspace(ctx, workspace, workspaceId)
+	}
+	return nil, fmt.Errorf("UpdateWorkspace-error")
+}
+
+func (m MockWorkspaceService) DeleteWorkspaceById(ctx context.Context, workspaceId string) error {
+	if m.FakeDeleteWorkspaceById != nil {
+		return m.FakeDeleteWorkspaceById(ctx, workspaceId)
+	}
+	return fmt.Errorf("DeleteWorkspaceById-error")
+}
+
+func (m MockWorkspaceService) GetMembers(ctx context.Context, workspaceId string) ([]*models.Membership, error) {
+	if m.FakeGetMembers != nil {
+		return m.FakeGetMembers(ctx, workspaceId)
+	}
+	return nil, fmt.Errorf("GetMembers-error")
+}
+
+func (m MockWorkspaceService) AddMember(ctx context.Context, workspaceId string, membership *models.Membership) (*models.Membership, error) {
+	if m.FakeAddMember != nil {
+		return m.FakeAddMember(ctx, workspaceId, membership)
+	}
+	return nil, fmt.Errorf("AddMember-error")
+}
+
+func (m MockWorkspaceService) UpdateMember(ctx context.Context, workspaceId string, membership *models.Membership) (*models.Membership, error) {
+	if m.FakeUpdateMember != nil {
+		return m.FakeUpdateMember(ctx, workspaceId, membership)
+	}
+	return nil, fmt.Errorf("UpdateMember-error")
+}
+
+func (m MockWorkspaceService) RemoveMember(ctx context.Context, workspaceId string, subject string) error {
+	if m.FakeRemoveMember != nil {
+		return m.FakeRemoveMember(ctx, workspaceId, subject)
+	}
+	return fmt.Errorf("RemoveMember-error")
+}
+
+func (m MockWorkspaceService) GetMembershipRole(ctx context.Context, workspaceId string, subject string) (string, error) {
+	if m.FakeGetMembershipRole != nil {
+		return m.FakeGetMembershipRole(ctx, workspaceId, subject)
+	}
+	return "", fmt.Errorf("GetMembershipRole-error")
+}
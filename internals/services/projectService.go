@@ -0,0 +1,184 @@
+package services
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/workflow"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// project keys prefix the task keys, e.g. OPS in OPS-123
+var projectKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+
+type ProjectService interface {
+	GetProjects(context context.Context) ([]*models.Project, error)
+	GetProjectById(context context.Context, projectId string) (*models.Project, error)
+	CreateProject(context context.Context, project *models.Project) (*models.Project, error)
+	UpdateProject(context context.Context, project *models.Project, projectId string) (*models.Project, error)
+	DeleteProjectById(context context.Context, projectId string) error
+	// GetWorkflow returns the workflow the tasks of the project follow, its own or the one of the deployment
+	GetWorkflow(context context.Context, projectId string) (*workflow.Workflow, error)
+}
+
+type projectService struct {
+	dbservice db.ProjectDbService
+	tasks     db.DbService
+	workflow  *workflow.Workflow
+	clock     commons.Clock
+}
+
+// function to build the project service, the tasks are only read to refuse deleting a project that still has some
+func NewProjectService(dbservice db.ProjectDbService, tasks db.DbService, defaultWorkflow *workflow.Workflow) ProjectService {
+	return &projectService{dbservice: dbservice, tasks: tasks, workflow: defaultWorkflow, clock: commons.SystemClock}
+}
+
+func (s *projectService) GetProjects(ctx context.Context) ([]*models.Project, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	projectSchemas, err := s.dbservice.GetProjects(ctx)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	projects := make([]*models.Project, len(projectSchemas))
+	for i, projectSchema := range projectSchemas {
+		projects[i] = commons.MapToProjectModel(projectSchema)
+	}
+	return projects, nil
+}
+
+func (s *projectService) GetProjectById(ctx context.Context, projectId string) (*models.Project, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	projectSchema, err := s.dbservice.GetProjectById(ctx, projectId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToProjectModel(projectSchema), nil
+}
+
+func (s *projectService) CreateProject(ctx context.Context, project *models.Project) (*models.Project, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	project.Key = strings.ToUpper(strings.TrimSpace(project.Key))
+	if !projectKeyPattern.MatchString(project.Key) {
+		return nil, apperrors.NewValidationError("Key must be 2 to 10 letters and digits starting with a letter",
+			map[string]interface{}{"field": "key"})
+	}
+	if err := validateProject(project); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	projectSchema := &dbmodels.ProjectSchema{
+		Key:         project.Key,
+		Name:        strings.TrimSpace(project.Name),
+		Description: project.Description,
+		Workflow:    project.Workflow,
+		CreatedBy:   appauth.GetSubject(ctx),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	projectId, err := s.dbservice.SaveProject(ctx, projectSchema)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	projectSchema.ID, _ = primitive.ObjectIDFromHex(projectId)
+	return commons.MapToProjectModel(projectSchema), nil
+}
+
+// function to replace the name, description and workflow of the project, the key never changes
+// because the keys of its tasks are built from it
+func (s *projectService) UpdateProject(ctx context.Context, project *models.Project, projectId string) (*models.Project, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if err := validateProject(project); err != nil {
+		return nil, err
+	}
+	current, err := s.dbservice.GetProjectById(ctx, projectId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if len(project.Key) > 0 && !strings.EqualFold(project.Key, current.Key) {
+		return nil, apperrors.NewValidationError("Key is read-only", map[string]interface{}{"field": "key"})
+	}
+	current.Name = strings.TrimSpace(project.Name)
+	current.Description = project.Description
+	current.Workflow = project.Workflow
+	current.UpdatedAt = s.now()
+	if err := s.dbservice.UpdateProject(ctx, current); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToProjectModel(current), nil
+}
+
+// function to delete the project, a project still holding tasks is kept, those in the trash included
+func (s *projectService) DeleteProjectById(ctx context.Context, projectId string) error {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if _, err := s.dbservice.GetProjectById(ctx, projectId); err != nil {
+		logger.Error(err)
+		return err
+	}
+	page, err := s.tasks.GetTasks(ctx, &models.TaskQuery{Limit: 1, SortBy: models.SortByCreatedAt, ProjectID: projectId})
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if page.Total > 0 {
+		return apperrors.NewConflictError(fmt.Sprintf("project %s still has %d tasks", projectId, page.Total), nil)
+	}
+	trashed, err := s.tasks.GetTasks(ctx, &models.TaskQuery{Limit: 1, SortBy: models.SortByCreatedAt, ProjectID: projectId, Deleted: true})
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if trashed.Total > 0 {
+		return apperrors.NewConflictError(fmt.Sprintf("project %s still has %d tasks in the trash", projectId, trashed.Total), nil)
+	}
+	if err := s.dbservice.DeleteProjectById(ctx, projectId); err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *projectService) GetWorkflow(ctx context.Context, projectId string) (*workflow.Workflow, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	projectSchema, err := s.dbservice.GetProjectById(ctx, projectId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if projectSchema.Workflow != nil {
+		return projectSchema.Workflow, nil
+	}
+	return s.workflow, nil
+}
+
+func (s *projectService) now() time.Time {
+	return s.clock.Now().UTC().Truncate(time.Millisecond)
+}
+
+// function to check the project rules shared by create and update
+func validateProject(project *models.Project) error {
+	if len(strings.TrimSpace(project.Name)) == 0 {
+		return apperrors.NewValidationError("Name is required", map[string]interface{}{"field": "name"})
+	}
+	if project.Workflow != nil {
+		if err := project.Workflow.Validate(); err != nil {
+			return apperrors.NewValidationError(fmt.Sprintf("invalid workflow: %v", err), map[string]interface{}{"field": "workflow"})
+		}
+	}
+	return nil
+}
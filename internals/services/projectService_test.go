@@ -0,0 +1,89 @@
+package services
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/workflow"
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var _ = Describe("ProjectService", func() {
+	projectId := primitive.NewObjectID()
+	existingProject := func(ctx context.Context, id string) (*dbmodels.ProjectSchema, error) {
+		return &dbmodels.ProjectSchema{ID: projectId, Key: "OPS", Name: "Operations"}, nil
+	}
+
+	Describe("CreateProject", func() {
+		It("normalizes the key and records the creator", func() {
+			var saved *dbmodels.ProjectSchema
+			projects := db.MockProjectDbService{
+				FakeSaveProject: func(ctx context.Context, project *dbmodels.ProjectSchema) (string, error) {
+					saved = project
+					return projectId.Hex(), nil
+				},
+			}
+			service := NewProjectService(projects, db.MockDbService{}, workflow.Default())
+
+			project, err := service.CreateProject(asUser("user-1"), &models.Project{Key: " ops ", Name: "Operations"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(project.ID).To(Equal(projectId))
+			Expect(saved.Key).To(Equal("OPS"))
+			Expect(saved.CreatedBy).To(Equal("user-1"))
+		})
+
+		It("rejects an invalid key or workflow", func() {
+			service := NewProjectService(db.MockProjectDbService{}, db.MockDbService{}, workflow.Default())
+
+			_, err := service.CreateProject(asUser("user-1"), &models.Project{Key: "O-1", Name: "Operations"})
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+
+			_, err = service.CreateProject(asUser("user-1"), &models.Project{Key: "OPS", Name: "Operations",
+				Workflow: &workflow.Workflow{Initial: "Todo", Statuses: []string{"Doing"}}})
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+	})
+
+	Describe("UpdateProject", func() {
+		It("keeps the key", func() {
+			service := NewProjectService(db.MockProjectDbService{FakeGetProjectById: existingProject}, db.MockDbService{}, workflow.Default())
+
+			_, err := service.UpdateProject(asUser("user-1"), &models.Project{Key: "DEV", Name: "Operations"}, projectId.Hex())
+
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+	})
+
+	Describe("DeleteProjectById", func() {
+		It("keeps a project that still has tasks", func() {
+			tasks := db.MockDbService{
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*dbmodels.TaskPage, error) {
+					Expect(query.ProjectID).To(Equal(projectId.Hex()))
+					return &dbmodels.TaskPage{Total: 2}, nil
+				},
+			}
+			service := NewProjectService(db.MockProjectDbService{FakeGetProjectById: existingProject}, tasks, workflow.Default())
+
+			err := service.DeleteProjectById(asUser("user-1"), projectId.Hex())
+
+			Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+		})
+	})
+
+	Describe("GetWorkflow", func() {
+		It("falls back to the workflow of the deployment", func() {
+			service := NewProjectService(db.MockProjectDbService{FakeGetProjectById: existingProject}, db.MockDbService{}, workflow.Default())
+
+			definition, err := service.GetWorkflow(asUser("user-1"), projectId.Hex())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(definition.Initial).To(Equal("New"))
+		})
+	})
+})
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/recurrence"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// the recurring tasks are read a page at a time
+const recurrencePageSize = 100
+
+// RecurrenceService creates the occurrences of the recurring tasks, it is run by the scheduler
+// rather than on behalf of a caller so it works across the workspaces
+type RecurrenceService interface {
+	// MaterializeOccurrences creates the next occurrence of every recurring task that is done or due
+	// and returns the number of occurrences created
+	MaterializeOccurrences(context context.Context) (int, error)
+}
+
+type recurrenceService struct {
+	*taskService
+}
+
+// function to build the recurrence service, it takes the options of the task service so the occurrences
+// follow the same workflows and get a key in their project
+func NewRecurrenceService(dbservice db.DbService, opts ...TaskServiceOption) RecurrenceService {
+	return &recurrenceService{taskService: newTaskService(dbservice, opts...)}
+}
+
+// function to walk the latest occurrence of every series, a task that cannot be handled is logged
+// and retried on the next run without holding up the others
+func (s *recurrenceService) MaterializeOccurrences(ctx context.Context) (int, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	created := 0
+	afterId := ""
+	for {
+		tasks, err := s.dbservice.GetRecurringTasks(ctx, afterId, recurrencePageSize)
+		if err != nil {
+			logger.Error(err)
+			return created, err
+		}
+		for _, task := range tasks {
+			materialized, err := s.materialize(ctx, task)
+			if err != nil {
+				logger.Errorf("failed to create the next occurrence of task %s: %v", task.ID.Hex(), err)
+				continue
+			}
+			if materialized {
+				created++
+			}
+		}
+		if len(tasks) < recurrencePageSize {
+			return created, nil
+		}
+		afterId = tasks[len(tasks)-1].ID.Hex()
+	}
+}
+
+// function to create the next occurrence of the task once it is done or due and link it from the task.
+// the occurrence gets an id derived from the task, so a run repeated after a crash or by another replica
+// finds it instead of creating it twice
+func (s *recurrenceService) materialize(ctx context.Context, task *dbmodels.TaskSchema) (bool, error) {
+	ctx = appauth.WithWorkspace(ctx, task.WorkspaceID)
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return false, err
+	}
+	if task.DueDate == nil {
+		return false, fmt.Errorf("the task has no due date")
+	}
+	taskWorkflow, err := s.workflowFor(ctx, task.ProjectID)
+	if err != nil {
+		return false, err
+	}
+	now := s.now()
+	if !taskWorkflow.IsDone(task.Status) && task.DueDate.After(now) {
+		return false, nil
+	}
+
+	nextId := occurrenceId(task.ID)
+	exists, err := s.occurrenceExists(ctx, nextId)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		occurrence, err := s.nextOccurrence(ctx, task, rule, taskWorkflow.Initial, now)
+		if err != nil {
+			return false, err
+		}
+		occurrence.ID = nextId
+		if _, err := s.dbservice.SaveTask(ctx, occurrence); err != nil && !apperrors.Is(err, apperrors.Conflict) {
+			return false, err
+		}
+	}
+
+	// a concurrent change of the task fails the write, the next run links it
+	fields := map[string]interface{}{"nextOccurrenceId": nextId.Hex()}
+	if _, err := s.dbservice.PatchTask(ctx, task.ID.Hex(), fields, task.Version); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// function to check a previous run created the occurrence, it may be in the trash already.
+// the check comes before the occurrence is built so a repeated run does not allocate another project key
+func (s *recurrenceService) occurrenceExists(ctx context.Context, occurrenceId primitive.ObjectID) (bool, error) {
+	_, err := s.dbservice.GetTaskById(ctx, occurrenceId.Hex())
+	if apperrors.Is(err, apperrors.NotFound) {
+		_, err = s.dbservice.GetDeletedTaskById(ctx, occurrenceId.Hex())
+	}
+	if apperrors.Is(err, apperrors.NotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// function to copy the task into its next occurrence, due on the first date of the rule after the due date of the
+// task and after now, so the occurrences missed while the task was open are skipped.
+// the start date keeps its distance to the due date
+func (s *recurrenceService) nextOccurrence(ctx context.Context, task *dbmodels.TaskSchema, rule *recurrence.Rule, status string, now time.Time) (*dbmodels.TaskSchema, error) {
+	dueDate := *task.DueDate
+	for first := true; first || !dueDate.After(now); first = false {
+		next := rule.Next(dueDate)
+		if !next.After(dueDate) {
+			return nil, fmt.Errorf("the recurrence %s has no occurrence after %s", task.Recurrence, dueDate.Format(time.RFC3339))
+		}
+		dueDate = next
+	}
+	var startDate *time.Time
+	if task.StartDate != nil {
+		start := task.StartDate.Add(dueDate.Sub(*task.DueDate))
+		startDate = &start
+	}
+	var originalEstimate, remainingEstimate *int64
+	if task.OriginalEstimate != nil {
+		original, remaining := *task.OriginalEstimate, *task.OriginalEstimate
+		originalEstimate, remainingEstimate = &original, &remaining
+	}
+	// the checklist starts over
+	var checklist []dbmodels.ChecklistItemSchema
+	for _, item := range task.Checklist {
+		checklist = append(checklist, dbmodels.ChecklistItemSchema{ID: primitive.NewObjectID(), Text: item.Text})
+	}
+
+	occurrence := &dbmodels.TaskSchema{
+		Title:             task.Title,
+		Description:       task.Description,
+		Status:            status,
+		Priority:          task.Priority,
+		PriorityRank:      task.PriorityRank,
+		StartDate:         startDate,
+		DueDate:           &dueDate,
+		OriginalEstimate:  originalEstimate,
+		RemainingEstimate: remainingEstimate,
+		Recurrence:        task.Recurrence,
+		RecurrenceOf:      task.ID.Hex(),
+		CreatedBy:         task.CreatedBy,
+		Reporter:          task.Reporter,
+		Assignees:         task.Assignees,
+		Labels:            task.Labels,
+		ProjectID:         task.ProjectID,
+		ParentID:          task.ParentID,
+		Checklist:         checklist,
+		CustomFields:      task.CustomFields,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if len(occurrence.ProjectID) > 0 {
+		key, err := s.projects.AllocateTaskKey(ctx, occurrence.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		occurrence.Key = key
+	}
+	return occurrence, nil
+}
+
+// function to derive the id of the next occurrence from the id of the task, the same on every replica
+func occurrenceId(taskId primitive.ObjectID) primitive.ObjectID {
+	sum := sha256.Sum256([]byte("occurrence/" + taskId.Hex()))
+	var id primitive.ObjectID
+	copy(id[:], sum[:len(id)])
+	return id
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RecurrenceService", func() {
+	var (
+		ctx     context.Context
+		clock   *fakeClock
+		taskDb  db.DbService
+		tasks   TaskService
+		service RecurrenceService
+		dueDate time.Time
+	)
+
+	BeforeEach(func() {
+		ctx = asUser("user-1")
+		clock = &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+		taskDb = db.NewKVDbService(db.NewMemoryStore())
+		tasks = NewTaskService(taskDb, WithClock(clock))
+		service = NewRecurrenceService(taskDb, WithClock(clock))
+		// a wednesday
+		dueDate = time.Date(2024, 5, 1, 17, 0, 0, 0, time.UTC)
+	})
+
+	create := func(ctx context.Context, recurrence string) string {
+		startDate := dueDate.Add(-2 * time.Hour)
+		estimate := int64(600)
+		taskId, err := tasks.CreateTask(ctx, &models.Task{Title: "Standup notes", Description: "Description", Recurrence: recurrence,
+			StartDate: &startDate, DueDate: &dueDate, OriginalEstimate: &estimate, Labels: []string{"ops"},
+			Checklist: []models.ChecklistItem{{Text: "Send", Done: true}}})
+		Expect(err).NotTo(HaveOccurred())
+		return taskId
+	}
+
+	complete := func(ctx context.Context, taskId string) {
+		_, err := taskDb.PatchTask(ctx, taskId, map[string]interface{}{"status": "Done"}, 0)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	It("validates the rule and stores it in its canonical form", func() {
+		_, err := tasks.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", Recurrence: "FREQ=HOURLY", DueDate: &dueDate})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = tasks.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", Recurrence: "FREQ=DAILY"})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+
+		task, err := tasks.GetTaskById(ctx, create(ctx, "rrule:freq=weekly;byday=fr,mo"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Recurrence).To(Equal("FREQ=WEEKLY;BYDAY=MO,FR"))
+	})
+
+	It("creates the next occurrence of a completed task once", func() {
+		taskId := create(ctx, "FREQ=WEEKLY;BYDAY=MO,FR")
+		created, err := service.MaterializeOccurrences(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal(0))
+
+		complete(ctx, taskId)
+		created, err = service.MaterializeOccurrences(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal(1))
+		created, err = service.MaterializeOccurrences(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal(0))
+
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.NextOccurrenceID).NotTo(BeEmpty())
+		next, err := tasks.GetTaskById(ctx, task.NextOccurrenceID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(next.RecurrenceOf).To(Equal(taskId))
+		Expect(next.Recurrence).To(Equal("FREQ=WEEKLY;BYDAY=MO,FR"))
+		Expect(next.Status).To(Equal("New"))
+		Expect(*next.DueDate).To(Equal(time.Date(2024, 5, 3, 17, 0, 0, 0, time.UTC)))
+		Expect(*next.StartDate).To(Equal(time.Date(2024, 5, 3, 15, 0, 0, 0, time.UTC)))
+		Expect(next.CreatedBy).To(Equal("user-1"))
+		Expect(next.Labels).To(Equal([]string{"ops"}))
+		Expect(*next.RemainingEstimate).To(Equal(int64(600)))
+		Expect(next.Checklist).To(HaveLen(1))
+		Expect(next.Checklist[0].Done).To(BeFalse())
+	})
+
+	It("creates the next occurrence once the due date passed, skipping the missed ones", func() {
+		taskId := create(ctx, "FREQ=DAILY")
+		clock.now = dueDate.Add(72*time.Hour + time.Minute)
+		created, err := service.MaterializeOccurrences(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal(1))
+
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		next, err := tasks.GetTaskById(ctx, task.NextOccurrenceID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*next.DueDate).To(Equal(dueDate.Add(96 * time.Hour)))
+	})
+
+	It("links the occurrence a previous run created", func() {
+		taskId := create(ctx, "FREQ=DAILY")
+		complete(ctx, taskId)
+		// the run stopped after creating the occurrence
+		failing := db.MockDbService{
+			FakeGetRecurringTasks:  taskDb.GetRecurringTasks,
+			FakeGetTaskById:        taskDb.GetTaskById,
+			FakeGetDeletedTaskById: taskDb.GetDeletedTaskById,
+			FakeSaveTask:           taskDb.SaveTask,
+			FakePatchTask: func(ctx context.Context, taskId string, fields map[string]interface{}, version int64) (*dbmodels.TaskSchema, error) {
+				return nil, apperrors.NewPreconditionFailedError("task has been modified")
+			},
+		}
+		created, err := NewRecurrenceService(failing, WithClock(clock)).MaterializeOccurrences(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal(0))
+
+		created, err = service.MaterializeOccurrences(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal(1))
+		page, err := tasks.GetTasks(ctx, &models.TaskQuery{Limit: 10})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(page.Tasks).To(HaveLen(2))
+	})
+
+	It("allocates no project key for an occurrence a previous run created and the user trashed", func() {
+		projects := db.NewKVProjectDbService(db.NewMemoryStore())
+		projectId, err := projects.SaveProject(ctx, &dbmodels.ProjectSchema{Key: "OPS", Name: "Operations"})
+		Expect(err).NotTo(HaveOccurred())
+		allocated := 0
+		counting := db.MockProjectDbService{
+			FakeGetProjectById: projects.GetProjectById,
+			FakeAllocateTaskKey: func(ctx context.Context, projectId string) (string, error) {
+				allocated++
+				return projects.AllocateTaskKey(ctx, projectId)
+			},
+		}
+		tasks := NewTaskService(taskDb, WithProjects(counting), WithClock(clock))
+		taskId, err := tasks.CreateTask(ctx, &models.Task{Title: "Standup notes", Description: "Description", Recurrence: "FREQ=DAILY",
+			DueDate: &dueDate, ProjectID: projectId})
+		Expect(err).NotTo(HaveOccurred())
+		complete(ctx, taskId)
+		// the run stopped after creating the occurrence
+		failing := db.MockDbService{
+			FakeGetRecurringTasks:  taskDb.GetRecurringTasks,
+			FakeGetTaskById:        taskDb.GetTaskById,
+			FakeGetDeletedTaskById: taskDb.GetDeletedTaskById,
+			FakeSaveTask:           taskDb.SaveTask,
+			FakePatchTask: func(ctx context.Context, taskId string, fields map[string]interface{}, version int64) (*dbmodels.TaskSchema, error) {
+				return nil, apperrors.NewPreconditionFailedError("task has been modified")
+			},
+		}
+		_, err = NewRecurrenceService(failing, WithProjects(counting), WithClock(clock)).MaterializeOccurrences(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allocated).To(Equal(2))
+		id, err := primitive.ObjectIDFromHex(taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tasks.DeleteTaskById(ctx, occurrenceId(id).Hex(), 0, false)).To(Succeed())
+
+		created, err := NewRecurrenceService(taskDb, WithProjects(counting), WithClock(clock)).MaterializeOccurrences(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal(1))
+		Expect(allocated).To(Equal(2))
+	})
+
+	It("creates the occurrences in the workspace of the task", func() {
+		teamCtx := appauth.WithWorkspace(ctx, "team-a")
+		taskId := create(teamCtx, "FREQ=MONTHLY;BYMONTHDAY=-1")
+		complete(teamCtx, taskId)
+		created, err := service.MaterializeOccurrences(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created).To(Equal(1))
+
+		task, err := tasks.GetTaskById(teamCtx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		next, err := tasks.GetTaskById(teamCtx, task.NextOccurrenceID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(*next.DueDate).To(Equal(time.Date(2024, 5, 31, 17, 0, 0, 0, time.UTC)))
+		_, err = tasks.GetTaskById(ctx, task.NextOccurrenceID)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+	})
+})
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// function to get the custom field definitions of the workspace by key, none without a custom field store
+func (s *taskService) customFieldDefinitions(ctx context.Context) (map[string]*dbmodels.CustomFieldSchema, error) {
+	definitions := map[string]*dbmodels.CustomFieldSchema{}
+	if s.fields == nil {
+		return definitions, nil
+	}
+	fields, err := s.fields.GetCustomFields(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range fields {
+		definitions[field.Key] = field
+	}
+	return definitions, nil
+}
+
+// function to check the custom field values a task is written with against the definitions of the workspace and
+// type them. values holds every value the task ends up with, a nil value leaves the field out. a new task gets the
+// defaults of the fields it has no value for and must have every required field, an existing one cannot lose the
+// value of a required field. an enum value whose option was removed since can be written back unchanged
+func (s *taskService) customFieldValues(ctx context.Context, values, current map[string]interface{}, creating bool) (map[string]interface{}, error) {
+	if len(values) == 0 && len(current) == 0 && !creating {
+		return values, nil
+	}
+	definitions, err := s.customFieldDefinitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	typed := map[string]interface{}{}
+	for key, value := range values {
+		name := "customFields." + key
+		definition, ok := definitions[key]
+		if !ok {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("Unknown custom field %s", key), map[string]interface{}{"field": name})
+		}
+		if value == nil {
+			continue
+		}
+		if typed[key], err = customFieldValue(definition, value, name); err != nil {
+			stored, ok := current[key].(string)
+			if !ok || stored != value {
+				return nil, err
+			}
+			typed[key] = stored
+		}
+	}
+	for key, definition := range definitions {
+		if _, ok := typed[key]; ok {
+			continue
+		}
+		if creating && definition.Default != nil {
+			typed[key] = commons.MapToCustomFieldValue(definition.Default)
+			continue
+		}
+		if _, had := current[key]; definition.Required && (creating || had) {
+			name := "customFields." + key
+			return nil, apperrors.NewValidationError(fmt.Sprintf("%s is required", name), map[string]interface{}{"field": name})
+		}
+	}
+	return typed, nil
+}
+
+// function to type the custom field filters and check the custom field sort of the query against the definitions
+func (s *taskService) customFieldQuery(ctx context.Context, query *models.TaskQuery) error {
+	sortKey := strings.TrimPrefix(query.SortBy, models.CustomFieldParamPrefix)
+	if len(query.CustomFields) == 0 && sortKey == query.SortBy {
+		return nil
+	}
+	definitions, err := s.customFieldDefinitions(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := definitions[sortKey]; !ok && sortKey != query.SortBy {
+		return apperrors.NewBadRequestError(fmt.Sprintf("invalid sort field: %s", query.SortBy))
+	}
+	for _, filter := range query.CustomFields {
+		definition, ok := definitions[filter.Key]
+		name := models.CustomFieldParamPrefix + filter.Key
+		if !ok {
+			return apperrors.NewBadRequestError(fmt.Sprintf("unknown custom field: %s", filter.Key))
+		}
+		for i, value := range filter.Values {
+			if filter.Values[i], err = customFieldParam(definition, value, name); err != nil {
+				return err
+			}
+		}
+		if filter.Gte != nil {
+			if filter.Gte, err = customFieldParam(definition, filter.Gte, name+".gte"); err != nil {
+				return err
+			}
+		}
+		if filter.Lt != nil {
+			if filter.Lt, err = customFieldParam(definition, filter.Lt, name+".lt"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func sameCustomFields(a, b map[string]interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, valueA := range a {
+		valueB, ok := b[key]
+		if !ok {
+			return false
+		}
+		timeA, isTimeA := valueA.(time.Time)
+		timeB, isTimeB := valueB.(time.Time)
+		if isTimeA || isTimeB {
+			if !isTimeA || !isTimeB || !timeA.Equal(timeB) {
+				return false
+			}
+		} else if valueA != valueB {
+			return false
+		}
+	}
+	return true
+}
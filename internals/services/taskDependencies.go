@@ -0,0 +1,245 @@
+package services
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/workflow"
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// unlinkAttempts bounds the writes taking back a link that closed a cycle, each one lost to a concurrent change of the task
+const unlinkAttempts = 3
+
+// function to record that the blocker blocks the task, a link that would close a cycle is rejected and one that
+// closed a cycle with a concurrent link is taken back with a conflict. linking a task that already blocks it changes nothing
+func (s *taskService) AddDependency(ctx context.Context, taskId string, blockerId string, version int64) (*models.Task, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	current, err := s.taskForDependencyChange(ctx, taskId, version)
+	if err != nil {
+		return nil, err
+	}
+	if blockerId == taskId {
+		return nil, apperrors.NewValidationError("A task cannot block itself", map[string]interface{}{"field": "blockerId"})
+	}
+
+	graph, err := s.dbservice.GetDependencyGraph(ctx, blockerId)
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) || apperrors.Is(err, apperrors.InvalidID) {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("blocking task %s not found", blockerId), map[string]interface{}{"field": "blockerId"})
+		}
+		logger.Error(err)
+		return nil, err
+	}
+	blocker := graph[0]
+	if containsId(current.BlockedBy, blocker.ID) {
+		return commons.MapToModel(current), nil
+	}
+	if blocks(graph, current.ID, blocker.ID) {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("task %s already depends on task %s, the link would create a cycle", blockerId, taskId),
+			map[string]interface{}{"field": "blockerId"})
+	}
+
+	blockedBy := append(append([]primitive.ObjectID{}, current.BlockedBy...), blocker.ID)
+	updated, err := s.dbservice.PatchTask(ctx, taskId, map[string]interface{}{"blockedBy": blockedBy, "updatedAt": s.now()}, current.Version)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	// the write only checks the version of the task, a concurrent link the other way passes the check above too.
+	// the graph is read again once the link is written and the link is taken back when it closed a cycle
+	graph, err = s.dbservice.GetDependencyGraph(ctx, blockerId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if blocks(graph, current.ID, blocker.ID) {
+		if err := s.unlinkBlocker(ctx, updated, blocker.ID); err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		return nil, apperrors.NewConflictError(fmt.Sprintf("task %s was linked to task %s concurrently, the link would create a cycle", blockerId, taskId), nil)
+	}
+	return commons.MapToModel(updated), nil
+}
+
+// function to take back the link to the blocker, the task is read again when it changed in the meantime
+func (s *taskService) unlinkBlocker(ctx context.Context, task *dbmodels.TaskSchema, blockerId primitive.ObjectID) error {
+	for attempt := 0; ; attempt++ {
+		blockedBy := []primitive.ObjectID{}
+		for _, id := range task.BlockedBy {
+			if id != blockerId {
+				blockedBy = append(blockedBy, id)
+			}
+		}
+		_, err := s.dbservice.PatchTask(ctx, task.ID.Hex(), map[string]interface{}{"blockedBy": blockedBy, "updatedAt": s.now()}, task.Version)
+		if !apperrors.Is(err, apperrors.PreconditionFailed) || attempt == unlinkAttempts-1 {
+			return err
+		}
+		if task, err = s.dbservice.GetTaskById(ctx, task.ID.Hex()); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *taskService) RemoveDependency(ctx context.Context, taskId string, blockerId string, version int64) (*models.Task, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	current, err := s.taskForDependencyChange(ctx, taskId, version)
+	if err != nil {
+		return nil, err
+	}
+
+	blockedBy := []primitive.ObjectID{}
+	for _, id := range current.BlockedBy {
+		if id.Hex() != blockerId {
+			blockedBy = append(blockedBy, id)
+		}
+	}
+	if len(blockedBy) == len(current.BlockedBy) {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("task %s is not blocked by task %s", taskId, blockerId))
+	}
+	updated, err := s.dbservice.PatchTask(ctx, taskId, map[string]interface{}{"blockedBy": blockedBy, "updatedAt": s.now()}, current.Version)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToModel(updated), nil
+}
+
+func (s *taskService) GetDependencyGraph(ctx context.Context, taskId string) (*models.DependencyGraph, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	tasks, err := s.dbservice.GetDependencyGraph(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+
+	graph := &models.DependencyGraph{Nodes: []*models.DependencyNode{}, Edges: []*models.DependencyEdge{}}
+	inGraph := map[primitive.ObjectID]bool{}
+	workflows := map[string]*workflow.Workflow{}
+	for _, task := range tasks {
+		node, err := s.dependencyNode(ctx, task, workflows)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		graph.Nodes = append(graph.Nodes, node)
+		inGraph[task.ID] = true
+	}
+	for _, task := range tasks {
+		for _, blockerId := range task.BlockedBy {
+			// links to deleted tasks are left out
+			if inGraph[blockerId] {
+				graph.Edges = append(graph.Edges, &models.DependencyEdge{From: blockerId.Hex(), To: task.ID.Hex()})
+			}
+		}
+	}
+	return graph, nil
+}
+
+// function to refuse moving the task to a done status while one of its blockers is still open,
+// the conflict lists the open blockers
+func (s *taskService) checkBlockers(ctx context.Context, task *dbmodels.TaskSchema) error {
+	open := []*models.DependencyNode{}
+	workflows := map[string]*workflow.Workflow{}
+	for _, blockerId := range task.BlockedBy {
+		blocker, err := s.dbservice.GetTaskById(ctx, blockerId.Hex())
+		if apperrors.Is(err, apperrors.NotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		node, err := s.dependencyNode(ctx, blocker, workflows)
+		if err != nil {
+			return err
+		}
+		if !node.Done {
+			open = append(open, node)
+		}
+	}
+	if len(open) > 0 {
+		err := apperrors.NewConflictError(fmt.Sprintf("task %s is blocked by %d open tasks", task.ID.Hex(), len(open)), nil)
+		err.Details = map[string]interface{}{"blockers": open}
+		return err
+	}
+	return nil
+}
+
+// function to load the task a link is added to or removed from, checking the precondition and the caller
+func (s *taskService) taskForDependencyChange(ctx context.Context, taskId string, version int64) (*dbmodels.TaskSchema, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	current, err := s.dbservice.GetTaskById(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if version > 0 && current.Version != version {
+		return nil, apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", taskId))
+	}
+	if err := authorizeTaskWrite(ctx, current); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// function to describe the task in a graph, the workflows of the projects are looked up once
+func (s *taskService) dependencyNode(ctx context.Context, task *dbmodels.TaskSchema, workflows map[string]*workflow.Workflow) (*models.DependencyNode, error) {
+	taskWorkflow, found := workflows[task.ProjectID]
+	if !found {
+		var err error
+		if taskWorkflow, err = s.workflowFor(ctx, task.ProjectID); err != nil {
+			return nil, err
+		}
+		workflows[task.ProjectID] = taskWorkflow
+	}
+	return &models.DependencyNode{
+		ID:     task.ID.Hex(),
+		Key:    task.Key,
+		Title:  task.Title,
+		Status: task.Status,
+		Done:   taskWorkflow.IsDone(task.Status),
+	}, nil
+}
+
+// function to check whether the task is among the blockers of the blocker, following the links of the graph
+func blocks(graph []*dbmodels.TaskSchema, taskId primitive.ObjectID, blockerId primitive.ObjectID) bool {
+	byId := map[primitive.ObjectID]*dbmodels.TaskSchema{}
+	for _, task := range graph {
+		byId[task.ID] = task
+	}
+	seen := map[primitive.ObjectID]bool{blockerId: true}
+	queue := []primitive.ObjectID{blockerId}
+	for len(queue) > 0 {
+		task, found := byId[queue[0]]
+		queue = queue[1:]
+		if !found {
+			continue
+		}
+		for _, id := range task.BlockedBy {
+			if id == taskId {
+				return true
+			}
+			if !seen[id] {
+				seen[id] = true
+				queue = append(queue, id)
+			}
+		}
+	}
+	return false
+}
+
+func containsId(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+// function to check the new parent of the task exists, is neither the task nor one of its subtasks,
+// and keeps the hierarchy within the maximum depth. taskId is empty for a task being created
+func (s *taskService) checkParent(ctx context.Context, taskId string, parentId string) error {
+	if len(parentId) == 0 {
+		return nil
+	}
+	if parentId == taskId {
+		return apperrors.NewValidationError("A task cannot be its own parent", map[string]interface{}{"field": "parentId"})
+	}
+
+	// depth of the parent, top level tasks are at depth 1
+	depth := 0
+	for ancestorId := parentId; len(ancestorId) > 0; {
+		ancestor, err := s.dbservice.GetTaskById(ctx, ancestorId)
+		if err != nil {
+			if depth == 0 && (apperrors.Is(err, apperrors.NotFound) || apperrors.Is(err, apperrors.InvalidID)) {
+				return apperrors.NewValidationError(fmt.Sprintf("parent task %s not found", parentId), map[string]interface{}{"field": "parentId"})
+			}
+			return err
+		}
+		if ancestor.ID.Hex() == taskId {
+			return apperrors.NewValidationError("A task cannot be a subtask of one of its subtasks", map[string]interface{}{"field": "parentId"})
+		}
+		depth++
+		if depth >= s.maxDepth {
+			return s.depthError()
+		}
+		ancestorId = ancestor.ParentID
+	}
+
+	// the subtasks of a task that moves move along with it
+	height := 1
+	if len(taskId) > 0 {
+		levels, err := s.subtaskLevels(ctx, taskId, s.maxDepth)
+		if err != nil {
+			return err
+		}
+		height += len(levels)
+	}
+	if depth+height > s.maxDepth {
+		return s.depthError()
+	}
+	return nil
+}
+
+func (s *taskService) depthError() error {
+	return apperrors.NewValidationError(fmt.Sprintf("Subtasks can be nested %d levels deep at most", s.maxDepth),
+		map[string]interface{}{"field": "parentId", "maxDepth": s.maxDepth})
+}
+
+// function to list the subtasks of the task level by level, the direct subtasks first.
+// at most maxLevels levels are read, 0 reads them all
+func (s *taskService) subtaskLevels(ctx context.Context, taskId string, maxLevels int) ([][]*dbmodels.TaskSchema, error) {
+	return s.subtaskLevelsIn(ctx, taskId, maxLevels, false)
+}
+
+// function to list the subtasks of a task of the trash level by level, they are all in the trash too
+func (s *taskService) trashedSubtaskLevels(ctx context.Context, taskId string) ([][]*dbmodels.TaskSchema, error) {
+	return s.subtaskLevelsIn(ctx, taskId, 0, true)
+}
+
+func (s *taskService) subtaskLevelsIn(ctx context.Context, taskId string, maxLevels int, deleted bool) ([][]*dbmodels.TaskSchema, error) {
+	var levels [][]*dbmodels.TaskSchema
+	seen := map[string]bool{taskId: true}
+	parents := []string{taskId}
+	for len(parents) > 0 && (maxLevels == 0 || len(levels) < maxLevels) {
+		var level []*dbmodels.TaskSchema
+		var next []string
+		for _, parentId := range parents {
+			children, err := s.subtasks(ctx, parentId, deleted)
+			if err != nil {
+				return nil, err
+			}
+			for _, child := range children {
+				// guards against a cycle written before the hierarchy was checked
+				if seen[child.ID.Hex()] {
+					continue
+				}
+				seen[child.ID.Hex()] = true
+				level = append(level, child)
+				next = append(next, child.ID.Hex())
+			}
+		}
+		if len(level) == 0 {
+			break
+		}
+		levels = append(levels, level)
+		parents = next
+	}
+	return levels, nil
+}
+
+// function to read every direct subtask of the task, page by page, those of the trash when deleted is set
+func (s *taskService) subtasks(ctx context.Context, parentId string, deleted bool) ([]*dbmodels.TaskSchema, error) {
+	query := &models.TaskQuery{Limit: models.MaxTaskLimit, SortBy: models.SortByCreatedAt, ParentID: parentId, Deleted: deleted}
+	var subtasks []*dbmodels.TaskSchema
+	for {
+		page, err := s.dbservice.GetTasks(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		subtasks = append(subtasks, page.Tasks...)
+		if len(page.NextCursor) == 0 {
+			return subtasks, nil
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+// function to count the direct subtasks of the task and those in a done status of its workflow
+func (s *taskService) rollup(ctx context.Context, task *dbmodels.TaskSchema) (*models.SubtaskRollup, error) {
+	parentId := task.ID.Hex()
+	total, err := s.dbservice.GetTasks(ctx, &models.TaskQuery{Limit: 1, SortBy: models.SortByCreatedAt, ParentID: parentId})
+	if err != nil {
+		return nil, err
+	}
+	rollup := &models.SubtaskRollup{TotalSubtasks: total.Total}
+	if total.Total == 0 {
+		return rollup, nil
+	}
+
+	taskWorkflow, err := s.workflowFor(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if len(taskWorkflow.Done) > 0 {
+		done, err := s.dbservice.GetTasks(ctx, &models.TaskQuery{Limit: 1, SortBy: models.SortByCreatedAt, ParentID: parentId, Status: taskWorkflow.Done})
+		if err != nil {
+			return nil, err
+		}
+		rollup.CompletedSubtasks = done.Total
+	}
+	rollup.PercentComplete = rollup.CompletedSubtasks * 100 / rollup.TotalSubtasks
+	return rollup, nil
+}
+
+// function to move the subtasks of the task to the trash, the deepest first so a failure never leaves a live subtask
+// under a parent in the trash. every subtask is checked before the first one is moved, they all get the time of the
+// task so they are restored along with it
+func (s *taskService) trashSubtasks(ctx context.Context, levels [][]*dbmodels.TaskSchema, deletedAt time.Time) error {
+	for _, level := range levels {
+		for _, subtask := range level {
+			if err := authorizeTaskDelete(ctx, subtask); err != nil {
+				return err
+			}
+		}
+	}
+	for i := len(levels) - 1; i >= 0; i-- {
+		for _, subtask := range levels[i] {
+			err := s.dbservice.TrashTask(ctx, subtask.ID.Hex(), 0, deletedAt, appauth.GetSubject(ctx))
+			if err != nil && !apperrors.Is(err, apperrors.NotFound) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// function to delete the task of the trash for good with its subtasks, the deepest first, and what belongs to them.
+// it returns the number of tasks deleted
+func (s *taskService) deleteTrashedTask(ctx context.Context, task *dbmodels.TaskSchema, levels [][]*dbmodels.TaskSchema, version int64) (int, error) {
+	deleted := 0
+	for i := len(levels) - 1; i >= 0; i-- {
+		for _, subtask := range levels[i] {
+			err := s.dbservice.DeleteTaskById(ctx, subtask.ID.Hex(), 0)
+			if apperrors.Is(err, apperrors.NotFound) {
+				continue
+			}
+			if err != nil {
+				return deleted, err
+			}
+			s.cleanupDeletedTask(ctx, subtask)
+			deleted++
+		}
+	}
+	if err := s.dbservice.DeleteTaskById(ctx, task.ID.Hex(), version); err != nil {
+		return deleted, err
+	}
+	s.cleanupDeletedTask(ctx, task)
+	return deleted + 1, nil
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/models"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+)
+
+// function to apply the patch, version is the If-Match precondition or 0.
+// the write is always conditional on the version that was read, so concurrent changes are not lost
+func (s *taskService) PatchTask(ctx context.Context, taskId string, patch *models.TaskPatch, version int64) (*models.Task, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	current, err := s.dbservice.GetTaskById(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if version > 0 && current.Version != version {
+		return nil, apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", taskId))
+	}
+	if err := authorizeTaskWrite(ctx, current); err != nil {
+		return nil, err
+	}
+
+	before := commons.MapToModel(current)
+	after, err := applyTaskPatch(before, patch)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateTask(after); err != nil {
+		return nil, err
+	}
+	if after.Status != before.Status {
+		taskWorkflow, err := s.workflowFor(ctx, current.ProjectID)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		if after.Status, err = taskWorkflow.CheckTransition(before.Status, after.Status); err != nil {
+			return nil, err
+		}
+		if !taskWorkflow.IsDone(before.Status) && taskWorkflow.IsDone(after.Status) {
+			if err := s.checkBlockers(ctx, current); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if after.ParentID != before.ParentID {
+		if err := s.checkParent(ctx, taskId, after.ParentID); err != nil {
+			return nil, err
+		}
+	}
+
+	fields := changedTaskFields(before, after)
+	customFields, err := s.customFieldValues(ctx, after.CustomFields, before.CustomFields, false)
+	if err != nil {
+		return nil, err
+	}
+	if !sameCustomFields(before.CustomFields, customFields) {
+		fields["customFields"] = customFields
+	}
+	if len(fields) == 0 {
+		return before, nil
+	}
+	fields["updatedAt"] = s.now()
+	updated, err := s.dbservice.PatchTask(ctx, taskId, fields, current.Version)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToModel(updated), nil
+}
+
+// function to apply the patch document to the json representation of the task
+func applyTaskPatch(task *models.Task, patch *models.TaskPatch) (*models.Task, error) {
+	document, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch patch.ContentType {
+	case models.MergePatchContentType:
+		patched, err = jsonpatch.MergePatch(document, patch.Document)
+		if err != nil {
+			return nil, apperrors.NewBadRequestError("invalid merge patch document")
+		}
+	case models.JsonPatchContentType:
+		operations, err := jsonpatch.DecodePatch(patch.Document)
+		if err != nil {
+			return nil, apperrors.NewBadRequestError("invalid json patch document")
+		}
+		patched, err = operations.Apply(document)
+		if errors.Is(err, jsonpatch.ErrTestFailed) {
+			return nil, apperrors.NewConflictError("json patch test operation failed", err)
+		}
+		if err != nil {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("json patch could not be applied: %v", err), nil)
+		}
+	default:
+		return nil, apperrors.NewBadRequestError(fmt.Sprintf("unsupported patch content type: %s", patch.ContentType))
+	}
+
+	var result models.Task
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&result); err != nil {
+		return nil, apperrors.NewValidationError(fmt.Sprintf("patched task is invalid: %v", err), nil)
+	}
+	if result.ID != task.ID || !result.CreatedAt.Equal(task.CreatedAt) || !result.UpdatedAt.Equal(task.UpdatedAt) ||
+		result.Version != task.Version || result.CreatedBy != task.CreatedBy || result.WorkspaceID != task.WorkspaceID ||
+		result.ProjectID != task.ProjectID || result.Key != task.Key || result.TimeSpent != task.TimeSpent || !sameStrings(result.BlockedBy, task.BlockedBy) ||
+		!sameAttachments(result.Attachments, task.Attachments) || !sameChecklist(result.Checklist, task.Checklist) ||
+		result.RecurrenceOf != task.RecurrenceOf || result.NextOccurrenceID != task.NextOccurrenceID ||
+		result.DeletedAt != nil || len(result.DeletedBy) > 0 {
+		return nil, apperrors.NewValidationError("id, createdAt, updatedAt, version, createdBy, workspaceId, projectId, key, timeSpent, blockedBy, attachments, checklist, recurrenceOf, nextOccurrenceId, deletedAt and deletedBy are read-only", nil)
+	}
+	return &result, nil
+}
+
+// function to list the db fields the patch changed, so only those are written
+func changedTaskFields(before, after *models.Task) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if before.Title != after.Title {
+		fields["title"] = after.Title
+	}
+	if before.Description != after.Description {
+		fields["description"] = after.Description
+	}
+	if before.Status != after.Status {
+		fields["status"] = after.Status
+	}
+	if before.Priority != after.Priority {
+		fields["priority"] = after.Priority
+		fields["priorityRank"] = models.PriorityRank(after.Priority)
+	}
+	if !sameTime(before.StartDate, after.StartDate) {
+		fields["startDate"] = after.StartDate
+	}
+	if !sameTime(before.DueDate, after.DueDate) {
+		fields["dueDate"] = after.DueDate
+	}
+	if !sameEstimate(before.OriginalEstimate, after.OriginalEstimate) {
+		fields["originalEstimate"] = after.OriginalEstimate
+	}
+	if !sameEstimate(before.RemainingEstimate, after.RemainingEstimate) {
+		fields["remainingEstimate"] = after.RemainingEstimate
+	}
+	if before.Reporter != after.Reporter {
+		fields["reporter"] = after.Reporter
+	}
+	if !sameStrings(before.Assignees, after.Assignees) {
+		fields["assignees"] = after.Assignees
+	}
+	if !sameStrings(before.Labels, after.Labels) {
+		fields["labels"] = after.Labels
+	}
+	if before.ParentID != after.ParentID {
+		fields["parentId"] = after.ParentID
+	}
+	if before.Recurrence != after.Recurrence {
+		fields["recurrence"] = after.Recurrence
+	}
+	return fields
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func sameEstimate(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameAttachments(a, b []models.Attachment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID || a[i].Name != b[i].Name || a[i].Size != b[i].Size || a[i].ContentType != b[i].ContentType ||
+			a[i].SHA256 != b[i].SHA256 || a[i].UploadedBy != b[i].UploadedBy || !a[i].UploadedAt.Equal(b[i].UploadedAt) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameChecklist(a, b []models.ChecklistItem) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID || a[i].Text != b[i].Text || a[i].Done != b[i].Done || a[i].DoneBy != b[i].DoneBy ||
+			(a[i].DoneAt == nil) != (b[i].DoneAt == nil) || (a[i].DoneAt != nil && !a[i].DoneAt.Equal(*b[i].DoneAt)) {
+			return false
+		}
+	}
+	return true
+}
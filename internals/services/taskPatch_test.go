@@ -0,0 +1,217 @@
+package services
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PatchTask", func() {
+	now := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+	created := time.Date(2024, 2, 1, 8, 0, 0, 0, time.UTC)
+
+	var (
+		patchedFields map[string]interface{}
+		mockDbService db.MockDbService
+	)
+
+	BeforeEach(func() {
+		patchedFields = nil
+		mockDbService = db.MockDbService{
+			FakeGetTaskById: func(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error) {
+				return &dbmodels.TaskSchema{
+					ID: id, Title: "Task 1", Description: "Task 1 Description", Status: "New",
+					CreatedAt: created, UpdatedAt: created, Version: 2,
+				}, nil
+			},
+			FakePatchTask: func(ctx context.Context, taskId string, fields map[string]interface{}, version int64) (*dbmodels.TaskSchema, error) {
+				Expect(version).To(Equal(int64(2)))
+				patchedFields = fields
+				return &dbmodels.TaskSchema{
+					ID: id, Title: "Task 1", Description: "Task 1 Description", Status: fields["status"].(string),
+					CreatedAt: created, UpdatedAt: fields["updatedAt"].(time.Time),
+				}, nil
+			},
+		}
+	})
+
+	patchTaskVersion := func(contentType string, document string, version int64) (*models.Task, error) {
+		service := NewTaskService(mockDbService, WithClock(fakeClock{now: now}))
+		ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+		return service.PatchTask(ctx, id.Hex(), &models.TaskPatch{ContentType: contentType, Document: []byte(document)}, version)
+	}
+
+	patchTask := func(contentType string, document string) (*models.Task, error) {
+		return patchTaskVersion(contentType, document, 0)
+	}
+
+	It("merge patch only sets the changed fields", func() {
+		task, err := patchTask(models.MergePatchContentType, `{"status": "InProgress"}`)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Status).To(Equal("InProgress"))
+		Expect(patchedFields).To(Equal(map[string]interface{}{"status": "InProgress", "updatedAt": now}))
+	})
+
+	It("merge patch sets the priority with its rank and the due date", func() {
+		mockDbService.FakePatchTask = func(ctx context.Context, taskId string, fields map[string]interface{}, version int64) (*dbmodels.TaskSchema, error) {
+			patchedFields = fields
+			return &dbmodels.TaskSchema{ID: id, Title: "Task 1", Priority: fields["priority"].(string)}, nil
+		}
+
+		task, err := patchTask(models.MergePatchContentType, `{"priority": "urgent", "dueDate": "2024-04-01T00:00:00Z"}`)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Priority).To(Equal(models.PriorityUrgent))
+		due := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+		Expect(patchedFields).To(Equal(map[string]interface{}{
+			"priority": "urgent", "priorityRank": 4, "dueDate": &due, "updatedAt": now,
+		}))
+	})
+
+	It("rejects an unknown priority", func() {
+		_, err := patchTask(models.MergePatchContentType, `{"priority": "someday"}`)
+
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		Expect(patchedFields).To(BeNil())
+	})
+
+	It("writes conditionally on the version that was read", func() {
+		_, err := patchTaskVersion(models.MergePatchContentType, `{"status": "InProgress"}`, 2)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patchedFields).To(HaveKey("status"))
+	})
+
+	It("stale If-Match fails the precondition without writing", func() {
+		_, err := patchTaskVersion(models.MergePatchContentType, `{"status": "InProgress"}`, 1)
+
+		Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+		Expect(patchedFields).To(BeNil())
+	})
+
+	It("json patch replaces the status", func() {
+		task, err := patchTask(models.JsonPatchContentType,
+			`[{"op": "test", "path": "/status", "value": "New"}, {"op": "replace", "path": "/status", "value": "InProgress"}]`)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Status).To(Equal("InProgress"))
+		Expect(patchedFields).To(HaveKey("status"))
+		Expect(patchedFields).NotTo(HaveKey("title"))
+	})
+
+	It("rejects a transition the workflow does not allow", func() {
+		_, err := patchTask(models.MergePatchContentType, `{"status": "Done"}`)
+
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		var appErr *apperrors.AppError
+		Expect(errors.As(err, &appErr)).To(BeTrue())
+		Expect(appErr.Details["allowed"]).To(Equal([]string{"InProgress"}))
+		Expect(patchedFields).To(BeNil())
+	})
+
+	It("stores the status as spelled in the workflow", func() {
+		task, err := patchTask(models.MergePatchContentType, `{"status": "INPROGRESS"}`)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Status).To(Equal("InProgress"))
+		Expect(patchedFields["status"]).To(Equal("InProgress"))
+	})
+
+	It("does not write when only the case of the status changed", func() {
+		_, err := patchTask(models.MergePatchContentType, `{"status": "new"}`)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patchedFields).To(BeNil())
+	})
+
+	It("does not write when nothing changed", func() {
+		task, err := patchTask(models.MergePatchContentType, `{"status": "New"}`)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Status).To(Equal("New"))
+		Expect(patchedFields).To(BeNil())
+	})
+
+	It("failed test operation is a conflict", func() {
+		_, err := patchTask(models.JsonPatchContentType, `[{"op": "test", "path": "/status", "value": "Done"}]`)
+
+		Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+	})
+
+	It("operation on a missing path is a validation error", func() {
+		_, err := patchTask(models.JsonPatchContentType, `[{"op": "remove", "path": "/priority"}]`)
+
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+
+	It("malformed document is a bad request", func() {
+		_, err := patchTask(models.JsonPatchContentType, `{"op": "replace"}`)
+
+		Expect(apperrors.Is(err, apperrors.BadRequest)).To(BeTrue())
+	})
+
+	It("rejects removing a required field", func() {
+		_, err := patchTask(models.MergePatchContentType, `{"title": null}`)
+
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		Expect(patchedFields).To(BeNil())
+	})
+
+	It("rejects read-only fields", func() {
+		_, err := patchTask(models.MergePatchContentType, `{"createdAt": "2020-01-01T00:00:00Z"}`)
+
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+
+	It("rejects moving the task to the trash", func() {
+		_, err := patchTask(models.MergePatchContentType, `{"deletedAt": "2020-01-01T00:00:00Z"}`)
+
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+
+	It("rejects linking occurrences", func() {
+		_, err := patchTask(models.MergePatchContentType, `{"nextOccurrenceId": "6650a1b2c3d4e5f601234567"}`)
+
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+
+	It("rejects changing the creator", func() {
+		_, err := patchTask(models.MergePatchContentType, `{"createdBy": "user-2"}`)
+
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+
+	It("sets the assignees", func() {
+		mockDbService.FakePatchTask = func(ctx context.Context, taskId string, fields map[string]interface{}, version int64) (*dbmodels.TaskSchema, error) {
+			patchedFields = fields
+			return &dbmodels.TaskSchema{ID: id, Assignees: fields["assignees"].([]string)}, nil
+		}
+
+		task, err := patchTask(models.JsonPatchContentType, `[{"op": "add", "path": "/assignees", "value": ["user-2"]}]`)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.Assignees).To(Equal([]string{"user-2"}))
+		Expect(patchedFields).To(Equal(map[string]interface{}{"assignees": []string{"user-2"}, "updatedAt": now}))
+	})
+
+	It("rejects version changes", func() {
+		_, err := patchTask(models.JsonPatchContentType, `[{"op": "replace", "path": "/version", "value": 9}]`)
+
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+
+	It("rejects unknown fields", func() {
+		_, err := patchTask(models.MergePatchContentType, `{"owner": "someone"}`)
+
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+})
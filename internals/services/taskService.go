@@ -2,28 +2,117 @@ package services
 
 import (
 	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
 	"TaskSvc/commons/apploggers"
 	"TaskSvc/internals/db"
-	dbmodel "TaskSvc/internals/db/models"
+	dbmodels "TaskSvc/internals/db/models"
 	"TaskSvc/internals/models"
+	"TaskSvc/internals/recurrence"
+	"TaskSvc/internals/workflow"
 	"context"
-	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type TaskService interface {
 	GetTaskById(context context.Context, taskId string) (*models.Task, error)
-	DeleteTaskById(context context.Context, taskId string) error
-	GetTasks(context context.Context) ([]*models.Task, error)
+	// DeleteTaskById moves the task to the trash. it refuses a task with subtasks unless cascade is set,
+	// then they go to the trash too
+	DeleteTaskById(context context.Context, taskId string, version int64, cascade bool) error
+	GetTasks(context context.Context, query *models.TaskQuery) (*models.TaskList, error)
 	CreateTask(context context.Context, task *models.Task) (string, error)
-	UpdateTask(context context.Context, task *models.Task, taskId string) error
+	UpdateTask(context context.Context, task *models.Task, taskId string, version int64) error
+	PatchTask(context context.Context, taskId string, patch *models.TaskPatch, version int64) (*models.Task, error)
+	AddDependency(context context.Context, taskId string, blockerId string, version int64) (*models.Task, error)
+	RemoveDependency(context context.Context, taskId string, blockerId string, version int64) (*models.Task, error)
+	GetDependencyGraph(context context.Context, taskId string) (*models.DependencyGraph, error)
 }
 
 type taskService struct {
 	dbservice db.DbService
+	projects  db.ProjectDbService
+	comments  db.CommentDbService
+	blobs     db.BlobStore
+	fields    db.CustomFieldDbService
+	worklogs  db.WorklogDbService
+	clock     commons.Clock
+	workflow  *workflow.Workflow
+	maxDepth  int
+}
+
+type TaskServiceOption func(*taskService)
+
+// option to replace the system clock used for the createdAt and updatedAt timestamps
+func WithClock(clock commons.Clock) TaskServiceOption {
+	return func(s *taskService) {
+		s.clock = clock
+	}
+}
+
+// option to replace the built-in workflow the status changes are checked against
+func WithWorkflow(workflow *workflow.Workflow) TaskServiceOption {
+	return func(s *taskService) {
+		s.workflow = workflow
+	}
+}
+
+// option to group tasks in projects, without it tasks cannot be created in a project
+func WithProjects(projects db.ProjectDbService) TaskServiceOption {
+	return func(s *taskService) {
+		s.projects = projects
+	}
+}
+
+// option to change the number of levels a task hierarchy can have
+func WithMaxSubtaskDepth(maxDepth int) TaskServiceOption {
+	return func(s *taskService) {
+		s.maxDepth = maxDepth
+	}
+}
+
+// option to count the comments of a task when it is read and delete them along with it
+func WithComments(comments db.CommentDbService) TaskServiceOption {
+	return func(s *taskService) {
+		s.comments = comments
+	}
+}
+
+// option to delete the content of the attachments of a task along with it
+func WithBlobStore(blobs db.BlobStore) TaskServiceOption {
+	return func(s *taskService) {
+		s.blobs = blobs
+	}
+}
+
+// option to check the custom field values of the tasks against the definitions of the workspace,
+// without it tasks cannot have custom fields
+func WithCustomFields(fields db.CustomFieldDbService) TaskServiceOption {
+	return func(s *taskService) {
+		s.fields = fields
+	}
+}
+
+// option to delete the worklogs and the running timers of a task along with it
+func WithWorklogs(worklogs db.WorklogDbService) TaskServiceOption {
+	return func(s *taskService) {
+		s.worklogs = worklogs
+	}
+}
+
+func NewTaskService(dbservice db.DbService, opts ...TaskServiceOption) TaskService {
+	return newTaskService(dbservice, opts...)
 }
 
-func NewTaskService(dbservice db.DbService) TaskService {
-	return &taskService{dbservice: dbservice}
+func newTaskService(dbservice db.DbService, opts ...TaskServiceOption) *taskService {
+	service := &taskService{dbservice: dbservice, clock: commons.SystemClock, workflow: workflow.Default(), maxDepth: models.DefaultMaxSubtaskDepth}
+	for _, opt := range opts {
+		opt(service)
+	}
+	return service
 }
 
 func (s *taskService) GetTaskById(ctx context.Context, taskId string) (*models.Task, error) {
@@ -33,44 +122,189 @@ func (s *taskService) GetTaskById(ctx context.Context, taskId string) (*models.T
 		logger.Error(err)
 		return nil, err
 	}
-	return commons.MapToModel(taskSchema), nil
+	task := commons.MapToModel(taskSchema)
+	if task.Rollup, err = s.rollup(ctx, taskSchema); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if s.comments != nil {
+		count, err := s.comments.CountComments(ctx, taskId)
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		task.CommentCount = &count
+	}
+	return task, nil
 }
 
-func (s *taskService) GetTasks(ctx context.Context) ([]*models.Task, error) {
+func (s *taskService) GetTasks(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
 	logger := apploggers.GetLoggerWithCorrelationid(ctx)
-	taskSchemas, err := s.dbservice.GetTasks(ctx)
+	var err error
+	if query.Assignee, err = resolveCurrentUser(ctx, query.Assignee, "assignee"); err != nil {
+		return nil, err
+	}
+	if query.CreatedBy, err = resolveCurrentUser(ctx, query.CreatedBy, "created_by"); err != nil {
+		return nil, err
+	}
+	if query.Overdue || len(query.Status) > 0 {
+		// the workflow of the project when listing one, the workflow of the deployment otherwise
+		taskWorkflow, err := s.workflowFor(ctx, query.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		// statuses are stored as spelled in the workflow, the filter matches them exactly
+		for i, status := range query.Status {
+			if canonical, ok := taskWorkflow.Canonical(status); ok {
+				query.Status[i] = canonical
+			}
+		}
+		if query.Overdue {
+			query.OverdueAt = s.now()
+			query.DoneStatuses = taskWorkflow.Done
+		}
+	}
+	if err := s.customFieldQuery(ctx, query); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	page, err := s.dbservice.GetTasks(ctx, query)
 	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
 
-	tasks := make([]*models.Task, len(taskSchemas))
-	for i, taskSchema := range taskSchemas {
+	tasks := make([]*models.Task, len(page.Tasks))
+	for i, taskSchema := range page.Tasks {
 		tasks[i] = commons.MapToModel(taskSchema)
 	}
 
-	return tasks, nil
+	return &models.TaskList{
+		Total:      page.Total,
+		Tasks:      tasks,
+		NextCursor: page.NextCursor,
+	}, nil
 }
 
-func (s *taskService) DeleteTaskById(ctx context.Context, taskId string) error {
+// function to move the task to the trash, only its creator or an admin can. a task with subtasks is kept unless
+// cascade is set, the caller must then be allowed to delete every subtask too. nothing of the task is removed
+// until it is deleted from the trash, so it can be restored as it was
+func (s *taskService) DeleteTaskById(ctx context.Context, taskId string, version int64, cascade bool) error {
 	logger := apploggers.GetLoggerWithCorrelationid(ctx)
-	if err := s.dbservice.DeleteTaskById(ctx, taskId); err != nil {
+	current, err := s.dbservice.GetTaskById(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if version > 0 && current.Version != version {
+		return apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", taskId))
+	}
+	if err := authorizeTaskDelete(ctx, current); err != nil {
+		return err
+	}
+	levels, err := s.subtaskLevels(ctx, taskId, 0)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	deletedAt := s.now()
+	if len(levels) > 0 {
+		if !cascade {
+			return apperrors.NewConflictError(fmt.Sprintf("task %s still has %d subtasks", taskId, len(levels[0])), nil)
+		}
+		if err := s.trashSubtasks(ctx, levels, deletedAt); err != nil {
+			logger.Error(err)
+			return err
+		}
+	}
+	if err := s.dbservice.TrashTask(ctx, taskId, current.Version, deletedAt, appauth.GetSubject(ctx)); err != nil {
 		logger.Error(err)
 		return err
 	}
 	return nil
 }
 
+// function to drop the task deleted from the trash from the tasks it blocked and delete its comments, attachments
+// and worklogs. a failure is only logged, the task is gone and what is left behind is never reached through it
+func (s *taskService) cleanupDeletedTask(ctx context.Context, task *dbmodels.TaskSchema) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	taskId := task.ID.Hex()
+	if err := s.dbservice.RemoveBlocker(ctx, taskId); err != nil {
+		logger.Error(err)
+	}
+	if s.comments != nil {
+		if err := s.comments.DeleteComments(ctx, taskId); err != nil {
+			logger.Error(err)
+		}
+	}
+	if s.blobs != nil {
+		for _, attachment := range task.Attachments {
+			if err := s.blobs.Delete(ctx, attachment.ID.Hex()); err != nil {
+				logger.Error(err)
+			}
+		}
+	}
+	if s.worklogs != nil {
+		if err := s.worklogs.DeleteWorklogs(ctx, taskId); err != nil {
+			logger.Error(err)
+		}
+	}
+}
+
 func (s *taskService) CreateTask(ctx context.Context, task *models.Task) (string, error) {
 	logger := apploggers.GetLoggerWithCorrelationid(ctx)
-	var taskSchema dbmodel.TaskSchema
-	pbyes, _ := json.Marshal(task)
-	err := json.Unmarshal(pbyes, &taskSchema)
+	taskWorkflow, err := s.workflowFor(ctx, task.ProjectID)
 	if err != nil {
-		logger.Error(err)
+		if apperrors.Is(err, apperrors.NotFound) || apperrors.Is(err, apperrors.InvalidID) {
+			return "", apperrors.NewValidationError(fmt.Sprintf("project %s not found", task.ProjectID), map[string]interface{}{"field": "projectId"})
+		}
+		return "", err
+	}
+	if len(strings.TrimSpace(task.Status)) == 0 {
+		task.Status = taskWorkflow.Initial
+	}
+	if err := validateTask(task); err != nil {
+		return "", err
+	}
+	status, err := taskWorkflow.CheckStatus(task.Status)
+	if err != nil {
+		return "", err
+	}
+	if err := s.checkParent(ctx, "", task.ParentID); err != nil {
+		return "", err
+	}
+
+	// ids and timestamps are owned by the server, whatever the client sent
+	taskSchema := commons.MapToSchema(task)
+	taskSchema.ID = primitive.NilObjectID
+	taskSchema.Status = status
+	taskSchema.CreatedBy = appauth.GetSubject(ctx)
+	if len(taskSchema.Reporter) == 0 {
+		taskSchema.Reporter = taskSchema.CreatedBy
+	}
+	taskSchema.CreatedAt = s.now()
+	taskSchema.UpdatedAt = taskSchema.CreatedAt
+	// nothing is logged yet, all of the original estimate remains
+	if taskSchema.RemainingEstimate == nil && taskSchema.OriginalEstimate != nil {
+		remaining := *taskSchema.OriginalEstimate
+		taskSchema.RemainingEstimate = &remaining
+	}
+	if taskSchema.Checklist, err = newChecklist(ctx, task.Checklist, taskSchema.CreatedAt); err != nil {
+		return "", err
+	}
+	if taskSchema.CustomFields, err = s.customFieldValues(ctx, task.CustomFields, nil, true); err != nil {
 		return "", err
 	}
-	taskId, err := s.dbservice.SaveTask(ctx, &taskSchema)
+	if len(taskSchema.CustomFields) == 0 {
+		taskSchema.CustomFields = nil
+	}
+	if len(taskSchema.ProjectID) > 0 {
+		if taskSchema.Key, err = s.projects.AllocateTaskKey(ctx, taskSchema.ProjectID); err != nil {
+			logger.Error(err)
+			return "", err
+		}
+	}
+	taskId, err := s.dbservice.SaveTask(ctx, taskSchema)
 	if err != nil {
 		logger.Error(err)
 		return "", err
@@ -78,14 +312,132 @@ func (s *taskService) CreateTask(ctx context.Context, task *models.Task) (string
 	return taskId, nil
 }
 
-func (s *taskService) UpdateTask(ctx context.Context, task *models.Task, taskId string) error {
+// function to replace the task, the status change is checked against the workflow
+// and the write is conditional on the version that was read like PatchTask
+func (s *taskService) UpdateTask(ctx context.Context, task *models.Task, taskId string, version int64) error {
 	logger := apploggers.GetLoggerWithCorrelationid(ctx)
-	var taskSchema dbmodel.TaskSchema
-	pbyes, _ := json.Marshal(task)
-	err := json.Unmarshal(pbyes, &taskSchema)
+	if err := validateTask(task); err != nil {
+		return err
+	}
+	current, err := s.dbservice.GetTaskById(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if version > 0 && current.Version != version {
+		return apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", taskId))
+	}
+	if err := authorizeTaskWrite(ctx, current); err != nil {
+		return err
+	}
+	taskWorkflow, err := s.workflowFor(ctx, current.ProjectID)
 	if err != nil {
 		logger.Error(err)
 		return err
 	}
-	return s.dbservice.UpdateTask(ctx, &taskSchema, taskId)
+	status, err := taskWorkflow.CheckTransition(current.Status, task.Status)
+	if err != nil {
+		return err
+	}
+	if task.ParentID != current.ParentID {
+		if err := s.checkParent(ctx, taskId, task.ParentID); err != nil {
+			return err
+		}
+	}
+	if !taskWorkflow.IsDone(current.Status) && taskWorkflow.IsDone(status) {
+		if err := s.checkBlockers(ctx, current); err != nil {
+			return err
+		}
+	}
+
+	// without custom fields in the payload the task keeps its values
+	taskSchema := commons.MapToSchema(task)
+	if task.CustomFields != nil {
+		current := commons.MapToCustomFieldValues(current.CustomFields)
+		if taskSchema.CustomFields, err = s.customFieldValues(ctx, task.CustomFields, current, false); err != nil {
+			return err
+		}
+	}
+	taskSchema.Status = status
+	taskSchema.UpdatedAt = s.now()
+	if err := s.dbservice.UpdateTask(ctx, taskSchema, taskId, current.Version); err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// function to get the workflow the tasks of the project follow, the workflow of the deployment
+// for tasks outside of a project and projects that do not override it
+func (s *taskService) workflowFor(ctx context.Context, projectId string) (*workflow.Workflow, error) {
+	if len(projectId) == 0 {
+		return s.workflow, nil
+	}
+	if s.projects == nil {
+		return nil, apperrors.NewNotFoundError(fmt.Sprintf("project %s not found", projectId))
+	}
+	project, err := s.projects.GetProjectById(ctx, projectId)
+	if err != nil {
+		return nil, err
+	}
+	if project.Workflow != nil {
+		return project.Workflow, nil
+	}
+	return s.workflow, nil
+}
+
+// function to get the current time at the millisecond precision mongo stores
+func (s *taskService) now() time.Time {
+	return s.clock.Now().UTC().Truncate(time.Millisecond)
+}
+
+// function to check the task rules shared by every write path, the labels are normalized in place
+func validateTask(task *models.Task) error {
+	if len(strings.TrimSpace(task.Title)) == 0 {
+		return apperrors.NewValidationError("Title is required", map[string]interface{}{"field": "title"})
+	}
+	if len(strings.TrimSpace(task.Description)) == 0 {
+		return apperrors.NewValidationError("Description is required", map[string]interface{}{"field": "description"})
+	}
+	if len(strings.TrimSpace(task.Status)) == 0 {
+		return apperrors.NewValidationError("Status is required", map[string]interface{}{"field": "status"})
+	}
+	if !models.ValidPriority(task.Priority) {
+		return apperrors.NewValidationError("Priority must be low, medium, high or urgent", map[string]interface{}{"field": "priority"})
+	}
+	if (task.OriginalEstimate != nil && *task.OriginalEstimate < 0) || (task.RemainingEstimate != nil && *task.RemainingEstimate < 0) {
+		return apperrors.NewValidationError("Estimates must not be negative", map[string]interface{}{"field": "originalEstimate"})
+	}
+	if task.StartDate != nil && task.DueDate != nil && task.StartDate.After(*task.DueDate) {
+		return apperrors.NewValidationError("Start date must not be after the due date", map[string]interface{}{"field": "startDate"})
+	}
+	for _, assignee := range task.Assignees {
+		if len(strings.TrimSpace(assignee)) == 0 {
+			return apperrors.NewValidationError("Assignees must not be empty", map[string]interface{}{"field": "assignees"})
+		}
+	}
+	labels, err := normalizeLabels(task.Labels)
+	if err != nil {
+		return err
+	}
+	task.Labels = labels
+	return validateRecurrence(task)
+}
+
+// function to check the recurrence rule of the task and store it in its canonical form,
+// the occurrences are counted from the due date so a recurring task needs one
+func validateRecurrence(task *models.Task) error {
+	if len(strings.TrimSpace(task.Recurrence)) == 0 {
+		task.Recurrence = ""
+		return nil
+	}
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		return apperrors.NewValidationError(fmt.Sprintf("Invalid recurrence: %v", err), map[string]interface{}{"field": "recurrence"})
+	}
+	if task.DueDate == nil {
+		return apperrors.NewValidationError("A recurring task needs a due date", map[string]interface{}{"field": "dueDate"})
+	}
+	task.Recurrence = rule.String()
+	return nil
 }
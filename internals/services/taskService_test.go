@@ -1,13 +1,19 @@
 package services
 
 import (
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
 	"TaskSvc/commons/apploggers"
 	"TaskSvc/internals/db"
 	dbmodels "TaskSvc/internals/db/models"
 	"TaskSvc/internals/models"
+	"TaskSvc/internals/workflow"
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -24,6 +30,51 @@ func convertToModelTask(taskSchema *dbmodels.TaskSchema) *models.Task {
 
 var id = primitive.NewObjectID()
 
+type fakeClock struct {
+	now time.Time
+}
+
+func (f fakeClock) Now() time.Time {
+	return f.now
+}
+
+// function to fake the task an update reads before writing
+func currentTask(status string) func(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error) {
+	return func(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error) {
+		return &dbmodels.TaskSchema{Title: "Task", Description: "Description", Status: status, Version: 4}, nil
+	}
+}
+
+// function to fake a task created by the subject
+func ownedTask(createdBy string) func(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error) {
+	return func(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error) {
+		return &dbmodels.TaskSchema{Title: "Task", Description: "Description", Status: "New", CreatedBy: createdBy, Version: 4}, nil
+	}
+}
+
+// function to fake a task without subtasks
+func noSubtasks(ctx context.Context, query *models.TaskQuery) (*dbmodels.TaskPage, error) {
+	return &dbmodels.TaskPage{Tasks: []*dbmodels.TaskSchema{}}, nil
+}
+
+// function to build a merge patch of the document
+func mergePatch(document string) *models.TaskPatch {
+	return &models.TaskPatch{ContentType: models.MergePatchContentType, Document: []byte(document)}
+}
+
+// function to get a context authenticated as the subject with the roles of the built-in policy
+func asUser(subject string, roles ...string) context.Context {
+	ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+	granted := make([]interface{}, len(roles))
+	for i, role := range roles {
+		granted[i] = role
+	}
+	claims := jwt.MapClaims{"sub": subject, "roles": granted}
+	policy := appauth.DefaultPolicy()
+	resolved := policy.RolesFor(subject, claims)
+	return appauth.WithAccess(appauth.WithIdentity(ctx, subject, claims), resolved, policy.PermissionsOf(resolved))
+}
+
 var _ = Describe("TaskService", func() {
 
 	Describe("GetTaskById", func() {
@@ -32,6 +83,7 @@ var _ = Describe("TaskService", func() {
 				FakeGetTaskById: func(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error) {
 					return &dbmodels.TaskSchema{ID: id, Title: "Task 1", Description: "Task 1 Description", Status: "Pending"}, nil
 				},
+				FakeGetTasks: noSubtasks,
 			}
 
 			service := NewTaskService(mockDbService)
@@ -63,10 +115,15 @@ var _ = Describe("TaskService", func() {
 	Describe("GetTasks", func() {
 		It("valid", func() {
 			mockDbService := db.MockDbService{
-				FakeGetTasks: func(ctx context.Context) ([]*dbmodels.TaskSchema, error) {
-					return []*dbmodels.TaskSchema{
-						{ID: id, Title: "Task 1", Description: "Task 1 Description", Status: "Pending"},
-						{ID: id, Title: "Task 2", Description: "Task 2 Description", Status: "Completed"},
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*dbmodels.TaskPage, error) {
+					Expect(query.Limit).To(Equal(int64(2)))
+					return &dbmodels.TaskPage{
+						Tasks: []*dbmodels.TaskSchema{
+							{ID: id, Title: "Task 1", Description: "Task 1 Description", Status: "Pending"},
+							{ID: id, Title: "Task 2", Description: "Task 2 Description", Status: "Completed"},
+						},
+						Total:      5,
+						NextCursor: "next",
 					}, nil
 				},
 			}
@@ -74,17 +131,19 @@ var _ = Describe("TaskService", func() {
 			service := NewTaskService(mockDbService)
 			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
 
-			tasks, err := service.GetTasks(ctx)
+			tasks, err := service.GetTasks(ctx, &models.TaskQuery{Limit: 2})
 
 			Expect(err).NotTo(HaveOccurred())
-			Expect(len(tasks)).To(Equal(2))
-			Expect(tasks[0].Title).To(Equal("Task 1"))
-			Expect(tasks[1].Status).To(Equal("Completed"))
+			Expect(len(tasks.Tasks)).To(Equal(2))
+			Expect(tasks.Tasks[0].Title).To(Equal("Task 1"))
+			Expect(tasks.Tasks[1].Status).To(Equal("Completed"))
+			Expect(tasks.Total).To(Equal(int64(5)))
+			Expect(tasks.NextCursor).To(Equal("next"))
 		})
 
 		It("error fetching tasks", func() {
 			mockDbService := db.MockDbService{
-				FakeGetTasks: func(ctx context.Context) ([]*dbmodels.TaskSchema, error) {
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*dbmodels.TaskPage, error) {
 					return nil, fmt.Errorf("database error")
 				},
 			}
@@ -92,7 +151,7 @@ var _ = Describe("TaskService", func() {
 			service := NewTaskService(mockDbService)
 			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
 
-			tasks, err := service.GetTasks(ctx)
+			tasks, err := service.GetTasks(ctx, &models.TaskQuery{Limit: 2})
 
 			Expect(err).To(HaveOccurred())
 			Expect(tasks).To(BeNil())
@@ -110,7 +169,7 @@ var _ = Describe("TaskService", func() {
 			service := NewTaskService(mockDbService)
 			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
 
-			task := &dbmodels.TaskSchema{Title: "New Task", Description: "New Task Description", Status: "Pending"}
+			task := &dbmodels.TaskSchema{Title: "New Task", Description: "New Task Description", Status: "New"}
 			modelTask := convertToModelTask(task)
 
 			taskId, err := service.CreateTask(ctx, modelTask)
@@ -139,10 +198,182 @@ var _ = Describe("TaskService", func() {
 		})
 	})
 
+	Describe("timestamps", func() {
+		now := time.Date(2024, 3, 1, 10, 30, 0, 123456789, time.UTC)
+		clientTime := time.Date(1999, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		It("stamps createdAt and updatedAt on insert, ignoring client values", func() {
+			var saved *dbmodels.TaskSchema
+			mockDbService := db.MockDbService{
+				FakeSaveTask: func(ctx context.Context, task *dbmodels.TaskSchema) (string, error) {
+					saved = task
+					return "1", nil
+				},
+			}
+
+			service := NewTaskService(mockDbService, WithClock(fakeClock{now: now}))
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			_, err := service.CreateTask(ctx, &models.Task{
+				ID:          primitive.NewObjectID(),
+				Title:       "New Task",
+				Description: "New Task Description",
+				Status:      "New",
+				CreatedAt:   clientTime,
+				UpdatedAt:   clientTime,
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(saved.ID.IsZero()).To(BeTrue())
+			Expect(saved.CreatedAt).To(Equal(now.Truncate(time.Millisecond)))
+			Expect(saved.UpdatedAt).To(Equal(saved.CreatedAt))
+		})
+
+		It("stamps updatedAt on update", func() {
+			var updated *dbmodels.TaskSchema
+			mockDbService := db.MockDbService{
+				FakeGetTaskById: currentTask("New"),
+				FakeUpdateTask: func(ctx context.Context, task *dbmodels.TaskSchema, taskId string, version int64) error {
+					updated = task
+					return nil
+				},
+			}
+
+			service := NewTaskService(mockDbService, WithClock(fakeClock{now: now}))
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			err := service.UpdateTask(ctx, &models.Task{
+				Title:       "Task",
+				Description: "Description",
+				Status:      "New",
+				UpdatedAt:   clientTime,
+			}, "1", 0)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.UpdatedAt).To(Equal(now.Truncate(time.Millisecond)))
+		})
+
+		It("maps timestamps back to the model", func() {
+			mockDbService := db.MockDbService{
+				FakeGetTaskById: func(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error) {
+					return &dbmodels.TaskSchema{ID: id, Title: "Task 1", CreatedAt: clientTime, UpdatedAt: now}, nil
+				},
+				FakeGetTasks: noSubtasks,
+			}
+
+			service := NewTaskService(mockDbService)
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			task, err := service.GetTaskById(ctx, id.Hex())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.CreatedAt).To(Equal(clientTime))
+			Expect(task.UpdatedAt).To(Equal(now))
+		})
+	})
+
+	Describe("validation", func() {
+		It("rejects a task without title", func() {
+			service := NewTaskService(db.MockDbService{})
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			_, err := service.CreateTask(ctx, &models.Task{Description: "New Task Description", Status: "Pending"})
+
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("rejects an update without status", func() {
+			service := NewTaskService(db.MockDbService{})
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			err := service.UpdateTask(ctx, &models.Task{Title: "Task", Description: "Description"}, "1", 0)
+
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("rejects an unknown priority", func() {
+			service := NewTaskService(db.MockDbService{})
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			_, err := service.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", Status: "New", Priority: "critical"})
+
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("rejects a start date after the due date", func() {
+			service := NewTaskService(db.MockDbService{})
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+			start := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+			due := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+			_, err := service.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", Status: "New", StartDate: &start, DueDate: &due})
+
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+	})
+
+	Describe("planning", func() {
+		It("stores the rank of the priority", func() {
+			var saved *dbmodels.TaskSchema
+			mockDbService := db.MockDbService{
+				FakeSaveTask: func(ctx context.Context, task *dbmodels.TaskSchema) (string, error) {
+					saved = task
+					return "1", nil
+				},
+			}
+			service := NewTaskService(mockDbService)
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+			due := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+			_, err := service.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", Status: "New", Priority: models.PriorityHigh, DueDate: &due})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(saved.Priority).To(Equal(models.PriorityHigh))
+			Expect(saved.PriorityRank).To(Equal(3))
+			Expect(saved.DueDate).To(Equal(&due))
+		})
+
+		It("asks for the tasks overdue at the current time", func() {
+			now := time.Date(2024, 3, 1, 10, 30, 0, 0, time.UTC)
+			var received *models.TaskQuery
+			mockDbService := db.MockDbService{
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*dbmodels.TaskPage, error) {
+					received = query
+					return &dbmodels.TaskPage{}, nil
+				},
+			}
+			service := NewTaskService(mockDbService, WithClock(fakeClock{now: now}))
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			_, err := service.GetTasks(ctx, &models.TaskQuery{Overdue: true})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(received.OverdueAt).To(Equal(now))
+		})
+
+		It("filters by the statuses as spelled in the workflow", func() {
+			var received *models.TaskQuery
+			mockDbService := db.MockDbService{
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*dbmodels.TaskPage, error) {
+					received = query
+					return &dbmodels.TaskPage{}, nil
+				},
+			}
+			service := NewTaskService(mockDbService)
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			_, err := service.GetTasks(ctx, &models.TaskQuery{Status: []string{"done", "Pending"}})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(received.Status).To(Equal([]string{"Done", "Pending"}))
+		})
+	})
+
 	Describe("UpdateTask", func() {
 		It("valid", func() {
 			mockDbService := db.MockDbService{
-				FakeUpdateTask: func(ctx context.Context, task *dbmodels.TaskSchema, taskId string) error {
+				FakeGetTaskById: currentTask("New"),
+				FakeUpdateTask: func(ctx context.Context, task *dbmodels.TaskSchema, taskId string, version int64) error {
 					return nil
 				},
 			}
@@ -150,17 +381,18 @@ var _ = Describe("TaskService", func() {
 			service := NewTaskService(mockDbService)
 			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
 
-			task := &dbmodels.TaskSchema{Title: "Updated Task", Description: "Updated Task Description", Status: "In-progress"}
+			task := &dbmodels.TaskSchema{Title: "Updated Task", Description: "Updated Task Description", Status: "InProgress"}
 			modelTask := convertToModelTask(task)
 
-			err := service.UpdateTask(ctx, modelTask, "1")
+			err := service.UpdateTask(ctx, modelTask, "1", 0)
 
 			Expect(err).NotTo(HaveOccurred())
 		})
 
 		It("error updating task", func() {
 			mockDbService := db.MockDbService{
-				FakeUpdateTask: func(ctx context.Context, task *dbmodels.TaskSchema, taskId string) error {
+				FakeGetTaskById: currentTask("New"),
+				FakeUpdateTask: func(ctx context.Context, task *dbmodels.TaskSchema, taskId string, version int64) error {
 					return fmt.Errorf("database error")
 				},
 			}
@@ -168,44 +400,587 @@ var _ = Describe("TaskService", func() {
 			service := NewTaskService(mockDbService)
 			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
 
-			task := &dbmodels.TaskSchema{Title: "Updated Task", Description: "Updated Task Description", Status: "In-progress"}
+			task := &dbmodels.TaskSchema{Title: "Updated Task", Description: "Updated Task Description", Status: "InProgress"}
 			modelTask := convertToModelTask(task)
 
-			err := service.UpdateTask(ctx, modelTask, "1")
+			err := service.UpdateTask(ctx, modelTask, "1", 0)
 
 			Expect(err).To(HaveOccurred())
 		})
 	})
 
-	Describe("DeleteTaskById", func() {
-		It("valid", func() {
+	Describe("workflow", func() {
+		It("creates tasks in the initial status", func() {
+			var saved *dbmodels.TaskSchema
 			mockDbService := db.MockDbService{
-				FakeDeleteTaskById: func(ctx context.Context, taskId string) error {
+				FakeSaveTask: func(ctx context.Context, task *dbmodels.TaskSchema) (string, error) {
+					saved = task
+					return "1", nil
+				},
+			}
+			service := NewTaskService(mockDbService)
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			_, err := service.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(saved.Status).To(Equal("New"))
+		})
+
+		It("rejects an unknown status on create", func() {
+			service := NewTaskService(db.MockDbService{})
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			_, err := service.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", Status: "Pending"})
+
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("updates along a transition with the version that was read", func() {
+			var updated *dbmodels.TaskSchema
+			var writtenVersion int64
+			mockDbService := db.MockDbService{
+				FakeGetTaskById: currentTask("Review"),
+				FakeUpdateTask: func(ctx context.Context, task *dbmodels.TaskSchema, taskId string, version int64) error {
+					updated, writtenVersion = task, version
 					return nil
 				},
 			}
+			service := NewTaskService(mockDbService)
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			err := service.UpdateTask(ctx, &models.Task{Title: "Task", Description: "Description", Status: "done"}, "1", 0)
 
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.Status).To(Equal("Done"))
+			Expect(writtenVersion).To(Equal(int64(4)))
+		})
+
+		It("updates a task stored in a legacy spelling of its status", func() {
+			var updated *dbmodels.TaskSchema
+			mockDbService := db.MockDbService{
+				FakeGetTaskById: currentTask("done"),
+				FakeUpdateTask: func(ctx context.Context, task *dbmodels.TaskSchema, taskId string, version int64) error {
+					updated = task
+					return nil
+				},
+			}
 			service := NewTaskService(mockDbService)
 			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
 
-			err := service.DeleteTaskById(ctx, "1")
+			err := service.UpdateTask(ctx, &models.Task{Title: "Renamed", Description: "Description", Status: "done"}, "1", 0)
 
 			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.Status).To(Equal("Done"))
+			Expect(service.UpdateTask(ctx, &models.Task{Title: "Task", Description: "Description", Status: "InProgress"}, "1", 0)).To(Succeed())
+		})
+
+		It("rejects an illegal transition with the allowed next statuses", func() {
+			service := NewTaskService(db.MockDbService{FakeGetTaskById: currentTask("New")})
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			err := service.UpdateTask(ctx, &models.Task{Title: "Task", Description: "Description", Status: "Done"}, "1", 0)
+
+			var appErr *apperrors.AppError
+			Expect(errors.As(err, &appErr)).To(BeTrue())
+			Expect(appErr.Code).To(Equal(apperrors.Validation))
+			Expect(appErr.Details["allowed"]).To(Equal([]string{"InProgress"}))
+		})
+
+		It("fails the precondition on a stale version before checking the transition", func() {
+			service := NewTaskService(db.MockDbService{FakeGetTaskById: currentTask("New")})
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			err := service.UpdateTask(ctx, &models.Task{Title: "Task", Description: "Description", Status: "Done"}, "1", 3)
+
+			Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+		})
+
+		It("uses the configured workflow", func() {
+			custom := &workflow.Workflow{
+				Initial:     "Open",
+				Statuses:    []string{"Open", "Closed"},
+				Transitions: map[string][]string{"Open": {"Closed"}},
+			}
+			service := NewTaskService(db.MockDbService{FakeGetTaskById: currentTask("Closed")}, WithWorkflow(custom))
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			err := service.UpdateTask(ctx, &models.Task{Title: "Task", Description: "Description", Status: "Open"}, "1", 0)
+
+			var appErr *apperrors.AppError
+			Expect(errors.As(err, &appErr)).To(BeTrue())
+			Expect(appErr.Details["allowed"]).To(Equal([]string{}))
+		})
+	})
+
+	Describe("DeleteTaskById", func() {
+		It("valid", func() {
+			var deletedVersion int64
+			clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+			mockDbService := db.MockDbService{
+				FakeGetTaskById: ownedTask("user-1"),
+				FakeGetTasks:    noSubtasks,
+				FakeTrashTask: func(ctx context.Context, taskId string, version int64, deletedAt time.Time, deletedBy string) error {
+					deletedVersion = version
+					Expect(deletedAt).To(Equal(clock.now))
+					Expect(deletedBy).To(Equal("user-1"))
+					return nil
+				},
+			}
+
+			service := NewTaskService(mockDbService, WithClock(clock))
+			ctx := asUser("user-1")
+
+			err := service.DeleteTaskById(ctx, "1", 0, false)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deletedVersion).To(Equal(int64(4)))
+		})
+
+		It("passes through not found", func() {
+			mockDbService := db.MockDbService{
+				FakeGetTaskById: func(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error) {
+					return nil, apperrors.NewNotFoundError("task 1 not found")
+				},
+			}
+
+			service := NewTaskService(mockDbService)
+			ctx := asUser("user-1")
+
+			err := service.DeleteTaskById(ctx, "1", 0, false)
+
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
 		})
 
 		It("error deleting task", func() {
 			mockDbService := db.MockDbService{
-				FakeDeleteTaskById: func(ctx context.Context, taskId string) error {
+				FakeGetTaskById: ownedTask("user-1"),
+				FakeGetTasks:    noSubtasks,
+				FakeTrashTask: func(ctx context.Context, taskId string, version int64, deletedAt time.Time, deletedBy string) error {
 					return fmt.Errorf("database error")
 				},
 			}
 
 			service := NewTaskService(mockDbService)
-			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+			ctx := asUser("user-1")
 
-			err := service.DeleteTaskById(ctx, "1")
+			err := service.DeleteTaskById(ctx, "1", 0, false)
 
 			Expect(err).To(HaveOccurred())
 		})
+
+		It("forbids deleting a task created by someone else", func() {
+			service := NewTaskService(db.MockDbService{FakeGetTaskById: ownedTask("user-1")})
+
+			err := service.DeleteTaskById(asUser("user-2"), "1", 0, false)
+
+			Expect(apperrors.Is(err, apperrors.Forbidden)).To(BeTrue())
+		})
+
+		It("lets a maintainer delete any task", func() {
+			mockDbService := db.MockDbService{
+				FakeGetTaskById: currentTask("New"),
+				FakeGetTasks:    noSubtasks,
+				FakeTrashTask: func(ctx context.Context, taskId string, version int64, deletedAt time.Time, deletedBy string) error {
+					return nil
+				},
+			}
+			service := NewTaskService(mockDbService)
+
+			err := service.DeleteTaskById(asUser("user-2", appauth.MaintainerRole), "1", 0, false)
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("fails the precondition on a stale version", func() {
+			service := NewTaskService(db.MockDbService{FakeGetTaskById: ownedTask("user-1")})
+
+			err := service.DeleteTaskById(asUser("user-1"), "1", 3, false)
+
+			Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+		})
+	})
+
+	Describe("ownership", func() {
+		It("records the creator and defaults the reporter to them", func() {
+			var saved *dbmodels.TaskSchema
+			mockDbService := db.MockDbService{
+				FakeSaveTask: func(ctx context.Context, task *dbmodels.TaskSchema) (string, error) {
+					saved = task
+					return "1", nil
+				},
+			}
+			service := NewTaskService(mockDbService)
+
+			_, err := service.CreateTask(asUser("user-1"), &models.Task{
+				Title: "Task", Description: "Description", CreatedBy: "someone-else", Assignees: []string{"user-2"},
+			})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(saved.CreatedBy).To(Equal("user-1"))
+			Expect(saved.Reporter).To(Equal("user-1"))
+			Expect(saved.Assignees).To(Equal([]string{"user-2"}))
+		})
+
+		It("rejects an empty assignee", func() {
+			service := NewTaskService(db.MockDbService{})
+
+			_, err := service.CreateTask(asUser("user-1"), &models.Task{Title: "Task", Description: "Description", Assignees: []string{" "}})
+
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("lets an assignee update the task", func() {
+			mockDbService := db.MockDbService{
+				FakeGetTaskById: func(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error) {
+					return &dbmodels.TaskSchema{Status: "New", CreatedBy: "user-1", Assignees: []string{"user-2"}}, nil
+				},
+				FakeUpdateTask: func(ctx context.Context, task *dbmodels.TaskSchema, taskId string, version int64) error {
+					return nil
+				},
+			}
+			service := NewTaskService(mockDbService)
+
+			err := service.UpdateTask(asUser("user-2"), &models.Task{Title: "Task", Description: "Description", Status: "New"}, "1", 0)
+
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("forbids updates by someone not on the task", func() {
+			service := NewTaskService(db.MockDbService{FakeGetTaskById: ownedTask("user-1")})
+
+			err := service.UpdateTask(asUser("user-3"), &models.Task{Title: "Task", Description: "Description", Status: "New"}, "1", 0)
+
+			Expect(apperrors.Is(err, apperrors.Forbidden)).To(BeTrue())
+		})
+
+		It("resolves me in the filters to the caller", func() {
+			var received *models.TaskQuery
+			mockDbService := db.MockDbService{
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*dbmodels.TaskPage, error) {
+					received = query
+					return &dbmodels.TaskPage{}, nil
+				},
+			}
+			service := NewTaskService(mockDbService)
+
+			_, err := service.GetTasks(asUser("user-1"), &models.TaskQuery{Assignee: models.CurrentUser, CreatedBy: "user-2"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(received.Assignee).To(Equal("user-1"))
+			Expect(received.CreatedBy).To(Equal("user-2"))
+		})
+
+		It("rejects me without an authenticated caller", func() {
+			service := NewTaskService(db.MockDbService{})
+			ctx, _ := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+
+			_, err := service.GetTasks(ctx, &models.TaskQuery{CreatedBy: models.CurrentUser})
+
+			Expect(apperrors.Is(err, apperrors.BadRequest)).To(BeTrue())
+		})
+	})
+
+	Describe("projects", func() {
+		projectId := primitive.NewObjectID().Hex()
+		kanban := &workflow.Workflow{
+			Initial:     "Todo",
+			Statuses:    []string{"Todo", "Doing", "Shipped"},
+			Done:        []string{"Shipped"},
+			Transitions: map[string][]string{"Todo": {"Doing"}, "Doing": {"Shipped"}},
+		}
+		projects := db.MockProjectDbService{
+			FakeGetProjectById: func(ctx context.Context, id string) (*dbmodels.ProjectSchema, error) {
+				if id != projectId {
+					return nil, apperrors.NewNotFoundError(fmt.Sprintf("project %s not found", id))
+				}
+				return &dbmodels.ProjectSchema{Key: "OPS", Workflow: kanban}, nil
+			},
+			FakeAllocateTaskKey: func(ctx context.Context, id string) (string, error) {
+				return "OPS-7", nil
+			},
+		}
+
+		It("creates the task with a key and the initial status of the project", func() {
+			var saved *dbmodels.TaskSchema
+			mockDbService := db.MockDbService{
+				FakeSaveTask: func(ctx context.Context, task *dbmodels.TaskSchema) (string, error) {
+					saved = task
+					return "1", nil
+				},
+			}
+			service := NewTaskService(mockDbService, WithProjects(projects))
+
+			_, err := service.CreateTask(asUser("user-1"), &models.Task{Title: "Task", Description: "Description", ProjectID: projectId, Key: "OPS-1"})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(saved.ProjectID).To(Equal(projectId))
+			Expect(saved.Key).To(Equal("OPS-7"))
+			Expect(saved.Status).To(Equal("Todo"))
+		})
+
+		It("rejects an unknown project", func() {
+			service := NewTaskService(db.MockDbService{}, WithProjects(projects))
+
+			_, err := service.CreateTask(asUser("user-1"), &models.Task{Title: "Task", Description: "Description", ProjectID: primitive.NewObjectID().Hex()})
+
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("checks the transitions against the workflow of the project", func() {
+			mockDbService := db.MockDbService{
+				FakeGetTaskById: func(ctx context.Context, taskId string) (*dbmodels.TaskSchema, error) {
+					return &dbmodels.TaskSchema{Title: "Task", Description: "Description", Status: "Todo", ProjectID: projectId, Version: 1}, nil
+				},
+				FakeUpdateTask: func(ctx context.Context, task *dbmodels.TaskSchema, taskId string, version int64) error {
+					return nil
+				},
+			}
+			service := NewTaskService(mockDbService, WithProjects(projects))
+
+			err := service.UpdateTask(asUser("user-1"), &models.Task{Title: "Task", Description: "Description", Status: "Shipped"}, id.Hex(), 0)
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+
+			err = service.UpdateTask(asUser("user-1"), &models.Task{Title: "Task", Description: "Description", Status: "doing"}, id.Hex(), 0)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("uses the done statuses of the project for overdue tasks", func() {
+			var received *models.TaskQuery
+			mockDbService := db.MockDbService{
+				FakeGetTasks: func(ctx context.Context, query *models.TaskQuery) (*dbmodels.TaskPage, error) {
+					received = query
+					return &dbmodels.TaskPage{}, nil
+				},
+			}
+			service := NewTaskService(mockDbService, WithProjects(projects))
+
+			_, err := service.GetTasks(asUser("user-1"), &models.TaskQuery{ProjectID: projectId, Overdue: true})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(received.DoneStatuses).To(Equal([]string{"Shipped"}))
+		})
+	})
+
+	Describe("subtasks", func() {
+		var (
+			ctx     context.Context
+			service TaskService
+		)
+
+		BeforeEach(func() {
+			ctx = asUser("user-1")
+			service = NewTaskService(db.NewKVDbService(db.NewMemoryStore()), WithMaxSubtaskDepth(3))
+		})
+
+		create := func(parentId string, status string) string {
+			taskId, err := service.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", Status: status, ParentID: parentId})
+			Expect(err).NotTo(HaveOccurred())
+			return taskId
+		}
+
+		It("rolls up the progress of the direct subtasks", func() {
+			parent := create("", "")
+			create(parent, "Done")
+			child := create(parent, "")
+			create(child, "Done")
+			create(parent, "")
+
+			task, err := service.GetTaskById(ctx, parent)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.Rollup).To(Equal(&models.SubtaskRollup{CompletedSubtasks: 1, TotalSubtasks: 3, PercentComplete: 33}))
+		})
+
+		It("rejects an unknown parent", func() {
+			_, err := service.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", ParentID: primitive.NewObjectID().Hex()})
+
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("rejects a hierarchy deeper than the maximum", func() {
+			root := create("", "")
+			child := create(root, "")
+			grandchild := create(child, "")
+
+			_, err := service.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", ParentID: grandchild})
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+
+			// moving the child under another task would push the grandchild too deep
+			other := create(create("", ""), "")
+			_, err = service.PatchTask(ctx, child, mergePatch(`{"parentId":"`+other+`"}`), 0)
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("rejects a cycle", func() {
+			root := create("", "")
+			child := create(root, "")
+
+			_, err := service.PatchTask(ctx, root, mergePatch(`{"parentId":"`+child+`"}`), 0)
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+
+			_, err = service.PatchTask(ctx, root, mergePatch(`{"parentId":"`+root+`"}`), 0)
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("keeps a task with subtasks unless the delete cascades", func() {
+			root := create("", "")
+			child := create(root, "")
+			grandchild := create(child, "")
+
+			err := service.DeleteTaskById(ctx, root, 0, false)
+			Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+
+			Expect(service.DeleteTaskById(ctx, root, 0, true)).To(Succeed())
+			for _, taskId := range []string{root, child, grandchild} {
+				_, err = service.GetTaskById(ctx, taskId)
+				Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+			}
+		})
+
+		It("does not cascade over subtasks the caller cannot delete", func() {
+			root := create("", "")
+			child, err := service.CreateTask(asUser("user-2"), &models.Task{Title: "Task", Description: "Description", ParentID: root})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = service.DeleteTaskById(ctx, root, 0, true)
+			Expect(apperrors.Is(err, apperrors.Forbidden)).To(BeTrue())
+			_, err = service.GetTaskById(ctx, child)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Describe("dependencies", func() {
+		var (
+			ctx     context.Context
+			taskDb  db.DbService
+			service TaskService
+		)
+
+		BeforeEach(func() {
+			ctx = asUser("user-1")
+			taskDb = db.NewKVDbService(db.NewMemoryStore())
+			service = NewTaskService(taskDb)
+		})
+
+		create := func(title string) string {
+			taskId, err := service.CreateTask(ctx, &models.Task{Title: title, Description: "Description"})
+			Expect(err).NotTo(HaveOccurred())
+			return taskId
+		}
+
+		It("links a blocker once and unlinks it", func() {
+			design, build := create("Design"), create("Build")
+
+			task, err := service.AddDependency(ctx, build, design, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.BlockedBy).To(Equal([]string{design}))
+			task, err = service.AddDependency(ctx, build, design, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.BlockedBy).To(HaveLen(1))
+
+			task, err = service.RemoveDependency(ctx, build, design, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.BlockedBy).To(BeEmpty())
+			_, err = service.RemoveDependency(ctx, build, design, 0)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+
+		It("rejects a link that would create a cycle", func() {
+			design, build, ship := create("Design"), create("Build"), create("Ship")
+			_, err := service.AddDependency(ctx, build, design, 0)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = service.AddDependency(ctx, ship, build, 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = service.AddDependency(ctx, design, ship, 0)
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+			_, err = service.AddDependency(ctx, design, design, 0)
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+			_, err = service.AddDependency(ctx, design, primitive.NewObjectID().Hex(), 0)
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("takes back a link that closed a cycle with a concurrent link", func() {
+			design, build := create("Design"), create("Build")
+			reads := 0
+			racing := db.MockDbService{
+				FakeGetTaskById: taskDb.GetTaskById,
+				FakePatchTask:   taskDb.PatchTask,
+				FakeGetDependencyGraph: func(ctx context.Context, taskId string) ([]*dbmodels.TaskSchema, error) {
+					graph, err := taskDb.GetDependencyGraph(ctx, taskId)
+					if reads++; reads == 1 {
+						// the link the other way is written between the check and the write of this one
+						_, err := service.AddDependency(ctx, design, build, 0)
+						Expect(err).NotTo(HaveOccurred())
+					}
+					return graph, err
+				},
+			}
+
+			_, err := NewTaskService(racing).AddDependency(ctx, build, design, 0)
+
+			Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+			task, err := service.GetTaskById(ctx, build)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.BlockedBy).To(BeEmpty())
+			task, err = service.GetTaskById(ctx, design)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.BlockedBy).To(Equal([]string{build}))
+		})
+
+		It("keeps a task out of done while a blocker is open", func() {
+			design, build := create("Design"), create("Build")
+			_, err := service.AddDependency(ctx, build, design, 0)
+			Expect(err).NotTo(HaveOccurred())
+			for _, status := range []string{"InProgress", "Review"} {
+				_, err = service.PatchTask(ctx, build, mergePatch(`{"status":"`+status+`"}`), 0)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			_, err = service.PatchTask(ctx, build, mergePatch(`{"status":"Done"}`), 0)
+			Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+			var appErr *apperrors.AppError
+			Expect(errors.As(err, &appErr)).To(BeTrue())
+			blockers := appErr.Details["blockers"].([]*models.DependencyNode)
+			Expect(blockers).To(HaveLen(1))
+			Expect(blockers[0].ID).To(Equal(design))
+
+			for _, status := range []string{"InProgress", "Review", "Done"} {
+				_, err = service.PatchTask(ctx, design, mergePatch(`{"status":"`+status+`"}`), 0)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			_, err = service.PatchTask(ctx, build, mergePatch(`{"status":"Done"}`), 0)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("returns the graph, leaves out a task in the trash and drops the links of a deleted task", func() {
+			design, build, ship := create("Design"), create("Build"), create("Ship")
+			_, err := service.AddDependency(ctx, build, design, 0)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = service.AddDependency(ctx, ship, build, 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			graph, err := service.GetDependencyGraph(ctx, ship)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(graph.Nodes).To(HaveLen(3))
+			Expect(graph.Edges).To(ConsistOf(
+				&models.DependencyEdge{From: design, To: build},
+				&models.DependencyEdge{From: build, To: ship},
+			))
+
+			Expect(service.DeleteTaskById(ctx, design, 0, false)).To(Succeed())
+			graph, err = service.GetDependencyGraph(ctx, ship)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(graph.Nodes).To(HaveLen(2))
+			task, err := service.GetTaskById(ctx, build)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.BlockedBy).To(Equal([]string{design}))
+
+			Expect(NewTrashService(taskDb, time.Hour).DeleteTrashedTask(ctx, design, 0)).To(Succeed())
+			task, err = service.GetTaskById(ctx, build)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(task.BlockedBy).To(BeEmpty())
+		})
 	})
 })
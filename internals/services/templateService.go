@@ -0,0 +1,404 @@
+package services
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// the name of a template has 200 characters at most
+const maxTemplateNameLength = 200
+
+type TemplateService interface {
+	GetTemplates(context context.Context) ([]*models.Template, error)
+	GetTemplateById(context context.Context, templateId string) (*models.Template, error)
+	CreateTemplate(context context.Context, template *models.Template) (*models.Template, error)
+	UpdateTemplate(context context.Context, template *models.Template, templateId string) (*models.Template, error)
+	DeleteTemplate(context context.Context, templateId string) error
+	// InstantiateTemplate creates the tasks of the template with the variables substituted, all of them in one write
+	InstantiateTemplate(context context.Context, templateId string, instantiation *models.TemplateInstantiation) (*models.TemplateInstance, error)
+}
+
+type templateService struct {
+	*taskService
+	templates db.TemplateDbService
+}
+
+// function to build the template service, it takes the options of the task service so the tasks it creates
+// follow the same workflows, get a key in their project and the defaults of the custom fields
+func NewTemplateService(templates db.TemplateDbService, tasks db.DbService, opts ...TaskServiceOption) TemplateService {
+	return &templateService{taskService: newTaskService(tasks, opts...), templates: templates}
+}
+
+func (s *templateService) GetTemplates(ctx context.Context) ([]*models.Template, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	templateSchemas, err := s.templates.GetTemplates(ctx)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	templates := make([]*models.Template, len(templateSchemas))
+	for i, templateSchema := range templateSchemas {
+		templates[i] = commons.MapToTemplateModel(templateSchema)
+	}
+	return templates, nil
+}
+
+func (s *templateService) GetTemplateById(ctx context.Context, templateId string) (*models.Template, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	templateSchema, err := s.templates.GetTemplateById(ctx, templateId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToTemplateModel(templateSchema), nil
+}
+
+func (s *templateService) CreateTemplate(ctx context.Context, template *models.Template) (*models.Template, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	now := s.now()
+	templateSchema := &dbmodels.TemplateSchema{CreatedBy: appauth.GetSubject(ctx), CreatedAt: now, UpdatedAt: now}
+	if err := s.setTemplateDefinition(templateSchema, template); err != nil {
+		return nil, err
+	}
+	templateId, err := s.templates.SaveTemplate(ctx, templateSchema)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	templateSchema.ID, _ = primitive.ObjectIDFromHex(templateId)
+	return commons.MapToTemplateModel(templateSchema), nil
+}
+
+// function to replace the name, the description and the tasks of the template, the tasks created from it are not changed
+func (s *templateService) UpdateTemplate(ctx context.Context, template *models.Template, templateId string) (*models.Template, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	current, err := s.templates.GetTemplateById(ctx, templateId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if err := s.setTemplateDefinition(current, template); err != nil {
+		return nil, err
+	}
+	current.UpdatedAt = s.now()
+	if err := s.templates.UpdateTemplate(ctx, current); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToTemplateModel(current), nil
+}
+
+func (s *templateService) DeleteTemplate(ctx context.Context, templateId string) error {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if err := s.templates.DeleteTemplateById(ctx, templateId); err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// function to create the tasks of the template, the root task first then its subtasks level by level. every task
+// gets its id before the write so the subtasks can refer to their parent, and they are saved in a single InsertMany
+func (s *templateService) InstantiateTemplate(ctx context.Context, templateId string, instantiation *models.TemplateInstantiation) (*models.TemplateInstance, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	templateSchema, err := s.templates.GetTemplateById(ctx, templateId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	for _, name := range templateSchema.Variables {
+		if _, ok := instantiation.Variables[name]; !ok {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("Variable %s is required", name), map[string]interface{}{"field": "variables." + name})
+		}
+	}
+	taskWorkflow, err := s.workflowFor(ctx, instantiation.ProjectID)
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) || apperrors.Is(err, apperrors.InvalidID) {
+			return nil, apperrors.NewValidationError(fmt.Sprintf("project %s not found", instantiation.ProjectID), map[string]interface{}{"field": "projectId"})
+		}
+		return nil, err
+	}
+	customFields, err := s.customFieldValues(ctx, nil, nil, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(customFields) == 0 {
+		customFields = nil
+	}
+
+	now := s.now()
+	start := now
+	if instantiation.Start != nil {
+		start = instantiation.Start.UTC().Truncate(time.Millisecond)
+	}
+	type pending struct {
+		task     *dbmodels.TemplateTaskSchema
+		parentId string
+	}
+	var tasks []*dbmodels.TaskSchema
+	for level := []pending{{task: templateSchema.Task}}; len(level) > 0; {
+		var next []pending
+		for _, item := range level {
+			taskSchema, err := s.instantiateTask(ctx, item.task, instantiation.Variables, taskWorkflow.Initial, start, now)
+			if err != nil {
+				return nil, err
+			}
+			taskSchema.ID = primitive.NewObjectID()
+			taskSchema.ProjectID = instantiation.ProjectID
+			taskSchema.ParentID = item.parentId
+			taskSchema.CustomFields = customFields
+			tasks = append(tasks, taskSchema)
+			for _, subtask := range item.task.Subtasks {
+				next = append(next, pending{task: subtask, parentId: taskSchema.ID.Hex()})
+			}
+		}
+		level = next
+	}
+	if len(instantiation.ProjectID) > 0 {
+		for _, taskSchema := range tasks {
+			if taskSchema.Key, err = s.projects.AllocateTaskKey(ctx, instantiation.ProjectID); err != nil {
+				logger.Error(err)
+				return nil, err
+			}
+		}
+	}
+
+	taskIds, err := s.dbservice.SaveTasks(ctx, tasks)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return &models.TemplateInstance{ID: taskIds[0], TaskIDs: taskIds}, nil
+}
+
+// function to build a task of the template with the variables substituted and the dates counted from start,
+// it is checked like a task created through the api
+func (s *templateService) instantiateTask(ctx context.Context, templateTask *dbmodels.TemplateTaskSchema, variables map[string]string, status string,
+	start time.Time, now time.Time) (*dbmodels.TaskSchema, error) {
+	title, err := substituteVariables(templateTask.Title, variables)
+	if err != nil {
+		return nil, err
+	}
+	description, err := substituteVariables(templateTask.Description, variables)
+	if err != nil {
+		return nil, err
+	}
+	task := &models.Task{
+		Title:            title,
+		Description:      description,
+		Status:           status,
+		Priority:         templateTask.Priority,
+		StartDate:        relativeDate(start, templateTask.StartInDays),
+		DueDate:          relativeDate(start, templateTask.DueInDays),
+		Assignees:        templateTask.Assignees,
+		Labels:           templateTask.Labels,
+		OriginalEstimate: templateTask.OriginalEstimate,
+	}
+	if err := validateTask(task); err != nil {
+		return nil, err
+	}
+
+	taskSchema := commons.MapToSchema(task)
+	taskSchema.CreatedBy = appauth.GetSubject(ctx)
+	taskSchema.Reporter = taskSchema.CreatedBy
+	taskSchema.CreatedAt = now
+	taskSchema.UpdatedAt = now
+	if taskSchema.OriginalEstimate != nil {
+		remaining := *taskSchema.OriginalEstimate
+		taskSchema.RemainingEstimate = &remaining
+	}
+	checklist := make([]models.ChecklistItem, len(templateTask.Checklist))
+	for i, text := range templateTask.Checklist {
+		checklist[i] = models.ChecklistItem{Text: text}
+	}
+	if taskSchema.Checklist, err = newChecklist(ctx, checklist, now); err != nil {
+		return nil, err
+	}
+	if len(taskSchema.Checklist) == 0 {
+		taskSchema.Checklist = nil
+	}
+	return taskSchema, nil
+}
+
+// function to check the template and set its definition on the schema, along with the variables its tasks use
+func (s *templateService) setTemplateDefinition(templateSchema *dbmodels.TemplateSchema, template *models.Template) error {
+	name := strings.TrimSpace(template.Name)
+	if len(name) == 0 {
+		return apperrors.NewValidationError("Name is required", map[string]interface{}{"field": "name"})
+	}
+	if utf8.RuneCountInString(name) > maxTemplateNameLength {
+		return apperrors.NewValidationError(fmt.Sprintf("Name must not be longer than %d characters", maxTemplateNameLength),
+			map[string]interface{}{"field": "name"})
+	}
+	if template.Task == nil {
+		return apperrors.NewValidationError("Task is required", map[string]interface{}{"field": "task"})
+	}
+	checker := &templateChecker{maxDepth: s.maxDepth}
+	if err := checker.check(template.Task, "task", 1); err != nil {
+		return err
+	}
+	templateSchema.Name = name
+	templateSchema.Description = strings.TrimSpace(template.Description)
+	templateSchema.Task = commons.MapToTemplateTaskSchema(template.Task)
+	templateSchema.Variables = checker.variables
+	return nil
+}
+
+// templateChecker walks the tasks of a template, counting them and collecting the variables they use
+type templateChecker struct {
+	maxDepth  int
+	count     int
+	variables []string
+}
+
+// function to check the task of the template and its subtasks, the text fields are trimmed and the labels
+// normalized in place. field is the path of the task in the payload, e.g. task.subtasks.0
+func (c *templateChecker) check(task *models.TemplateTask, field string, depth int) error {
+	if task == nil {
+		return apperrors.NewValidationError(fmt.Sprintf("%s is required", field), map[string]interface{}{"field": field})
+	}
+	c.count++
+	if c.count > models.MaxTemplateTasks {
+		return apperrors.NewValidationError(fmt.Sprintf("A template can have %d tasks at most", models.MaxTemplateTasks),
+			map[string]interface{}{"field": field})
+	}
+	if depth > c.maxDepth {
+		return apperrors.NewValidationError(fmt.Sprintf("Subtasks can be nested %d levels deep at most", c.maxDepth),
+			map[string]interface{}{"field": field, "maxDepth": c.maxDepth})
+	}
+
+	task.Title = strings.TrimSpace(task.Title)
+	task.Description = strings.TrimSpace(task.Description)
+	if len(task.Title) == 0 {
+		return apperrors.NewValidationError("Title is required", map[string]interface{}{"field": field + ".title"})
+	}
+	if len(task.Description) == 0 {
+		return apperrors.NewValidationError("Description is required", map[string]interface{}{"field": field + ".description"})
+	}
+	if err := c.collectVariables(task.Title, field+".title"); err != nil {
+		return err
+	}
+	if err := c.collectVariables(task.Description, field+".description"); err != nil {
+		return err
+	}
+	if !models.ValidPriority(task.Priority) {
+		return apperrors.NewValidationError("Priority must be low, medium, high or urgent", map[string]interface{}{"field": field + ".priority"})
+	}
+	for _, days := range []*int{task.StartInDays, task.DueInDays} {
+		if days != nil && (*days < -models.MaxTemplateDays || *days > models.MaxTemplateDays) {
+			return apperrors.NewValidationError(fmt.Sprintf("Relative dates must be within %d days", models.MaxTemplateDays),
+				map[string]interface{}{"field": field + ".dueInDays"})
+		}
+	}
+	if task.StartInDays != nil && task.DueInDays != nil && *task.StartInDays > *task.DueInDays {
+		return apperrors.NewValidationError("Start date must not be after the due date", map[string]interface{}{"field": field + ".startInDays"})
+	}
+	if task.OriginalEstimate != nil && *task.OriginalEstimate < 0 {
+		return apperrors.NewValidationError("Estimates must not be negative", map[string]interface{}{"field": field + ".originalEstimate"})
+	}
+	for _, assignee := range task.Assignees {
+		if len(strings.TrimSpace(assignee)) == 0 {
+			return apperrors.NewValidationError("Assignees must not be empty", map[string]interface{}{"field": field + ".assignees"})
+		}
+	}
+	labels, err := normalizeLabels(task.Labels)
+	if err != nil {
+		return err
+	}
+	task.Labels = labels
+	if len(task.Checklist) > models.MaxChecklistItems {
+		return apperrors.NewValidationError(fmt.Sprintf("A checklist cannot have more than %d items", models.MaxChecklistItems),
+			map[string]interface{}{"field": field + ".checklist"})
+	}
+	for i, item := range task.Checklist {
+		if task.Checklist[i], err = checklistText(item); err != nil {
+			return err
+		}
+	}
+
+	for i, subtask := range task.Subtasks {
+		if err := c.check(subtask, fmt.Sprintf("%s.subtasks.%d", field, i), depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// function to add the variables the text uses to the list, in order of first use. the text can only hold plain
+// text and {{.Name}} actions, so instantiating a template substitutes values and never runs anything else
+func (c *templateChecker) collectVariables(text string, field string) error {
+	parsed, err := template.New(field).Parse(text)
+	if err != nil {
+		return apperrors.NewValidationError(fmt.Sprintf("Invalid template: %v", err), map[string]interface{}{"field": field})
+	}
+	if parsed.Tree == nil {
+		return nil
+	}
+	for _, node := range parsed.Tree.Root.Nodes {
+		switch node := node.(type) {
+		case *parse.TextNode:
+		case *parse.ActionNode:
+			name, ok := variableName(node)
+			if !ok {
+				return apperrors.NewValidationError(fmt.Sprintf("Only variables like {{.Name}} are supported, not %s", node.String()),
+					map[string]interface{}{"field": field})
+			}
+			if !containsString(c.variables, name) {
+				c.variables = append(c.variables, name)
+			}
+		default:
+			return apperrors.NewValidationError(fmt.Sprintf("Only variables like {{.Name}} are supported, not %s", node.String()),
+				map[string]interface{}{"field": field})
+		}
+	}
+	return nil
+}
+
+// function to get the name of the variable an action prints, false when the action is anything else
+func variableName(node *parse.ActionNode) (string, bool) {
+	if len(node.Pipe.Decl) > 0 || len(node.Pipe.Cmds) != 1 || len(node.Pipe.Cmds[0].Args) != 1 {
+		return "", false
+	}
+	field, ok := node.Pipe.Cmds[0].Args[0].(*parse.FieldNode)
+	if !ok || len(field.Ident) != 1 {
+		return "", false
+	}
+	return field.Ident[0], true
+}
+
+// function to substitute the variables in a text checked by collectVariables
+func substituteVariables(text string, variables map[string]string) (string, error) {
+	parsed, err := template.New("text").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var substituted bytes.Buffer
+	if err := parsed.Execute(&substituted, variables); err != nil {
+		return "", apperrors.NewValidationError(fmt.Sprintf("Failed to substitute the variables: %v", err), map[string]interface{}{"field": "variables"})
+	}
+	return substituted.String(), nil
+}
+
+// function to get the date days after start, nil without days
+func relativeDate(start time.Time, days *int) *time.Time {
+	if days == nil {
+		return nil
+	}
+	date := start.AddDate(0, 0, *days)
+	return &date
+}
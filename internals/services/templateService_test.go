@@ -0,0 +1,181 @@
+package services
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/workflow"
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplateService", func() {
+	var (
+		ctx      context.Context
+		clock    *fakeClock
+		store    db.KVStore
+		taskDb   db.DbService
+		tasks    TaskService
+		projects db.ProjectDbService
+		service  TemplateService
+	)
+
+	BeforeEach(func() {
+		ctx = asUser("user-1")
+		clock = &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+		store = db.NewMemoryStore()
+		taskDb = db.NewKVDbService(store)
+		projects = db.NewKVProjectDbService(store)
+		tasks = NewTaskService(taskDb, WithProjects(projects), WithClock(clock))
+		service = NewTemplateService(db.NewKVTemplateDbService(store), taskDb, WithProjects(projects), WithClock(clock))
+	})
+
+	days := func(days int) *int {
+		return &days
+	}
+
+	onboarding := func() *models.Template {
+		estimate := int64(3600)
+		return &models.Template{Name: " Onboarding ", Task: &models.TemplateTask{
+			Title:       "Onboard {{.Customer}}",
+			Description: "Everything {{.Customer}} needs",
+			Priority:    "high",
+			Labels:      []string{"Onboarding"},
+			DueInDays:   days(14),
+			Subtasks: []*models.TemplateTask{
+				{Title: "Kickoff with {{.Contact}}", Description: "Call {{ .Contact }}", StartInDays: days(1), DueInDays: days(2),
+					OriginalEstimate: &estimate, Checklist: []string{"Agenda", "Notes"}},
+				{Title: "Accounts", Description: "Create the accounts of {{.Customer}}", Assignees: []string{"user-2"},
+					Subtasks: []*models.TemplateTask{{Title: "Admin account", Description: "For {{.Contact}}"}}},
+			},
+		}}
+	}
+
+	It("collects the variables of the titles and descriptions", func() {
+		template, err := service.CreateTemplate(ctx, onboarding())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(template.Name).To(Equal("Onboarding"))
+		Expect(template.Variables).To(Equal([]string{"Customer", "Contact"}))
+		Expect(template.CreatedBy).To(Equal("user-1"))
+		Expect(template.Task.Labels).To(Equal([]string{"onboarding"}))
+
+		update := onboarding()
+		update.Task.Subtasks = nil
+		template, err = service.UpdateTemplate(ctx, update, template.ID.Hex())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(template.Variables).To(Equal([]string{"Customer"}))
+	})
+
+	It("validates the template", func() {
+		for _, modify := range []func(template *models.Template){
+			func(template *models.Template) { template.Name = " " },
+			func(template *models.Template) { template.Task = nil },
+			func(template *models.Template) { template.Task.Subtasks[0].Title = "" },
+			func(template *models.Template) { template.Task.Subtasks[1].Priority = "someday" },
+			func(template *models.Template) { template.Task.Subtasks[0].StartInDays = days(3) },
+			func(template *models.Template) { template.Task.DueInDays = days(models.MaxTemplateDays + 1) },
+			func(template *models.Template) { template.Task.Title = "Onboard {{.Customer.Name}}" },
+			func(template *models.Template) { template.Task.Title = `{{printf "%s" .Customer}}` },
+			func(template *models.Template) { template.Task.Title = "{{if .Customer}}Onboard{{end}}" },
+			func(template *models.Template) { template.Task.Title = "Onboard {{.Customer" },
+		} {
+			template := onboarding()
+			modify(template)
+			_, err := service.CreateTemplate(ctx, template)
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue(), fmt.Sprintf("%v", err))
+		}
+
+		_, err := service.CreateTemplate(ctx, onboarding())
+		Expect(err).NotTo(HaveOccurred())
+		deep := onboarding()
+		leaf := deep.Task
+		for depth := 1; depth < models.DefaultMaxSubtaskDepth; depth++ {
+			leaf.Subtasks = []*models.TemplateTask{{Title: "Level", Description: "Level"}}
+			leaf = leaf.Subtasks[0]
+		}
+		_, err = service.CreateTemplate(ctx, deep)
+		Expect(err).NotTo(HaveOccurred())
+		leaf.Subtasks = []*models.TemplateTask{{Title: "Too deep", Description: "Too deep"}}
+		_, err = service.CreateTemplate(ctx, deep)
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+	})
+
+	It("creates the tasks with the variables substituted and the dates counted from the start", func() {
+		template, err := service.CreateTemplate(ctx, onboarding())
+		Expect(err).NotTo(HaveOccurred())
+		project, err := NewProjectService(projects, taskDb, workflow.Default()).CreateProject(ctx, &models.Project{Key: "OPS", Name: "Operations"})
+		Expect(err).NotTo(HaveOccurred())
+
+		start := time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)
+		instance, err := service.InstantiateTemplate(ctx, template.ID.Hex(), &models.TemplateInstantiation{
+			Variables: map[string]string{"Customer": "Acme", "Contact": "Jo"}, Start: &start, ProjectID: project.ID.Hex()})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(instance.TaskIDs).To(HaveLen(4))
+		Expect(instance.ID).To(Equal(instance.TaskIDs[0]))
+
+		root, err := tasks.GetTaskById(ctx, instance.ID)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(root.Title).To(Equal("Onboard Acme"))
+		Expect(root.Description).To(Equal("Everything Acme needs"))
+		Expect(root.Priority).To(Equal("high"))
+		Expect(root.Labels).To(Equal([]string{"onboarding"}))
+		Expect(*root.DueDate).To(Equal(start.AddDate(0, 0, 14)))
+		Expect(root.Key).To(Equal("OPS-1"))
+		Expect(root.Reporter).To(Equal("user-1"))
+
+		kickoff, err := tasks.GetTaskById(ctx, instance.TaskIDs[1])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kickoff.Title).To(Equal("Kickoff with Jo"))
+		Expect(kickoff.ParentID).To(Equal(instance.ID))
+		Expect(*kickoff.StartDate).To(Equal(start.AddDate(0, 0, 1)))
+		Expect(*kickoff.RemainingEstimate).To(Equal(int64(3600)))
+		Expect(kickoff.Checklist).To(HaveLen(2))
+		Expect(kickoff.Checklist[0].Done).To(BeFalse())
+
+		admin, err := tasks.GetTaskById(ctx, instance.TaskIDs[3])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(admin.Title).To(Equal("Admin account"))
+		Expect(admin.Description).To(Equal("For Jo"))
+		Expect(admin.ParentID).To(Equal(instance.TaskIDs[2]))
+		Expect(admin.Key).To(Equal("OPS-4"))
+	})
+
+	It("requires every variable and creates nothing without them", func() {
+		template, err := service.CreateTemplate(ctx, onboarding())
+		Expect(err).NotTo(HaveOccurred())
+		_, err = service.InstantiateTemplate(ctx, template.ID.Hex(), &models.TemplateInstantiation{Variables: map[string]string{"Customer": "Acme"}})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = service.InstantiateTemplate(ctx, template.ID.Hex(), &models.TemplateInstantiation{
+			Variables: map[string]string{"Customer": "Acme", "Contact": "Jo"}, ProjectID: "missing"})
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+
+		list, err := tasks.GetTasks(ctx, &models.TaskQuery{Limit: models.DefaultTaskLimit})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Tasks).To(BeEmpty())
+	})
+
+	It("creates the tasks in a single write", func() {
+		template, err := service.CreateTemplate(ctx, onboarding())
+		Expect(err).NotTo(HaveOccurred())
+		saved := 0
+		mockDbService := db.MockDbService{
+			FakeSaveTasks: func(ctx context.Context, tasks []*dbmodels.TaskSchema) ([]string, error) {
+				saved++
+				Expect(tasks).To(HaveLen(4))
+				Expect(tasks[0].Status).To(Equal(workflow.Default().Initial))
+				Expect(tasks[0].CreatedAt).To(Equal(clock.now))
+				return nil, fmt.Errorf("SaveTasks-error")
+			},
+		}
+		service = NewTemplateService(db.NewKVTemplateDbService(store), mockDbService, WithClock(clock))
+		_, err = service.InstantiateTemplate(ctx, template.ID.Hex(), &models.TemplateInstantiation{
+			Variables: map[string]string{"Customer": "Acme", "Contact": "Jo"}})
+		Expect(err).To(MatchError("SaveTasks-error"))
+		Expect(saved).To(Equal(1))
+	})
+})
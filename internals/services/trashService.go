@@ -0,0 +1,173 @@
+package services
+
+import (
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+	"time"
+)
+
+// the expired tasks are read a page at a time
+const purgePageSize = 100
+
+// TrashService lists, restores and deletes for good the tasks TaskService.DeleteTaskById moved to the trash
+type TrashService interface {
+	// GetTrash lists the tasks in the trash, the query filters and sorts them like GetTasks does the live ones
+	GetTrash(context context.Context, query *models.TaskQuery) (*models.TaskList, error)
+	// RestoreTask moves the task out of the trash with the subtasks that went to the trash along with it
+	RestoreTask(context context.Context, taskId string, version int64) (*models.Task, error)
+	// DeleteTrashedTask deletes the task of the trash for good, with its subtasks, comments, attachments and worklogs
+	DeleteTrashedTask(context context.Context, taskId string, version int64) error
+	// PurgeExpiredTasks deletes for good the tasks that have been in the trash longer than the retention, in every
+	// workspace, and returns the number of tasks deleted. it is run by the scheduler
+	PurgeExpiredTasks(context context.Context) (int, error)
+}
+
+type trashService struct {
+	*taskService
+	retention time.Duration
+}
+
+// function to build the trash service, it takes the options of the task service so what belongs to a task
+// is deleted along with it
+func NewTrashService(dbservice db.DbService, retention time.Duration, opts ...TaskServiceOption) TrashService {
+	return &trashService{taskService: newTaskService(dbservice, opts...), retention: retention}
+}
+
+func (s *trashService) GetTrash(ctx context.Context, query *models.TaskQuery) (*models.TaskList, error) {
+	query.Deleted = true
+	return s.GetTasks(ctx, query)
+}
+
+// function to move the task out of the trash, the caller must be allowed to delete it and every subtask restored
+// with it. a subtask is only restored once its parent is, the subtasks moved to the trash on their own before it stay
+func (s *trashService) RestoreTask(ctx context.Context, taskId string, version int64) (*models.Task, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	current, err := s.dbservice.GetDeletedTaskById(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if version > 0 && current.Version != version {
+		return nil, apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", taskId))
+	}
+	if err := authorizeTaskDelete(ctx, current); err != nil {
+		return nil, err
+	}
+	if len(current.ParentID) > 0 {
+		if _, err := s.dbservice.GetTaskById(ctx, current.ParentID); err != nil {
+			if apperrors.Is(err, apperrors.NotFound) {
+				return nil, apperrors.NewConflictError(fmt.Sprintf("the parent task %s of task %s is in the trash, restore it first", current.ParentID, taskId), nil)
+			}
+			logger.Error(err)
+			return nil, err
+		}
+	}
+
+	levels, err := s.trashedSubtaskLevels(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	restored := map[string]bool{taskId: true}
+	var subtasks []*dbmodels.TaskSchema
+	for _, level := range levels {
+		for _, subtask := range level {
+			if restored[subtask.ParentID] && subtask.DeletedAt.Equal(*current.DeletedAt) {
+				if err := authorizeTaskDelete(ctx, subtask); err != nil {
+					return nil, err
+				}
+				restored[subtask.ID.Hex()] = true
+				subtasks = append(subtasks, subtask)
+			}
+		}
+	}
+
+	// the parents first so a failure never leaves a live subtask under a parent in the trash
+	if err := s.dbservice.RestoreTask(ctx, taskId, current.Version); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	for _, subtask := range subtasks {
+		err := s.dbservice.RestoreTask(ctx, subtask.ID.Hex(), 0)
+		if err != nil && !apperrors.Is(err, apperrors.NotFound) {
+			logger.Error(err)
+			return nil, err
+		}
+	}
+	return s.GetTaskById(ctx, taskId)
+}
+
+// function to delete the task of the trash for good, the caller must be allowed to delete it and its subtasks
+func (s *trashService) DeleteTrashedTask(ctx context.Context, taskId string, version int64) error {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	current, err := s.dbservice.GetDeletedTaskById(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if version > 0 && current.Version != version {
+		return apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", taskId))
+	}
+	if err := authorizeTaskDelete(ctx, current); err != nil {
+		return err
+	}
+	levels, err := s.trashedSubtaskLevels(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	for _, level := range levels {
+		for _, subtask := range level {
+			if err := authorizeTaskDelete(ctx, subtask); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := s.deleteTrashedTask(ctx, current, levels, current.Version); err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// function to walk the expired tasks of every workspace, a task that cannot be deleted is logged
+// and retried on the next run without holding up the others
+func (s *trashService) PurgeExpiredTasks(ctx context.Context) (int, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	deletedBefore := s.now().Add(-s.retention)
+	purged := 0
+	afterId := ""
+	for {
+		tasks, err := s.dbservice.GetDeletedTasks(ctx, deletedBefore, afterId, purgePageSize)
+		if err != nil {
+			logger.Error(err)
+			return purged, err
+		}
+		for _, task := range tasks {
+			deleted, err := s.purge(appauth.WithWorkspace(ctx, task.WorkspaceID), task)
+			purged += deleted
+			// a subtask is gone already when its parent came first
+			if err != nil && !apperrors.Is(err, apperrors.NotFound) {
+				logger.Errorf("failed to purge task %s: %v", task.ID.Hex(), err)
+			}
+		}
+		if len(tasks) < purgePageSize {
+			return purged, nil
+		}
+		afterId = tasks[len(tasks)-1].ID.Hex()
+	}
+}
+
+func (s *trashService) purge(ctx context.Context, task *dbmodels.TaskSchema) (int, error) {
+	levels, err := s.trashedSubtaskLevels(ctx, task.ID.Hex())
+	if err != nil {
+		return 0, err
+	}
+	return s.deleteTrashedTask(ctx, task, levels, 0)
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/db"
+	"TaskSvc/internals/models"
+	"TaskSvc/internals/workflow"
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TrashService", func() {
+	var (
+		ctx      context.Context
+		clock    *fakeClock
+		store    db.KVStore
+		taskDb   db.DbService
+		comments db.CommentDbService
+		tasks    TaskService
+		service  TrashService
+	)
+
+	BeforeEach(func() {
+		ctx = asUser("user-1")
+		clock = &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+		store = db.NewMemoryStore()
+		taskDb = db.NewKVDbService(store)
+		comments = db.NewKVCommentDbService(store)
+		tasks = NewTaskService(taskDb, WithComments(comments), WithClock(clock))
+		service = NewTrashService(taskDb, 24*time.Hour, WithComments(comments), WithClock(clock))
+	})
+
+	create := func(ctx context.Context, parentId string) string {
+		taskId, err := tasks.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", ParentID: parentId})
+		Expect(err).NotTo(HaveOccurred())
+		return taskId
+	}
+
+	trashIds := func() []string {
+		list, err := service.GetTrash(ctx, &models.TaskQuery{Limit: models.DefaultTaskLimit, SortBy: models.SortByDeletedAt})
+		Expect(err).NotTo(HaveOccurred())
+		var ids []string
+		for _, task := range list.Tasks {
+			ids = append(ids, task.ID.Hex())
+		}
+		return ids
+	}
+
+	It("restores a task with the subtasks that went to the trash along with it", func() {
+		root := create(ctx, "")
+		child := create(ctx, root)
+		grandchild := create(ctx, child)
+		earlier := create(ctx, root)
+		Expect(tasks.DeleteTaskById(ctx, earlier, 0, false)).To(Succeed())
+		clock.now = clock.now.Add(time.Minute)
+		Expect(tasks.DeleteTaskById(ctx, root, 0, true)).To(Succeed())
+		Expect(trashIds()).To(Equal([]string{earlier, root, child, grandchild}))
+
+		_, err := service.RestoreTask(ctx, child, 0)
+		Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+		_, err = service.RestoreTask(asUser("user-2"), root, 0)
+		Expect(apperrors.Is(err, apperrors.Forbidden)).To(BeTrue())
+		_, err = service.RestoreTask(ctx, root, 1)
+		Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+
+		task, err := service.RestoreTask(ctx, root, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.ID.Hex()).To(Equal(root))
+		Expect(task.DeletedAt).To(BeNil())
+		for _, taskId := range []string{child, grandchild} {
+			_, err = tasks.GetTaskById(ctx, taskId)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(trashIds()).To(Equal([]string{earlier}))
+		_, err = service.RestoreTask(ctx, root, 0)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+	})
+
+	It("deletes a task of the trash for good with its subtasks and comments", func() {
+		root := create(ctx, "")
+		child := create(ctx, root)
+		_, err := NewCommentService(comments, taskDb).AddComment(ctx, child, &models.Comment{Body: "Comment"})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = service.DeleteTrashedTask(ctx, root, 0)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		Expect(tasks.DeleteTaskById(ctx, root, 0, true)).To(Succeed())
+		err = service.DeleteTrashedTask(asUser("user-2"), root, 0)
+		Expect(apperrors.Is(err, apperrors.Forbidden)).To(BeTrue())
+
+		Expect(service.DeleteTrashedTask(ctx, root, 0)).To(Succeed())
+		Expect(trashIds()).To(BeEmpty())
+		count, err := comments.CountComments(ctx, child)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(BeZero())
+	})
+
+	It("purges the tasks past the retention in every workspace", func() {
+		teamA := appauth.WithWorkspace(ctx, "team-a")
+		expired := create(ctx, "")
+		subtask := create(ctx, expired)
+		other := create(teamA, "")
+		recent := create(ctx, "")
+		Expect(tasks.DeleteTaskById(ctx, expired, 0, true)).To(Succeed())
+		Expect(tasks.DeleteTaskById(teamA, other, 0, false)).To(Succeed())
+		clock.now = clock.now.Add(12 * time.Hour)
+		Expect(tasks.DeleteTaskById(ctx, recent, 0, false)).To(Succeed())
+
+		clock.now = clock.now.Add(13 * time.Hour)
+		purged, err := service.PurgeExpiredTasks(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(purged).To(Equal(3))
+		Expect(trashIds()).To(Equal([]string{recent}))
+		for _, taskId := range []string{expired, subtask} {
+			_, err = taskDb.GetDeletedTaskById(ctx, taskId)
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		}
+		_, err = taskDb.GetDeletedTaskById(teamA, other)
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+	})
+
+	It("keeps a project holding tasks in the trash", func() {
+		projects := db.NewKVProjectDbService(store)
+		project, err := NewProjectService(projects, taskDb, workflow.Default()).CreateProject(ctx, &models.Project{Key: "OPS", Name: "Operations"})
+		Expect(err).NotTo(HaveOccurred())
+		tasks = NewTaskService(taskDb, WithProjects(projects), WithClock(clock))
+		taskId, err := tasks.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", ProjectID: project.ID.Hex()})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tasks.DeleteTaskById(ctx, taskId, 0, false)).To(Succeed())
+
+		err = NewProjectService(projects, taskDb, workflow.Default()).DeleteProjectById(ctx, project.ID.Hex())
+		Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+		Expect(service.DeleteTrashedTask(ctx, taskId, 0)).To(Succeed())
+		Expect(NewProjectService(projects, taskDb, workflow.Default()).DeleteProjectById(ctx, project.ID.Hex())).To(Succeed())
+	})
+})
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// number of times the time spent is written again when the task changed in the meantime, like the attachments
+const worklogWriteAttempts = 3
+
+type WorklogService interface {
+	GetWorklogs(context context.Context, taskId string) ([]*models.Worklog, error)
+	// LogWork records the worklog and adds it to the time spent of the task, version is the If-Match precondition or 0
+	LogWork(context context.Context, taskId string, input *models.WorklogInput, version int64) (*models.Worklog, error)
+	StartTimer(context context.Context, taskId string) (*models.Timer, error)
+	// StopTimer stops the timer of the caller on the task and logs the time it ran
+	StopTimer(context context.Context, taskId string, note string) (*models.Worklog, error)
+	// GetTimers returns the timers the caller is running
+	GetTimers(context context.Context) ([]*models.Timer, error)
+	GetTimeReport(context context.Context, query *models.TimeReportQuery) (*models.TimeReport, error)
+}
+
+type worklogService struct {
+	worklogs db.WorklogDbService
+	tasks    db.DbService
+	clock    commons.Clock
+}
+
+func NewWorklogService(worklogs db.WorklogDbService, tasks db.DbService) WorklogService {
+	return &worklogService{worklogs: worklogs, tasks: tasks, clock: commons.SystemClock}
+}
+
+func (s *worklogService) GetWorklogs(ctx context.Context, taskId string) ([]*models.Worklog, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	// the task must be visible to the caller, its worklogs are only reached through it
+	if _, err := s.tasks.GetTaskById(ctx, taskId); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	worklogSchemas, err := s.worklogs.GetWorklogs(ctx, taskId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	worklogs := make([]*models.Worklog, len(worklogSchemas))
+	for i, worklogSchema := range worklogSchemas {
+		worklogs[i] = commons.MapToWorklogModel(worklogSchema)
+	}
+	return worklogs, nil
+}
+
+func (s *worklogService) LogWork(ctx context.Context, taskId string, input *models.WorklogInput, version int64) (*models.Worklog, error) {
+	if input.Duration <= 0 {
+		return nil, apperrors.NewValidationError("Duration must be a positive number of seconds", map[string]interface{}{"field": "duration"})
+	}
+	if err := validateWorklogNote(input.Note); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	started := now.Add(-time.Duration(input.Duration) * time.Second)
+	if input.Started != nil {
+		started = input.Started.UTC().Truncate(time.Millisecond)
+	}
+	if started.After(now) {
+		return nil, apperrors.NewValidationError("Started must not be in the future", map[string]interface{}{"field": "started"})
+	}
+
+	current, err := s.writableTask(ctx, taskId, version)
+	if err != nil {
+		return nil, err
+	}
+	worklog := &dbmodels.WorklogSchema{
+		TaskID:    taskId,
+		ProjectID: current.ProjectID,
+		Author:    appauth.GetSubject(ctx),
+		Started:   started,
+		Duration:  input.Duration,
+		Note:      input.Note,
+		CreatedAt: now,
+	}
+	if err := s.logWork(ctx, current, worklog, version); err != nil {
+		return nil, err
+	}
+	return commons.MapToWorklogModel(worklog), nil
+}
+
+func (s *worklogService) StartTimer(ctx context.Context, taskId string) (*models.Timer, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if _, err := s.writableTask(ctx, taskId, 0); err != nil {
+		return nil, err
+	}
+	timer := &dbmodels.TimerSchema{TaskID: taskId, Subject: appauth.GetSubject(ctx), StartedAt: s.now()}
+	timerId, err := s.worklogs.StartTimer(ctx, timer)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	timer.ID, _ = primitive.ObjectIDFromHex(timerId)
+	return commons.MapToTimerModel(timer), nil
+}
+
+// function to stop the timer and log the time it ran, a second at least. the timer is started again
+// when the worklog cannot be recorded, so the time is not lost
+func (s *worklogService) StopTimer(ctx context.Context, taskId string, note string) (*models.Worklog, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if err := validateWorklogNote(note); err != nil {
+		return nil, err
+	}
+	current, err := s.writableTask(ctx, taskId, 0)
+	if err != nil {
+		return nil, err
+	}
+	timer, err := s.worklogs.StopTimer(ctx, appauth.GetSubject(ctx), taskId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	now := s.now()
+	duration := int64(now.Sub(timer.StartedAt) / time.Second)
+	if duration < 1 {
+		duration = 1
+	}
+	worklog := &dbmodels.WorklogSchema{
+		TaskID:    taskId,
+		ProjectID: current.ProjectID,
+		Author:    timer.Subject,
+		Started:   timer.StartedAt,
+		Duration:  duration,
+		Note:      note,
+		CreatedAt: now,
+	}
+	if err := s.logWork(ctx, current, worklog, 0); err != nil {
+		if _, restartErr := s.worklogs.StartTimer(ctx, timer); restartErr != nil {
+			logger.Error(restartErr)
+		}
+		return nil, err
+	}
+	return commons.MapToWorklogModel(worklog), nil
+}
+
+func (s *worklogService) GetTimers(ctx context.Context) ([]*models.Timer, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	timerSchemas, err := s.worklogs.GetTimers(ctx, appauth.GetSubject(ctx))
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	timers := make([]*models.Timer, len(timerSchemas))
+	for i, timerSchema := range timerSchemas {
+		timers[i] = commons.MapToTimerModel(timerSchema)
+	}
+	return timers, nil
+}
+
+func (s *worklogService) GetTimeReport(ctx context.Context, query *models.TimeReportQuery) (*models.TimeReport, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	var err error
+	if query.User, err = resolveCurrentUser(ctx, query.User, "user"); err != nil {
+		return nil, err
+	}
+	rowSchemas, err := s.worklogs.GetTimeReport(ctx, query)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	report := &models.TimeReport{GroupBy: query.GroupBy, Rows: make([]*models.TimeReportRow, len(rowSchemas))}
+	if report.GroupBy == nil {
+		report.GroupBy = []string{}
+	}
+	for i, rowSchema := range rowSchemas {
+		report.Rows[i] = &models.TimeReportRow{
+			User:      rowSchema.Key.User,
+			ProjectID: rowSchema.Key.ProjectID,
+			TaskID:    rowSchema.Key.TaskID,
+			Day:       rowSchema.Key.Day,
+			Duration:  rowSchema.Duration,
+			Worklogs:  rowSchema.Worklogs,
+		}
+		report.Duration += rowSchema.Duration
+	}
+	return report, nil
+}
+
+// function to read the task the caller wants to log work on, version is the If-Match precondition or 0
+func (s *worklogService) writableTask(ctx context.Context, taskId string, version int64) (*dbmodels.TaskSchema, error) {
+	current, err := s.tasks.GetTaskById(ctx, taskId)
+	if err != nil {
+		apploggers.GetLoggerWithCorrelationid(ctx).Error(err)
+		return nil, err
+	}
+	if version > 0 && current.Version != version {
+		return nil, apperrors.NewPreconditionFailedError(fmt.Sprintf("task %s has been modified", taskId))
+	}
+	if err := authorizeTaskWrite(ctx, current); err != nil {
+		return nil, err
+	}
+	return current, nil
+}
+
+// function to save the worklog, then add its duration to the time spent of the task and take it off the remaining
+// estimate. the worklog is removed again when the task cannot be updated, so the time spent stays the sum of the worklogs
+func (s *worklogService) logWork(ctx context.Context, current *dbmodels.TaskSchema, worklog *dbmodels.WorklogSchema, version int64) error {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	worklogId, err := s.worklogs.SaveWorklog(ctx, worklog)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	worklog.ID, _ = primitive.ObjectIDFromHex(worklogId)
+
+	taskId := worklog.TaskID
+	for attempt := 1; ; attempt++ {
+		fields := map[string]interface{}{"timeSpent": current.TimeSpent + worklog.Duration, "updatedAt": s.now()}
+		if current.RemainingEstimate != nil {
+			remaining := *current.RemainingEstimate - worklog.Duration
+			if remaining < 0 {
+				remaining = 0
+			}
+			fields["remainingEstimate"] = &remaining
+		}
+		_, err = s.tasks.PatchTask(ctx, taskId, fields, current.Version)
+		// without a precondition a concurrent change of the task is not a reason to lose the worklog
+		if err == nil || version > 0 || attempt == worklogWriteAttempts || !apperrors.Is(err, apperrors.PreconditionFailed) {
+			break
+		}
+		if current, err = s.tasks.GetTaskById(ctx, taskId); err != nil {
+			break
+		}
+	}
+	if err != nil {
+		logger.Error(err)
+		if deleteErr := s.worklogs.DeleteWorklogById(ctx, taskId, worklogId); deleteErr != nil {
+			logger.Error(deleteErr)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *worklogService) now() time.Time {
+	return s.clock.Now().UTC().Truncate(time.Millisecond)
+}
+
+func validateWorklogNote(note string) error {
+	if utf8.RuneCountInString(note) > models.MaxWorklogNoteLength {
+		return apperrors.NewValidationError(fmt.Sprintf("The note must not be longer than %d characters", models.MaxWorklogNoteLength),
+			map[string]interface{}{"field": "note"})
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WorklogService", func() {
+	var (
+		ctx      context.Context
+		clock    *fakeClock
+		tasks    TaskService
+		taskDb   db.DbService
+		service  WorklogService
+		worklogs db.WorklogDbService
+		taskId   string
+	)
+
+	BeforeEach(func() {
+		ctx = asUser("user-1")
+		clock = &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+		store := db.NewMemoryStore()
+		taskDb = db.NewKVDbService(store)
+		worklogs = db.NewKVWorklogDbService(store)
+		tasks = NewTaskService(taskDb, WithWorklogs(worklogs), WithClock(clock))
+		service = NewWorklogService(worklogs, taskDb)
+		service.(*worklogService).clock = clock
+
+		estimate := int64(3600)
+		var err error
+		taskId, err = tasks.CreateTask(ctx, &models.Task{Title: "Task", Description: "Description", OriginalEstimate: &estimate})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("adds the logged work to the time spent and takes it off the remaining estimate", func() {
+		worklog, err := service.LogWork(ctx, taskId, &models.WorklogInput{Duration: 1800, Note: "review"}, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(worklog.Author).To(Equal("user-1"))
+		Expect(worklog.Started).To(Equal(clock.now.Add(-30 * time.Minute)))
+
+		started := clock.now.Add(-24 * time.Hour)
+		_, err = service.LogWork(ctx, taskId, &models.WorklogInput{Duration: 2400, Started: &started}, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.TimeSpent).To(Equal(int64(4200)))
+		Expect(*task.OriginalEstimate).To(Equal(int64(3600)))
+		Expect(*task.RemainingEstimate).To(Equal(int64(0)))
+
+		logged, err := service.GetWorklogs(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(logged).To(HaveLen(2))
+		Expect(logged[0].Started).To(Equal(started))
+	})
+
+	It("validates the worklog and the precondition", func() {
+		_, err := service.LogWork(ctx, taskId, &models.WorklogInput{Duration: 0}, 0)
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		future := clock.now.Add(time.Minute)
+		_, err = service.LogWork(ctx, taskId, &models.WorklogInput{Duration: 60, Started: &future}, 0)
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = service.LogWork(ctx, taskId, &models.WorklogInput{Duration: 60, Note: strings.Repeat("a", models.MaxWorklogNoteLength+1)}, 0)
+		Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		_, err = service.LogWork(ctx, taskId, &models.WorklogInput{Duration: 60}, 7)
+		Expect(apperrors.Is(err, apperrors.PreconditionFailed)).To(BeTrue())
+		_, err = service.LogWork(asUser("user-2"), taskId, &models.WorklogInput{Duration: 60}, 0)
+		Expect(apperrors.Is(err, apperrors.Forbidden)).To(BeTrue())
+	})
+
+	It("logs the time a timer ran when it is stopped", func() {
+		timer, err := service.StartTimer(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timer.StartedAt).To(Equal(clock.now))
+		_, err = service.StartTimer(ctx, taskId)
+		Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+
+		timers, err := service.GetTimers(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(timers).To(HaveLen(1))
+
+		clock.now = clock.now.Add(25 * time.Minute)
+		worklog, err := service.StopTimer(ctx, taskId, "pairing")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(worklog.Duration).To(Equal(int64(1500)))
+		Expect(worklog.Note).To(Equal("pairing"))
+		_, err = service.StopTimer(ctx, taskId, "")
+		Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+
+		task, err := tasks.GetTaskById(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(task.TimeSpent).To(Equal(int64(1500)))
+	})
+
+	It("removes the worklog when the task cannot be updated", func() {
+		mockDbService := db.MockDbService{
+			FakeGetTaskById: ownedTask("user-1"),
+			FakePatchTask: func(ctx context.Context, taskId string, fields map[string]interface{}, version int64) (*dbmodels.TaskSchema, error) {
+				return nil, fmt.Errorf("PatchTask-error")
+			},
+		}
+		service = NewWorklogService(worklogs, mockDbService)
+		_, err := service.LogWork(ctx, taskId, &models.WorklogInput{Duration: 60}, 0)
+		Expect(err).To(MatchError("PatchTask-error"))
+
+		logged, err := worklogs.GetWorklogs(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(logged).To(BeEmpty())
+	})
+
+	It("deletes the worklogs along with the task", func() {
+		_, err := service.LogWork(ctx, taskId, &models.WorklogInput{Duration: 60}, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tasks.DeleteTaskById(ctx, taskId, 0, false)).To(Succeed())
+		Expect(NewTrashService(taskDb, time.Hour, WithWorklogs(worklogs)).DeleteTrashedTask(ctx, taskId, 0)).To(Succeed())
+
+		logged, err := worklogs.GetWorklogs(ctx, taskId)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(logged).To(BeEmpty())
+	})
+
+	It("sums the time logged by group", func() {
+		_, err := service.LogWork(ctx, taskId, &models.WorklogInput{Duration: 60}, 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = service.LogWork(ctx, taskId, &models.WorklogInput{Duration: 120}, 0)
+		Expect(err).NotTo(HaveOccurred())
+
+		report, err := service.GetTimeReport(ctx, &models.TimeReportQuery{User: models.CurrentUser, GroupBy: []string{models.TimeReportByUser}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report.Duration).To(Equal(int64(180)))
+		Expect(report.Rows).To(Equal([]*models.TimeReportRow{{User: "user-1", Duration: 180, Worklogs: 2}}))
+	})
+})
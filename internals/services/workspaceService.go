@@ -0,0 +1,397 @@
+package services
+
+import (
+	"TaskSvc/commons"
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/commons/apploggers"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// workspace ids are sent in the X-Workspace-Id header and the token, they are kept to url-safe slugs
+var workspaceIdPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,63}$`)
+
+type WorkspaceService interface {
+	GetWorkspaces(context context.Context) ([]*models.Workspace, error)
+	GetWorkspaceById(context context.Context, workspaceId string) (*models.Workspace, error)
+	CreateWorkspace(context context.Context, workspace *models.Workspace) (*models.Workspace, error)
+	UpdateWorkspace(context context.Context, workspace *models.Workspace, workspaceId string) (*models.Workspace, error)
+	DeleteWorkspaceById(context context.Context, workspaceId string) error
+
+	GetMembers(context context.Context, workspaceId string) ([]*models.Membership, error)
+	AddMember(context context.Context, workspaceId string, membership *models.Membership) (*models.Membership, error)
+	UpdateMember(context context.Context, workspaceId string, membership *models.Membership) (*models.Membership, error)
+	RemoveMember(context context.Context, workspaceId string, subject string) error
+
+	// GetMembershipRole looks up the role of the subject in the workspace without any access check,
+	// it is empty when the subject is not a member
+	GetMembershipRole(context context.Context, workspaceId string, subject string) (string, error)
+}
+
+type workspaceService struct {
+	dbservice db.WorkspaceDbService
+	tasks     db.DbService
+	policy    *appauth.Policy
+	clock     commons.Clock
+}
+
+// function to build the workspace service, the tasks are only read to refuse deleting a workspace that still has some
+func NewWorkspaceService(dbservice db.WorkspaceDbService, tasks db.DbService, policy *appauth.Policy) WorkspaceService {
+	return &workspaceService{dbservice: dbservice, tasks: tasks, policy: policy, clock: commons.SystemClock}
+}
+
+// function to list the workspaces the caller is a member of, every workspace for callers administering workspaces
+func (s *workspaceService) GetWorkspaces(ctx context.Context) ([]*models.Workspace, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	var workspaceIds []string
+	if !appauth.HasPermission(ctx, appauth.PermissionWorkspaceAdmin) {
+		memberships, err := s.dbservice.GetMembershipsOf(ctx, appauth.GetSubject(ctx))
+		if err != nil {
+			logger.Error(err)
+			return nil, err
+		}
+		workspaceIds = []string{}
+		for _, membership := range memberships {
+			workspaceIds = append(workspaceIds, membership.WorkspaceID)
+		}
+	}
+	workspaceSchemas, err := s.dbservice.GetWorkspaces(ctx, workspaceIds)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	workspaces := make([]*models.Workspace, len(workspaceSchemas))
+	for i, workspaceSchema := range workspaceSchemas {
+		workspaces[i] = commons.MapToWorkspaceModel(workspaceSchema)
+	}
+	return workspaces, nil
+}
+
+func (s *workspaceService) GetWorkspaceById(ctx context.Context, workspaceId string) (*models.Workspace, error) {
+	workspaceSchema, err := s.getWorkspace(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeMember(ctx, workspaceId); err != nil {
+		return nil, err
+	}
+	return commons.MapToWorkspaceModel(workspaceSchema), nil
+}
+
+// function to create the workspace, the caller becomes its first owner
+func (s *workspaceService) CreateWorkspace(ctx context.Context, workspace *models.Workspace) (*models.Workspace, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if len(strings.TrimSpace(workspace.Name)) == 0 {
+		return nil, apperrors.NewValidationError("Name is required", map[string]interface{}{"field": "name"})
+	}
+	if len(workspace.ID) == 0 {
+		workspace.ID = primitive.NewObjectID().Hex()
+	}
+	if !workspaceIdPattern.MatchString(workspace.ID) {
+		return nil, apperrors.NewValidationError("Id must be lowercase letters, digits and dashes, at most 64 characters",
+			map[string]interface{}{"field": "id"})
+	}
+	if workspace.ID == appauth.DefaultWorkspace {
+		return nil, apperrors.NewConflictError(fmt.Sprintf("workspace %s already exists", workspace.ID), nil)
+	}
+
+	now := s.now()
+	subject := appauth.GetSubject(ctx)
+	workspaceSchema := &dbmodels.WorkspaceSchema{
+		ID:        workspace.ID,
+		Name:      strings.TrimSpace(workspace.Name),
+		CreatedBy: subject,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.dbservice.SaveWorkspace(ctx, workspaceSchema); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	membership := &dbmodels.MembershipSchema{
+		WorkspaceID: workspaceSchema.ID,
+		Subject:     subject,
+		Role:        appauth.OwnerRole,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.dbservice.SaveMembership(ctx, membership); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToWorkspaceModel(workspaceSchema), nil
+}
+
+func (s *workspaceService) UpdateWorkspace(ctx context.Context, workspace *models.Workspace, workspaceId string) (*models.Workspace, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if len(strings.TrimSpace(workspace.Name)) == 0 {
+		return nil, apperrors.NewValidationError("Name is required", map[string]interface{}{"field": "name"})
+	}
+	workspaceSchema, err := s.getWorkspace(ctx, workspaceId)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeOwner(ctx, workspaceId); err != nil {
+		return nil, err
+	}
+	workspaceSchema.Name = strings.TrimSpace(workspace.Name)
+	workspaceSchema.UpdatedAt = s.now()
+	if err := s.dbservice.UpdateWorkspace(ctx, workspaceSchema); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToWorkspaceModel(workspaceSchema), nil
+}
+
+// function to delete the workspace with its memberships, a workspace still holding tasks is kept,
+// those in the trash included
+func (s *workspaceService) DeleteWorkspaceById(ctx context.Context, workspaceId string) error {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if _, err := s.getWorkspace(ctx, workspaceId); err != nil {
+		return err
+	}
+	if err := s.authorizeOwner(ctx, workspaceId); err != nil {
+		return err
+	}
+	page, err := s.tasks.GetTasks(appauth.WithWorkspace(ctx, workspaceId), &models.TaskQuery{Limit: 1, SortBy: models.SortByCreatedAt})
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if page.Total > 0 {
+		return apperrors.NewConflictError(fmt.Sprintf("workspace %s still has %d tasks", workspaceId, page.Total), nil)
+	}
+	trashed, err := s.tasks.GetTasks(appauth.WithWorkspace(ctx, workspaceId), &models.TaskQuery{Limit: 1, SortBy: models.SortByCreatedAt, Deleted: true})
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if trashed.Total > 0 {
+		return apperrors.NewConflictError(fmt.Sprintf("workspace %s still has %d tasks in the trash", workspaceId, trashed.Total), nil)
+	}
+	if err := s.dbservice.DeleteMemberships(ctx, workspaceId); err != nil {
+		logger.Error(err)
+		return err
+	}
+	if err := s.dbservice.DeleteWorkspaceById(ctx, workspaceId); err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+func (s *workspaceService) GetMembers(ctx context.Context, workspaceId string) ([]*models.Membership, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if _, err := s.getWorkspace(ctx, workspaceId); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeMember(ctx, workspaceId); err != nil {
+		return nil, err
+	}
+	membershipSchemas, err := s.dbservice.GetMemberships(ctx, workspaceId)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	memberships := make([]*models.Membership, len(membershipSchemas))
+	for i, membershipSchema := range membershipSchemas {
+		memberships[i] = commons.MapToMembershipModel(membershipSchema)
+	}
+	return memberships, nil
+}
+
+func (s *workspaceService) AddMember(ctx context.Context, workspaceId string, membership *models.Membership) (*models.Membership, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if err := s.validateMembership(membership); err != nil {
+		return nil, err
+	}
+	if _, err := s.getWorkspace(ctx, workspaceId); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeOwner(ctx, workspaceId); err != nil {
+		return nil, err
+	}
+	now := s.now()
+	membershipSchema := &dbmodels.MembershipSchema{
+		WorkspaceID: workspaceId,
+		Subject:     membership.Subject,
+		Role:        membership.Role,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.dbservice.SaveMembership(ctx, membershipSchema); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToMembershipModel(membershipSchema), nil
+}
+
+// function to change the role of a member, the last owner of the workspace keeps the role
+func (s *workspaceService) UpdateMember(ctx context.Context, workspaceId string, membership *models.Membership) (*models.Membership, error) {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if err := s.validateMembership(membership); err != nil {
+		return nil, err
+	}
+	if _, err := s.getWorkspace(ctx, workspaceId); err != nil {
+		return nil, err
+	}
+	if err := s.authorizeOwner(ctx, workspaceId); err != nil {
+		return nil, err
+	}
+	current, err := s.dbservice.GetMembership(ctx, workspaceId, membership.Subject)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	if current.Role == appauth.OwnerRole && membership.Role != appauth.OwnerRole {
+		if err := s.checkOtherOwner(ctx, workspaceId, membership.Subject); err != nil {
+			return nil, err
+		}
+	}
+	current.Role = membership.Role
+	current.UpdatedAt = s.now()
+	if err := s.dbservice.UpdateMembership(ctx, current); err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	return commons.MapToMembershipModel(current), nil
+}
+
+// function to remove a member, the last owner of the workspace cannot be removed
+func (s *workspaceService) RemoveMember(ctx context.Context, workspaceId string, subject string) error {
+	logger := apploggers.GetLoggerWithCorrelationid(ctx)
+	if _, err := s.getWorkspace(ctx, workspaceId); err != nil {
+		return err
+	}
+	if err := s.authorizeOwner(ctx, workspaceId); err != nil {
+		return err
+	}
+	current, err := s.dbservice.GetMembership(ctx, workspaceId, subject)
+	if err != nil {
+		logger.Error(err)
+		return err
+	}
+	if current.Role == appauth.OwnerRole {
+		if err := s.checkOtherOwner(ctx, workspaceId, subject); err != nil {
+			return err
+		}
+	}
+	if err := s.dbservice.DeleteMembership(ctx, workspaceId, subject); err != nil {
+		logger.Error(err)
+		return err
+	}
+	return nil
+}
+
+// function to look up the role of the subject, the workspace must exist
+func (s *workspaceService) GetMembershipRole(ctx context.Context, workspaceId string, subject string) (string, error) {
+	if _, err := s.getWorkspace(ctx, workspaceId); err != nil {
+		return "", err
+	}
+	if len(subject) == 0 {
+		return "", nil
+	}
+	membership, err := s.dbservice.GetMembership(ctx, workspaceId, subject)
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return membership.Role, nil
+}
+
+func (s *workspaceService) getWorkspace(ctx context.Context, workspaceId string) (*dbmodels.WorkspaceSchema, error) {
+	workspaceSchema, err := s.dbservice.GetWorkspaceById(ctx, workspaceId)
+	if err != nil {
+		apploggers.GetLoggerWithCorrelationid(ctx).Error(err)
+		return nil, err
+	}
+	return workspaceSchema, nil
+}
+
+// function to check the caller is a member of the workspace or administers every workspace,
+// non members get not found so the workspace ids of other teams are not disclosed
+func (s *workspaceService) authorizeMember(ctx context.Context, workspaceId string) error {
+	role, err := s.callerRole(ctx, workspaceId)
+	if err != nil {
+		return err
+	}
+	if len(role) == 0 && !appauth.HasPermission(ctx, appauth.PermissionWorkspaceAdmin) {
+		return apperrors.NewNotFoundError(fmt.Sprintf("workspace %s not found", workspaceId))
+	}
+	return nil
+}
+
+// function to check the caller is an owner of the workspace or administers every workspace
+func (s *workspaceService) authorizeOwner(ctx context.Context, workspaceId string) error {
+	if appauth.HasPermission(ctx, appauth.PermissionWorkspaceAdmin) {
+		return nil
+	}
+	role, err := s.callerRole(ctx, workspaceId)
+	if err != nil {
+		return err
+	}
+	if len(role) == 0 {
+		return apperrors.NewNotFoundError(fmt.Sprintf("workspace %s not found", workspaceId))
+	}
+	if role != appauth.OwnerRole {
+		return apperrors.NewForbiddenError(fmt.Sprintf("only an owner of workspace %s can manage it", workspaceId))
+	}
+	return nil
+}
+
+func (s *workspaceService) callerRole(ctx context.Context, workspaceId string) (string, error) {
+	subject := appauth.GetSubject(ctx)
+	if len(subject) == 0 {
+		return "", nil
+	}
+	membership, err := s.dbservice.GetMembership(ctx, workspaceId, subject)
+	if err != nil {
+		if apperrors.Is(err, apperrors.NotFound) {
+			return "", nil
+		}
+		apploggers.GetLoggerWithCorrelationid(ctx).Error(err)
+		return "", err
+	}
+	return membership.Role, nil
+}
+
+// function to check another member of the workspace is an owner, so it is never left without one
+func (s *workspaceService) checkOtherOwner(ctx context.Context, workspaceId string, subject string) error {
+	memberships, err := s.dbservice.GetMemberships(ctx, workspaceId)
+	if err != nil {
+		apploggers.GetLoggerWithCorrelationid(ctx).Error(err)
+		return err
+	}
+	for _, membership := range memberships {
+		if membership.Subject != subject && membership.Role == appauth.OwnerRole {
+			return nil
+		}
+	}
+	return apperrors.NewConflictError(fmt.Sprintf("%s is the last owner of workspace %s", subject, workspaceId), nil)
+}
+
+func (s *workspaceService) validateMembership(membership *models.Membership) error {
+	if len(strings.TrimSpace(membership.Subject)) == 0 {
+		return apperrors.NewValidationError("Subject is required", map[string]interface{}{"field": "subject"})
+	}
+	if !s.policy.IsWorkspaceRole(membership.Role) {
+		return apperrors.NewValidationError(fmt.Sprintf("Workspace role %s is not defined by the policy", membership.Role),
+			map[string]interface{}{"field": "role"})
+	}
+	return nil
+}
+
+func (s *workspaceService) now() time.Time {
+	return s.clock.Now().UTC().Truncate(time.Millisecond)
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"TaskSvc/commons/appauth"
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/db"
+	dbmodels "TaskSvc/internals/db/models"
+	"TaskSvc/internals/models"
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// function to fake the memberships of a workspace, keyed by subject
+func workspaceMembers(members map[string]string) func(ctx context.Context, workspaceId string, subject string) (*dbmodels.MembershipSchema, error) {
+	return func(ctx context.Context, workspaceId string, subject string) (*dbmodels.MembershipSchema, error) {
+		role, ok := members[subject]
+		if !ok {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("%s is not a member of workspace %s", subject, workspaceId))
+		}
+		return &dbmodels.MembershipSchema{WorkspaceID: workspaceId, Subject: subject, Role: role}, nil
+	}
+}
+
+func existingWorkspace(ctx context.Context, workspaceId string) (*dbmodels.WorkspaceSchema, error) {
+	return &dbmodels.WorkspaceSchema{ID: workspaceId, Name: "Team"}, nil
+}
+
+var _ = Describe("WorkspaceService", func() {
+	var (
+		workspaceDb db.MockWorkspaceDbService
+		taskDb      db.MockDbService
+		members     map[string]string
+		service     func() WorkspaceService
+	)
+
+	BeforeEach(func() {
+		members = map[string]string{"user-1": appauth.OwnerRole, "user-2": appauth.MemberRole}
+		workspaceDb = db.MockWorkspaceDbService{
+			FakeGetWorkspaceById: existingWorkspace,
+			FakeGetMembership:    workspaceMembers(members),
+			FakeGetMemberships: func(ctx context.Context, workspaceId string) ([]*dbmodels.MembershipSchema, error) {
+				var memberships []*dbmodels.MembershipSchema
+				for subject, role := range members {
+					memberships = append(memberships, &dbmodels.MembershipSchema{WorkspaceID: workspaceId, Subject: subject, Role: role})
+				}
+				return memberships, nil
+			},
+		}
+		taskDb = db.MockDbService{}
+		service = func() WorkspaceService {
+			return NewWorkspaceService(workspaceDb, taskDb, appauth.DefaultPolicy())
+		}
+	})
+
+	Describe("CreateWorkspace", func() {
+		It("makes the creator the first owner", func() {
+			var saved *dbmodels.MembershipSchema
+			workspaceDb.FakeSaveWorkspace = func(ctx context.Context, workspace *dbmodels.WorkspaceSchema) error {
+				return nil
+			}
+			workspaceDb.FakeSaveMembership = func(ctx context.Context, membership *dbmodels.MembershipSchema) error {
+				saved = membership
+				return nil
+			}
+			workspace, err := service().CreateWorkspace(asUser("user-3"), &models.Workspace{ID: "team-a", Name: " Team A "})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(workspace.Name).To(Equal("Team A"))
+			Expect(workspace.CreatedBy).To(Equal("user-3"))
+			Expect(saved.WorkspaceID).To(Equal("team-a"))
+			Expect(saved.Subject).To(Equal("user-3"))
+			Expect(saved.Role).To(Equal(appauth.OwnerRole))
+		})
+
+		It("rejects an invalid or reserved id", func() {
+			_, err := service().CreateWorkspace(asUser("user-3"), &models.Workspace{ID: "Team A", Name: "Team A"})
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+			_, err = service().CreateWorkspace(asUser("user-3"), &models.Workspace{ID: appauth.DefaultWorkspace, Name: "Default"})
+			Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+		})
+	})
+
+	Describe("GetWorkspaces", func() {
+		It("lists the workspaces of the caller", func() {
+			workspaceDb.FakeGetMembershipsOf = func(ctx context.Context, subject string) ([]*dbmodels.MembershipSchema, error) {
+				return []*dbmodels.MembershipSchema{{WorkspaceID: "team-a", Subject: subject}}, nil
+			}
+			workspaceDb.FakeGetWorkspaces = func(ctx context.Context, workspaceIds []string) ([]*dbmodels.WorkspaceSchema, error) {
+				Expect(workspaceIds).To(Equal([]string{"team-a"}))
+				return []*dbmodels.WorkspaceSchema{{ID: "team-a"}}, nil
+			}
+			workspaces, err := service().GetWorkspaces(asUser("user-1"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(workspaces).To(HaveLen(1))
+		})
+
+		It("lists every workspace for an admin of the deployment", func() {
+			workspaceDb.FakeGetWorkspaces = func(ctx context.Context, workspaceIds []string) ([]*dbmodels.WorkspaceSchema, error) {
+				Expect(workspaceIds).To(BeNil())
+				return []*dbmodels.WorkspaceSchema{{ID: "team-a"}, {ID: "team-b"}}, nil
+			}
+			workspaces, err := service().GetWorkspaces(asUser("root", appauth.AdminRole))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(workspaces).To(HaveLen(2))
+		})
+	})
+
+	Describe("GetWorkspaceById", func() {
+		It("hides the workspace from non members", func() {
+			_, err := service().GetWorkspaceById(asUser("stranger"), "team-a")
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+	})
+
+	Describe("UpdateWorkspace", func() {
+		It("allows only the owners of the workspace", func() {
+			workspaceDb.FakeUpdateWorkspace = func(ctx context.Context, workspace *dbmodels.WorkspaceSchema) error {
+				return nil
+			}
+			_, err := service().UpdateWorkspace(asUser("user-2"), &models.Workspace{Name: "Renamed"}, "team-a")
+			Expect(apperrors.Is(err, apperrors.Forbidden)).To(BeTrue())
+
+			workspace, err := service().UpdateWorkspace(asUser("user-1"), &models.Workspace{Name: "Renamed"}, "team-a")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(workspace.Name).To(Equal("Renamed"))
+		})
+	})
+
+	Describe("DeleteWorkspaceById", func() {
+		It("keeps a workspace that still has tasks", func() {
+			taskDb.FakeGetTasks = func(ctx context.Context, query *models.TaskQuery) (*dbmodels.TaskPage, error) {
+				Expect(appauth.GetWorkspace(ctx)).To(Equal("team-a"))
+				return &dbmodels.TaskPage{Total: 3}, nil
+			}
+			err := service().DeleteWorkspaceById(asUser("user-1"), "team-a")
+			Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+		})
+
+		It("deletes an empty workspace with its memberships", func() {
+			var deleted []string
+			taskDb.FakeGetTasks = func(ctx context.Context, query *models.TaskQuery) (*dbmodels.TaskPage, error) {
+				return &dbmodels.TaskPage{}, nil
+			}
+			workspaceDb.FakeDeleteMemberships = func(ctx context.Context, workspaceId string) error {
+				deleted = append(deleted, "memberships")
+				return nil
+			}
+			workspaceDb.FakeDeleteWorkspaceById = func(ctx context.Context, workspaceId string) error {
+				deleted = append(deleted, "workspace")
+				return nil
+			}
+			Expect(service().DeleteWorkspaceById(asUser("user-1"), "team-a")).To(Succeed())
+			Expect(deleted).To(Equal([]string{"memberships", "workspace"}))
+		})
+	})
+
+	Describe("members", func() {
+		It("rejects a role that is not a workspace role", func() {
+			_, err := service().AddMember(asUser("user-1"), "team-a", &models.Membership{Subject: "user-3", Role: appauth.AdminRole})
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("adds a member", func() {
+			workspaceDb.FakeSaveMembership = func(ctx context.Context, membership *dbmodels.MembershipSchema) error {
+				return nil
+			}
+			membership, err := service().AddMember(asUser("user-1"), "team-a", &models.Membership{Subject: "user-3", Role: appauth.ViewerRole})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(membership.WorkspaceID).To(Equal("team-a"))
+			Expect(membership.Role).To(Equal(appauth.ViewerRole))
+		})
+
+		It("keeps the last owner", func() {
+			_, err := service().UpdateMember(asUser("user-1"), "team-a", &models.Membership{Subject: "user-1", Role: appauth.MemberRole})
+			Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+			err = service().RemoveMember(asUser("user-1"), "team-a", "user-1")
+			Expect(apperrors.Is(err, apperrors.Conflict)).To(BeTrue())
+		})
+
+		It("removes an owner when another owner remains", func() {
+			members["user-4"] = appauth.OwnerRole
+			workspaceDb.FakeDeleteMembership = func(ctx context.Context, workspaceId string, subject string) error {
+				return nil
+			}
+			Expect(service().RemoveMember(asUser("user-4"), "team-a", "user-1")).To(Succeed())
+		})
+	})
+
+	Describe("GetMembershipRole", func() {
+		It("is empty for non members and fails for a missing workspace", func() {
+			role, err := service().GetMembershipRole(asUser("user-1"), "team-a", "user-2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role).To(Equal(appauth.MemberRole))
+
+			role, err = service().GetMembershipRole(asUser("user-1"), "team-a", "stranger")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(role).To(BeEmpty())
+
+			workspaceDb.FakeGetWorkspaceById = func(ctx context.Context, workspaceId string) (*dbmodels.WorkspaceSchema, error) {
+				return nil, apperrors.NewNotFoundError("workspace missing not found")
+			}
+			_, err = service().GetMembershipRole(asUser("user-1"), "missing", "user-2")
+			Expect(apperrors.Is(err, apperrors.NotFound)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,170 @@
+package workflow
+
+import (
+	"TaskSvc/commons/apperrors"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Workflow lists the task statuses and the transitions allowed between them.
+// Initial is the status of new tasks, Done the statuses of finished tasks.
+type Workflow struct {
+	Initial     string              `json:"initial"`
+	Statuses    []string            `json:"statuses"`
+	Done        []string            `json:"done"`
+	Transitions map[string][]string `json:"transitions"`
+}
+
+// function to get the built-in workflow, New -> InProgress -> Review -> Done with reopening
+func Default() *Workflow {
+	return &Workflow{
+		Initial:  "New",
+		Statuses: []string{"New", "InProgress", "Review", "Done"},
+		Done:     []string{"Done"},
+		Transitions: map[string][]string{
+			"New":        {"InProgress"},
+			"InProgress": {"Review", "New"},
+			"Review":     {"Done", "InProgress"},
+			"Done":       {"InProgress"},
+		},
+	}
+}
+
+// function to load the workflow from a json file, the built-in workflow is used when path is empty
+func Load(path string) (*Workflow, error) {
+	if len(path) == 0 {
+		return Default(), nil
+	}
+	pbytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var workflow Workflow
+	if err := json.Unmarshal(pbytes, &workflow); err != nil {
+		return nil, fmt.Errorf("invalid workflow file %s: %v", path, err)
+	}
+	if err := workflow.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid workflow file %s: %v", path, err)
+	}
+	return &workflow, nil
+}
+
+// function to check the workflow is consistent, every status it refers to must be listed
+// and spelled as in the list, so lookups by the canonical spelling find it
+func (w *Workflow) Validate() error {
+	if len(w.Statuses) == 0 {
+		return fmt.Errorf("no statuses")
+	}
+	known := map[string]bool{}
+	for _, status := range w.Statuses {
+		if known[strings.ToLower(status)] {
+			return fmt.Errorf("status %s is listed twice", status)
+		}
+		known[strings.ToLower(status)] = true
+	}
+	if !w.IsStatus(w.Initial) {
+		return fmt.Errorf("initial status %s is not a status", w.Initial)
+	}
+	referred := append([]string{w.Initial}, w.Done...)
+	for _, status := range w.Done {
+		if !w.IsStatus(status) {
+			return fmt.Errorf("done status %s is not a status", status)
+		}
+	}
+	for from, targets := range w.Transitions {
+		if !w.IsStatus(from) {
+			return fmt.Errorf("transition from unknown status %s", from)
+		}
+		for _, to := range targets {
+			if !w.IsStatus(to) {
+				return fmt.Errorf("transition from %s to unknown status %s", from, to)
+			}
+		}
+		referred = append(append(referred, from), targets...)
+	}
+	for _, status := range referred {
+		if canonical, _ := w.Canonical(status); canonical != status {
+			return fmt.Errorf("status %s is spelled %s in the statuses", status, canonical)
+		}
+	}
+	return nil
+}
+
+// function to get the spelling of the status in the workflow, statuses match case-insensitively
+func (w *Workflow) Canonical(status string) (string, bool) {
+	for _, candidate := range w.Statuses {
+		if strings.EqualFold(candidate, strings.TrimSpace(status)) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+func (w *Workflow) IsStatus(status string) bool {
+	_, ok := w.Canonical(status)
+	return ok
+}
+
+// function to check the status is a done status, tasks stored in another spelling of it are done too
+func (w *Workflow) IsDone(status string) bool {
+	canonical, ok := w.Canonical(status)
+	if !ok {
+		return false
+	}
+	for _, done := range w.Done {
+		if done == canonical {
+			return true
+		}
+	}
+	return false
+}
+
+// function to list the statuses a task can move to, tasks in a status the workflow
+// does not know, from before it was introduced, can move to any status
+func (w *Workflow) AllowedNext(from string) []string {
+	canonical, ok := w.Canonical(from)
+	if !ok {
+		return w.Statuses
+	}
+	if next, ok := w.Transitions[canonical]; ok {
+		return next
+	}
+	return []string{}
+}
+
+// function to check the status a new task is created in
+func (w *Workflow) CheckStatus(status string) (string, error) {
+	canonical, ok := w.Canonical(status)
+	if !ok {
+		return "", apperrors.NewValidationError(fmt.Sprintf("unknown status: %s", status), map[string]interface{}{
+			"field":   "status",
+			"allowed": w.Statuses,
+		})
+	}
+	return canonical, nil
+}
+
+// function to check the task can move from one status to the other, keeping the status in another
+// spelling is not a transition. returns the status as spelled in the workflow or a validation error
+// listing the allowed next statuses
+func (w *Workflow) CheckTransition(from string, to string) (string, error) {
+	canonical, err := w.CheckStatus(to)
+	if err != nil {
+		return "", err
+	}
+	if current, ok := w.Canonical(from); ok && current == canonical {
+		return canonical, nil
+	}
+	allowed := w.AllowedNext(from)
+	for _, next := range allowed {
+		if next == canonical {
+			return canonical, nil
+		}
+	}
+	return "", apperrors.NewValidationError(fmt.Sprintf("status cannot change from %s to %s", from, canonical), map[string]interface{}{
+		"field":   "status",
+		"allowed": allowed,
+	})
+}
@@ -0,0 +1,104 @@
+package workflow_test
+
+import (
+	"TaskSvc/commons/apperrors"
+	"TaskSvc/internals/workflow"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Workflow", func() {
+	writeFile := func(content string) string {
+		path := filepath.Join(GinkgoT().TempDir(), "workflow.json")
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	It("uses the built-in workflow without a file", func() {
+		loaded, err := workflow.Load("")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(workflow.Default()))
+	})
+
+	It("loads the shipped workflow file", func() {
+		loaded, err := workflow.Load("../../configs/workflow.json")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded).To(Equal(workflow.Default()))
+	})
+
+	It("loads a workflow file", func() {
+		loaded, err := workflow.Load(writeFile(`{"initial": "Open", "statuses": ["Open", "Closed"], "done": ["Closed"], "transitions": {"Open": ["Closed"]}}`))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(loaded.Initial).To(Equal("Open"))
+		Expect(loaded.AllowedNext("Open")).To(Equal([]string{"Closed"}))
+		Expect(loaded.IsDone("Closed")).To(BeTrue())
+	})
+
+	DescribeTable("rejects invalid workflow files",
+		func(content string, message string) {
+			_, err := workflow.Load(writeFile(content))
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("not json", `statuses: [Open]`, "invalid workflow file"),
+		Entry("no statuses", `{"initial": "Open"}`, "no statuses"),
+		Entry("unknown initial", `{"initial": "Draft", "statuses": ["Open"]}`, "initial status Draft is not a status"),
+		Entry("duplicate status", `{"initial": "Open", "statuses": ["Open", "open"]}`, "status open is listed twice"),
+		Entry("unknown done", `{"initial": "Open", "statuses": ["Open"], "done": ["Closed"]}`, "done status Closed is not a status"),
+		Entry("unknown target", `{"initial": "Open", "statuses": ["Open"], "transitions": {"Open": ["Closed"]}}`, "transition from Open to unknown status Closed"),
+		Entry("misspelled done", `{"initial": "Open", "statuses": ["Open", "Closed"], "done": ["CLOSED"]}`, "status CLOSED is spelled Closed in the statuses"),
+		Entry("misspelled transition", `{"initial": "Open", "statuses": ["Open", "Closed"], "transitions": {"open": ["Closed"]}}`, "status open is spelled Open in the statuses"),
+	)
+
+	Describe("CheckTransition", func() {
+		taskWorkflow := workflow.Default()
+
+		It("allows a transition and returns the canonical status", func() {
+			status, err := taskWorkflow.CheckTransition("Review", "done")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal("Done"))
+		})
+
+		It("allows keeping the status", func() {
+			status, err := taskWorkflow.CheckTransition("New", "NEW")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal("New"))
+		})
+
+		It("treats a status stored in another spelling as the canonical one", func() {
+			status, err := taskWorkflow.CheckTransition("done", "Done")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal("Done"))
+			_, err = taskWorkflow.CheckTransition("DONE", "InProgress")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(taskWorkflow.AllowedNext("done")).To(Equal([]string{"InProgress"}))
+			Expect(taskWorkflow.IsDone("DONE")).To(BeTrue())
+		})
+
+		It("allows reopening", func() {
+			_, err := taskWorkflow.CheckTransition("Done", "InProgress")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("lists the allowed next statuses of an illegal transition", func() {
+			_, err := taskWorkflow.CheckTransition("New", "Done")
+			var appErr *apperrors.AppError
+			Expect(err).To(BeAssignableToTypeOf(appErr))
+			appErr = err.(*apperrors.AppError)
+			Expect(appErr.Code).To(Equal(apperrors.Validation))
+			Expect(appErr.Details["allowed"]).To(Equal([]string{"InProgress"}))
+		})
+
+		It("rejects an unknown status", func() {
+			_, err := taskWorkflow.CheckTransition("New", "Pending")
+			Expect(apperrors.Is(err, apperrors.Validation)).To(BeTrue())
+		})
+
+		It("lets tasks in a status from before the workflow move to any status", func() {
+			_, err := taskWorkflow.CheckTransition("Pending", "Done")
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -2,35 +2,133 @@ package main
 
 import (
 	"TaskSvc/apis"
-	"TaskSvc/apis/middleware"
 	"TaskSvc/commons/apploggers"
 	"TaskSvc/configs"
 	"TaskSvc/internals/db"
+	"TaskSvc/internals/scheduler"
 	"TaskSvc/internals/services"
 	"context"
-
-	"github.com/gin-gonic/gin"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// shutdownTimeout bounds the time the requests in flight get to finish on shutdown
+const shutdownTimeout = 10 * time.Second
+
 func main() {
-	context, logger := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
-	err := configs.NewApplicationConfig(context)
+	baseCtx, logger := apploggers.NewLoggerWithCorrelationid(context.Background(), "")
+	// the context is done on SIGINT or SIGTERM, the server and the background jobs then stop
+	ctx, stop := signal.NotifyContext(baseCtx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	err := configs.NewApplicationConfig(ctx)
+	if err != nil {
+		logger.Errorf("Error in Appconfig: %v", err)
+		return
+	}
+
+	storage, err := db.NewStorage(configs.AppConfig)
 	if err != nil {
-		logger.Errorf("Error in Appconfig:", err)
+		logger.Errorf("Error in storage: %v", err)
+		return
+	}
+	// closed once the server and the jobs have stopped, with a context that is not done
+	defer storage.Close(baseCtx)
+
+	if err := storage.EnsureIndexes(ctx, configs.AppConfig.Workflow); err != nil {
+		logger.Errorf("Error in storage: %v", err)
+		return
 	}
 
-	dbService := db.NewDbService(configs.AppConfig.DbClient)
-	taskService := services.NewTaskService(dbService)
-	taskController := apis.NewTaskController(taskService)
+	tasks := storage.Tasks()
+	projects := storage.Projects()
+	comments := storage.Comments()
+	customFields := storage.CustomFields()
+	worklogs := storage.Worklogs()
+	taskService := services.NewTaskService(tasks,
+		services.WithWorkflow(configs.AppConfig.Workflow),
+		services.WithProjects(projects),
+		services.WithComments(comments),
+		services.WithBlobStore(storage.Blobs()),
+		services.WithCustomFields(customFields),
+		services.WithWorklogs(worklogs))
+	projectService := services.NewProjectService(projects, tasks, configs.AppConfig.Workflow)
+	commentService := services.NewCommentService(comments, tasks)
+	attachmentService := services.NewAttachmentService(tasks, storage.Blobs(),
+		services.WithAttachmentLimits(configs.AppConfig.AttachmentMaxSize, configs.AppConfig.AttachmentTypes))
+	labelService := services.NewLabelService(tasks)
+	checklistService := services.NewChecklistService(tasks)
+	customFieldService := services.NewCustomFieldService(customFields, tasks)
+	worklogService := services.NewWorklogService(worklogs, tasks)
+	workspaceService := services.NewWorkspaceService(storage.Workspaces(), tasks, configs.AppConfig.Policy)
+	templateService := services.NewTemplateService(storage.Templates(), tasks,
+		services.WithWorkflow(configs.AppConfig.Workflow),
+		services.WithProjects(projects),
+		services.WithCustomFields(customFields))
+	recurrenceService := services.NewRecurrenceService(tasks,
+		services.WithWorkflow(configs.AppConfig.Workflow),
+		services.WithProjects(projects))
+	trashService := services.NewTrashService(tasks, configs.AppConfig.TrashRetention,
+		services.WithWorkflow(configs.AppConfig.Workflow),
+		services.WithProjects(projects),
+		services.WithComments(comments),
+		services.WithBlobStore(storage.Blobs()),
+		services.WithCustomFields(customFields),
+		services.WithWorklogs(worklogs))
 
-	// Initialize Gin router
-	r := gin.Default()
+	// the background jobs run on one replica at a time, under a lease in the storage
+	jobs := scheduler.New(storage.Leases(), scheduler.Holder())
+	jobs.Add("recurrence", configs.AppConfig.RecurrenceInterval, func(ctx context.Context) error {
+		created, err := recurrenceService.MaterializeOccurrences(ctx)
+		if created > 0 {
+			apploggers.GetLoggerWithCorrelationid(ctx).Infof("created %d occurrences of recurring tasks", created)
+		}
+		return err
+	})
+	jobs.Add("purge", configs.AppConfig.PurgeInterval, func(ctx context.Context) error {
+		purged, err := trashService.PurgeExpiredTasks(ctx)
+		if purged > 0 {
+			apploggers.GetLoggerWithCorrelationid(ctx).Infof("purged %d tasks from the trash", purged)
+		}
+		return err
+	})
+	jobs.Start(ctx)
 
-	r.GET("/public/tasks", taskController.GetTasks)
-	r.GET("/tasks/:id", middleware.AuthenticateJWT, taskController.GetTaskById)
-	r.POST("/tasks", middleware.AuthenticateJWT, taskController.CreateTask)
-	r.PUT("/tasks/:id", middleware.AuthenticateJWT, taskController.UpdateTask)
-	r.DELETE("/tasks/:id", middleware.AuthenticateJWT, taskController.DeleteTask)
+	r := apis.NewRouter(apis.RouterConfig{
+		TokenVerifier:      configs.AppConfig.TokenVerifier,
+		Policy:             configs.AppConfig.Policy,
+		TaskService:        taskService,
+		ProjectService:     projectService,
+		CommentService:     commentService,
+		AttachmentService:  attachmentService,
+		LabelService:       labelService,
+		ChecklistService:   checklistService,
+		CustomFieldService: customFieldService,
+		TemplateService:    templateService,
+		TrashService:       trashService,
+		WorklogService:     worklogService,
+		WorkspaceService:   workspaceService,
+		Workflow:           configs.AppConfig.Workflow,
+	})
+	srv := &http.Server{Addr: ":" + configs.AppConfig.HttpPort, Handler: r}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Errorf("Error in server: %v", err)
+			stop()
+		}
+	}()
 
-	r.Run(":" + configs.AppConfig.HttpPort)
+	<-ctx.Done()
+	stop()
+	logger.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(baseCtx, shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Errorf("Error in server shutdown: %v", err)
+	}
+	// the jobs stop with the context and release their leases so another replica takes over at once
+	jobs.Wait()
 }